@@ -0,0 +1,323 @@
+/*
+ * Copyright 2025 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+package govcd
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/vmware/go-vcloud-director/v3/util"
+)
+
+// batchBusyEntityRegexp matches errors returned by VCD when an entity is locked by another
+// operation. Such operations are safe to retry.
+var batchBusyEntityRegexp = regexp.MustCompile(`(BUSY_ENTITY|is currently busy|is busy completing an operation|another transaction|entity is busy)`)
+
+// ErrBatchItemSkipped is set as the error of batch items that were not run because the batch was
+// stopped after a failure
+var ErrBatchItemSkipped = errors.New("batch item skipped after a previous failure")
+
+// BatchOperation performs a single mutation. Asynchronous operations return the task they started,
+// so that all tasks of a batch are submitted first and awaited together. Synchronous operations
+// return a nil task.
+type BatchOperation func() (*Task, error)
+
+// BatchItem is a named operation in a batch
+type BatchItem struct {
+	// Name identifies the item in results and progress reports (e.g. the name of a VM)
+	Name      string
+	Operation BatchOperation
+}
+
+// NewBatchItems builds a list of batch items by applying the same operation to each entity
+func NewBatchItems[T any](entities []T, name func(T) string, operation func(T) (*Task, error)) []BatchItem {
+	items := make([]BatchItem, len(entities))
+	for index, entity := range entities {
+		entity := entity
+		items[index] = BatchItem{
+			Name:      name(entity),
+			Operation: func() (*Task, error) { return operation(entity) },
+		}
+	}
+	return items
+}
+
+// BatchTask adapts the return values of the many govcd functions that return a Task value (e.g.
+// VM.PowerOff) to a BatchOperation result
+func BatchTask(task Task, err error) (*Task, error) {
+	if err != nil {
+		return nil, err
+	}
+	if task.Task == nil {
+		return nil, nil
+	}
+	return &task, nil
+}
+
+// BatchOptions controls the execution of RunBatch. The zero value is usable.
+type BatchOptions struct {
+	// Workers is the maximum number of operations submitted at the same time. Defaults to 10
+	Workers int
+	// MaxRetries is the number of times an item is retried when it fails with a retryable error,
+	// either while submitting or in its task. Defaults to 0 (no retries)
+	MaxRetries int
+	// RetryDelay is the pause before retrying an item. Defaults to 5 seconds
+	RetryDelay time.Duration
+	// RetryableErrors matches errors that are worth retrying. Defaults to busy entity errors
+	RetryableErrors *regexp.Regexp
+	// StopOnFirstError stops submitting new operations after the first failure. Items that were not
+	// run get ErrBatchItemSkipped
+	StopOnFirstError bool
+	// TaskPollInterval is the pause between checks of submitted tasks. Defaults to 3 seconds
+	TaskPollInterval time.Duration
+	// Progress, if set, is called every time an item reaches a final state. Calls are serialized
+	Progress func(progress BatchProgress)
+}
+
+// BatchProgress is passed to BatchOptions.Progress
+type BatchProgress struct {
+	Total     int
+	Completed int
+	Failed    int
+	// Last is the item that has just reached a final state
+	Last BatchResult
+}
+
+// BatchResult is the outcome of a single batch item
+type BatchResult struct {
+	Index    int
+	Name     string
+	Attempts int
+	// Task is the last task started by the item, if any
+	Task  *Task
+	Error error
+}
+
+// BatchReport holds the results of a batch in the same order as the input items
+type BatchReport struct {
+	Results []BatchResult
+}
+
+// Failed returns the results that have an error, including skipped ones
+func (report *BatchReport) Failed() []BatchResult {
+	var failed []BatchResult
+	for _, result := range report.Results {
+		if result.Error != nil {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
+
+// Err returns an error summarizing all failures, or nil if all items succeeded
+func (report *BatchReport) Err() error {
+	failed := report.Failed()
+	if len(failed) == 0 {
+		return nil
+	}
+	errs := make([]error, len(failed))
+	for index, result := range failed {
+		errs[index] = fmt.Errorf("%s: %w", result.Name, result.Error)
+	}
+	return fmt.Errorf("%d of %d batch items failed: %w", len(failed), len(report.Results), errors.Join(errs...))
+}
+
+// RunBatch runs many independent operations concurrently with at most BatchOptions.Workers of
+// them being submitted at the same time.
+//
+// Execution happens in rounds. In each round, pending items are submitted by the workers and the
+// tasks they return are then awaited together. Items that fail with a retryable error (by default,
+// entity busy errors) are submitted again in the next round, up to BatchOptions.MaxRetries times.
+//
+// RunBatch never returns an error on its own: per item errors are collected in the report. Use
+// BatchReport.Err to get a summary error.
+func RunBatch(items []BatchItem, options *BatchOptions) *BatchReport {
+	runner := newBatchRunner(items, options)
+	pending := make([]int, len(items))
+	for index := range items {
+		pending[index] = index
+	}
+
+	for round := 0; len(pending) > 0; round++ {
+		if round > 0 {
+			time.Sleep(runner.options.RetryDelay)
+		}
+		submitted := runner.submit(pending)
+		retries := runner.awaitTasks(submitted)
+
+		pending = nil
+		for _, index := range retries {
+			result := &runner.report.Results[index]
+			if runner.stopped() {
+				runner.finish(index)
+				continue
+			}
+			util.Logger.Printf("[DEBUG] RunBatch: retrying item '%s' after attempt %d: %s", result.Name, result.Attempts, result.Error)
+			result.Error = nil
+			pending = append(pending, index)
+		}
+	}
+	return runner.report
+}
+
+// batchRunner holds the shared state of a RunBatch execution
+type batchRunner struct {
+	items   []BatchItem
+	options BatchOptions
+	report  *BatchReport
+
+	mutex     sync.Mutex
+	completed int
+	failed    int
+	stop      bool
+}
+
+func newBatchRunner(items []BatchItem, options *BatchOptions) *batchRunner {
+	runner := &batchRunner{
+		items:  items,
+		report: &BatchReport{Results: make([]BatchResult, len(items))},
+	}
+	if options != nil {
+		runner.options = *options
+	}
+	if runner.options.Workers <= 0 {
+		runner.options.Workers = 10
+	}
+	if runner.options.RetryDelay <= 0 {
+		runner.options.RetryDelay = 5 * time.Second
+	}
+	if runner.options.TaskPollInterval <= 0 {
+		runner.options.TaskPollInterval = 3 * time.Second
+	}
+	if runner.options.RetryableErrors == nil {
+		runner.options.RetryableErrors = batchBusyEntityRegexp
+	}
+	for index, item := range items {
+		runner.report.Results[index] = BatchResult{Index: index, Name: item.Name}
+	}
+	return runner
+}
+
+// submit runs the operations of the given items with a bounded pool of workers. Synchronous
+// operations and final failures are finished right away. It returns the indexes of items that
+// started a task or failed with a retryable error
+func (runner *batchRunner) submit(indexes []int) []int {
+	queue := make(chan int)
+	var submitted []int
+	var wg sync.WaitGroup
+
+	for worker := 0; worker < runner.options.Workers && worker < len(indexes); worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range queue {
+				result := &runner.report.Results[index]
+				if runner.stopped() {
+					result.Error = ErrBatchItemSkipped
+					runner.finish(index)
+					continue
+				}
+				result.Attempts++
+				task, err := runner.items[index].Operation()
+				result.Task = task
+				result.Error = err
+
+				if (err == nil && task == nil) || (err != nil && !runner.retryable(result)) {
+					runner.finish(index)
+					continue
+				}
+				runner.mutex.Lock()
+				submitted = append(submitted, index)
+				runner.mutex.Unlock()
+			}
+		}()
+	}
+	for _, index := range indexes {
+		queue <- index
+	}
+	close(queue)
+	wg.Wait()
+	return submitted
+}
+
+// awaitTasks waits for all tasks of the submitted items and returns the indexes of items that
+// need to be retried, either because of a submission error or a task error
+func (runner *batchRunner) awaitTasks(submitted []int) []int {
+	var retries []int
+	var running []int
+	for _, index := range submitted {
+		result := &runner.report.Results[index]
+		switch {
+		case result.Error != nil:
+			retries = append(retries, index)
+		case result.Task != nil:
+			running = append(running, index)
+		}
+	}
+
+	for len(running) > 0 {
+		var stillRunning []int
+		for _, index := range running {
+			result := &runner.report.Results[index]
+			err := result.Task.Refresh()
+			switch {
+			case err != nil:
+				result.Error = err
+			case isTaskRunning(result.Task.Task.Status):
+				stillRunning = append(stillRunning, index)
+				continue
+			case result.Task.Task.Status == "error":
+				result.Error = fmt.Errorf("task did not complete successfully: %s", result.Task.getErrorMessage(nil))
+			}
+			if result.Error != nil && runner.retryable(result) {
+				retries = append(retries, index)
+				continue
+			}
+			runner.finish(index)
+		}
+		running = stillRunning
+		if len(running) > 0 {
+			time.Sleep(runner.options.TaskPollInterval)
+		}
+	}
+	return retries
+}
+
+// retryable returns true if the item failed with a retryable error and has attempts left
+func (runner *batchRunner) retryable(result *BatchResult) bool {
+	return result.Attempts <= runner.options.MaxRetries && runner.options.RetryableErrors.MatchString(result.Error.Error())
+}
+
+// finish records the final state of an item and reports progress
+func (runner *batchRunner) finish(index int) {
+	runner.mutex.Lock()
+	defer runner.mutex.Unlock()
+
+	result := runner.report.Results[index]
+	runner.completed++
+	if result.Error != nil {
+		runner.failed++
+		if runner.options.StopOnFirstError && !errors.Is(result.Error, ErrBatchItemSkipped) {
+			runner.stop = true
+		}
+	}
+	if runner.options.Progress != nil {
+		runner.options.Progress(BatchProgress{
+			Total:     len(runner.items),
+			Completed: runner.completed,
+			Failed:    runner.failed,
+			Last:      result,
+		})
+	}
+}
+
+func (runner *batchRunner) stopped() bool {
+	runner.mutex.Lock()
+	defer runner.mutex.Unlock()
+	return runner.stop
+}
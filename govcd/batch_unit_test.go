@@ -0,0 +1,112 @@
+//go:build unit || ALL
+
+/*
+ * Copyright 2025 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+package govcd
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Test_RunBatch checks worker limits, retries of busy entities and result collection for
+// synchronous operations
+func Test_RunBatch(t *testing.T) {
+	var running, maxRunning int32
+	attempts := make(map[string]int)
+	var mutex sync.Mutex
+
+	names := []string{"vm-0", "vm-1", "vm-2", "vm-3", "vm-4", "vm-5", "vm-6", "vm-7"}
+	items := NewBatchItems(names, func(name string) string { return name }, func(name string) (*Task, error) {
+		current := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			observed := atomic.LoadInt32(&maxRunning)
+			if current <= observed || atomic.CompareAndSwapInt32(&maxRunning, observed, current) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+
+		mutex.Lock()
+		attempts[name]++
+		attempt := attempts[name]
+		mutex.Unlock()
+
+		switch {
+		case name == "vm-3" && attempt == 1:
+			return nil, fmt.Errorf("[400:BUSY_ENTITY] - The entity is busy completing an operation")
+		case name == "vm-5":
+			return nil, fmt.Errorf("permanent failure")
+		}
+		return nil, nil
+	})
+
+	var progressCalls int
+	report := RunBatch(items, &BatchOptions{
+		Workers:    3,
+		MaxRetries: 2,
+		RetryDelay: time.Millisecond,
+		Progress: func(progress BatchProgress) {
+			progressCalls++
+			if progress.Total != len(names) {
+				t.Errorf("unexpected total %d", progress.Total)
+			}
+		},
+	})
+
+	if maxRunning > 3 {
+		t.Fatalf("expected at most 3 concurrent operations, got %d", maxRunning)
+	}
+	if progressCalls != len(names) {
+		t.Fatalf("expected %d progress calls, got %d", len(names), progressCalls)
+	}
+	if report.Results[3].Error != nil || report.Results[3].Attempts != 2 {
+		t.Fatalf("expected busy item to succeed on second attempt, got %+v", report.Results[3])
+	}
+	if report.Results[5].Error == nil || report.Results[5].Attempts != 1 {
+		t.Fatalf("expected permanent failure without retries, got %+v", report.Results[5])
+	}
+	if len(report.Failed()) != 1 || report.Err() == nil {
+		t.Fatalf("expected exactly one failure, got %d", len(report.Failed()))
+	}
+}
+
+// Test_RunBatchStopOnFirstError checks that remaining items are skipped after a failure
+func Test_RunBatchStopOnFirstError(t *testing.T) {
+	var items []BatchItem
+	for index := 0; index < 20; index++ {
+		index := index
+		items = append(items, BatchItem{
+			Name: fmt.Sprintf("item-%d", index),
+			Operation: func() (*Task, error) {
+				if index == 0 {
+					return nil, fmt.Errorf("failure")
+				}
+				time.Sleep(5 * time.Millisecond)
+				return nil, nil
+			},
+		})
+	}
+
+	report := RunBatch(items, &BatchOptions{Workers: 1, StopOnFirstError: true})
+	if report.Results[0].Error == nil {
+		t.Fatalf("expected first item to fail")
+	}
+	for _, result := range report.Results[1:] {
+		if !errors.Is(result.Error, ErrBatchItemSkipped) {
+			t.Fatalf("expected item '%s' to be skipped, got error: %v", result.Name, result.Error)
+		}
+	}
+
+	task, err := BatchTask(Task{}, nil)
+	if task != nil || err != nil {
+		t.Fatalf("expected empty task to be converted to nil")
+	}
+}
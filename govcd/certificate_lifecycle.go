@@ -0,0 +1,439 @@
+/*
+ * Copyright 2025 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+package govcd
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+	"github.com/vmware/go-vcloud-director/v3/util"
+)
+
+// Kinds of CertificateConsumer
+const (
+	CertificateConsumerAlbVirtualService = "albVirtualService"
+	CertificateConsumerAlbPool           = "albPool"
+	CertificateConsumerIpSecVpnTunnel    = "ipSecVpnTunnel"
+	// CertificateConsumerTrustedCertificate is an entry of the trusted certificates store with the same certificate.
+	// The store is used to verify LDAP and OIDC servers
+	CertificateConsumerTrustedCertificate = "trustedCertificate"
+)
+
+// CertificateDetails contains the parsed content of a library certificate. When the library item contains a chain,
+// the details refer to the first certificate
+type CertificateDetails struct {
+	Certificate  *Certificate
+	Subject      string
+	Issuer       string
+	DnsNames     []string
+	SerialNumber string
+	NotBefore    time.Time
+	NotAfter     time.Time
+}
+
+// CertificateScope defines where library certificates and their consumers are searched
+type CertificateScope struct {
+	// Org is the Org whose certificate library is used. When nil, the certificate library of the current user Org
+	// is used
+	Org *AdminOrg
+	// EdgeGateways are searched for ALB virtual services, ALB pools and IPsec VPN tunnels using certificates
+	EdgeGateways []*NsxtEdgeGateway
+	// TrustedCertificates enables the search in the trusted certificates store. It requires a System user
+	TrustedCertificates bool
+}
+
+// CertificateConsumer is an entity that uses a library certificate
+type CertificateConsumer struct {
+	Kind          string
+	Id            string
+	Name          string
+	EdgeGatewayId string
+	// Field is the field of the consumer that references the certificate, such as 'certificateRef'
+	Field string
+}
+
+// CertificateExpiry is a certificate that is about to expire, with the entities using it
+type CertificateExpiry struct {
+	CertificateDetails
+	Consumers []CertificateConsumer
+}
+
+// CertificateProvider obtains a renewed certificate, in the style of an ACME client. The returned item must contain
+// the PEM encoded certificate and, for certificates used by servers, its private key. When Alias is empty or equal
+// to the current one, an alias is generated from the current alias and the new validity start date
+type CertificateProvider interface {
+	RenewCertificate(current *CertificateDetails) (*types.CertificateLibraryItem, error)
+}
+
+// CertificateProviderFunc allows using a function as CertificateProvider
+type CertificateProviderFunc func(current *CertificateDetails) (*types.CertificateLibraryItem, error)
+
+// RenewCertificate calls the function
+func (provider CertificateProviderFunc) RenewCertificate(current *CertificateDetails) (*types.CertificateLibraryItem, error) {
+	return provider(current)
+}
+
+// CertificateRotationResult contains the outcome of VCDClient.RotateCertificate
+type CertificateRotationResult struct {
+	New *Certificate
+	// Consumers are the entities that now use the new certificate
+	Consumers []CertificateConsumer
+	// Retired is true when the old certificate was removed from the library
+	Retired bool
+}
+
+// certificateUse is a consumer of a certificate, with a function that makes it use another certificate
+type certificateUse struct {
+	consumer CertificateConsumer
+	repoint  func(newCertificate *Certificate) error
+}
+
+// GetDetails parses the certificate with crypto/x509
+func (certificate *Certificate) GetDetails() (*CertificateDetails, error) {
+	if certificate.CertificateLibrary == nil {
+		return nil, fmt.Errorf("certificate has no content")
+	}
+	parsed, err := parseFirstCertificate(certificate.CertificateLibrary.Certificate)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing certificate '%s': %s", certificate.CertificateLibrary.Alias, err)
+	}
+	return &CertificateDetails{
+		Certificate:  certificate,
+		Subject:      parsed.Subject.String(),
+		Issuer:       parsed.Issuer.String(),
+		DnsNames:     parsed.DNSNames,
+		SerialNumber: parsed.SerialNumber.String(),
+		NotBefore:    parsed.NotBefore,
+		NotAfter:     parsed.NotAfter,
+	}, nil
+}
+
+// GetExpiringCertificates returns the library certificates that expire within the given duration, including the
+// ones already expired, sorted by expiration date. Each certificate lists the consumers found in the scope.
+// Library certificates that can't be parsed are skipped with a warning in the log.
+func (vcdClient *VCDClient) GetExpiringCertificates(scope CertificateScope, within time.Duration) ([]*CertificateExpiry, error) {
+	var certificates []*Certificate
+	var err error
+	if scope.Org != nil {
+		certificates, err = scope.Org.GetAllCertificatesFromLibrary(nil)
+	} else {
+		certificates, err = vcdClient.Client.GetAllCertificatesFromLibrary(nil)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving certificates: %s", err)
+	}
+
+	expiring := filterExpiringCertificates(getCertificatesDetails(certificates), time.Now().Add(within))
+
+	var result []*CertificateExpiry
+	for _, details := range expiring {
+		consumers, err := vcdClient.GetCertificateConsumers(details.Certificate, scope)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, &CertificateExpiry{CertificateDetails: *details, Consumers: consumers})
+	}
+	return result, nil
+}
+
+// GetCertificateConsumers returns the entities in scope that use the certificate
+func (vcdClient *VCDClient) GetCertificateConsumers(certificate *Certificate, scope CertificateScope) ([]CertificateConsumer, error) {
+	uses, err := vcdClient.getCertificateUses(certificate, scope)
+	if err != nil {
+		return nil, err
+	}
+	consumers := make([]CertificateConsumer, len(uses))
+	for index, use := range uses {
+		consumers[index] = use.consumer
+	}
+	return consumers, nil
+}
+
+// RotateCertificate replaces a library certificate with a renewed one obtained from provider. The new certificate is
+// added to the library, all consumers found in scope are changed to use it and, when retireOld is true, the old
+// certificate is removed from the library.
+//
+// The renewed certificate must expire after the current one. If repointing a consumer fails, the old certificate is
+// kept and the error lists the consumers that were already changed.
+func (vcdClient *VCDClient) RotateCertificate(certificate *Certificate, provider CertificateProvider, scope CertificateScope, retireOld bool) (*CertificateRotationResult, error) {
+	if provider == nil {
+		return nil, fmt.Errorf("a certificate provider is required to rotate a certificate")
+	}
+	current, err := certificate.GetDetails()
+	if err != nil {
+		return nil, err
+	}
+	renewed, err := provider.RenewCertificate(current)
+	if err != nil {
+		return nil, fmt.Errorf("error renewing certificate '%s': %s", certificate.CertificateLibrary.Alias, err)
+	}
+	newConfig, err := prepareRenewedCertificate(current, renewed)
+	if err != nil {
+		return nil, err
+	}
+
+	uses, err := vcdClient.getCertificateUses(certificate, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &CertificateRotationResult{}
+	if scope.Org != nil {
+		result.New, err = scope.Org.AddCertificateToLibrary(newConfig)
+	} else {
+		result.New, err = vcdClient.Client.AddCertificateToLibrary(newConfig)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error adding renewed certificate '%s': %s", newConfig.Alias, err)
+	}
+
+	for _, use := range uses {
+		err = use.repoint(result.New)
+		if err != nil {
+			return result, fmt.Errorf("error changing %s '%s' to certificate '%s' (%d of %d consumers changed, old certificate kept): %s",
+				use.consumer.Kind, use.consumer.Name, newConfig.Alias, len(result.Consumers), len(uses), err)
+		}
+		result.Consumers = append(result.Consumers, use.consumer)
+	}
+
+	if retireOld {
+		err = certificate.Delete()
+		if err != nil {
+			return result, fmt.Errorf("error retiring certificate '%s': %s", certificate.CertificateLibrary.Alias, err)
+		}
+		result.Retired = true
+	}
+	return result, nil
+}
+
+// getCertificateUses finds the consumers of a certificate in scope
+func (vcdClient *VCDClient) getCertificateUses(certificate *Certificate, scope CertificateScope) ([]certificateUse, error) {
+	id := certificate.CertificateLibrary.Id
+	var uses []certificateUse
+
+	for _, egw := range scope.EdgeGateways {
+		egwId := egw.EdgeGateway.ID
+
+		virtualServices, err := vcdClient.GetAllAlbVirtualServices(egwId, nil)
+		if err != nil {
+			return nil, fmt.Errorf("error retrieving ALB virtual services of Edge Gateway '%s': %s", egw.EdgeGateway.Name, err)
+		}
+		for _, virtualService := range virtualServices {
+			config := virtualService.NsxtAlbVirtualService
+			if config.CertificateRef == nil || config.CertificateRef.ID != id {
+				continue
+			}
+			virtualService := virtualService
+			uses = append(uses, certificateUse{
+				consumer: CertificateConsumer{Kind: CertificateConsumerAlbVirtualService, Id: config.ID, Name: config.Name, EdgeGatewayId: egwId, Field: "certificateRef"},
+				repoint: func(newCertificate *Certificate) error {
+					update := *virtualService.NsxtAlbVirtualService
+					update.CertificateRef = &types.OpenApiReference{ID: newCertificate.CertificateLibrary.Id}
+					_, err := virtualService.Update(&update)
+					return err
+				},
+			})
+		}
+
+		pools, err := vcdClient.GetAllAlbPools(egwId, nil)
+		if err != nil {
+			return nil, fmt.Errorf("error retrieving ALB pools of Edge Gateway '%s': %s", egw.EdgeGateway.Name, err)
+		}
+		for _, pool := range pools {
+			config := pool.NsxtAlbPool
+			if !openApiReferencesContainId(config.CaCertificateRefs, id) {
+				continue
+			}
+			pool := pool
+			uses = append(uses, certificateUse{
+				consumer: CertificateConsumer{Kind: CertificateConsumerAlbPool, Id: config.ID, Name: config.Name, EdgeGatewayId: egwId, Field: "caCertificateRefs"},
+				repoint: func(newCertificate *Certificate) error {
+					update := *pool.NsxtAlbPool
+					update.CaCertificateRefs = replaceOpenApiReferenceId(update.CaCertificateRefs, id, newCertificate.CertificateLibrary.Id)
+					_, err := pool.Update(&update)
+					return err
+				},
+			})
+		}
+
+		tunnels, err := egw.GetAllIpSecVpnTunnels(nil)
+		if err != nil {
+			return nil, fmt.Errorf("error retrieving IPsec VPN tunnels of Edge Gateway '%s': %s", egw.EdgeGateway.Name, err)
+		}
+		for _, tunnel := range tunnels {
+			config := tunnel.NsxtIpSecVpn
+			usesCertificate := config.CertificateRef != nil && config.CertificateRef.ID == id
+			usesCaCertificate := config.CaCertificateRef != nil && config.CaCertificateRef.ID == id
+			if !usesCertificate && !usesCaCertificate {
+				continue
+			}
+			field := "certificateRef"
+			if !usesCertificate {
+				field = "caCertificateRef"
+			} else if usesCaCertificate {
+				field = "certificateRef,caCertificateRef"
+			}
+			tunnel := tunnel
+			uses = append(uses, certificateUse{
+				consumer: CertificateConsumer{Kind: CertificateConsumerIpSecVpnTunnel, Id: config.ID, Name: config.Name, EdgeGatewayId: egwId, Field: field},
+				repoint: func(newCertificate *Certificate) error {
+					update := *tunnel.NsxtIpSecVpn
+					if usesCertificate {
+						update.CertificateRef = &types.OpenApiReference{ID: newCertificate.CertificateLibrary.Id}
+					}
+					if usesCaCertificate {
+						update.CaCertificateRef = &types.OpenApiReference{ID: newCertificate.CertificateLibrary.Id}
+					}
+					_, err := tunnel.Update(&update)
+					return err
+				},
+			})
+		}
+	}
+
+	if scope.TrustedCertificates {
+		trustedCertificates, err := vcdClient.GetAllTrustedCertificates(nil)
+		if err != nil {
+			return nil, fmt.Errorf("error retrieving trusted certificates: %s", err)
+		}
+		for _, trusted := range trustedCertificates {
+			same, err := sameCertificate(trusted.TrustedCertificate.Certificate, certificate.CertificateLibrary.Certificate)
+			if err != nil {
+				util.Logger.Printf("[WARN] skipping trusted certificate '%s': %s", trusted.TrustedCertificate.Alias, err)
+				continue
+			}
+			if !same {
+				continue
+			}
+			trusted := trusted
+			uses = append(uses, certificateUse{
+				consumer: CertificateConsumer{Kind: CertificateConsumerTrustedCertificate, Id: trusted.TrustedCertificate.ID, Name: trusted.TrustedCertificate.Alias, Field: "certificate"},
+				repoint: func(newCertificate *Certificate) error {
+					leaf, err := firstCertificatePem(newCertificate.CertificateLibrary.Certificate)
+					if err != nil {
+						return err
+					}
+					update := *trusted.TrustedCertificate
+					update.Certificate = leaf
+					_, err = trusted.Update(&update)
+					return err
+				},
+			})
+		}
+	}
+	return uses, nil
+}
+
+// prepareRenewedCertificate validates the renewed certificate and sets its alias
+func prepareRenewedCertificate(current *CertificateDetails, renewed *types.CertificateLibraryItem) (*types.CertificateLibraryItem, error) {
+	if renewed == nil {
+		return nil, fmt.Errorf("certificate provider returned no certificate")
+	}
+	parsed, err := parseFirstCertificate(renewed.Certificate)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing renewed certificate: %s", err)
+	}
+	if !parsed.NotAfter.After(current.NotAfter) {
+		return nil, fmt.Errorf("renewed certificate expires on %s, not after the current one (%s)",
+			parsed.NotAfter.Format(time.RFC3339), current.NotAfter.Format(time.RFC3339))
+	}
+	result := *renewed
+	result.Id = ""
+	currentAlias := current.Certificate.CertificateLibrary.Alias
+	if result.Alias == "" || result.Alias == currentAlias {
+		result.Alias = fmt.Sprintf("%s-%s", currentAlias, parsed.NotBefore.UTC().Format("20060102"))
+	}
+	return &result, nil
+}
+
+// getCertificatesDetails returns the details of the library certificates. Certificates that can't be parsed are
+// skipped with a warning, so that one of them doesn't prevent reporting on the others
+func getCertificatesDetails(certificates []*Certificate) []*CertificateDetails {
+	var result []*CertificateDetails
+	for _, certificate := range certificates {
+		details, err := certificate.GetDetails()
+		if err != nil {
+			util.Logger.Printf("[WARN] skipping library certificate: %s", err)
+			continue
+		}
+		result = append(result, details)
+	}
+	return result
+}
+
+// filterExpiringCertificates returns the certificates that expire before the given time, sorted by expiration date
+func filterExpiringCertificates(certificates []*CertificateDetails, before time.Time) []*CertificateDetails {
+	var result []*CertificateDetails
+	for _, details := range certificates {
+		if details.NotAfter.Before(before) {
+			result = append(result, details)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].NotAfter.Before(result[j].NotAfter) })
+	return result
+}
+
+// parseFirstCertificate parses the first certificate of a PEM text
+func parseFirstCertificate(pemText string) (*x509.Certificate, error) {
+	rest := []byte(pemText)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			return nil, fmt.Errorf("no certificate found in PEM text")
+		}
+		if block.Type == "CERTIFICATE" {
+			return x509.ParseCertificate(block.Bytes)
+		}
+	}
+}
+
+// firstCertificatePem returns the first certificate of a PEM text, which may contain a chain
+func firstCertificatePem(pemText string) (string, error) {
+	parsed, err := parseFirstCertificate(pemText)
+	if err != nil {
+		return "", err
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: parsed.Raw})), nil
+}
+
+// sameCertificate returns true when the first certificates of two PEM texts are identical, regardless of formatting
+func sameCertificate(first, second string) (bool, error) {
+	firstCertificate, err := parseFirstCertificate(first)
+	if err != nil {
+		return false, err
+	}
+	secondCertificate, err := parseFirstCertificate(second)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(firstCertificate.Raw, secondCertificate.Raw), nil
+}
+
+func openApiReferencesContainId(references []types.OpenApiReference, id string) bool {
+	for _, reference := range references {
+		if reference.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// replaceOpenApiReferenceId returns a copy of references where the reference with oldId is replaced by newId
+func replaceOpenApiReferenceId(references []types.OpenApiReference, oldId, newId string) []types.OpenApiReference {
+	result := make([]types.OpenApiReference, len(references))
+	for index, reference := range references {
+		if reference.ID == oldId {
+			reference = types.OpenApiReference{ID: newId}
+		}
+		result[index] = reference
+	}
+	return result
+}
@@ -0,0 +1,143 @@
+//go:build unit || ALL
+
+/*
+ * Copyright 2025 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+package govcd
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// makeSelfSignedCertificatePem creates a self-signed certificate valid between notBefore and notAfter
+func makeSelfSignedCertificatePem(t *testing.T, commonName string, notBefore, notAfter time.Time) string {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("error generating key: %s", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(notAfter.Unix()),
+		Subject:      pkix.Name{CommonName: commonName},
+		DNSNames:     []string{commonName},
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("error creating certificate: %s", err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
+
+// Test_CertificateDetails checks parsing and expiry filtering of library certificates
+func Test_CertificateDetails(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	soon := &Certificate{CertificateLibrary: &types.CertificateLibraryItem{Id: "id-soon", Alias: "soon",
+		Certificate: makeSelfSignedCertificatePem(t, "soon.example.com", now.Add(-time.Hour), now.Add(10*24*time.Hour))}}
+	expired := &Certificate{CertificateLibrary: &types.CertificateLibraryItem{Id: "id-expired", Alias: "expired",
+		Certificate: makeSelfSignedCertificatePem(t, "expired.example.com", now.Add(-48*time.Hour), now.Add(-time.Hour))}}
+	later := &Certificate{CertificateLibrary: &types.CertificateLibraryItem{Id: "id-later", Alias: "later",
+		Certificate: makeSelfSignedCertificatePem(t, "later.example.com", now.Add(-time.Hour), now.Add(365*24*time.Hour))}}
+
+	invalid := &Certificate{CertificateLibrary: &types.CertificateLibraryItem{Id: "id-invalid", Alias: "invalid", Certificate: "not a certificate"}}
+	_, err := invalid.GetDetails()
+	if err == nil {
+		t.Fatalf("expected error for invalid certificate")
+	}
+
+	// the invalid certificate is skipped and doesn't prevent reporting on the others
+	allDetails := getCertificatesDetails([]*Certificate{soon, invalid, expired, later})
+	if len(allDetails) != 3 {
+		t.Fatalf("expected 3 parsed certificates, got %d", len(allDetails))
+	}
+	if allDetails[0].Subject != "CN=soon.example.com" || !reflect.DeepEqual(allDetails[0].DnsNames, []string{"soon.example.com"}) ||
+		!allDetails[0].NotAfter.Equal(now.Add(10*24*time.Hour)) {
+		t.Fatalf("unexpected details: %+v", allDetails[0])
+	}
+
+	expiring := filterExpiringCertificates(allDetails, now.Add(30*24*time.Hour))
+	if len(expiring) != 2 || expiring[0].Certificate != expired || expiring[1].Certificate != soon {
+		t.Fatalf("unexpected expiring certificates: %+v", expiring)
+	}
+}
+
+// Test_prepareRenewedCertificate checks validation and alias generation for renewed certificates
+func Test_prepareRenewedCertificate(t *testing.T) {
+	now := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	currentCertificate := &Certificate{CertificateLibrary: &types.CertificateLibraryItem{Id: "id-web", Alias: "web",
+		Certificate: makeSelfSignedCertificatePem(t, "web.example.com", now.Add(-365*24*time.Hour), now)}}
+	current, err := currentCertificate.GetDetails()
+	if err != nil {
+		t.Fatalf("error parsing certificate: %s", err)
+	}
+
+	renewedPem := makeSelfSignedCertificatePem(t, "web.example.com", now.Add(-24*time.Hour), now.Add(90*24*time.Hour))
+	renewed, err := prepareRenewedCertificate(current, &types.CertificateLibraryItem{Id: "old-id", Alias: "web", Certificate: renewedPem})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if renewed.Alias != "web-20250228" || renewed.Id != "" || renewed.Certificate != renewedPem {
+		t.Fatalf("unexpected renewed certificate: %+v", renewed)
+	}
+	renewed, err = prepareRenewedCertificate(current, &types.CertificateLibraryItem{Alias: "web-2025", Certificate: renewedPem})
+	if err != nil || renewed.Alias != "web-2025" {
+		t.Fatalf("expected given alias to be kept, got %+v (error %v)", renewed, err)
+	}
+
+	olderPem := makeSelfSignedCertificatePem(t, "web.example.com", now.Add(-24*time.Hour), now.Add(-time.Hour))
+	_, err = prepareRenewedCertificate(current, &types.CertificateLibraryItem{Certificate: olderPem})
+	if err == nil {
+		t.Fatalf("expected error for certificate expiring before the current one")
+	}
+	_, err = prepareRenewedCertificate(current, nil)
+	if err == nil {
+		t.Fatalf("expected error for missing certificate")
+	}
+}
+
+// Test_sameCertificate checks certificate comparison across formatting differences and chains
+func Test_sameCertificate(t *testing.T) {
+	now := time.Now()
+	leaf := makeSelfSignedCertificatePem(t, "leaf.example.com", now, now.Add(time.Hour))
+	other := makeSelfSignedCertificatePem(t, "other.example.com", now, now.Add(time.Hour))
+	chain := leaf + other
+	reformatted := strings.TrimSpace(strings.ReplaceAll(leaf, "\n", "\r\n"))
+
+	for _, test := range []struct {
+		first, second string
+		expected      bool
+	}{
+		{leaf, reformatted, true},
+		{leaf, chain, true},
+		{leaf, other, false},
+	} {
+		same, err := sameCertificate(test.first, test.second)
+		if err != nil || same != test.expected {
+			t.Fatalf("expected %t, got %t (error %v)", test.expected, same, err)
+		}
+	}
+
+	first, err := firstCertificatePem(chain)
+	if err != nil || first != leaf {
+		t.Fatalf("unexpected first certificate of chain (error %v):\n%s", err, first)
+	}
+
+	references := []types.OpenApiReference{{ID: "a"}, {ID: "old", Name: "old"}}
+	replaced := replaceOpenApiReferenceId(references, "old", "new")
+	if !reflect.DeepEqual(replaced, []types.OpenApiReference{{ID: "a"}, {ID: "new"}}) || references[1].ID != "old" {
+		t.Fatalf("unexpected replaced references: %+v", replaced)
+	}
+}
@@ -0,0 +1,435 @@
+/*
+ * Copyright 2025 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+package govcd
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+	"github.com/vmware/go-vcloud-director/v3/util"
+)
+
+// Sharing types of independent disks
+const (
+	DiskSharingNone        = "None"
+	DiskSharingMultiWriter = "DiskSharing"       // The disk is attached to many VMs, which write to it concurrently
+	DiskSharingController  = "ControllerSharing" // The disk controller is shared, as for physical bus sharing clusters
+)
+
+// diskBus describes a bus sub type of independent disks and the VM disk controller it uses
+type diskBus struct {
+	busType       string
+	vmAdapterType string // Legacy ID of the disk controller, as used in types.DiskSettings
+	scsi          bool
+}
+
+// diskBuses maps the bus sub types of independent disks to their bus type and VM disk controller
+var diskBuses = map[string]diskBus{
+	"ide":                    {busType: "5", vmAdapterType: "1"},
+	"buslogic":               {busType: "6", vmAdapterType: "2", scsi: true},
+	"lsilogic":               {busType: "6", vmAdapterType: "3", scsi: true},
+	"lsilogicsas":            {busType: "6", vmAdapterType: "4", scsi: true},
+	"VirtualSCSI":            {busType: "6", vmAdapterType: "5", scsi: true},
+	"vmware.sata.ahci":       {busType: "20", vmAdapterType: "6"},
+	"vmware.nvme.controller": {busType: "20", vmAdapterType: "7"},
+}
+
+// DiskUsageReportEntry describes the usage and attachments of an independent disk
+type DiskUsageReportEntry struct {
+	Name               string
+	HREF               string
+	Description        string
+	Status             string
+	OwnerName          string
+	StorageProfileName string
+	DatastoreName      string
+	SizeMb             int64
+	Iops               int64
+	BusType            string
+	BusSubType         string
+	SharingType        string
+	Shareable          bool
+	Encrypted          bool
+	AttachedVms        []*types.Reference
+}
+
+// Resize changes the size of the independent disk. Disks can only grow, and the increase must fit in the storage
+// profile limit. Disks attached to a single VM are resized online, and the guest OS must then extend its partitions.
+// Shared disks must be detached from all VMs before being resized.
+func (disk *Disk) Resize(newSizeMb int64) (Task, error) {
+	err := disk.Refresh()
+	if err != nil {
+		return Task{}, fmt.Errorf("error refreshing disk: %s", err)
+	}
+
+	var storageProfile *types.VdcStorageProfile
+	if disk.Disk.StorageProfile != nil && disk.Disk.StorageProfile.HREF != "" {
+		storageProfile, err = disk.client.GetStorageProfileByHref(disk.Disk.StorageProfile.HREF)
+		if err != nil {
+			return Task{}, fmt.Errorf("error retrieving storage profile of disk '%s': %s", disk.Disk.Name, err)
+		}
+	}
+	attachedVms, err := disk.GetAttachedVmsHrefs()
+	if err != nil {
+		return Task{}, fmt.Errorf("error retrieving VMs attached to disk '%s': %s", disk.Disk.Name, err)
+	}
+	err = validateDiskResize(disk.Disk, newSizeMb, storageProfile, len(attachedVms))
+	if err != nil {
+		return Task{}, err
+	}
+
+	editHref := getUrlFromLink(disk.Disk.Link, types.RelEdit, types.MimeDisk)
+	if editHref == "" {
+		return Task{}, fmt.Errorf("could not find request URL for update disk in disk Link")
+	}
+	util.Logger.Printf("[TRACE] Resize disk '%s' from %d MB to %d MB\n", disk.Disk.Name, disk.Disk.SizeMb, newSizeMb)
+
+	xmlPayload := &types.Disk{
+		Xmlns:          types.XMLNamespaceVCloud,
+		Name:           disk.Disk.Name,
+		Description:    disk.Disk.Description,
+		SizeMb:         newSizeMb,
+		Iops:           disk.Disk.Iops,
+		StorageProfile: disk.Disk.StorageProfile,
+		Owner:          disk.Disk.Owner,
+	}
+	return disk.client.ExecuteTaskRequest(editHref, http.MethodPut, types.MimeDisk, "error resizing disk: %s", xmlPayload)
+}
+
+// validateDiskResize checks that the disk can grow to newSizeMb within the limit of its storage profile
+func validateDiskResize(disk *types.Disk, newSizeMb int64, storageProfile *types.VdcStorageProfile, attachedVms int) error {
+	if newSizeMb <= disk.SizeMb {
+		return fmt.Errorf("new size of disk '%s' (%d MB) must be larger than its current size (%d MB): disks cannot shrink",
+			disk.Name, newSizeMb, disk.SizeMb)
+	}
+	if disk.Shareable && attachedVms > 0 {
+		return fmt.Errorf("shared disk '%s' is attached to %d VMs: it must be detached before resizing", disk.Name, attachedVms)
+	}
+	if storageProfile == nil || storageProfile.Limit == 0 {
+		return nil
+	}
+
+	limitMb := storageProfile.Limit
+	switch strings.ToUpper(storageProfile.Units) {
+	case "GB":
+		limitMb *= 1024
+	case "TB":
+		limitMb *= 1024 * 1024
+	}
+	availableMb := limitMb - storageProfile.StorageUsedMB
+	if newSizeMb-disk.SizeMb > availableMb {
+		return fmt.Errorf("storage profile '%s' has %d MB available: disk '%s' cannot grow by %d MB",
+			storageProfile.Name, availableMb, disk.Name, newSizeMb-disk.SizeMb)
+	}
+	return nil
+}
+
+// MoveToVdc moves the detached independent disk to another VDC of the same organization, using the storage profile
+// with the given name in the target VDC. When storageProfileName is empty, the storage profile with the same name as
+// the current one is used. The move is only possible when VCD offers it in the disk links.
+func (disk *Disk) MoveToVdc(targetVdc *Vdc, storageProfileName string) (Task, error) {
+	if targetVdc == nil || targetVdc.Vdc == nil || targetVdc.Vdc.HREF == "" {
+		return Task{}, fmt.Errorf("target VDC cannot be empty")
+	}
+	err := disk.Refresh()
+	if err != nil {
+		return Task{}, fmt.Errorf("error refreshing disk: %s", err)
+	}
+	if getUrlFromLink(disk.Disk.Link, "up", types.MimeVDC) == targetVdc.Vdc.HREF {
+		return Task{}, fmt.Errorf("disk '%s' is already in VDC '%s'", disk.Disk.Name, targetVdc.Vdc.Name)
+	}
+	attachedVms, err := disk.GetAttachedVmsHrefs()
+	if err != nil {
+		return Task{}, fmt.Errorf("error retrieving VMs attached to disk '%s': %s", disk.Disk.Name, err)
+	}
+	if len(attachedVms) > 0 {
+		return Task{}, fmt.Errorf("disk '%s' is attached to %d VMs: it must be detached before moving", disk.Disk.Name, len(attachedVms))
+	}
+
+	if storageProfileName == "" && disk.Disk.StorageProfile != nil {
+		storageProfileName = disk.Disk.StorageProfile.Name
+	}
+	var storageProfile *types.Reference
+	if storageProfileName != "" && targetVdc.Vdc.VdcStorageProfiles != nil {
+		storageProfile = findReferenceByName(targetVdc.Vdc.VdcStorageProfiles.VdcStorageProfile, storageProfileName)
+	}
+	if storageProfileName != "" && storageProfile == nil {
+		return Task{}, fmt.Errorf("storage profile '%s' is not available in VDC '%s'", storageProfileName, targetVdc.Vdc.Name)
+	}
+
+	var moveLink *types.Link
+	for _, link := range disk.Disk.Link {
+		if link.Rel == types.RelMove {
+			moveLink = link
+			break
+		}
+	}
+	if moveLink == nil {
+		return Task{}, fmt.Errorf("disk '%s' cannot be moved: VCD does not offer a move link for it", disk.Disk.Name)
+	}
+
+	params := &types.DiskMoveParams{
+		Xmlns:          types.XMLNamespaceVCloud,
+		Vdc:            &types.Reference{HREF: targetVdc.Vdc.HREF},
+		StorageProfile: storageProfile,
+	}
+	return disk.client.ExecuteTaskRequest(moveLink.HREF, http.MethodPost, moveLink.Type, "error moving disk: %s", params)
+}
+
+// CreateSharedDisk creates an independent disk that can be attached to many VMs at the same time, such as the quorum
+// or data disks of a cluster. Shared disks use a SCSI controller: busSubType is one of "VirtualSCSI" (default),
+// "lsilogicsas", "lsilogic" or "buslogic". When storageProfileName is empty, the default storage profile of the VDC is
+// used.
+func (vdc *Vdc) CreateSharedDisk(name, description string, sizeMb int64, busSubType, storageProfileName string) (Task, error) {
+	if busSubType == "" {
+		busSubType = "VirtualSCSI"
+	}
+	bus, ok := diskBuses[busSubType]
+	if !ok || !bus.scsi {
+		return Task{}, fmt.Errorf("shared disks require a SCSI bus sub type, got '%s'", busSubType)
+	}
+
+	diskParams := &types.DiskCreateParams{
+		Disk: &types.Disk{
+			Name:        name,
+			Description: description,
+			SizeMb:      sizeMb,
+			BusType:     bus.busType,
+			BusSubType:  busSubType,
+			Shareable:   true,
+			SharingType: DiskSharingMultiWriter,
+		},
+	}
+	if storageProfileName != "" {
+		storageProfile, err := vdc.FindStorageProfileReference(storageProfileName)
+		if err != nil {
+			return Task{}, fmt.Errorf("error finding storage profile '%s': %s", storageProfileName, err)
+		}
+		diskParams.Disk.StorageProfile = &storageProfile
+	}
+	return vdc.CreateDisk(diskParams)
+}
+
+// AttachToVms attaches the independent disk to the given VMs, using the same bus and unit numbers in all of them,
+// as clustering software usually requires. The bus and unit numbers are the first ones that are free in all VMs on
+// the disk controller of the disk bus sub type. Only shared disks can be attached to more than one VM.
+// It returns the bus and unit numbers used. When attaching to one of the VMs fails, the disk is detached from the VMs
+// where it was attached by this call.
+func (disk *Disk) AttachToVms(vms []*VM) (int, int, error) {
+	if len(vms) == 0 {
+		return 0, 0, fmt.Errorf("at least one VM is required")
+	}
+	err := disk.Refresh()
+	if err != nil {
+		return 0, 0, fmt.Errorf("error refreshing disk: %s", err)
+	}
+	attachedVms, err := disk.GetAttachedVmsHrefs()
+	if err != nil {
+		return 0, 0, fmt.Errorf("error retrieving VMs attached to disk '%s': %s", disk.Disk.Name, err)
+	}
+	for _, vm := range vms {
+		if contains(vm.VM.HREF, attachedVms) {
+			return 0, 0, fmt.Errorf("disk '%s' is already attached to VM '%s'", disk.Disk.Name, vm.VM.Name)
+		}
+	}
+	if !disk.Disk.Shareable && len(attachedVms)+len(vms) > 1 {
+		return 0, 0, fmt.Errorf("disk '%s' is not shareable and can only be attached to one VM", disk.Disk.Name)
+	}
+
+	busNumber, unitNumber, err := disk.selectBusUnit(vms)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var attached []*VM
+	for _, vm := range vms {
+		task, err := vm.AttachDisk(&types.DiskAttachOrDetachParams{
+			Disk:       &types.Reference{HREF: disk.Disk.HREF},
+			BusNumber:  addrOf(busNumber),
+			UnitNumber: addrOf(unitNumber),
+		})
+		if err == nil {
+			err = task.WaitTaskCompletion()
+		}
+		if err != nil {
+			attachErr := fmt.Errorf("error attaching disk '%s' to VM '%s': %s", disk.Disk.Name, vm.VM.Name, err)
+			rollbackErr := disk.detachFromVms(attached)
+			if rollbackErr != nil {
+				return 0, 0, fmt.Errorf("%s. Error detaching the disk from the VMs where it was attached: %s", attachErr, rollbackErr)
+			}
+			return 0, 0, attachErr
+		}
+		attached = append(attached, vm)
+	}
+	return busNumber, unitNumber, nil
+}
+
+// detachFromVms detaches the independent disk from the given VMs, returning the errors of all of them
+func (disk *Disk) detachFromVms(vms []*VM) error {
+	var errs []string
+	for _, vm := range vms {
+		task, err := vm.DetachDisk(&types.DiskAttachOrDetachParams{Disk: &types.Reference{HREF: disk.Disk.HREF}})
+		if err == nil {
+			err = task.WaitTaskCompletion()
+		}
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("VM '%s': %s", vm.VM.Name, err))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// DetachFromAllVms detaches the independent disk from all the VMs where it is attached
+func (disk *Disk) DetachFromAllVms() error {
+	attachedVms, err := disk.GetAttachedVmsHrefs()
+	if err != nil {
+		return fmt.Errorf("error retrieving VMs attached to disk '%s': %s", disk.Disk.Name, err)
+	}
+	for _, vmHref := range attachedVms {
+		vm, err := disk.client.GetVMByHref(vmHref)
+		if err != nil {
+			return fmt.Errorf("error retrieving VM %s: %s", vmHref, err)
+		}
+		task, err := vm.DetachDisk(&types.DiskAttachOrDetachParams{Disk: &types.Reference{HREF: disk.Disk.HREF}})
+		if err == nil {
+			err = task.WaitTaskCompletion()
+		}
+		if err != nil {
+			return fmt.Errorf("error detaching disk '%s' from VM '%s': %s", disk.Disk.Name, vm.VM.Name, err)
+		}
+	}
+	return disk.Refresh()
+}
+
+// selectBusUnit finds bus and unit numbers that are free in all the VMs, using the disk controller limits of the
+// hardware version of the first VM
+func (disk *Disk) selectBusUnit(vms []*VM) (int, int, error) {
+	busSubType := disk.Disk.BusSubType
+	if busSubType == "" {
+		busSubType = "lsilogicsas"
+	}
+	bus, ok := diskBuses[busSubType]
+	if !ok {
+		return 0, 0, fmt.Errorf("unknown bus sub type '%s' of disk '%s'", busSubType, disk.Disk.Name)
+	}
+
+	var vmDisks [][]*types.DiskSettings
+	for _, vm := range vms {
+		err := vm.Refresh()
+		if err != nil {
+			return 0, 0, fmt.Errorf("error refreshing VM '%s': %s", vm.VM.Name, err)
+		}
+		if vm.VM.VmSpecSection == nil || vm.VM.VmSpecSection.HardwareVersion == nil {
+			return 0, 0, fmt.Errorf("VM '%s' has no hardware specification", vm.VM.Name)
+		}
+		var disks []*types.DiskSettings
+		if vm.VM.VmSpecSection.DiskSection != nil {
+			disks = vm.VM.VmSpecSection.DiskSection.DiskSettings
+		}
+		vmDisks = append(vmDisks, disks)
+	}
+
+	vdc, err := vms[0].GetParentVdc()
+	if err != nil {
+		return 0, 0, err
+	}
+	hardwareVersion, err := vdc.GetHardwareVersion(vms[0].VM.VmSpecSection.HardwareVersion.Value)
+	if err != nil {
+		return 0, 0, fmt.Errorf("error retrieving hardware version of VM '%s': %s", vms[0].VM.Name, err)
+	}
+	adapter := findHardDiskAdapter(hardwareVersion.HardDiskAdapter, bus.vmAdapterType)
+	if adapter == nil {
+		return 0, 0, fmt.Errorf("hardware version %s has no disk controller for bus sub type '%s'", hardwareVersion.Name, busSubType)
+	}
+
+	busNumber, unitNumber, ok := selectCommonDiskBusUnit(adapter, vmDisks)
+	if !ok {
+		return 0, 0, fmt.Errorf("no bus and unit numbers are free on controller '%s' in all VMs", adapter.Name)
+	}
+	return busNumber, unitNumber, nil
+}
+
+// selectCommonDiskBusUnit returns the first bus and unit numbers of the disk controller that are free in all the
+// given disk lists
+func selectCommonDiskBusUnit(adapter *types.HardDiskAdapter, vmDisks [][]*types.DiskSettings) (int, int, bool) {
+	used := make(map[string]bool)
+	for _, disks := range vmDisks {
+		for _, disk := range disks {
+			if disk.AdapterType == strconv.Itoa(adapter.LegacyId) || disk.AdapterType == adapter.Id {
+				used[diskSlotKey(adapter, disk.BusNumber, disk.UnitNumber)] = true
+			}
+		}
+	}
+	candidate := &types.DiskSettings{}
+	if !assignDiskBusUnit(candidate, adapter, used) {
+		return 0, 0, false
+	}
+	return candidate.BusNumber, candidate.UnitNumber, true
+}
+
+// GetDiskUsageReport returns the size, storage, bus and sharing settings of all the independent disks of the VDC,
+// with the VMs where each disk is attached
+func (vdc *Vdc) GetDiskUsageReport() ([]*DiskUsageReportEntry, error) {
+	queryType := vdc.client.GetQueryType(types.QtDisk)
+	results, err := vdc.client.cumulativeQuery(queryType, nil, map[string]string{
+		"type":          queryType,
+		"filter":        "vdc==" + vdc.vdcId(),
+		"filterEncoded": "true",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error querying disks: %s", err)
+	}
+	records := results.Results.DiskRecord
+	if vdc.client.IsSysAdmin {
+		records = results.Results.AdminDiskRecord
+	}
+
+	entries := make([]*DiskUsageReportEntry, len(records))
+	var attached []*DiskUsageReportEntry
+	for index, record := range records {
+		entries[index] = &DiskUsageReportEntry{
+			Name:               record.Name,
+			HREF:               record.HREF,
+			Description:        record.Description,
+			Status:             record.Status,
+			OwnerName:          record.OwnerName,
+			StorageProfileName: record.StorageProfileName,
+			DatastoreName:      record.DataStoreName,
+			SizeMb:             record.SizeMb,
+			Iops:               record.Iops,
+			BusType:            record.BusType,
+			BusSubType:         record.BusSubType,
+			SharingType:        record.SharingType,
+			Shareable:          record.IsShareable,
+			Encrypted:          record.Encrypted,
+		}
+		if record.IsAttached || record.AttachedVmCount > 0 {
+			attached = append(attached, entries[index])
+		}
+	}
+
+	items := NewBatchItems(attached, func(entry *DiskUsageReportEntry) string { return entry.Name },
+		func(entry *DiskUsageReportEntry) (*Task, error) {
+			vms := &types.Vms{}
+			_, err := vdc.client.ExecuteRequest(entry.HREF+"/attachedVms", http.MethodGet, types.MimeVMs,
+				"error retrieving attached VMs: %s", nil, vms)
+			if err != nil {
+				return nil, err
+			}
+			entry.AttachedVms = vms.VmReference
+			return nil, nil
+		})
+	err = RunBatch(items, nil).Err()
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving VMs attached to disks: %s", err)
+	}
+	return entries, nil
+}
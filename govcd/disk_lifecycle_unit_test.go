@@ -0,0 +1,75 @@
+//go:build unit || ALL
+
+/*
+ * Copyright 2025 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+package govcd
+
+import (
+	"testing"
+
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// Test_validateDiskResize checks size, sharing and storage profile limit validations
+func Test_validateDiskResize(t *testing.T) {
+	profile := &types.VdcStorageProfile{Name: "gold", Units: "GB", Limit: 10, StorageUsedMB: 8 * 1024}
+
+	tests := []struct {
+		name        string
+		disk        *types.Disk
+		newSizeMb   int64
+		profile     *types.VdcStorageProfile
+		attachedVms int
+		wantErr     bool
+	}{
+		{"grow within limit", &types.Disk{Name: "data", SizeMb: 1024}, 3072, profile, 1, false},
+		{"grow beyond limit", &types.Disk{Name: "data", SizeMb: 1024}, 4096, profile, 0, true},
+		{"shrink", &types.Disk{Name: "data", SizeMb: 1024}, 512, profile, 0, true},
+		{"attached shared disk", &types.Disk{Name: "quorum", SizeMb: 1024, Shareable: true}, 2048, nil, 2, true},
+		{"detached shared disk", &types.Disk{Name: "quorum", SizeMb: 1024, Shareable: true}, 2048, nil, 0, false},
+		{"unlimited profile", &types.Disk{Name: "data", SizeMb: 1024}, 1024 * 1024, &types.VdcStorageProfile{Units: "MB"}, 0, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := validateDiskResize(test.disk, test.newSizeMb, test.profile, test.attachedVms)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("expected error: %t, got %v", test.wantErr, err)
+			}
+		})
+	}
+}
+
+// Test_selectCommonDiskBusUnit checks that the selected slot is free in all VMs and skips reserved units
+func Test_selectCommonDiskBusUnit(t *testing.T) {
+	adapter := &types.HardDiskAdapter{Id: "VirtualSCSI", LegacyId: 5, Name: "Paravirtual"}
+	adapter.BusNumberRanges.End = 3
+	adapter.UnitNumberRanges.End = 15
+	adapter.ReservedBusUnitNumber.UnitNumber = 7
+
+	vmDisks := [][]*types.DiskSettings{
+		{
+			{AdapterType: "5", BusNumber: 0, UnitNumber: 0},
+			{AdapterType: "5", BusNumber: 0, UnitNumber: 1},
+			{AdapterType: "4", BusNumber: 0, UnitNumber: 2},
+		},
+		{
+			{AdapterType: "5", BusNumber: 0, UnitNumber: 0},
+			{AdapterType: "5", BusNumber: 0, UnitNumber: 2},
+			{AdapterType: "5", BusNumber: 0, UnitNumber: 3},
+		},
+	}
+	bus, unit, ok := selectCommonDiskBusUnit(adapter, vmDisks)
+	if !ok || bus != 0 || unit != 4 {
+		t.Fatalf("expected bus 0 unit 4, got %d %d %t", bus, unit, ok)
+	}
+
+	for unit := 4; unit <= 15; unit++ {
+		vmDisks[0] = append(vmDisks[0], &types.DiskSettings{AdapterType: "5", BusNumber: 0, UnitNumber: unit})
+	}
+	bus, unit, ok = selectCommonDiskBusUnit(adapter, vmDisks)
+	if !ok || bus != 1 || unit != 0 {
+		t.Fatalf("expected bus 1 unit 0, got %d %d %t", bus, unit, ok)
+	}
+}
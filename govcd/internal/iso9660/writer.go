@@ -0,0 +1,348 @@
+/*
+ * Copyright 2025 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+// Package iso9660 writes small ISO 9660 images with Joliet extensions. Images have a single root directory, which
+// is enough for seed images such as cloud-init NoCloud data sources.
+package iso9660
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+const (
+	sectorSize = 2048
+
+	// The first 16 sectors are the system area, followed by the volume descriptors
+	systemAreaSectors = 16
+
+	volumeDescriptorPrimary       = 1
+	volumeDescriptorSupplementary = 2
+	volumeDescriptorTerminator    = 255
+
+	fileFlagDirectory = 0x02
+
+	// Joliet level 3 escape sequence, stored in the supplementary volume descriptor
+	jolietEscapeSequence = "%/E"
+
+	maxVolumeIdentifierLength = 16
+	maxJolietNameLength       = 64
+	dirRecordFixedLength      = 33
+	pathTableSize             = 10
+)
+
+// File is a file to be stored in the root directory of the image
+type File struct {
+	Name string
+	Data []byte
+}
+
+// Build returns an ISO 9660 image with Joliet extensions containing the given files in its root directory.
+// volumeIdentifier is the label of the volume, and modTime is used as creation and modification date of the
+// volume and its files.
+func Build(volumeIdentifier string, files []File, modTime time.Time) ([]byte, error) {
+	if volumeIdentifier == "" || len(volumeIdentifier) > maxVolumeIdentifierLength {
+		return nil, fmt.Errorf("volume identifier must have between 1 and %d characters", maxVolumeIdentifierLength)
+	}
+
+	sorted := make([]File, len(files))
+	copy(sorted, files)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+
+	primaryNames := make([]string, len(sorted))
+	jolietNames := make([]string, len(sorted))
+	for index, file := range sorted {
+		if file.Name == "" || strings.ContainsAny(file.Name, `/\`) {
+			return nil, fmt.Errorf("invalid file name '%s'", file.Name)
+		}
+		if index > 0 && file.Name == sorted[index-1].Name {
+			return nil, fmt.Errorf("duplicate file name '%s'", file.Name)
+		}
+		if len(utf16.Encode([]rune(file.Name))) > maxJolietNameLength {
+			return nil, fmt.Errorf("file name '%s' is longer than %d characters", file.Name, maxJolietNameLength)
+		}
+		jolietNames[index] = file.Name + ";1"
+	}
+	err := assignPrimaryNames(sorted, primaryNames)
+	if err != nil {
+		return nil, err
+	}
+
+	// Layout: system area, primary, supplementary and terminator descriptors, the four path tables, the two root
+	// directories and then the file contents, which are shared by both directory trees
+	primaryPathTableL := uint32(systemAreaSectors + 3)
+	primaryPathTableM := primaryPathTableL + 1
+	jolietPathTableL := primaryPathTableL + 2
+	jolietPathTableM := primaryPathTableL + 3
+	primaryRoot := primaryPathTableL + 4
+	primaryRootSectors := directorySectors(primaryNames, encodePrimaryName)
+	jolietRoot := primaryRoot + primaryRootSectors
+	jolietRootSectors := directorySectors(jolietNames, encodeJolietName)
+
+	fileExtents := make([]uint32, len(sorted))
+	nextSector := jolietRoot + jolietRootSectors
+	for index, file := range sorted {
+		fileExtents[index] = nextSector
+		nextSector += sectorsFor(len(file.Data))
+	}
+	totalSectors := nextSector
+
+	image := make([]byte, int(totalSectors)*sectorSize)
+
+	primaryRootRecord := directoryRecord(primaryRoot, primaryRootSectors*sectorSize, fileFlagDirectory, []byte{0}, modTime)
+	jolietRootRecord := directoryRecord(jolietRoot, jolietRootSectors*sectorSize, fileFlagDirectory, []byte{0}, modTime)
+
+	writeVolumeDescriptor(sectorAt(image, systemAreaSectors), volumeDescriptor{
+		kind:             volumeDescriptorPrimary,
+		volumeIdentifier: strings.ToUpper(volumeIdentifier),
+		totalSectors:     totalSectors,
+		pathTableL:       primaryPathTableL,
+		pathTableM:       primaryPathTableM,
+		rootRecord:       primaryRootRecord,
+		modTime:          modTime,
+	})
+	writeVolumeDescriptor(sectorAt(image, systemAreaSectors+1), volumeDescriptor{
+		kind:             volumeDescriptorSupplementary,
+		volumeIdentifier: volumeIdentifier,
+		totalSectors:     totalSectors,
+		pathTableL:       jolietPathTableL,
+		pathTableM:       jolietPathTableM,
+		rootRecord:       jolietRootRecord,
+		modTime:          modTime,
+	})
+	terminator := sectorAt(image, systemAreaSectors+2)
+	terminator[0] = volumeDescriptorTerminator
+	copy(terminator[1:], "CD001")
+	terminator[6] = 1
+
+	writePathTable(sectorAt(image, primaryPathTableL), primaryRoot, binary.LittleEndian)
+	writePathTable(sectorAt(image, primaryPathTableM), primaryRoot, binary.BigEndian)
+	writePathTable(sectorAt(image, jolietPathTableL), jolietRoot, binary.LittleEndian)
+	writePathTable(sectorAt(image, jolietPathTableM), jolietRoot, binary.BigEndian)
+
+	writeDirectory(image, primaryRoot, primaryRootRecord, sorted, primaryNames, fileExtents, encodePrimaryName, modTime)
+	writeDirectory(image, jolietRoot, jolietRootRecord, sorted, jolietNames, fileExtents, encodeJolietName, modTime)
+
+	for index, file := range sorted {
+		copy(image[int(fileExtents[index])*sectorSize:], file.Data)
+	}
+	return image, nil
+}
+
+// assignPrimaryNames sets ISO 9660 level 1 names (8.3 upper case d-characters) for the files, adding a numeric
+// suffix when two names collide
+func assignPrimaryNames(files []File, names []string) error {
+	used := make(map[string]bool)
+	for index, file := range files {
+		base, extension := file.Name, ""
+		if dot := strings.LastIndex(file.Name, "."); dot > 0 {
+			base, extension = file.Name[:dot], file.Name[dot+1:]
+		}
+		base, extension = toDCharacters(base, 8), toDCharacters(extension, 3)
+
+		candidate := base + "." + extension + ";1"
+		for counter := 1; used[candidate]; counter++ {
+			if counter > 999 {
+				return fmt.Errorf("too many files with a name similar to '%s'", file.Name)
+			}
+			suffix := fmt.Sprintf("%d", counter)
+			prefix := base
+			if len(prefix)+len(suffix) > 8 {
+				prefix = prefix[:8-len(suffix)]
+			}
+			candidate = prefix + suffix + "." + extension + ";1"
+		}
+		used[candidate] = true
+		names[index] = candidate
+	}
+	return nil
+}
+
+// toDCharacters converts a name to upper case d-characters (A-Z, 0-9 and _), truncated to maxLength
+func toDCharacters(name string, maxLength int) string {
+	var result strings.Builder
+	for _, character := range strings.ToUpper(name) {
+		if result.Len() == maxLength {
+			break
+		}
+		if (character >= 'A' && character <= 'Z') || (character >= '0' && character <= '9') {
+			result.WriteRune(character)
+		} else {
+			result.WriteByte('_')
+		}
+	}
+	return result.String()
+}
+
+func encodePrimaryName(name string) []byte {
+	return []byte(name)
+}
+
+func encodeJolietName(name string) []byte {
+	return ucs2(name)
+}
+
+// ucs2 encodes a string as big endian UCS-2, as required by Joliet
+func ucs2(value string) []byte {
+	var result []byte
+	for _, unit := range utf16.Encode([]rune(value)) {
+		result = append(result, byte(unit>>8), byte(unit))
+	}
+	return result
+}
+
+type volumeDescriptor struct {
+	kind             byte
+	volumeIdentifier string
+	totalSectors     uint32
+	pathTableL       uint32
+	pathTableM       uint32
+	rootRecord       []byte
+	modTime          time.Time
+}
+
+// writeVolumeDescriptor writes a primary or Joliet supplementary volume descriptor (ECMA-119 8.4 and 8.5)
+func writeVolumeDescriptor(sector []byte, descriptor volumeDescriptor) {
+	sector[0] = descriptor.kind
+	copy(sector[1:], "CD001")
+	sector[6] = 1
+
+	textField := func(offset, length int, value string) {
+		if descriptor.kind == volumeDescriptorSupplementary {
+			encoded := ucs2(value)
+			for position := 0; position+1 < length; position += 2 {
+				sector[offset+position], sector[offset+position+1] = 0x00, ' '
+			}
+			copy(sector[offset:offset+length], encoded)
+			return
+		}
+		copy(sector[offset:offset+length], bytes.Repeat([]byte{' '}, length))
+		copy(sector[offset:offset+length], value)
+	}
+
+	textField(8, 32, "")
+	textField(40, 32, descriptor.volumeIdentifier)
+	putBothEndian32(sector[80:], descriptor.totalSectors)
+	if descriptor.kind == volumeDescriptorSupplementary {
+		copy(sector[88:], jolietEscapeSequence)
+	}
+	putBothEndian16(sector[120:], 1)
+	putBothEndian16(sector[124:], 1)
+	putBothEndian16(sector[128:], sectorSize)
+	putBothEndian32(sector[132:], pathTableSize)
+	binary.LittleEndian.PutUint32(sector[140:], descriptor.pathTableL)
+	binary.BigEndian.PutUint32(sector[148:], descriptor.pathTableM)
+	copy(sector[156:], descriptor.rootRecord)
+	textField(190, 128, "")
+	textField(318, 128, "")
+	textField(446, 128, "")
+	textField(574, 128, "")
+	textField(702, 37, "")
+	textField(739, 37, "")
+	textField(776, 37, "")
+	copy(sector[813:], volumeDate(descriptor.modTime))
+	copy(sector[830:], volumeDate(descriptor.modTime))
+	copy(sector[847:], volumeDate(time.Time{}))
+	copy(sector[864:], volumeDate(time.Time{}))
+	sector[881] = 1
+}
+
+// writePathTable writes a path table containing only the root directory
+func writePathTable(sector []byte, rootExtent uint32, byteOrder binary.ByteOrder) {
+	sector[0] = 1
+	byteOrder.PutUint32(sector[2:], rootExtent)
+	byteOrder.PutUint16(sector[6:], 1)
+}
+
+// writeDirectory writes the root directory records, never letting a record cross a sector boundary
+func writeDirectory(image []byte, extent uint32, rootRecord []byte, files []File, names []string, fileExtents []uint32,
+	encode func(string) []byte, modTime time.Time) {
+	offset := int(extent) * sectorSize
+	position := 0
+	write := func(record []byte) {
+		if position%sectorSize+len(record) > sectorSize {
+			position += sectorSize - position%sectorSize
+		}
+		copy(image[offset+position:], record)
+		position += len(record)
+	}
+
+	parentRecord := make([]byte, len(rootRecord))
+	copy(parentRecord, rootRecord)
+	parentRecord[33] = 1
+
+	write(rootRecord)
+	write(parentRecord)
+	for index, file := range files {
+		write(directoryRecord(fileExtents[index], uint32(len(file.Data)), 0, encode(names[index]), modTime))
+	}
+}
+
+// directorySectors returns the number of sectors needed by a root directory with the given names
+func directorySectors(names []string, encode func(string) []byte) uint32 {
+	position := 2 * (dirRecordFixedLength + 1)
+	for _, name := range names {
+		length := recordLength(len(encode(name)))
+		if position%sectorSize+length > sectorSize {
+			position += sectorSize - position%sectorSize
+		}
+		position += length
+	}
+	return sectorsFor(position)
+}
+
+func recordLength(identifierLength int) int {
+	length := dirRecordFixedLength + identifierLength
+	if identifierLength%2 == 0 {
+		length++
+	}
+	return length
+}
+
+// directoryRecord builds a directory record (ECMA-119 9.1)
+func directoryRecord(extent, dataLength uint32, flags byte, identifier []byte, modTime time.Time) []byte {
+	record := make([]byte, recordLength(len(identifier)))
+	record[0] = byte(len(record))
+	putBothEndian32(record[2:], extent)
+	putBothEndian32(record[10:], dataLength)
+	utc := modTime.UTC()
+	copy(record[18:], []byte{byte(utc.Year() - 1900), byte(utc.Month()), byte(utc.Day()),
+		byte(utc.Hour()), byte(utc.Minute()), byte(utc.Second()), 0})
+	record[25] = flags
+	putBothEndian16(record[28:], 1)
+	record[32] = byte(len(identifier))
+	copy(record[33:], identifier)
+	return record
+}
+
+// volumeDate formats a date as used in volume descriptors (ECMA-119 8.4.26.1). A zero time means "not specified"
+func volumeDate(date time.Time) []byte {
+	if date.IsZero() {
+		return append([]byte("0000000000000000"), 0)
+	}
+	return append([]byte(date.UTC().Format("20060102150405")+"00"), 0)
+}
+
+func putBothEndian16(buffer []byte, value uint16) {
+	binary.LittleEndian.PutUint16(buffer, value)
+	binary.BigEndian.PutUint16(buffer[2:], value)
+}
+
+func putBothEndian32(buffer []byte, value uint32) {
+	binary.LittleEndian.PutUint32(buffer, value)
+	binary.BigEndian.PutUint32(buffer[4:], value)
+}
+
+func sectorsFor(size int) uint32 {
+	return uint32((size + sectorSize - 1) / sectorSize)
+}
+
+func sectorAt(image []byte, sector uint32) []byte {
+	return image[int(sector)*sectorSize : int(sector+1)*sectorSize]
+}
@@ -0,0 +1,731 @@
+/*
+ * Copyright 2025 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+package govcd
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"net/netip"
+	"sort"
+	"sync"
+
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// Usages of IP addresses reported in IpamAllocation
+const (
+	// IpamUsageGateway is the gateway address of a subnet
+	IpamUsageGateway = "gateway"
+	// IpamUsageVmNic is an address assigned to a VM NIC
+	IpamUsageVmNic = "vmNic"
+	// IpamUsageEdgeGateway is an address used by an Edge Gateway, as reported by NsxtEdgeGateway.GetUsedIpAddresses
+	IpamUsageEdgeGateway = "edgeGateway"
+	// IpamUsageDhcpBinding is an address reserved by a DHCP binding
+	IpamUsageDhcpBinding = "dhcpBinding"
+	// IpamUsageIpSpace is a floating IP allocated from an IP Space
+	IpamUsageIpSpace = "ipSpace"
+	// IpamUsageReservation is an address reserved with Ipam.ReserveNextFree
+	IpamUsageReservation = "reservation"
+)
+
+// Kinds of IpamConflict
+const (
+	// IpamConflictDuplicate is an address used by more than one owner in the same network
+	IpamConflictDuplicate = "duplicate"
+	// IpamConflictStaticInDhcpPool is a static address or DHCP binding inside a DHCP pool
+	IpamConflictStaticInDhcpPool = "staticInDhcpPool"
+	// IpamConflictPoolOverlap is a static IP pool that overlaps a DHCP pool
+	IpamConflictPoolOverlap = "poolOverlap"
+	// IpamConflictOutsideSubnet is an address that does not belong to any subnet of its network
+	IpamConflictOutsideSubnet = "outsideSubnet"
+)
+
+// IpamRange is an inclusive range of IP addresses
+type IpamRange struct {
+	Start netip.Addr
+	End   netip.Addr
+}
+
+// IpamSubnet is a subnet of an Org VDC network or of an Edge Gateway uplink
+type IpamSubnet struct {
+	// Network is the name of the Org VDC network. Edge Gateway uplinks are named "<edge gateway>/<uplink>"
+	Network   string
+	NetworkId string
+	Prefix    netip.Prefix
+	Gateway   netip.Addr
+	// StaticPools are the ranges from which static addresses are assigned
+	StaticPools []IpamRange
+	DhcpPools   []IpamRange
+}
+
+// IpamAllocation is an IP address in use or reserved
+type IpamAllocation struct {
+	Address netip.Addr
+	// Usage is one of the IpamUsage* constants
+	Usage string
+	// Network is the name of the network the address belongs to. When empty, the address belongs to all
+	// subnets that contain it
+	Network string
+	// Owner describes the user of the address, such as a VM name or a DHCP binding MAC address
+	Owner string
+	// Static is false for VM NIC addresses assigned by DHCP
+	Static bool
+}
+
+// IpamSubnetUsage contains the address usage of a single subnet. Counts are limited to math.MaxUint64, which
+// matters only for large IPv6 pools.
+type IpamSubnetUsage struct {
+	IpamSubnet
+	// Total is the number of addresses in static pools
+	Total uint64
+	// Used is the number of addresses in static pools that are in use
+	Used uint64
+	// Reserved is the number of addresses in static pools that are reserved, but not in use
+	Reserved uint64
+	// Free is the number of addresses in static pools that are neither used, reserved nor part of a DHCP pool
+	Free uint64
+	// Allocations are all allocations in the subnet, sorted by address
+	Allocations []IpamAllocation
+}
+
+// IpamConflict is an inconsistency in address assignments
+type IpamConflict struct {
+	Kind        string
+	Network     string
+	Address     netip.Addr
+	Allocations []IpamAllocation
+	Reason      string
+}
+
+// Ipam is an IP address management view of the networks of a VDC or a VDC Group. It is a snapshot: changes
+// made in VCD after it is built are not reflected.
+// Reservations made with ReserveNextFree are kept in memory and are safe for concurrent use by multiple
+// goroutines sharing the same Ipam.
+type Ipam struct {
+	mutex       sync.Mutex
+	subnets     []IpamSubnet
+	allocations []IpamAllocation
+}
+
+// IpamOptions define which sources are used to build an Ipam
+type IpamOptions struct {
+	// IpSpaces whose floating IP allocations are reported as IpamUsageIpSpace
+	IpSpaces []*IpSpace
+	// ReadVmNics retrieves every VM to read the addresses of all its NICs. Otherwise, only the primary NIC of
+	// each VM, as returned by the VM query, is used
+	ReadVmNics bool
+}
+
+// NewIpam creates an Ipam from subnets and allocations. It is used by Vdc.GetIpam and VdcGroup.GetIpam, and can
+// be used to build a view from other sources
+func NewIpam(subnets []IpamSubnet, allocations []IpamAllocation) (*Ipam, error) {
+	for _, subnet := range subnets {
+		if !subnet.Prefix.IsValid() {
+			return nil, fmt.Errorf("invalid prefix in subnet of network '%s'", subnet.Network)
+		}
+		for _, pool := range append(append([]IpamRange{}, subnet.StaticPools...), subnet.DhcpPools...) {
+			if !pool.Start.IsValid() || !pool.End.IsValid() || pool.Start.Is4() != pool.End.Is4() || pool.End.Less(pool.Start) {
+				return nil, fmt.Errorf("invalid range %s-%s in network '%s'", pool.Start, pool.End, subnet.Network)
+			}
+		}
+	}
+	for _, allocation := range allocations {
+		if !allocation.Address.IsValid() {
+			return nil, fmt.Errorf("invalid address for %s '%s'", allocation.Usage, allocation.Owner)
+		}
+	}
+	return &Ipam{subnets: subnets, allocations: allocations}, nil
+}
+
+// GetIpam builds an Ipam for the Org VDC networks and Edge Gateways of the VDC
+func (vdc *Vdc) GetIpam(options *IpamOptions) (*Ipam, error) {
+	networks, err := vdc.GetAllOpenApiOrgVdcNetworks(nil)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving Org VDC networks: %s", err)
+	}
+	edgeGateways, err := vdc.GetAllNsxtEdgeGateways(nil)
+	if err != nil && !ContainsNotFound(err) {
+		return nil, fmt.Errorf("error retrieving Edge Gateways: %s", err)
+	}
+	return buildIpam(vdc.client, networks, edgeGateways, []string{vdc.Vdc.HREF}, options)
+}
+
+// GetIpam builds an Ipam for the Org VDC networks and Edge Gateways of the VDC Group. VMs are read from all
+// local participating VDCs
+func (vdcGroup *VdcGroup) GetIpam(options *IpamOptions) (*Ipam, error) {
+	networks, err := vdcGroup.GetAllOpenApiOrgVdcNetworks(nil)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving Org VDC networks: %s", err)
+	}
+	edgeGateways, err := vdcGroup.GetAllNsxtEdgeGateways(nil)
+	if err != nil && !ContainsNotFound(err) {
+		return nil, fmt.Errorf("error retrieving Edge Gateways: %s", err)
+	}
+	var vdcHrefs []string
+	for _, participant := range vdcGroup.VdcGroup.ParticipatingOrgVdcs {
+		if participant.RemoteOrg {
+			continue
+		}
+		vdcHrefs = append(vdcHrefs, vdcGroup.client.rootVcdHref()+"/api/vdc/"+extractUuid(participant.VdcRef.ID))
+	}
+	return buildIpam(vdcGroup.client, networks, edgeGateways, vdcHrefs, options)
+}
+
+// buildIpam collects subnets and allocations from VCD
+func buildIpam(client *Client, networks []*OpenApiOrgVdcNetwork, edgeGateways []*NsxtEdgeGateway, vdcHrefs []string, options *IpamOptions) (*Ipam, error) {
+	if options == nil {
+		options = &IpamOptions{}
+	}
+	var subnets []IpamSubnet
+	var allocations []IpamAllocation
+
+	for _, network := range networks {
+		networkSubnets, networkAllocations, err := ipamFromOrgVdcNetwork(network)
+		if err != nil {
+			return nil, err
+		}
+		subnets = append(subnets, networkSubnets...)
+		allocations = append(allocations, networkAllocations...)
+	}
+
+	for _, edgeGateway := range edgeGateways {
+		for _, uplink := range edgeGateway.EdgeGateway.EdgeGatewayUplinks {
+			for _, subnet := range uplink.Subnets.Values {
+				ipamSubnet, err := newIpamSubnet(edgeGateway.EdgeGateway.Name+"/"+uplink.UplinkName, uplink.UplinkID, subnet.Gateway, subnet.PrefixLength)
+				if err != nil {
+					return nil, err
+				}
+				if subnet.IPRanges != nil {
+					for _, ipRange := range subnet.IPRanges.Values {
+						pool, err := parseIpamRange(ipRange.StartAddress, ipRange.EndAddress)
+						if err != nil {
+							return nil, err
+						}
+						ipamSubnet.StaticPools = append(ipamSubnet.StaticPools, pool)
+					}
+				}
+				subnets = append(subnets, ipamSubnet)
+			}
+		}
+		usedIpAddresses, err := edgeGateway.GetUsedIpAddresses(nil)
+		if err != nil {
+			return nil, fmt.Errorf("error retrieving used IP addresses of Edge Gateway '%s': %s", edgeGateway.EdgeGateway.Name, err)
+		}
+		for _, used := range usedIpAddresses {
+			address, err := netip.ParseAddr(used.IPAddress)
+			if err != nil {
+				return nil, fmt.Errorf("error parsing used IP address of Edge Gateway '%s': %s", edgeGateway.EdgeGateway.Name, err)
+			}
+			allocations = append(allocations, IpamAllocation{
+				Address: address,
+				Usage:   IpamUsageEdgeGateway,
+				Owner:   fmt.Sprintf("%s (%s)", edgeGateway.EdgeGateway.Name, used.Category),
+				Static:  true,
+			})
+		}
+	}
+
+	vmAllocations, err := ipamVmAllocations(client, vdcHrefs, options.ReadVmNics)
+	if err != nil {
+		return nil, err
+	}
+	allocations = append(allocations, vmAllocations...)
+
+	for _, ipSpace := range options.IpSpaces {
+		ipSpaceAllocations, err := ipSpace.GetAllIpSpaceAllocations(types.IpSpaceIpAllocationTypeFloatingIp, nil)
+		if err != nil {
+			return nil, fmt.Errorf("error retrieving allocations of IP Space '%s': %s", ipSpace.IpSpace.Name, err)
+		}
+		for _, allocation := range ipSpaceAllocations {
+			address, err := netip.ParseAddr(allocation.IpSpaceIpAllocation.Value)
+			if err != nil {
+				return nil, fmt.Errorf("error parsing allocation of IP Space '%s': %s", ipSpace.IpSpace.Name, err)
+			}
+			owner := ipSpace.IpSpace.Name
+			if allocation.IpSpaceIpAllocation.UsedByRef != nil {
+				owner = allocation.IpSpaceIpAllocation.UsedByRef.Name
+			}
+			allocations = append(allocations, IpamAllocation{Address: address, Usage: IpamUsageIpSpace, Owner: owner, Static: true})
+		}
+	}
+
+	return NewIpam(subnets, allocations)
+}
+
+// ipamFromOrgVdcNetwork returns the subnets, DHCP pools and DHCP bindings of an Org VDC network
+func ipamFromOrgVdcNetwork(network *OpenApiOrgVdcNetwork) ([]IpamSubnet, []IpamAllocation, error) {
+	config := network.OpenApiOrgVdcNetwork
+	var subnets []IpamSubnet
+	for _, subnet := range config.Subnets.Values {
+		ipamSubnet, err := newIpamSubnet(config.Name, config.ID, subnet.Gateway, subnet.PrefixLength)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, ipRange := range subnet.IPRanges.Values {
+			pool, err := parseIpamRange(ipRange.StartAddress, ipRange.EndAddress)
+			if err != nil {
+				return nil, nil, fmt.Errorf("error parsing static pool of network '%s': %s", config.Name, err)
+			}
+			ipamSubnet.StaticPools = append(ipamSubnet.StaticPools, pool)
+		}
+		subnets = append(subnets, ipamSubnet)
+	}
+
+	if !network.IsNsxt() || network.IsImported() {
+		return subnets, nil, nil
+	}
+	dhcp, err := network.GetOpenApiOrgVdcNetworkDhcp()
+	if err != nil {
+		return nil, nil, fmt.Errorf("error retrieving DHCP configuration of network '%s': %s", config.Name, err)
+	}
+	if dhcp.OpenApiOrgVdcNetworkDhcp.Enabled == nil || !*dhcp.OpenApiOrgVdcNetworkDhcp.Enabled {
+		return subnets, nil, nil
+	}
+	for _, dhcpPool := range dhcp.OpenApiOrgVdcNetworkDhcp.DhcpPools {
+		if dhcpPool.Enabled != nil && !*dhcpPool.Enabled {
+			continue
+		}
+		pool, err := parseIpamRange(dhcpPool.IPRange.StartAddress, dhcpPool.IPRange.EndAddress)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error parsing DHCP pool of network '%s': %s", config.Name, err)
+		}
+		for index := range subnets {
+			if subnets[index].Prefix.Contains(pool.Start) {
+				subnets[index].DhcpPools = append(subnets[index].DhcpPools, pool)
+			}
+		}
+	}
+
+	bindings, err := network.GetAllOpenApiOrgVdcNetworkDhcpBindings(nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error retrieving DHCP bindings of network '%s': %s", config.Name, err)
+	}
+	var allocations []IpamAllocation
+	for _, binding := range bindings {
+		if binding.OpenApiOrgVdcNetworkDhcpBinding.IpAddress == "" {
+			continue
+		}
+		address, err := netip.ParseAddr(binding.OpenApiOrgVdcNetworkDhcpBinding.IpAddress)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error parsing DHCP binding of network '%s': %s", config.Name, err)
+		}
+		allocations = append(allocations, IpamAllocation{
+			Address: address,
+			Usage:   IpamUsageDhcpBinding,
+			Network: config.Name,
+			Owner:   binding.OpenApiOrgVdcNetworkDhcpBinding.MacAddress,
+			Static:  true,
+		})
+	}
+	return subnets, allocations, nil
+}
+
+// ipamVmAllocations returns the NIC addresses of the VMs in the given VDCs
+func ipamVmAllocations(client *Client, vdcHrefs []string, readVmNics bool) ([]IpamAllocation, error) {
+	var allocations []IpamAllocation
+	for _, vdcHref := range vdcHrefs {
+		vmRecords, err := queryVmList(types.VmQueryFilterAll, client, "vdc", vdcHref)
+		if err != nil {
+			return nil, fmt.Errorf("error retrieving VMs: %s", err)
+		}
+		for _, vmRecord := range vmRecords {
+			if !readVmNics {
+				if vmRecord.IpAddress == "" {
+					continue
+				}
+				address, err := netip.ParseAddr(vmRecord.IpAddress)
+				if err != nil {
+					return nil, fmt.Errorf("error parsing IP address of VM '%s': %s", vmRecord.Name, err)
+				}
+				// The VM query does not return the allocation mode, so the address is considered static
+				allocations = append(allocations, IpamAllocation{
+					Address: address,
+					Usage:   IpamUsageVmNic,
+					Network: vmRecord.NetworkName,
+					Owner:   vmRecord.Name,
+					Static:  true,
+				})
+				continue
+			}
+
+			vm, err := client.GetVMByHref(vmRecord.HREF)
+			if err != nil {
+				return nil, fmt.Errorf("error retrieving VM '%s': %s", vmRecord.Name, err)
+			}
+			if vm.VM.NetworkConnectionSection == nil {
+				continue
+			}
+			for _, nic := range vm.VM.NetworkConnectionSection.NetworkConnection {
+				if nic.IPAddress == "" {
+					continue
+				}
+				address, err := netip.ParseAddr(nic.IPAddress)
+				if err != nil {
+					return nil, fmt.Errorf("error parsing IP address of VM '%s': %s", vmRecord.Name, err)
+				}
+				allocations = append(allocations, IpamAllocation{
+					Address: address,
+					Usage:   IpamUsageVmNic,
+					Network: nic.Network,
+					Owner:   fmt.Sprintf("%s (NIC %d)", vmRecord.Name, nic.NetworkConnectionIndex),
+					Static:  nic.IPAddressAllocationMode != types.IPAllocationModeDHCP,
+				})
+			}
+		}
+	}
+	return allocations, nil
+}
+
+func newIpamSubnet(network, networkId, gateway string, prefixLength int) (IpamSubnet, error) {
+	gatewayAddress, err := netip.ParseAddr(gateway)
+	if err != nil {
+		return IpamSubnet{}, fmt.Errorf("error parsing gateway of network '%s': %s", network, err)
+	}
+	prefix, err := gatewayAddress.Prefix(prefixLength)
+	if err != nil {
+		return IpamSubnet{}, fmt.Errorf("error parsing subnet of network '%s': %s", network, err)
+	}
+	return IpamSubnet{Network: network, NetworkId: networkId, Prefix: prefix, Gateway: gatewayAddress}, nil
+}
+
+func parseIpamRange(start, end string) (IpamRange, error) {
+	startAddress, err := netip.ParseAddr(start)
+	if err != nil {
+		return IpamRange{}, err
+	}
+	endAddress := startAddress
+	if end != "" {
+		endAddress, err = netip.ParseAddr(end)
+		if err != nil {
+			return IpamRange{}, err
+		}
+	}
+	return IpamRange{Start: startAddress, End: endAddress}, nil
+}
+
+// Subnets returns the usage of all subnets
+func (ipam *Ipam) Subnets() []IpamSubnetUsage {
+	ipam.mutex.Lock()
+	defer ipam.mutex.Unlock()
+
+	var result []IpamSubnetUsage
+	for _, subnet := range ipam.subnets {
+		usage := IpamSubnetUsage{IpamSubnet: subnet, Allocations: ipam.subnetAllocations(subnet)}
+		used := make(map[netip.Addr]bool)
+		reserved := make(map[netip.Addr]bool)
+		blocked := make(map[netip.Addr]bool)
+		for _, allocation := range usage.Allocations {
+			if !ipamRangesContain(subnet.StaticPools, allocation.Address) {
+				continue
+			}
+			if isIpamReservation(allocation.Usage) {
+				reserved[allocation.Address] = true
+			} else {
+				used[allocation.Address] = true
+			}
+			if !ipamRangesContain(subnet.DhcpPools, allocation.Address) {
+				blocked[allocation.Address] = true
+			}
+		}
+		for address := range used {
+			delete(reserved, address)
+		}
+
+		staticPools := normaliseIpamRanges(subnet.StaticPools)
+		total := ipamRangesSize(staticPools)
+		inDhcp := ipamRangesSize(intersectIpamRanges(staticPools, normaliseIpamRanges(subnet.DhcpPools)))
+		free := new(big.Int).Sub(total, inDhcp)
+		free.Sub(free, big.NewInt(int64(len(blocked))))
+
+		usage.Total = saturateUint64(total)
+		usage.Used = uint64(len(used))
+		usage.Reserved = uint64(len(reserved))
+		usage.Free = saturateUint64(free)
+		result = append(result, usage)
+	}
+	return result
+}
+
+// Conflicts returns the inconsistencies found in address assignments
+func (ipam *Ipam) Conflicts() []IpamConflict {
+	ipam.mutex.Lock()
+	defer ipam.mutex.Unlock()
+
+	var conflicts []IpamConflict
+	for _, subnet := range ipam.subnets {
+		staticPools := normaliseIpamRanges(subnet.StaticPools)
+		dhcpPools := normaliseIpamRanges(subnet.DhcpPools)
+		for _, overlap := range intersectIpamRanges(staticPools, dhcpPools) {
+			conflicts = append(conflicts, IpamConflict{
+				Kind:    IpamConflictPoolOverlap,
+				Network: subnet.Network,
+				Address: overlap.Start,
+				Reason:  fmt.Sprintf("static pool overlaps DHCP pool in range %s-%s", overlap.Start, overlap.End),
+			})
+		}
+
+		byAddress := make(map[netip.Addr][]IpamAllocation)
+		var addresses []netip.Addr
+		for _, allocation := range ipam.subnetAllocations(subnet) {
+			if allocation.Usage == IpamUsageDhcpBinding || (allocation.Usage == IpamUsageVmNic && allocation.Static) {
+				if ipamRangesContain(dhcpPools, allocation.Address) {
+					conflicts = append(conflicts, IpamConflict{
+						Kind:        IpamConflictStaticInDhcpPool,
+						Network:     subnet.Network,
+						Address:     allocation.Address,
+						Allocations: []IpamAllocation{allocation},
+						Reason:      fmt.Sprintf("%s '%s' uses an address of a DHCP pool", allocation.Usage, allocation.Owner),
+					})
+				}
+			}
+			if isIpamReservation(allocation.Usage) {
+				continue
+			}
+			if _, seen := byAddress[allocation.Address]; !seen {
+				addresses = append(addresses, allocation.Address)
+			}
+			byAddress[allocation.Address] = append(byAddress[allocation.Address], allocation)
+		}
+		for _, address := range addresses {
+			if users := ipamDistinctUsers(byAddress[address]); len(users) > 1 {
+				conflicts = append(conflicts, IpamConflict{
+					Kind:        IpamConflictDuplicate,
+					Network:     subnet.Network,
+					Address:     address,
+					Allocations: users,
+					Reason:      fmt.Sprintf("address is used %d times", len(users)),
+				})
+			}
+		}
+	}
+
+	for _, allocation := range ipam.allocations {
+		if allocation.Network == "" {
+			continue
+		}
+		networkFound, inSubnet := false, false
+		for _, subnet := range ipam.subnets {
+			if subnet.Network != allocation.Network {
+				continue
+			}
+			networkFound = true
+			inSubnet = inSubnet || subnet.Prefix.Contains(allocation.Address)
+		}
+		if networkFound && !inSubnet {
+			conflicts = append(conflicts, IpamConflict{
+				Kind:        IpamConflictOutsideSubnet,
+				Network:     allocation.Network,
+				Address:     allocation.Address,
+				Allocations: []IpamAllocation{allocation},
+				Reason:      fmt.Sprintf("%s '%s' uses an address outside of the network subnets", allocation.Usage, allocation.Owner),
+			})
+		}
+	}
+	return conflicts
+}
+
+// ReserveNextFree reserves the first address of the static pools of the network that is neither used, reserved,
+// nor part of a DHCP pool. When ipv6 is true, only IPv6 subnets are considered, otherwise only IPv4 ones.
+// The reservation is only held by the Ipam and is not stored in VCD.
+func (ipam *Ipam) ReserveNextFree(network string, ipv6 bool, owner string) (netip.Addr, error) {
+	ipam.mutex.Lock()
+	defer ipam.mutex.Unlock()
+
+	networkFound := false
+	for _, subnet := range ipam.subnets {
+		if subnet.Network != network || subnet.Prefix.Addr().Is6() != ipv6 {
+			continue
+		}
+		networkFound = true
+		// subnetAllocations includes the gateway and is sorted by address
+		var taken []netip.Addr
+		for _, allocation := range ipam.subnetAllocations(subnet) {
+			taken = append(taken, allocation.Address)
+		}
+		dhcpPools := normaliseIpamRanges(subnet.DhcpPools)
+		for _, pool := range normaliseIpamRanges(subnet.StaticPools) {
+			if address, found := firstFreeIpamAddress(pool, dhcpPools, taken); found {
+				ipam.allocations = append(ipam.allocations, IpamAllocation{
+					Address: address,
+					Usage:   IpamUsageReservation,
+					Network: network,
+					Owner:   owner,
+					Static:  true,
+				})
+				return address, nil
+			}
+		}
+	}
+	if !networkFound {
+		return netip.Addr{}, fmt.Errorf("%s: no matching subnet in network '%s'", ErrorEntityNotFound, network)
+	}
+	return netip.Addr{}, fmt.Errorf("no free address in network '%s'", network)
+}
+
+// Release removes a reservation made with ReserveNextFree
+func (ipam *Ipam) Release(network string, address netip.Addr) error {
+	ipam.mutex.Lock()
+	defer ipam.mutex.Unlock()
+
+	for index, allocation := range ipam.allocations {
+		if allocation.Usage == IpamUsageReservation && allocation.Network == network && allocation.Address == address {
+			ipam.allocations = append(ipam.allocations[:index], ipam.allocations[index+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("%s: no reservation of %s in network '%s'", ErrorEntityNotFound, address, network)
+}
+
+// subnetAllocations returns the allocations that belong to the subnet, including its gateway, sorted by address
+func (ipam *Ipam) subnetAllocations(subnet IpamSubnet) []IpamAllocation {
+	result := []IpamAllocation{{
+		Address: subnet.Gateway,
+		Usage:   IpamUsageGateway,
+		Network: subnet.Network,
+		Owner:   subnet.Network,
+		Static:  true,
+	}}
+	for _, allocation := range ipam.allocations {
+		if allocation.Network != "" && allocation.Network != subnet.Network {
+			continue
+		}
+		if subnet.Prefix.Contains(allocation.Address) {
+			result = append(result, allocation)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Address.Less(result[j].Address) })
+	return result
+}
+
+// ipamDistinctUsers returns the allocations of an address that belong to different users. The gateway address is
+// expected to be used by the Edge Gateway, and multiple NICs of the same VM may share an address.
+func ipamDistinctUsers(allocations []IpamAllocation) []IpamAllocation {
+	hasEdgeGateway := false
+	for _, allocation := range allocations {
+		hasEdgeGateway = hasEdgeGateway || allocation.Usage == IpamUsageEdgeGateway
+	}
+	seen := make(map[string]bool)
+	var result []IpamAllocation
+	for _, allocation := range allocations {
+		if allocation.Usage == IpamUsageGateway && hasEdgeGateway {
+			continue
+		}
+		key := allocation.Usage + "/" + allocation.Owner
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, allocation)
+	}
+	return result
+}
+
+func isIpamReservation(usage string) bool {
+	return usage == IpamUsageDhcpBinding || usage == IpamUsageIpSpace || usage == IpamUsageReservation
+}
+
+// firstFreeIpamAddress returns the first address of the pool that is neither in the normalised DHCP pools nor
+// in the sorted taken addresses. DHCP pools are skipped as a whole, so that large IPv6 pools are never walked one
+// address at a time
+func firstFreeIpamAddress(pool IpamRange, dhcpPools []IpamRange, taken []netip.Addr) (netip.Addr, bool) {
+	index := 0
+	address := pool.Start
+	for address.IsValid() && !pool.End.Less(address) {
+		inDhcpPool := false
+		for _, dhcpPool := range dhcpPools {
+			if !address.Less(dhcpPool.Start) && !dhcpPool.End.Less(address) {
+				address = dhcpPool.End.Next()
+				inDhcpPool = true
+				break
+			}
+		}
+		if inDhcpPool {
+			continue
+		}
+		for index < len(taken) && taken[index].Less(address) {
+			index++
+		}
+		if index < len(taken) && taken[index] == address {
+			address = address.Next()
+			continue
+		}
+		return address, true
+	}
+	return netip.Addr{}, false
+}
+
+func ipamRangesContain(ranges []IpamRange, address netip.Addr) bool {
+	for _, ipRange := range ranges {
+		if !address.Less(ipRange.Start) && !ipRange.End.Less(address) {
+			return true
+		}
+	}
+	return false
+}
+
+// normaliseIpamRanges returns sorted ranges with overlapping and adjacent ranges merged
+func normaliseIpamRanges(ranges []IpamRange) []IpamRange {
+	sorted := append([]IpamRange{}, ranges...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Start.Less(sorted[j].Start) })
+	var merged []IpamRange
+	for _, current := range sorted {
+		if len(merged) > 0 {
+			last := &merged[len(merged)-1]
+			if last.End.Is4() == current.Start.Is4() && (!last.End.Less(current.Start) || last.End.Next() == current.Start) {
+				if last.End.Less(current.End) {
+					last.End = current.End
+				}
+				continue
+			}
+		}
+		merged = append(merged, current)
+	}
+	return merged
+}
+
+// intersectIpamRanges returns the ranges contained in both normalised lists
+func intersectIpamRanges(first, second []IpamRange) []IpamRange {
+	var result []IpamRange
+	for _, a := range first {
+		for _, b := range second {
+			if a.Start.Is4() != b.Start.Is4() {
+				continue
+			}
+			start, end := a.Start, a.End
+			if start.Less(b.Start) {
+				start = b.Start
+			}
+			if b.End.Less(end) {
+				end = b.End
+			}
+			if !end.Less(start) {
+				result = append(result, IpamRange{Start: start, End: end})
+			}
+		}
+	}
+	return result
+}
+
+// ipamRangesSize returns the number of addresses in normalised ranges
+func ipamRangesSize(ranges []IpamRange) *big.Int {
+	total := new(big.Int)
+	for _, ipRange := range ranges {
+		start, end := ipRange.Start.As16(), ipRange.End.As16()
+		size := new(big.Int).Sub(new(big.Int).SetBytes(end[:]), new(big.Int).SetBytes(start[:]))
+		total.Add(total, size.Add(size, big.NewInt(1)))
+	}
+	return total
+}
+
+func saturateUint64(value *big.Int) uint64 {
+	if value.Sign() < 0 {
+		return 0
+	}
+	if !value.IsUint64() {
+		return math.MaxUint64
+	}
+	return value.Uint64()
+}
@@ -0,0 +1,213 @@
+//go:build unit || ALL
+
+/*
+ * Copyright 2025 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+package govcd
+
+import (
+	"math"
+	"net/netip"
+	"sync"
+	"testing"
+)
+
+// buildIpamWithConflicts returns an IPAM with IPv4, IPv6 and Edge Gateway subnets, whose allocations include one
+// conflict of each kind
+func buildIpamWithConflicts(t *testing.T) *Ipam {
+	subnets := []IpamSubnet{
+		{
+			Network:     "web",
+			Prefix:      netip.MustParsePrefix("10.0.0.0/24"),
+			Gateway:     netip.MustParseAddr("10.0.0.1"),
+			StaticPools: []IpamRange{{Start: netip.MustParseAddr("10.0.0.2"), End: netip.MustParseAddr("10.0.0.20")}},
+			DhcpPools:   []IpamRange{{Start: netip.MustParseAddr("10.0.0.15"), End: netip.MustParseAddr("10.0.0.30")}},
+		},
+		{
+			Network: "web",
+			Prefix:  netip.MustParsePrefix("2001:db8::/48"),
+			Gateway: netip.MustParseAddr("2001:db8::1"),
+			StaticPools: []IpamRange{
+				{Start: netip.MustParseAddr("2001:db8::2"), End: netip.MustParseAddr("2001:db8:0:ffff:ffff:ffff:ffff:ffff")},
+			},
+		},
+		{
+			Network:     "edge/uplink",
+			Prefix:      netip.MustParsePrefix("203.0.113.0/24"),
+			Gateway:     netip.MustParseAddr("203.0.113.1"),
+			StaticPools: []IpamRange{{Start: netip.MustParseAddr("203.0.113.10"), End: netip.MustParseAddr("203.0.113.13")}},
+		},
+	}
+	allocations := []IpamAllocation{
+		{Address: netip.MustParseAddr("10.0.0.2"), Usage: IpamUsageVmNic, Network: "web", Owner: "vm-1 (NIC 0)", Static: true},
+		{Address: netip.MustParseAddr("10.0.0.3"), Usage: IpamUsageVmNic, Network: "web", Owner: "vm-2 (NIC 0)", Static: true},
+		{Address: netip.MustParseAddr("10.0.0.3"), Usage: IpamUsageVmNic, Network: "web", Owner: "vm-3 (NIC 0)", Static: true},
+		{Address: netip.MustParseAddr("10.0.0.16"), Usage: IpamUsageVmNic, Network: "web", Owner: "vm-4 (NIC 0)", Static: true},
+		{Address: netip.MustParseAddr("10.0.0.17"), Usage: IpamUsageVmNic, Network: "web", Owner: "vm-5 (NIC 0)"},
+		{Address: netip.MustParseAddr("10.0.0.5"), Usage: IpamUsageDhcpBinding, Network: "web", Owner: "00:50:56:00:00:01", Static: true},
+		{Address: netip.MustParseAddr("192.168.0.5"), Usage: IpamUsageVmNic, Network: "web", Owner: "vm-6 (NIC 1)", Static: true},
+		{Address: netip.MustParseAddr("2001:db8::2"), Usage: IpamUsageVmNic, Network: "web", Owner: "vm-1 (NIC 1)", Static: true},
+		{Address: netip.MustParseAddr("203.0.113.1"), Usage: IpamUsageEdgeGateway, Owner: "edge (PRIMARY_IP)", Static: true},
+		{Address: netip.MustParseAddr("203.0.113.10"), Usage: IpamUsageEdgeGateway, Owner: "edge (NAT)", Static: true},
+		{Address: netip.MustParseAddr("203.0.113.11"), Usage: IpamUsageIpSpace, Owner: "edge", Static: true},
+	}
+	ipam, err := NewIpam(subnets, allocations)
+	if err != nil {
+		t.Fatalf("error creating IPAM: %s", err)
+	}
+	return ipam
+}
+
+// Test_IpamSubnets checks the usage computed for each subnet
+func Test_IpamSubnets(t *testing.T) {
+	ipam := buildIpamWithConflicts(t)
+	expected := []struct {
+		prefix                      string
+		total, used, reserved, free uint64
+	}{
+		// 19 addresses in the static pool, 6 of which are also in the DHCP pool.
+		// 10.0.0.2, 10.0.0.3, 10.0.0.16 and 10.0.0.17 are used, 10.0.0.5 is reserved
+		{"10.0.0.0/24", 19, 4, 1, 10},
+		{"2001:db8::/48", math.MaxUint64, 1, 0, math.MaxUint64},
+		{"203.0.113.0/24", 4, 1, 1, 2},
+	}
+	subnets := ipam.Subnets()
+	if len(subnets) != len(expected) {
+		t.Fatalf("expected %d subnets, got %d", len(expected), len(subnets))
+	}
+	for index, subnet := range subnets {
+		want := expected[index]
+		if subnet.Prefix.String() != want.prefix || subnet.Total != want.total || subnet.Used != want.used ||
+			subnet.Reserved != want.reserved || subnet.Free != want.free {
+			t.Fatalf("unexpected usage of %s: total %d, used %d, reserved %d, free %d", subnet.Prefix,
+				subnet.Total, subnet.Used, subnet.Reserved, subnet.Free)
+		}
+	}
+}
+
+// Test_IpamConflicts checks the detection of inconsistent address assignments
+func Test_IpamConflicts(t *testing.T) {
+	type conflictKey struct {
+		kind, address string
+	}
+	expected := map[conflictKey]bool{
+		{IpamConflictPoolOverlap, "10.0.0.15"}:      true,
+		{IpamConflictDuplicate, "10.0.0.3"}:         true,
+		{IpamConflictStaticInDhcpPool, "10.0.0.16"}: true,
+		{IpamConflictOutsideSubnet, "192.168.0.5"}:  true,
+	}
+	conflicts := buildIpamWithConflicts(t).Conflicts()
+	if len(conflicts) != len(expected) {
+		t.Fatalf("unexpected conflicts: %+v", conflicts)
+	}
+	for _, conflict := range conflicts {
+		if !expected[conflictKey{conflict.Kind, conflict.Address.String()}] {
+			t.Fatalf("unexpected conflict: %+v", conflict)
+		}
+		if conflict.Kind == IpamConflictDuplicate && len(conflict.Allocations) != 2 {
+			t.Fatalf("expected 2 allocations in duplicate conflict, got %+v", conflict.Allocations)
+		}
+	}
+}
+
+// Test_IpamReserveNextFree checks reservation and release of addresses, also from concurrent goroutines
+func Test_IpamReserveNextFree(t *testing.T) {
+	ipam := buildIpamWithConflicts(t)
+
+	address, err := ipam.ReserveNextFree("web", false, "test")
+	if err != nil || address.String() != "10.0.0.4" {
+		t.Fatalf("expected 10.0.0.4, got %s (error %v)", address, err)
+	}
+	address, err = ipam.ReserveNextFree("web", true, "test")
+	if err != nil || address.String() != "2001:db8::3" {
+		t.Fatalf("expected 2001:db8::3, got %s (error %v)", address, err)
+	}
+	err = ipam.Release("web", netip.MustParseAddr("10.0.0.4"))
+	if err != nil {
+		t.Fatalf("unexpected error releasing address: %s", err)
+	}
+	err = ipam.Release("web", netip.MustParseAddr("10.0.0.4"))
+	if err == nil {
+		t.Fatalf("expected error releasing an address that is not reserved")
+	}
+	_, err = ipam.ReserveNextFree("missing", false, "test")
+	if err == nil || !ContainsNotFound(err) {
+		t.Fatalf("expected not found error for unknown network, got %v", err)
+	}
+
+	// 10 addresses are free in the IPv4 subnet of "web"
+	var wg sync.WaitGroup
+	results := make(chan netip.Addr, 12)
+	for i := 0; i < 12; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			reserved, err := ipam.ReserveNextFree("web", false, "concurrent")
+			if err == nil {
+				results <- reserved
+			}
+		}()
+	}
+	wg.Wait()
+	close(results)
+	seen := make(map[netip.Addr]bool)
+	for reserved := range results {
+		if seen[reserved] {
+			t.Fatalf("address %s reserved twice", reserved)
+		}
+		seen[reserved] = true
+	}
+	if len(seen) != 10 {
+		t.Fatalf("expected 10 reservations, got %d", len(seen))
+	}
+	for _, subnet := range ipam.Subnets() {
+		if subnet.Prefix.String() == "10.0.0.0/24" && (subnet.Free != 0 || subnet.Reserved != 11) {
+			t.Fatalf("unexpected usage after reservations: free %d, reserved %d", subnet.Free, subnet.Reserved)
+		}
+	}
+}
+
+// Test_IpamReserveNextFreeLargeDhcpPool checks that a DHCP pool covering most of a large IPv6 static pool is
+// skipped as a whole instead of being walked address by address
+func Test_IpamReserveNextFreeLargeDhcpPool(t *testing.T) {
+	ipam, err := NewIpam([]IpamSubnet{{
+		Network:     "large",
+		Prefix:      netip.MustParsePrefix("2001:db8::/64"),
+		Gateway:     netip.MustParseAddr("2001:db8::1"),
+		StaticPools: []IpamRange{{Start: netip.MustParseAddr("2001:db8::1"), End: netip.MustParseAddr("2001:db8::ffff:ffff:ffff:ffff")}},
+		DhcpPools:   []IpamRange{{Start: netip.MustParseAddr("2001:db8::3"), End: netip.MustParseAddr("2001:db8::ffff:ffff:ffff:fffd")}},
+	}}, []IpamAllocation{
+		{Address: netip.MustParseAddr("2001:db8::2"), Usage: IpamUsageVmNic, Network: "large", Owner: "vm1", Static: true},
+		{Address: netip.MustParseAddr("2001:db8::ffff:ffff:ffff:fffe"), Usage: IpamUsageVmNic, Network: "large", Owner: "vm2", Static: true},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error creating Ipam: %s", err)
+	}
+
+	address, err := ipam.ReserveNextFree("large", true, "test")
+	if err != nil || address.String() != "2001:db8::ffff:ffff:ffff:ffff" {
+		t.Fatalf("expected 2001:db8::ffff:ffff:ffff:ffff, got %s (error %v)", address, err)
+	}
+	_, err = ipam.ReserveNextFree("large", true, "test")
+	if err == nil {
+		t.Fatalf("expected error when the static pool is exhausted")
+	}
+}
+
+// Test_NewIpamValidation checks that invalid input is rejected
+func Test_NewIpamValidation(t *testing.T) {
+	_, err := NewIpam([]IpamSubnet{{Network: "net", Prefix: netip.MustParsePrefix("10.0.0.0/24"),
+		StaticPools: []IpamRange{{Start: netip.MustParseAddr("10.0.0.20"), End: netip.MustParseAddr("10.0.0.10")}}}}, nil)
+	if err == nil {
+		t.Fatalf("expected error for reversed range")
+	}
+	_, err = NewIpam([]IpamSubnet{{Network: "net"}}, nil)
+	if err == nil {
+		t.Fatalf("expected error for missing prefix")
+	}
+	_, err = NewIpam(nil, []IpamAllocation{{Usage: IpamUsageVmNic, Owner: "vm"}})
+	if err == nil {
+		t.Fatalf("expected error for missing address")
+	}
+}
@@ -0,0 +1,391 @@
+/*
+ * Copyright 2025 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+package govcd
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// Object types reported in LeaseReportEntry
+const (
+	LeaseObjectVApp         = "vApp"
+	LeaseObjectVAppTemplate = "vAppTemplate"
+)
+
+// Actions that VCD takes when a lease expires, reported in LeaseReportEntry
+const (
+	LeaseExpirationUndeploy    = "undeploy"     // Runtime lease expiration: the vApp is suspended and undeployed
+	LeaseExpirationPowerOff    = "power off"    // Runtime lease expiration: the vApp is powered off and undeployed
+	LeaseExpirationDelete      = "delete"       // Storage lease expiration: the object is deleted
+	LeaseExpirationMarkExpired = "mark expired" // Storage lease expiration: the object is moved to the expired items
+)
+
+// LeaseReportEntry describes the lease of a vApp or vApp template
+type LeaseReportEntry struct {
+	ObjectType  string // LeaseObjectVApp or LeaseObjectVAppTemplate
+	Name        string
+	HREF        string
+	VdcName     string
+	CatalogName string // Only for vApp templates
+	OwnerName   string
+	StorageKB   int64
+	Deployed    bool
+	// Lease durations. 0 means that the lease never expires
+	DeploymentLeaseInSeconds int
+	StorageLeaseInSeconds    int
+	// Lease expiration dates. nil when the lease never expires or, for the deployment lease, when the vApp is not
+	// deployed
+	DeploymentLeaseExpiration *time.Time
+	StorageLeaseExpiration    *time.Time
+	// ExpiresAt and ExpirationAction describe the next expiration: the earliest of the two leases
+	ExpiresAt        *time.Time
+	ExpirationAction string // One of LeaseExpirationUndeploy, LeaseExpirationPowerOff, LeaseExpirationDelete or LeaseExpirationMarkExpired
+	Metadata         map[string]string
+
+	leaseHref string
+}
+
+// LeaseRule renews the leases of the objects whose metadata matches. With NeverExpire, the leases are set to never
+// expire. Otherwise, the leases are renewed for DeploymentLeaseInSeconds and StorageLeaseInSeconds, starting from
+// now. Zero durations keep the current duration of the object.
+type LeaseRule struct {
+	Name string
+	// ObjectType restricts the rule to LeaseObjectVApp or LeaseObjectVAppTemplate. Empty matches both
+	ObjectType string
+	// MetadataKey and MetadataValue select the objects. An empty MetadataValue matches any value of the key
+	MetadataKey   string
+	MetadataValue string
+
+	NeverExpire              bool
+	DeploymentLeaseInSeconds int // Ignored for vApp templates
+	StorageLeaseInSeconds    int
+}
+
+// LeasePolicy is a set of lease rules applied to all vApps and vApp templates of an organization. The first matching
+// rule applies to each object.
+type LeasePolicy struct {
+	Rules []LeaseRule
+	// Notify, if set, receives the objects that are about to expire and are not renewed by any rule, ordered by
+	// expiration
+	Notify func(expiring []*LeaseReportEntry) error
+	// DryRun computes the renewals without applying them. Notify is still called
+	DryRun bool
+	// Batch controls the concurrency of renewals. See RunBatch for defaults
+	Batch *BatchOptions
+}
+
+// LeaseRenewal is a lease renewal planned by a lease rule
+type LeaseRenewal struct {
+	Entry                    *LeaseReportEntry
+	Rule                     string
+	DeploymentLeaseInSeconds int
+	StorageLeaseInSeconds    int
+	Error                    error
+}
+
+// LeasePolicyResult is the outcome of AdminOrg.ApplyLeasePolicy
+type LeasePolicyResult struct {
+	Renewals []*LeaseRenewal
+	// Expiring holds the objects that no rule renewed
+	Expiring []*LeaseReportEntry
+}
+
+// GetLeaseReport returns the vApps and vApp templates of the organization whose leases expire within the given
+// number of days, or have expired already, ordered by expiration. A negative number of days returns all objects.
+func (adminOrg *AdminOrg) GetLeaseReport(withinDays int) ([]*LeaseReportEntry, error) {
+	if adminOrg.AdminOrg.OrgSettings == nil {
+		return nil, fmt.Errorf("settings of organization '%s' not available", adminOrg.AdminOrg.Name)
+	}
+	client := adminOrg.client
+	now := time.Now()
+
+	entries, err := adminOrg.queryLeaseObjects()
+	if err != nil {
+		return nil, err
+	}
+
+	items := NewBatchItems(entries, func(entry *LeaseReportEntry) string { return entry.Name },
+		func(entry *LeaseReportEntry) (*Task, error) {
+			lease := &types.LeaseSettingsSection{}
+			_, err := client.ExecuteRequest(entry.HREF+"/leaseSettingsSection/", http.MethodGet, "",
+				"error retrieving lease settings: %s", nil, lease)
+			if err != nil {
+				return nil, err
+			}
+			return nil, setLeaseExpiration(entry, lease, adminOrg.AdminOrg.OrgSettings)
+		})
+	err = RunBatch(items, nil).Err()
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving leases: %s", err)
+	}
+
+	var report []*LeaseReportEntry
+	for _, entry := range entries {
+		if withinDays < 0 || (entry.ExpiresAt != nil && entry.ExpiresAt.Before(now.AddDate(0, 0, withinDays))) {
+			report = append(report, entry)
+		}
+	}
+
+	items = NewBatchItems(report, func(entry *LeaseReportEntry) string { return entry.Name },
+		func(entry *LeaseReportEntry) (*Task, error) {
+			metadata, err := getMetadata(client, entry.HREF, entry.Name)
+			if err != nil {
+				return nil, err
+			}
+			entry.Metadata = make(map[string]string)
+			for _, metadataEntry := range metadata.MetadataEntry {
+				if metadataEntry.TypedValue != nil {
+					entry.Metadata[metadataEntry.Key] = metadataEntry.TypedValue.Value
+				}
+			}
+			return nil, nil
+		})
+	err = RunBatch(items, nil).Err()
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving metadata: %s", err)
+	}
+
+	sortLeaseReport(report)
+	return report, nil
+}
+
+// ApplyLeasePolicy renews the leases of the objects that expire within the given number of days and match a rule of
+// the policy, then notifies the objects that will still expire
+func (adminOrg *AdminOrg) ApplyLeasePolicy(withinDays int, policy LeasePolicy) (*LeasePolicyResult, error) {
+	report, err := adminOrg.GetLeaseReport(withinDays)
+	if err != nil {
+		return nil, err
+	}
+	result := planLeaseRenewals(report, policy.Rules)
+
+	if !policy.DryRun && len(result.Renewals) > 0 {
+		items := NewBatchItems(result.Renewals, func(renewal *LeaseRenewal) string { return renewal.Entry.Name },
+			func(renewal *LeaseRenewal) (*Task, error) {
+				return BatchTask(renewLease(adminOrg.client, renewal))
+			})
+		batchReport := RunBatch(items, policy.Batch)
+		for index, batchResult := range batchReport.Results {
+			result.Renewals[index].Error = batchResult.Error
+		}
+		err = batchReport.Err()
+		if err != nil {
+			err = fmt.Errorf("error renewing leases: %s", err)
+		}
+	}
+
+	if policy.Notify != nil && len(result.Expiring) > 0 {
+		notifyErr := policy.Notify(result.Expiring)
+		if notifyErr != nil && err == nil {
+			err = fmt.Errorf("error notifying expiring leases: %s", notifyErr)
+		}
+	}
+	return result, err
+}
+
+// queryLeaseObjects returns the vApps and vApp templates of the organization, without lease information
+func (adminOrg *AdminOrg) queryLeaseObjects() ([]*LeaseReportEntry, error) {
+	client := adminOrg.client
+	queryType := client.GetQueryType(types.QtVapp)
+	params := map[string]string{"type": queryType}
+	if client.IsSysAdmin {
+		params["filter"] = "org==" + url.QueryEscape(adminOrg.AdminOrg.HREF)
+	}
+	vappResults, err := client.cumulativeQuery(queryType, nil, params)
+	if err != nil {
+		return nil, fmt.Errorf("error querying vApps: %s", err)
+	}
+	vapps := vappResults.Results.VAppRecord
+	if client.IsSysAdmin {
+		vapps = vappResults.Results.AdminVAppRecord
+	}
+
+	var entries []*LeaseReportEntry
+	for _, vapp := range vapps {
+		entries = append(entries, &LeaseReportEntry{
+			ObjectType: LeaseObjectVApp,
+			Name:       vapp.Name,
+			HREF:       vapp.HREF,
+			VdcName:    vapp.VdcName,
+			OwnerName:  vapp.OwnerName,
+			StorageKB:  int64(vapp.StorageKB),
+			Deployed:   vapp.Deployed,
+		})
+	}
+
+	templates, err := queryVappTemplateListWithFilter(client, map[string]string{"org": adminOrg.AdminOrg.HREF})
+	if err != nil {
+		return nil, err
+	}
+	// vApp template records don't report their size: it is computed from the VMs they contain
+	var vmSearchParams map[string]string
+	if client.IsSysAdmin {
+		vmSearchParams = map[string]string{"org": url.QueryEscape(adminOrg.AdminOrg.HREF)}
+	}
+	templateVms, err := QueryVmList(types.VmQueryFilterOnlyTemplates, client, vmSearchParams)
+	if err != nil {
+		return nil, fmt.Errorf("error querying vApp template VMs: %s", err)
+	}
+	templateStorageMb := make(map[string]int64)
+	for _, vm := range templateVms {
+		storageMb, err := strconv.ParseInt(vm.TotalStorageAllocatedMb, 10, 64)
+		if err == nil {
+			templateStorageMb[extractUuid(vm.ContainerID)] += storageMb
+		}
+	}
+	for _, template := range templates {
+		entries = append(entries, &LeaseReportEntry{
+			ObjectType:  LeaseObjectVAppTemplate,
+			Name:        template.Name,
+			HREF:        template.HREF,
+			VdcName:     template.VdcName,
+			CatalogName: template.CatalogName,
+			OwnerName:   template.OwnerName,
+			StorageKB:   templateStorageMb[extractUuid(template.HREF)] * 1024,
+		})
+	}
+	return entries, nil
+}
+
+// setLeaseExpiration fills the lease information of the entry, and computes its next expiration using the lease
+// settings of the organization
+func setLeaseExpiration(entry *LeaseReportEntry, lease *types.LeaseSettingsSection, orgSettings *types.OrgSettings) error {
+	entry.leaseHref = lease.HREF
+	entry.DeploymentLeaseInSeconds = lease.DeploymentLeaseInSeconds
+	entry.StorageLeaseInSeconds = lease.StorageLeaseInSeconds
+	entry.DeploymentLeaseExpiration, entry.StorageLeaseExpiration = nil, nil
+	entry.ExpiresAt, entry.ExpirationAction = nil, ""
+
+	var err error
+	if entry.ObjectType == LeaseObjectVApp && entry.Deployed && lease.DeploymentLeaseExpiration != "" {
+		entry.DeploymentLeaseExpiration, err = parseLeaseExpiration(lease.DeploymentLeaseExpiration)
+		if err != nil {
+			return err
+		}
+	}
+	if lease.StorageLeaseExpiration != "" {
+		entry.StorageLeaseExpiration, err = parseLeaseExpiration(lease.StorageLeaseExpiration)
+		if err != nil {
+			return err
+		}
+	}
+
+	deleteOnExpiration := false
+	if entry.ObjectType == LeaseObjectVApp && orgSettings.OrgVAppLeaseSettings != nil &&
+		orgSettings.OrgVAppLeaseSettings.DeleteOnStorageLeaseExpiration != nil {
+		deleteOnExpiration = *orgSettings.OrgVAppLeaseSettings.DeleteOnStorageLeaseExpiration
+	}
+	if entry.ObjectType == LeaseObjectVAppTemplate && orgSettings.OrgVAppTemplateSettings != nil &&
+		orgSettings.OrgVAppTemplateSettings.DeleteOnStorageLeaseExpiration != nil {
+		deleteOnExpiration = *orgSettings.OrgVAppTemplateSettings.DeleteOnStorageLeaseExpiration
+	}
+
+	if entry.StorageLeaseExpiration != nil {
+		entry.ExpiresAt = entry.StorageLeaseExpiration
+		entry.ExpirationAction = LeaseExpirationMarkExpired
+		if deleteOnExpiration {
+			entry.ExpirationAction = LeaseExpirationDelete
+		}
+	}
+	if entry.DeploymentLeaseExpiration != nil && (entry.ExpiresAt == nil || !entry.ExpiresAt.Before(*entry.DeploymentLeaseExpiration)) {
+		entry.ExpiresAt = entry.DeploymentLeaseExpiration
+		entry.ExpirationAction = LeaseExpirationUndeploy
+		if orgSettings.OrgVAppLeaseSettings != nil && orgSettings.OrgVAppLeaseSettings.PowerOffOnRuntimeLeaseExpiration != nil &&
+			*orgSettings.OrgVAppLeaseSettings.PowerOffOnRuntimeLeaseExpiration {
+			entry.ExpirationAction = LeaseExpirationPowerOff
+		}
+	}
+	return nil
+}
+
+// parseLeaseExpiration parses a lease expiration date, such as "2025-03-01T10:00:00.000Z"
+func parseLeaseExpiration(value string) (*time.Time, error) {
+	expiration, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing lease expiration '%s': %s", value, err)
+	}
+	return &expiration, nil
+}
+
+// planLeaseRenewals applies the first matching rule to each entry
+func planLeaseRenewals(entries []*LeaseReportEntry, rules []LeaseRule) *LeasePolicyResult {
+	result := &LeasePolicyResult{}
+	for _, entry := range entries {
+		rule := matchLeaseRule(entry, rules)
+		if rule == nil {
+			result.Expiring = append(result.Expiring, entry)
+			continue
+		}
+
+		renewal := &LeaseRenewal{Entry: entry, Rule: rule.Name}
+		if !rule.NeverExpire {
+			renewal.DeploymentLeaseInSeconds = rule.DeploymentLeaseInSeconds
+			if renewal.DeploymentLeaseInSeconds == 0 {
+				renewal.DeploymentLeaseInSeconds = entry.DeploymentLeaseInSeconds
+			}
+			renewal.StorageLeaseInSeconds = rule.StorageLeaseInSeconds
+			if renewal.StorageLeaseInSeconds == 0 {
+				renewal.StorageLeaseInSeconds = entry.StorageLeaseInSeconds
+			}
+		}
+		if entry.ObjectType == LeaseObjectVAppTemplate {
+			renewal.DeploymentLeaseInSeconds = 0
+		}
+		result.Renewals = append(result.Renewals, renewal)
+	}
+	return result
+}
+
+// matchLeaseRule returns the first rule that matches the entry, or nil
+func matchLeaseRule(entry *LeaseReportEntry, rules []LeaseRule) *LeaseRule {
+	for index, rule := range rules {
+		if rule.ObjectType != "" && rule.ObjectType != entry.ObjectType {
+			continue
+		}
+		value, found := entry.Metadata[rule.MetadataKey]
+		if found && (rule.MetadataValue == "" || rule.MetadataValue == value) {
+			return &rules[index]
+		}
+	}
+	return nil
+}
+
+// renewLease updates the lease settings of the object in the renewal. VCD restarts the leases from the time of the
+// update, even when their durations don't change
+func renewLease(client *Client, renewal *LeaseRenewal) (Task, error) {
+	href := renewal.Entry.leaseHref
+	if href == "" {
+		href = renewal.Entry.HREF + "/leaseSettingsSection/"
+	}
+	leaseSettings := types.UpdateLeaseSettingsSection{
+		HREF:                  href,
+		XmlnsOvf:              types.XMLNamespaceOVF,
+		Xmlns:                 types.XMLNamespaceVCloud,
+		OVFInfo:               "Lease section settings",
+		Type:                  types.MimeLeaseSettingSection,
+		StorageLeaseInSeconds: &renewal.StorageLeaseInSeconds,
+	}
+	if renewal.Entry.ObjectType == LeaseObjectVApp {
+		leaseSettings.DeploymentLeaseInSeconds = &renewal.DeploymentLeaseInSeconds
+	}
+	return client.ExecuteTaskRequest(href, http.MethodPut, types.MimeLeaseSettingSection,
+		fmt.Sprintf("error renewing lease of %s '%s': %%s", renewal.Entry.ObjectType, renewal.Entry.Name), &leaseSettings)
+}
+
+// sortLeaseReport orders the entries by expiration, with the entries that never expire last
+func sortLeaseReport(entries []*LeaseReportEntry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].ExpiresAt == nil || entries[j].ExpiresAt == nil {
+			return entries[i].ExpiresAt != nil
+		}
+		return entries[i].ExpiresAt.Before(*entries[j].ExpiresAt)
+	})
+}
@@ -0,0 +1,98 @@
+//go:build unit || ALL
+
+/*
+ * Copyright 2025 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+package govcd
+
+import (
+	"testing"
+
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// Test_setLeaseExpiration checks the next expiration and action computed from leases and organization settings
+func Test_setLeaseExpiration(t *testing.T) {
+	orgSettings := &types.OrgSettings{
+		OrgVAppLeaseSettings:    &types.VAppLeaseSettings{DeleteOnStorageLeaseExpiration: addrOf(true), PowerOffOnRuntimeLeaseExpiration: addrOf(true)},
+		OrgVAppTemplateSettings: &types.VAppTemplateLeaseSettings{DeleteOnStorageLeaseExpiration: addrOf(false)},
+	}
+	lease := &types.LeaseSettingsSection{
+		HREF:                      "https://vcd/api/vApp/vapp-1/leaseSettingsSection/",
+		DeploymentLeaseInSeconds:  3600,
+		DeploymentLeaseExpiration: "2025-03-01T10:00:00.000Z",
+		StorageLeaseInSeconds:     7200,
+		StorageLeaseExpiration:    "2025-03-01T11:00:00.000+01:00",
+	}
+
+	tests := []struct {
+		name       string
+		entry      *LeaseReportEntry
+		action     string
+		expiration string
+	}{
+		{"deployed vApp", &LeaseReportEntry{ObjectType: LeaseObjectVApp, Deployed: true}, LeaseExpirationPowerOff, "2025-03-01T10:00:00Z"},
+		{"undeployed vApp", &LeaseReportEntry{ObjectType: LeaseObjectVApp}, LeaseExpirationDelete, "2025-03-01T10:00:00Z"},
+		{"vApp template", &LeaseReportEntry{ObjectType: LeaseObjectVAppTemplate}, LeaseExpirationMarkExpired, "2025-03-01T10:00:00Z"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := setLeaseExpiration(test.entry, lease, orgSettings)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if test.entry.ExpirationAction != test.action || test.entry.ExpiresAt.UTC().Format("2006-01-02T15:04:05Z") != test.expiration {
+				t.Fatalf("unexpected expiration %s at %s", test.entry.ExpirationAction, test.entry.ExpiresAt)
+			}
+			if (test.entry.DeploymentLeaseExpiration != nil) != test.entry.Deployed {
+				t.Fatalf("deployment lease expiration must be reported only for deployed vApps")
+			}
+		})
+	}
+
+	entry := &LeaseReportEntry{ObjectType: LeaseObjectVApp, Deployed: true}
+	err := setLeaseExpiration(entry, &types.LeaseSettingsSection{}, orgSettings)
+	if err != nil || entry.ExpiresAt != nil || entry.ExpirationAction != "" {
+		t.Fatalf("expected a lease that never expires, got %s %v", entry.ExpirationAction, err)
+	}
+	err = setLeaseExpiration(entry, &types.LeaseSettingsSection{StorageLeaseExpiration: "tomorrow"}, orgSettings)
+	if err == nil {
+		t.Fatalf("expected error for invalid expiration date")
+	}
+}
+
+// Test_planLeaseRenewals checks that the first matching rule is applied to each entry
+func Test_planLeaseRenewals(t *testing.T) {
+	prod := &LeaseReportEntry{ObjectType: LeaseObjectVApp, Name: "prod", DeploymentLeaseInSeconds: 3600, StorageLeaseInSeconds: 7200,
+		Metadata: map[string]string{"env": "prod"}}
+	dev := &LeaseReportEntry{ObjectType: LeaseObjectVApp, Name: "dev", DeploymentLeaseInSeconds: 3600, StorageLeaseInSeconds: 7200,
+		Metadata: map[string]string{"env": "dev", "keep": "yes"}}
+	template := &LeaseReportEntry{ObjectType: LeaseObjectVAppTemplate, Name: "template", StorageLeaseInSeconds: 7200,
+		Metadata: map[string]string{"keep": "true"}}
+	test := &LeaseReportEntry{ObjectType: LeaseObjectVApp, Name: "test", Metadata: map[string]string{"env": "test"}}
+
+	rules := []LeaseRule{
+		{Name: "production", MetadataKey: "env", MetadataValue: "prod", NeverExpire: true},
+		{Name: "keep templates", ObjectType: LeaseObjectVAppTemplate, MetadataKey: "keep", StorageLeaseInSeconds: 86400},
+		{Name: "keep", MetadataKey: "keep", DeploymentLeaseInSeconds: 600},
+	}
+	result := planLeaseRenewals([]*LeaseReportEntry{prod, dev, template, test}, rules)
+
+	if len(result.Expiring) != 1 || result.Expiring[0] != test {
+		t.Fatalf("unexpected expiring entries %v", result.Expiring)
+	}
+	expected := []LeaseRenewal{
+		{Entry: prod, Rule: "production"},
+		{Entry: dev, Rule: "keep", DeploymentLeaseInSeconds: 600, StorageLeaseInSeconds: 7200},
+		{Entry: template, Rule: "keep templates", StorageLeaseInSeconds: 86400},
+	}
+	if len(result.Renewals) != len(expected) {
+		t.Fatalf("expected %d renewals, got %d", len(expected), len(result.Renewals))
+	}
+	for index, renewal := range result.Renewals {
+		if *renewal != expected[index] {
+			t.Fatalf("unexpected renewal %d: %+v", index, renewal)
+		}
+	}
+}
@@ -0,0 +1,558 @@
+/*
+ * Copyright 2025 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+package govcd
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// This file binds metadata entries to the fields of a Go struct, using the "vcdmeta" struct tag:
+//
+//	type Tags struct {
+//		CostCenter string    `vcdmeta:"cost_center,domain=SYSTEM,readonly,required"`
+//		Replicas   int       `vcdmeta:"replicas"`
+//		Managed    *bool     `vcdmeta:"managed"`
+//		Expires    time.Time `vcdmeta:"expires"`
+//		Ignored    string    `vcdmeta:"-"`
+//	}
+//
+// The first element of the tag is the metadata key. The following options are supported:
+//   - domain=GENERAL|SYSTEM: domain of the entry. TENANT and PROVIDER are accepted as synonyms of GENERAL and SYSTEM,
+//     which are the names used by OpenAPI metadata. Defaults to GENERAL
+//   - readonly: the entry is read only for tenants. Only valid in the SYSTEM domain for XML metadata
+//   - hidden: the entry is hidden from tenants. Only valid in the SYSTEM domain and only for XML metadata
+//   - required: reading fails if the entry is missing and writing fails if the field has its zero value
+//   - namespace=<name>: namespace of the entry. Only for OpenAPI metadata
+//   - persistent: the entry is persistent. Only for OpenAPI metadata
+//
+// Supported field types are string, bool, signed and unsigned integers, time.Time and pointers to any of them. Pointer
+// fields are optional: they are set to nil when the entry is missing and are not written when nil.
+// The value type of each entry (types.MetadataStringValue, types.MetadataNumberValue...) is derived from the field type.
+
+// metadataTagName is the name of the struct tag used for metadata binding
+const metadataTagName = "vcdmeta"
+
+// MetadataMissingKeysError is returned when reading metadata into a struct with required fields whose
+// entries are missing
+type MetadataMissingKeysError struct {
+	Keys []string
+}
+
+func (err *MetadataMissingKeysError) Error() string {
+	return fmt.Sprintf("required metadata keys are missing: %s", strings.Join(err.Keys, ", "))
+}
+
+// XmlMetadataReader is implemented by all entities that can retrieve metadata with metadata_v2.go methods
+type XmlMetadataReader interface {
+	GetMetadata() (*types.Metadata, error)
+}
+
+// XmlMetadataWriter is implemented by all entities that can merge metadata with metadata_v2.go methods
+type XmlMetadataWriter interface {
+	MergeMetadataWithMetadataValues(metadata map[string]types.MetadataValue) error
+}
+
+// OpenApiMetadataReader is implemented by all entities that support OpenAPI metadata
+type OpenApiMetadataReader interface {
+	GetMetadata() ([]*OpenApiMetadataEntry, error)
+}
+
+// OpenApiMetadataWriter is implemented by all entities that support OpenAPI metadata
+type OpenApiMetadataWriter interface {
+	OpenApiMetadataReader
+	GetMetadataById(id string) (*OpenApiMetadataEntry, error)
+	AddMetadata(metadataEntry types.OpenApiMetadataEntry) (*OpenApiMetadataEntry, error)
+}
+
+// ReadMetadataInto retrieves the metadata of the given entity and sets the fields of target, which must be a pointer
+// to a struct with "vcdmeta" tags
+func ReadMetadataInto(entity XmlMetadataReader, target any) error {
+	metadata, err := entity.GetMetadata()
+	if err != nil {
+		return fmt.Errorf("error retrieving metadata: %s", err)
+	}
+	return setFieldsFromXmlMetadata(metadata, target)
+}
+
+// WriteMetadataFrom merges the metadata entries defined by the "vcdmeta" tags of source, which must be a struct or a
+// pointer to a struct, into the metadata of the given entity. Entries that are not bound to fields are not modified.
+func WriteMetadataFrom(entity XmlMetadataWriter, source any) error {
+	metadata, err := xmlMetadataFromFields(source)
+	if err != nil {
+		return err
+	}
+	if len(metadata) == 0 {
+		return nil
+	}
+	return entity.MergeMetadataWithMetadataValues(metadata)
+}
+
+// ReadOpenApiMetadataInto retrieves the OpenAPI metadata of the given entity and sets the fields of target, which
+// must be a pointer to a struct with "vcdmeta" tags. OpenAPI numbers are decoded as float64, so integers above 2^53
+// are read with reduced precision
+func ReadOpenApiMetadataInto(entity OpenApiMetadataReader, target any) error {
+	metadata, err := entity.GetMetadata()
+	if err != nil {
+		return fmt.Errorf("error retrieving metadata: %s", err)
+	}
+	entries := make([]*types.OpenApiMetadataEntry, len(metadata))
+	for index, entry := range metadata {
+		entries[index] = entry.MetadataEntry
+	}
+	return setFieldsFromOpenApiMetadata(entries, target)
+}
+
+// WriteOpenApiMetadataFrom creates or updates the OpenAPI metadata entries defined by the "vcdmeta" tags of source,
+// which must be a struct or a pointer to a struct. Entries that already have the wanted value are not modified.
+func WriteOpenApiMetadataFrom(entity OpenApiMetadataWriter, source any) error {
+	wanted, err := openApiMetadataFromFields(source)
+	if err != nil {
+		return err
+	}
+	if len(wanted) == 0 {
+		return nil
+	}
+
+	metadata, err := entity.GetMetadata()
+	if err != nil {
+		return fmt.Errorf("error retrieving metadata: %s", err)
+	}
+	existing := make(map[string]*OpenApiMetadataEntry)
+	for _, entry := range metadata {
+		existing[openApiMetadataEntryId(entry.MetadataEntry)] = entry
+	}
+
+	for _, entry := range wanted {
+		current, found := existing[openApiMetadataEntryId(&entry)]
+		if !found {
+			_, err = entity.AddMetadata(entry)
+			if err != nil {
+				return fmt.Errorf("error adding metadata entry '%s': %s", entry.KeyValue.Key, err)
+			}
+			continue
+		}
+		if metadataValuesEqual(current.MetadataEntry.KeyValue.Value.Value, entry.KeyValue.Value.Value) &&
+			current.MetadataEntry.IsPersistent == entry.IsPersistent && current.MetadataEntry.IsReadOnly == entry.IsReadOnly {
+			continue
+		}
+		// Retrieving the entry by ID is required to get its ETag
+		current, err = entity.GetMetadataById(current.MetadataEntry.ID)
+		if err != nil {
+			return fmt.Errorf("error retrieving metadata entry '%s': %s", entry.KeyValue.Key, err)
+		}
+		// Update sends the read-only flag of the receiver
+		current.MetadataEntry.IsReadOnly = entry.IsReadOnly
+		err = current.Update(entry.KeyValue.Value.Value, entry.IsPersistent)
+		if err != nil {
+			return fmt.Errorf("error updating metadata entry '%s': %s", entry.KeyValue.Key, err)
+		}
+	}
+	return nil
+}
+
+// ValidateMetadataBinding checks the "vcdmeta" tags of the given struct, or pointer to struct, and that all its
+// required fields are set
+func ValidateMetadataBinding(source any) error {
+	value, err := metadataStructValue(source, false)
+	if err != nil {
+		return err
+	}
+	fields, err := parseMetadataFields(value.Type())
+	if err != nil {
+		return err
+	}
+	var missing []string
+	for _, field := range fields {
+		if field.required && value.Field(field.index).IsZero() {
+			missing = append(missing, field.key)
+		}
+	}
+	if len(missing) > 0 {
+		return &MetadataMissingKeysError{Keys: missing}
+	}
+	return nil
+}
+
+// metadataField is a struct field bound to a metadata entry
+type metadataField struct {
+	index      int
+	name       string
+	key        string
+	system     bool
+	readOnly   bool
+	hidden     bool
+	required   bool
+	persistent bool
+	namespace  string
+}
+
+var metadataTimeType = reflect.TypeOf(time.Time{})
+
+// parseMetadataFields returns the fields of the given struct type that have a "vcdmeta" tag
+func parseMetadataFields(structType reflect.Type) ([]metadataField, error) {
+	var fields []metadataField
+	seen := make(map[string]string)
+	for index := 0; index < structType.NumField(); index++ {
+		structField := structType.Field(index)
+		tag, ok := structField.Tag.Lookup(metadataTagName)
+		if !ok || tag == "-" {
+			continue
+		}
+		if !structField.IsExported() {
+			return nil, fmt.Errorf("field %s has a %s tag but is not exported", structField.Name, metadataTagName)
+		}
+		if !isSupportedMetadataFieldType(structField.Type) {
+			return nil, fmt.Errorf("field %s has unsupported type %s for metadata binding", structField.Name, structField.Type)
+		}
+
+		options := strings.Split(tag, ",")
+		field := metadataField{index: index, name: structField.Name, key: strings.TrimSpace(options[0])}
+		if field.key == "" {
+			return nil, fmt.Errorf("field %s has an empty metadata key", structField.Name)
+		}
+		for _, option := range options[1:] {
+			option = strings.TrimSpace(option)
+			switch {
+			case option == "readonly":
+				field.readOnly = true
+			case option == "hidden":
+				field.hidden = true
+			case option == "required":
+				field.required = true
+			case option == "persistent":
+				field.persistent = true
+			case strings.HasPrefix(option, "namespace="):
+				field.namespace = strings.TrimPrefix(option, "namespace=")
+			case strings.HasPrefix(option, "domain="):
+				switch strings.ToUpper(strings.TrimPrefix(option, "domain=")) {
+				case "GENERAL", "TENANT":
+					field.system = false
+				case "SYSTEM", "PROVIDER":
+					field.system = true
+				default:
+					return nil, fmt.Errorf("field %s has invalid metadata option '%s'", structField.Name, option)
+				}
+			default:
+				return nil, fmt.Errorf("field %s has unknown metadata option '%s'", structField.Name, option)
+			}
+		}
+		if field.hidden && field.readOnly {
+			return nil, fmt.Errorf("field %s can't be both readonly and hidden", structField.Name)
+		}
+
+		id := fmt.Sprintf("%t/%s/%s", field.system, field.namespace, field.key)
+		if previous, found := seen[id]; found {
+			return nil, fmt.Errorf("fields %s and %s are bound to the same metadata key '%s'", previous, structField.Name, field.key)
+		}
+		seen[id] = structField.Name
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// isSupportedMetadataFieldType returns true if the given type can be bound to a metadata entry
+func isSupportedMetadataFieldType(fieldType reflect.Type) bool {
+	if fieldType.Kind() == reflect.Pointer {
+		fieldType = fieldType.Elem()
+	}
+	if fieldType == metadataTimeType {
+		return true
+	}
+	switch fieldType.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+// metadataStructValue returns the struct value behind the given input. If settable is true, the input must be a
+// non-nil pointer to a struct
+func metadataStructValue(input any, settable bool) (reflect.Value, error) {
+	value := reflect.ValueOf(input)
+	if value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return reflect.Value{}, fmt.Errorf("metadata binding target is a nil pointer")
+		}
+		value = value.Elem()
+	} else if settable {
+		return reflect.Value{}, fmt.Errorf("metadata binding target must be a pointer to a struct, got %T", input)
+	}
+	if value.Kind() != reflect.Struct {
+		return reflect.Value{}, fmt.Errorf("metadata binding requires a struct, got %T", input)
+	}
+	return value, nil
+}
+
+// setFieldsFromXmlMetadata sets the fields of target from the given XML metadata
+func setFieldsFromXmlMetadata(metadata *types.Metadata, target any) error {
+	values := make(map[string]string)
+	if metadata != nil {
+		for _, entry := range metadata.MetadataEntry {
+			if entry.TypedValue == nil {
+				continue
+			}
+			system := metadataDomainOf(entry.Domain) == "SYSTEM"
+			values[fmt.Sprintf("%t//%s", system, entry.Key)] = entry.TypedValue.Value
+		}
+	}
+	return setMetadataFields(target, values, false)
+}
+
+// setFieldsFromOpenApiMetadata sets the fields of target from the given OpenAPI metadata
+func setFieldsFromOpenApiMetadata(metadata []*types.OpenApiMetadataEntry, target any) error {
+	values := make(map[string]string)
+	for _, entry := range metadata {
+		values[openApiMetadataEntryId(entry)] = metadataValueText(entry.KeyValue.Value.Value)
+	}
+	return setMetadataFields(target, values, true)
+}
+
+// setMetadataFields sets the fields of target from a map of values indexed by "<system>/<namespace>/<key>".
+// Namespaces are only considered for OpenAPI metadata
+func setMetadataFields(target any, values map[string]string, withNamespace bool) error {
+	value, err := metadataStructValue(target, true)
+	if err != nil {
+		return err
+	}
+	fields, err := parseMetadataFields(value.Type())
+	if err != nil {
+		return err
+	}
+
+	var missing []string
+	for _, field := range fields {
+		namespace := ""
+		if withNamespace {
+			namespace = field.namespace
+		}
+		fieldValue := value.Field(field.index)
+		text, found := values[fmt.Sprintf("%t/%s/%s", field.system, namespace, field.key)]
+		if !found {
+			if field.required {
+				missing = append(missing, field.key)
+			}
+			fieldValue.SetZero()
+			continue
+		}
+		err = setMetadataFieldValue(fieldValue, text)
+		if err != nil {
+			return fmt.Errorf("error setting field %s from metadata key '%s': %s", field.name, field.key, err)
+		}
+	}
+	if len(missing) > 0 {
+		return &MetadataMissingKeysError{Keys: missing}
+	}
+	return nil
+}
+
+// setMetadataFieldValue parses the text of a metadata value into the given field
+func setMetadataFieldValue(field reflect.Value, text string) error {
+	if field.Kind() == reflect.Pointer {
+		pointer := reflect.New(field.Type().Elem())
+		err := setMetadataFieldValue(pointer.Elem(), text)
+		if err != nil {
+			return err
+		}
+		field.Set(pointer)
+		return nil
+	}
+
+	if field.Type() == metadataTimeType {
+		parsed, err := time.Parse(time.RFC3339Nano, text)
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(parsed))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(text)
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(text)
+		if err != nil {
+			return err
+		}
+		field.SetBool(parsed)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		parsed, err := strconv.ParseInt(text, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(parsed)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		parsed, err := strconv.ParseUint(text, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(parsed)
+	}
+	return nil
+}
+
+// boundMetadataValue is the value of a bound field ready to be written
+type boundMetadataValue struct {
+	field metadataField
+	text  string
+	// xmlType is one of types.MetadataStringValue, types.MetadataNumberValue, types.MetadataBooleanValue or
+	// types.MetadataDateTimeValue
+	xmlType string
+}
+
+// boundMetadataValues returns the values of the bound fields of source that need to be written, checking that
+// all required fields are set
+func boundMetadataValues(source any) ([]boundMetadataValue, error) {
+	err := ValidateMetadataBinding(source)
+	if err != nil {
+		return nil, err
+	}
+	value, _ := metadataStructValue(source, false)
+	fields, _ := parseMetadataFields(value.Type())
+
+	var result []boundMetadataValue
+	for _, field := range fields {
+		fieldValue := value.Field(field.index)
+		if fieldValue.Kind() == reflect.Pointer {
+			if fieldValue.IsNil() {
+				continue
+			}
+			fieldValue = fieldValue.Elem()
+		}
+		bound := boundMetadataValue{field: field}
+		switch {
+		case fieldValue.Type() == metadataTimeType:
+			bound.text = fieldValue.Interface().(time.Time).Format(time.RFC3339Nano)
+			bound.xmlType = types.MetadataDateTimeValue
+		case fieldValue.Kind() == reflect.String:
+			bound.text = fieldValue.String()
+			bound.xmlType = types.MetadataStringValue
+		case fieldValue.Kind() == reflect.Bool:
+			bound.text = strconv.FormatBool(fieldValue.Bool())
+			bound.xmlType = types.MetadataBooleanValue
+		default:
+			bound.text = fmt.Sprintf("%d", fieldValue.Interface())
+			bound.xmlType = types.MetadataNumberValue
+		}
+		result = append(result, bound)
+	}
+	return result, nil
+}
+
+// xmlMetadataFromFields converts the bound fields of source into a map usable by MergeMetadataWithMetadataValues
+func xmlMetadataFromFields(source any) (map[string]types.MetadataValue, error) {
+	values, err := boundMetadataValues(source)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]types.MetadataValue)
+	for _, bound := range values {
+		domain := &types.MetadataDomainTag{Domain: "GENERAL", Visibility: types.MetadataReadWriteVisibility}
+		if bound.field.system {
+			domain.Domain = "SYSTEM"
+			switch {
+			case bound.field.readOnly:
+				domain.Visibility = types.MetadataReadOnlyVisibility
+			case bound.field.hidden:
+				domain.Visibility = types.MetadataHiddenVisibility
+			}
+		} else if bound.field.readOnly || bound.field.hidden {
+			return nil, fmt.Errorf("field %s: metadata in the GENERAL domain is always read/write", bound.field.name)
+		}
+		if _, found := result[bound.field.key]; found {
+			return nil, fmt.Errorf("metadata key '%s' is bound to more than one field", bound.field.key)
+		}
+		result[bound.field.key] = types.MetadataValue{
+			Domain: domain,
+			TypedValue: &types.MetadataTypedValue{
+				XsiType: bound.xmlType,
+				Value:   bound.text,
+			},
+		}
+	}
+	return result, nil
+}
+
+// openApiMetadataFromFields converts the bound fields of source into OpenAPI metadata entries
+func openApiMetadataFromFields(source any) ([]types.OpenApiMetadataEntry, error) {
+	values, err := boundMetadataValues(source)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []types.OpenApiMetadataEntry
+	for _, bound := range values {
+		if bound.field.hidden {
+			return nil, fmt.Errorf("field %s: OpenAPI metadata does not support hidden entries", bound.field.name)
+		}
+		domain := "TENANT"
+		if bound.field.system {
+			domain = "PROVIDER"
+		}
+		// OpenAPI metadata has no date type, so dates are stored as strings
+		entryType := types.OpenApiMetadataStringEntry
+		var value any = bound.text
+		switch bound.xmlType {
+		case types.MetadataBooleanValue:
+			entryType = types.OpenApiMetadataBooleanEntry
+			value, _ = strconv.ParseBool(bound.text)
+		case types.MetadataNumberValue:
+			// json.Number keeps the precision of 64 bit integers, which a float64 would lose above 2^53
+			entryType = types.OpenApiMetadataNumberEntry
+			value = json.Number(bound.text)
+		}
+		result = append(result, types.OpenApiMetadataEntry{
+			IsPersistent: bound.field.persistent,
+			IsReadOnly:   bound.field.readOnly,
+			KeyValue: types.OpenApiMetadataKeyValue{
+				Domain:    domain,
+				Key:       bound.field.key,
+				Namespace: bound.field.namespace,
+				Value: types.OpenApiMetadataTypedValue{
+					Value: value,
+					Type:  entryType,
+				},
+			},
+		})
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].KeyValue.Key < result[j].KeyValue.Key })
+	return result, nil
+}
+
+// openApiMetadataEntryId returns the identifier used to match OpenAPI metadata entries and bound fields
+func openApiMetadataEntryId(entry *types.OpenApiMetadataEntry) string {
+	return fmt.Sprintf("%t/%s/%s", entry.KeyValue.Domain == "PROVIDER", entry.KeyValue.Namespace, entry.KeyValue.Key)
+}
+
+// metadataValueText returns the text representation of an OpenAPI metadata value. Numbers are decoded from JSON
+// as float64 and are printed without exponent
+func metadataValueText(value any) string {
+	if number, ok := value.(float64); ok {
+		return strconv.FormatFloat(number, 'f', -1, 64)
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// metadataValuesEqual compares an OpenAPI metadata value retrieved from VCD with a wanted one. Numbers retrieved from
+// VCD are decoded as float64, so wanted numbers are compared with the same precision
+func metadataValuesEqual(current, wanted any) bool {
+	if number, ok := wanted.(json.Number); ok {
+		wantedFloat, err := number.Float64()
+		currentFloat, isFloat := current.(float64)
+		return err == nil && isFloat && wantedFloat == currentFloat
+	}
+	return metadataValueText(current) == metadataValueText(wanted)
+}
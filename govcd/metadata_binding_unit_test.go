@@ -0,0 +1,176 @@
+//go:build unit || ALL
+
+/*
+ * Copyright 2025 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+package govcd
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+type testMetadataTags struct {
+	CostCenter string    `vcdmeta:"cost_center,domain=SYSTEM,readonly,required"`
+	Replicas   int       `vcdmeta:"replicas"`
+	Managed    *bool     `vcdmeta:"managed"`
+	Expires    time.Time `vcdmeta:"expires"`
+	Comment    string    `vcdmeta:"-"`
+	NotBound   string
+}
+
+// Test_MetadataBindingXml checks the conversion of tagged structs to and from XML metadata
+func Test_MetadataBindingXml(t *testing.T) {
+	expires := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	source := testMetadataTags{CostCenter: "cc-100", Replicas: 3, Expires: expires, Comment: "not written"}
+
+	values, err := xmlMetadataFromFields(source)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := map[string]types.MetadataValue{
+		"cost_center": {
+			Domain:     &types.MetadataDomainTag{Domain: "SYSTEM", Visibility: types.MetadataReadOnlyVisibility},
+			TypedValue: &types.MetadataTypedValue{XsiType: types.MetadataStringValue, Value: "cc-100"},
+		},
+		"replicas": {
+			Domain:     &types.MetadataDomainTag{Domain: "GENERAL", Visibility: types.MetadataReadWriteVisibility},
+			TypedValue: &types.MetadataTypedValue{XsiType: types.MetadataNumberValue, Value: "3"},
+		},
+		"expires": {
+			Domain:     &types.MetadataDomainTag{Domain: "GENERAL", Visibility: types.MetadataReadWriteVisibility},
+			TypedValue: &types.MetadataTypedValue{XsiType: types.MetadataDateTimeValue, Value: "2030-01-02T03:04:05Z"},
+		},
+	}
+	if !reflect.DeepEqual(values, expected) {
+		t.Fatalf("unexpected metadata values:\ngot:  %+v\nwant: %+v", values, expected)
+	}
+
+	metadata := &types.Metadata{MetadataEntry: []*types.MetadataEntry{
+		{Key: "cost_center", Domain: &types.MetadataDomainTag{Domain: "SYSTEM"}, TypedValue: &types.MetadataTypedValue{Value: "cc-200"}},
+		{Key: "replicas", TypedValue: &types.MetadataTypedValue{Value: "5"}},
+		{Key: "managed", TypedValue: &types.MetadataTypedValue{Value: "true"}},
+		{Key: "expires", TypedValue: &types.MetadataTypedValue{Value: "2030-01-02T03:04:05.000Z"}},
+	}}
+	var target testMetadataTags
+	err = setFieldsFromXmlMetadata(metadata, &target)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if target.CostCenter != "cc-200" || target.Replicas != 5 || target.Managed == nil || !*target.Managed || !target.Expires.Equal(expires) {
+		t.Fatalf("unexpected bound values: %+v", target)
+	}
+
+	// The cost center entry in the GENERAL domain does not match the field bound to the SYSTEM domain
+	metadata.MetadataEntry[0].Domain = nil
+	err = setFieldsFromXmlMetadata(metadata, &target)
+	var missingErr *MetadataMissingKeysError
+	if !errors.As(err, &missingErr) || !reflect.DeepEqual(missingErr.Keys, []string{"cost_center"}) {
+		t.Fatalf("expected missing key error, got %v", err)
+	}
+
+	err = setFieldsFromXmlMetadata(metadata, target)
+	if err == nil {
+		t.Fatalf("expected error when target is not a pointer")
+	}
+	_, err = xmlMetadataFromFields(testMetadataTags{})
+	if !errors.As(err, &missingErr) {
+		t.Fatalf("expected missing key error for a zero required field, got %v", err)
+	}
+}
+
+// Test_MetadataBindingOpenApi checks the conversion of tagged structs to and from OpenAPI metadata
+func Test_MetadataBindingOpenApi(t *testing.T) {
+	type tags struct {
+		Owner    string `vcdmeta:"owner,domain=PROVIDER,readonly,namespace=billing,persistent"`
+		Replicas uint16 `vcdmeta:"replicas"`
+		Enabled  bool   `vcdmeta:"enabled"`
+	}
+	entries, err := openApiMetadataFromFields(&tags{Owner: "team-a", Replicas: 2, Enabled: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := []types.OpenApiMetadataEntry{
+		{KeyValue: types.OpenApiMetadataKeyValue{Domain: "TENANT", Key: "enabled", Value: types.OpenApiMetadataTypedValue{Value: true, Type: types.OpenApiMetadataBooleanEntry}}},
+		{IsPersistent: true, IsReadOnly: true, KeyValue: types.OpenApiMetadataKeyValue{Domain: "PROVIDER", Key: "owner", Namespace: "billing", Value: types.OpenApiMetadataTypedValue{Value: "team-a", Type: types.OpenApiMetadataStringEntry}}},
+		{KeyValue: types.OpenApiMetadataKeyValue{Domain: "TENANT", Key: "replicas", Value: types.OpenApiMetadataTypedValue{Value: json.Number("2"), Type: types.OpenApiMetadataNumberEntry}}},
+	}
+	if !reflect.DeepEqual(entries, expected) {
+		t.Fatalf("unexpected entries:\ngot:  %+v\nwant: %+v", entries, expected)
+	}
+
+	var target tags
+	err = setFieldsFromOpenApiMetadata([]*types.OpenApiMetadataEntry{&entries[0], &entries[1], &entries[2]}, &target)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if target.Owner != "team-a" || target.Replicas != 2 || !target.Enabled {
+		t.Fatalf("unexpected bound values: %+v", target)
+	}
+
+	// Large integers and fractional seconds are written without loss
+	entries, err = openApiMetadataFromFields(struct {
+		Size    uint64    `vcdmeta:"size"`
+		Created time.Time `vcdmeta:"created"`
+	}{Size: 1<<60 + 1, Created: time.Date(2030, 1, 2, 3, 4, 5, 123000000, time.UTC)})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	payload, err := json.Marshal(entries)
+	if err != nil {
+		t.Fatalf("error encoding entries: %s", err)
+	}
+	for _, expected := range []string{`"value":1152921504606846977`, `"value":"2030-01-02T03:04:05.123Z"`} {
+		if !strings.Contains(string(payload), expected) {
+			t.Fatalf("expected %s in payload %s", expected, payload)
+		}
+	}
+	if !metadataValuesEqual(float64(1<<60), json.Number("1152921504606846977")) || metadataValuesEqual(float64(3), json.Number("2")) {
+		t.Fatalf("unexpected comparison of numbers retrieved from VCD")
+	}
+}
+
+// Test_parseMetadataFields checks that invalid tags are rejected
+func Test_parseMetadataFields(t *testing.T) {
+	invalid := []any{
+		struct {
+			A string `vcdmeta:",readonly"`
+		}{},
+		struct {
+			A string `vcdmeta:"a,domain=OTHER"`
+		}{},
+		struct {
+			A string `vcdmeta:"a,unknown"`
+		}{},
+		struct {
+			A float64 `vcdmeta:"a"`
+		}{},
+		struct {
+			A string `vcdmeta:"a"`
+			B string `vcdmeta:"a"`
+		}{},
+		struct {
+			A string `vcdmeta:"a,domain=SYSTEM,readonly,hidden"`
+		}{},
+	}
+	for index, value := range invalid {
+		_, err := parseMetadataFields(reflect.TypeOf(value))
+		if err == nil {
+			t.Fatalf("expected error for invalid struct #%d", index)
+		}
+	}
+
+	_, err := xmlMetadataFromFields(struct {
+		A string `vcdmeta:"a,readonly"`
+	}{A: "x"})
+	if err == nil {
+		t.Fatalf("expected error for a read only entry in the GENERAL domain")
+	}
+}
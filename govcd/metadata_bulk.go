@@ -0,0 +1,300 @@
+/*
+ * Copyright 2025 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+package govcd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+	"github.com/vmware/go-vcloud-director/v3/util"
+)
+
+// Actions reported in MetadataEntryChange
+const (
+	MetadataChangeAdd     = "add"
+	MetadataChangeUpdate  = "update"
+	MetadataChangeDelete  = "delete"
+	MetadataChangeIgnored = "ignored"
+)
+
+// MetadataKey identifies a metadata entry to delete in a MetadataPatch
+type MetadataKey struct {
+	Key      string
+	IsSystem bool
+}
+
+// MetadataPatch describes the metadata changes to apply to every object of a bulk operation.
+// Entries in Set are created or updated with their TypedValue and Domain, using the same format as
+// MergeMetadataWithMetadataValues. Entries in Delete are removed when present.
+type MetadataPatch struct {
+	Set    map[string]types.MetadataValue
+	Delete []MetadataKey
+}
+
+// MetadataEntryChange describes the change of a single metadata entry in a single object
+type MetadataEntryChange struct {
+	Key    string
+	Domain string // GENERAL or SYSTEM
+	Action string // One of MetadataChangeAdd, MetadataChangeUpdate, MetadataChangeDelete, MetadataChangeIgnored
+	// OldValue and NewValue are empty when the entry is added or deleted, respectively
+	OldValue string
+	NewValue string
+}
+
+// MetadataBulkOptions controls ApplyMetadataPatchByHrefs and ApplyMetadataPatchByQuery
+type MetadataBulkOptions struct {
+	// DryRun computes the changes of each object without applying them
+	DryRun bool
+	// Batch controls the concurrency of the operation. See RunBatch for defaults
+	Batch *BatchOptions
+}
+
+// MetadataBulkResult is the outcome of a metadata patch on a single object
+type MetadataBulkResult struct {
+	Href    string
+	Name    string
+	Changes []MetadataEntryChange
+	Error   error
+}
+
+// MetadataBulkReport holds the results of a bulk metadata operation in the same order as the
+// input objects
+type MetadataBulkReport struct {
+	Results []MetadataBulkResult
+}
+
+// Failed returns the results that have an error
+func (report *MetadataBulkReport) Failed() []MetadataBulkResult {
+	var failed []MetadataBulkResult
+	for _, result := range report.Results {
+		if result.Error != nil {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
+
+// Err returns an error summarizing all failures, or nil if the patch was applied to all objects
+func (report *MetadataBulkReport) Err() error {
+	failed := report.Failed()
+	if len(failed) == 0 {
+		return nil
+	}
+	messages := make([]string, len(failed))
+	for index, result := range failed {
+		messages[index] = fmt.Sprintf("%s: %s", result.Href, result.Error)
+	}
+	return fmt.Errorf("metadata patch failed on %d of %d objects:\n%s", len(failed), len(report.Results), strings.Join(messages, "\n"))
+}
+
+// ApplyMetadataPatchByQuery applies the given metadata patch to all objects of the given query type
+// that match the metadata filters. Query types are the ones supported by SearchByFilter, such as
+// types.QtVm or types.QtVapp. If isSystem is true, the filters refer to metadata in the SYSTEM domain.
+//
+// The IgnoredMetadata configuration of the client is respected: ignored entries are never changed
+// and are reported with the action MetadataChangeIgnored.
+func (vcdClient *VCDClient) ApplyMetadataPatchByQuery(queryType string, metadataFilters map[string]MetadataFilter, isSystem bool, patch MetadataPatch, options *MetadataBulkOptions) (*MetadataBulkReport, error) {
+	results, err := vcdClient.Client.queryByMetadataFilter(queryType, nil, nil, metadataFilters, isSystem)
+	if err != nil {
+		return nil, fmt.Errorf("error querying %s by metadata: %s", queryType, err)
+	}
+	items, err := resultToQueryItems(queryType, results)
+	if err != nil {
+		return nil, fmt.Errorf("error converting %s query results: %s", queryType, err)
+	}
+
+	objects := make([]metadataBulkObject, len(items))
+	for index, item := range items {
+		objects[index] = metadataBulkObject{href: item.GetHref(), name: item.GetName()}
+	}
+	return vcdClient.applyMetadataPatch(objects, patch, options)
+}
+
+// ApplyMetadataPatchByHrefs applies the given metadata patch to all the objects referenced by the
+// given HREFs. As with the other "ByHref" metadata methods, IgnoredMetadata filters that use
+// ObjectName do not apply here.
+func (vcdClient *VCDClient) ApplyMetadataPatchByHrefs(hrefs []string, patch MetadataPatch, options *MetadataBulkOptions) (*MetadataBulkReport, error) {
+	objects := make([]metadataBulkObject, len(hrefs))
+	for index, href := range hrefs {
+		objects[index] = metadataBulkObject{href: href}
+	}
+	return vcdClient.applyMetadataPatch(objects, patch, options)
+}
+
+// metadataBulkObject is an object targeted by a bulk metadata operation
+type metadataBulkObject struct {
+	href string
+	name string
+}
+
+// applyMetadataPatch runs the patch on all objects with RunBatch. For each object, the current
+// metadata is retrieved, the diff is computed and only the entries that change are sent to VCD:
+// deletions first, then a single merge task with all the additions and updates.
+func (vcdClient *VCDClient) applyMetadataPatch(objects []metadataBulkObject, patch MetadataPatch, options *MetadataBulkOptions) (*MetadataBulkReport, error) {
+	if len(patch.Set) == 0 && len(patch.Delete) == 0 {
+		return nil, fmt.Errorf("the metadata patch is empty")
+	}
+	if options == nil {
+		options = &MetadataBulkOptions{}
+	}
+	client := &vcdClient.Client
+
+	report := &MetadataBulkReport{Results: make([]MetadataBulkResult, len(objects))}
+	var mutex sync.Mutex
+	items := make([]BatchItem, len(objects))
+	for index, object := range objects {
+		index, object := index, object
+		report.Results[index] = MetadataBulkResult{Href: object.href, Name: object.name}
+		items[index] = BatchItem{
+			Name: object.href,
+			Operation: func() (*Task, error) {
+				current, err := getMetadata(client, object.href, object.name)
+				if err != nil {
+					return nil, err
+				}
+				changes, err := diffMetadataPatch(current, patch, object.href, object.name, client.IgnoredMetadata)
+				if err != nil {
+					return nil, err
+				}
+				mutex.Lock()
+				report.Results[index].Changes = changes
+				mutex.Unlock()
+				if options.DryRun {
+					return nil, nil
+				}
+				return applyMetadataChanges(client, object, patch, changes)
+			},
+		}
+	}
+
+	batchReport := RunBatch(items, options.Batch)
+	for index, result := range batchReport.Results {
+		report.Results[index].Error = result.Error
+	}
+	return report, nil
+}
+
+// applyMetadataChanges deletes the entries that need deletion and returns the merge task for the
+// entries that need to be added or updated, if any
+func applyMetadataChanges(client *Client, object metadataBulkObject, patch MetadataPatch, changes []MetadataEntryChange) (*Task, error) {
+	toMerge := make(map[string]types.MetadataValue)
+	for _, change := range changes {
+		switch change.Action {
+		case MetadataChangeDelete:
+			err := deleteMetadataAndWait(client, object.href, object.name, change.Key, change.Domain == "SYSTEM")
+			if err != nil {
+				return nil, err
+			}
+		case MetadataChangeAdd, MetadataChangeUpdate:
+			toMerge[change.Key] = patch.Set[change.Key]
+		}
+	}
+	if len(toMerge) == 0 {
+		return nil, nil
+	}
+	util.Logger.Printf("[DEBUG] merging %d metadata entries into %s", len(toMerge), object.href)
+	return BatchTask(mergeAllMetadata(client, object.href, object.name, toMerge))
+}
+
+// diffMetadataPatch computes the changes that the patch would make to the current metadata of an
+// object. The current metadata must be already filtered by IgnoredMetadata, so that deletions of
+// ignored entries are not planned. Unchanged entries are not reported. Changes are sorted by key.
+func diffMetadataPatch(current *types.Metadata, patch MetadataPatch, href, objectName string, metadataToIgnore []IgnoredMetadata) ([]MetadataEntryChange, error) {
+	existing := make(map[string]*types.MetadataEntry)
+	if current != nil {
+		for _, entry := range current.MetadataEntry {
+			existing[metadataDomainOf(entry.Domain)+"/"+entry.Key] = entry
+		}
+	}
+
+	var changes []MetadataEntryChange
+	for key, value := range patch.Set {
+		value := value
+		domain := metadataDomainOf(value.Domain)
+		newValue := ""
+		if value.TypedValue != nil {
+			newValue = value.TypedValue.Value
+		}
+		change := MetadataEntryChange{Key: key, Domain: domain, NewValue: newValue}
+
+		_, err := filterSingleXmlMetadataEntry(key, href, objectName, &value, metadataToIgnore)
+		if err != nil {
+			if !strings.Contains(err.Error(), "ignored") {
+				return nil, err
+			}
+			change.Action = MetadataChangeIgnored
+			changes = append(changes, change)
+			continue
+		}
+
+		entry, found := existing[domain+"/"+key]
+		switch {
+		case !found:
+			change.Action = MetadataChangeAdd
+		case metadataEntryEqual(entry, value):
+			continue
+		default:
+			change.Action = MetadataChangeUpdate
+			change.OldValue = entry.TypedValue.Value
+		}
+		changes = append(changes, change)
+	}
+
+	for _, key := range patch.Delete {
+		domain := "GENERAL"
+		if key.IsSystem {
+			domain = "SYSTEM"
+		}
+		entry, found := existing[domain+"/"+key.Key]
+		if !found {
+			continue
+		}
+		changes = append(changes, MetadataEntryChange{
+			Key:      key.Key,
+			Domain:   domain,
+			Action:   MetadataChangeDelete,
+			OldValue: entry.TypedValue.Value,
+		})
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		if changes[i].Key != changes[j].Key {
+			return changes[i].Key < changes[j].Key
+		}
+		return changes[i].Domain < changes[j].Domain
+	})
+	return changes, nil
+}
+
+// metadataEntryEqual returns true if the existing entry has the same type, value and visibility
+// as the wanted one
+func metadataEntryEqual(entry *types.MetadataEntry, value types.MetadataValue) bool {
+	if entry.TypedValue == nil || value.TypedValue == nil {
+		return entry.TypedValue == value.TypedValue
+	}
+	if entry.TypedValue.XsiType != value.TypedValue.XsiType || entry.TypedValue.Value != value.TypedValue.Value {
+		return false
+	}
+	return metadataVisibilityOf(entry.Domain) == metadataVisibilityOf(value.Domain)
+}
+
+// metadataDomainOf returns the domain of a metadata entry, which is GENERAL when not set
+func metadataDomainOf(domain *types.MetadataDomainTag) string {
+	if domain == nil || domain.Domain == "" {
+		return "GENERAL"
+	}
+	return domain.Domain
+}
+
+// metadataVisibilityOf returns the visibility of a metadata entry, which is READWRITE when not set
+func metadataVisibilityOf(domain *types.MetadataDomainTag) string {
+	if domain == nil || domain.Visibility == "" {
+		return types.MetadataReadWriteVisibility
+	}
+	return domain.Visibility
+}
@@ -0,0 +1,63 @@
+//go:build unit || ALL
+
+/*
+ * Copyright 2025 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+package govcd
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// Test_diffMetadataPatch checks the per object changes computed for a metadata patch
+func Test_diffMetadataPatch(t *testing.T) {
+	stringValue := func(value string) *types.MetadataTypedValue {
+		return &types.MetadataTypedValue{XsiType: types.MetadataStringValue, Value: value}
+	}
+	current := &types.Metadata{
+		MetadataEntry: []*types.MetadataEntry{
+			{Key: "costCenter", TypedValue: stringValue("cc-100")},
+			{Key: "owner", TypedValue: stringValue("team-a")},
+			{Key: "obsolete", TypedValue: stringValue("yes")},
+			{Key: "obsolete", TypedValue: stringValue("system"), Domain: &types.MetadataDomainTag{Domain: "SYSTEM", Visibility: types.MetadataReadOnlyVisibility}},
+		},
+	}
+	patch := MetadataPatch{
+		Set: map[string]types.MetadataValue{
+			"costCenter": {TypedValue: stringValue("cc-200"), Domain: &types.MetadataDomainTag{Domain: "GENERAL", Visibility: types.MetadataReadWriteVisibility}},
+			"owner":      {TypedValue: stringValue("team-a")},
+			"project":    {TypedValue: stringValue("apollo")},
+			"managed":    {TypedValue: stringValue("true")},
+		},
+		Delete: []MetadataKey{{Key: "obsolete"}, {Key: "missing"}},
+	}
+	ignored := []IgnoredMetadata{{KeyRegex: regexp.MustCompile(`^managed$`)}}
+	href := "https://vcd.example.com/api/vApp/vm-f5d9c4d4-1111-2222-3333-444455556666"
+
+	changes, err := diffMetadataPatch(current, patch, href, "vm1", ignored)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := []MetadataEntryChange{
+		{Key: "costCenter", Domain: "GENERAL", Action: MetadataChangeUpdate, OldValue: "cc-100", NewValue: "cc-200"},
+		{Key: "managed", Domain: "GENERAL", Action: MetadataChangeIgnored, NewValue: "true"},
+		{Key: "obsolete", Domain: "GENERAL", Action: MetadataChangeDelete, OldValue: "yes"},
+		{Key: "project", Domain: "GENERAL", Action: MetadataChangeAdd, NewValue: "apollo"},
+	}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Fatalf("unexpected changes:\ngot:  %+v\nwant: %+v", changes, expected)
+	}
+
+	changes, err = diffMetadataPatch(current, MetadataPatch{Set: map[string]types.MetadataValue{"owner": {TypedValue: stringValue("team-a")}}}, href, "vm1", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(changes) != 0 {
+		t.Fatalf("expected no changes for an unchanged entry, got %+v", changes)
+	}
+}
@@ -0,0 +1,404 @@
+/*
+ * Copyright 2025 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+package govcd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// Kinds of NetworkTopologyNode
+const (
+	// NetworkTopologyNodeProviderGateway is a Provider Gateway (External Network backed by a Tier-0 or VRF gateway)
+	NetworkTopologyNodeProviderGateway = "providerGateway"
+	// NetworkTopologyNodeExternalNetwork is an External Network backed by an NSX-T segment
+	NetworkTopologyNodeExternalNetwork = "externalNetwork"
+	NetworkTopologyNodeEdgeGateway     = "edgeGateway"
+	NetworkTopologyNodeOrgVdcNetwork   = "orgVdcNetwork"
+	NetworkTopologyNodeVappNetwork     = "vappNetwork"
+	NetworkTopologyNodeVm              = "vm"
+	// NetworkTopologyNodeRemotePeer is a device outside of VCD, such as a VPN endpoint or a BGP neighbor
+	NetworkTopologyNodeRemotePeer = "remotePeer"
+)
+
+// Kinds of NetworkTopologyEdge
+const (
+	// NetworkTopologyEdgeUplink connects an Edge Gateway to a Provider Gateway or External Network
+	NetworkTopologyEdgeUplink = "uplink"
+	// NetworkTopologyEdgeConnection connects a routed Org VDC network to its Edge Gateway
+	NetworkTopologyEdgeConnection = "connection"
+	// NetworkTopologyEdgeParentNetwork connects a vApp network to its parent Org VDC network
+	NetworkTopologyEdgeParentNetwork = "parentNetwork"
+	// NetworkTopologyEdgeNic connects a VM to the network of one of its NICs
+	NetworkTopologyEdgeNic         = "nic"
+	NetworkTopologyEdgeIpSecVpn    = "ipSecVpn"
+	NetworkTopologyEdgeL2Vpn       = "l2Vpn"
+	NetworkTopologyEdgeBgpNeighbor = "bgpNeighbor"
+)
+
+// NetworkTopology is a graph of the networking of an Org or a VDC Group
+type NetworkTopology struct {
+	Name  string                `json:"name"`
+	Nodes []NetworkTopologyNode `json:"nodes"`
+	Edges []NetworkTopologyEdge `json:"edges"`
+
+	nodeIndex map[string]int
+}
+
+// NetworkTopologyNode is an entity of the topology
+type NetworkTopologyNode struct {
+	// Id is the VCD ID of the entity. vApp networks are identified by "<vApp ID>/<network name>" and remote peers
+	// by "peer:<address>"
+	Id         string            `json:"id"`
+	Kind       string            `json:"kind"`
+	Name       string            `json:"name"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+// NetworkTopologyEdge is a relationship between two nodes
+type NetworkTopologyEdge struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Kind  string `json:"kind"`
+	Label string `json:"label,omitempty"`
+}
+
+// networkTopologySource contains the entities retrieved from VCD to build a NetworkTopology
+type networkTopologySource struct {
+	edgeGateways    []*types.OpenAPIEdgeGateway
+	ipSecVpnTunnels map[string][]*types.NsxtIpSecVpnTunnel
+	l2VpnTunnels    map[string][]*types.NsxtL2VpnTunnel
+	bgpNeighbors    map[string][]*types.EdgeBgpNeighbor
+	networks        []*types.OpenApiOrgVdcNetwork
+	vApps           []*types.VApp
+}
+
+// GetNetworkTopology builds a graph of the NSX-T Edge Gateways, Org VDC networks, vApp networks and VMs of the Org
+func (org *Org) GetNetworkTopology() (*NetworkTopology, error) {
+	// Org.GetAllNsxtEdgeGateways returns the Edge Gateways of all Orgs to System administrators
+	edgeGateways, err := org.GetAllNsxtEdgeGateways(queryParameterFilterAnd("orgRef.id=="+org.Org.ID, nil))
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving Edge Gateways: %s", err)
+	}
+	edgeGateways = filterNsxtEdgeGatewaysByOrg(edgeGateways, org.Org.ID)
+	networks, err := org.GetAllOpenApiOrgVdcNetworks(nil)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving Org VDC networks: %s", err)
+	}
+	vdcs, err := org.QueryOrgVdcList()
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving VDCs: %s", err)
+	}
+	var vdcHrefs []string
+	for _, vdc := range vdcs {
+		vdcHrefs = append(vdcHrefs, vdc.HREF)
+	}
+	source, err := getNetworkTopologySource(org.client, edgeGateways, networks, vdcHrefs)
+	if err != nil {
+		return nil, err
+	}
+	return buildNetworkTopology(org.Org.Name, source), nil
+}
+
+// GetNetworkTopology builds a graph of the NSX-T Edge Gateways and Org VDC networks of the VDC Group, together with
+// the vApp networks and VMs of its local participating VDCs
+func (vdcGroup *VdcGroup) GetNetworkTopology() (*NetworkTopology, error) {
+	edgeGateways, err := vdcGroup.GetAllNsxtEdgeGateways(nil)
+	if err != nil && !ContainsNotFound(err) {
+		return nil, fmt.Errorf("error retrieving Edge Gateways: %s", err)
+	}
+	networks, err := vdcGroup.GetAllOpenApiOrgVdcNetworks(nil)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving Org VDC networks: %s", err)
+	}
+	var vdcHrefs []string
+	for _, participant := range vdcGroup.VdcGroup.ParticipatingOrgVdcs {
+		if participant.RemoteOrg {
+			continue
+		}
+		vdcHrefs = append(vdcHrefs, vdcGroup.client.rootVcdHref()+"/api/vdc/"+extractUuid(participant.VdcRef.ID))
+	}
+	source, err := getNetworkTopologySource(vdcGroup.client, edgeGateways, networks, vdcHrefs)
+	if err != nil {
+		return nil, err
+	}
+	return buildNetworkTopology(vdcGroup.VdcGroup.Name, source), nil
+}
+
+// filterNsxtEdgeGatewaysByOrg returns the Edge Gateways that belong to the Org with the given ID, so that the
+// VPN peers and BGP neighbors of other tenants are never retrieved
+func filterNsxtEdgeGatewaysByOrg(edgeGateways []*NsxtEdgeGateway, orgId string) []*NsxtEdgeGateway {
+	var result []*NsxtEdgeGateway
+	for _, egw := range edgeGateways {
+		if egw.EdgeGateway.Org != nil && equalIds(orgId, egw.EdgeGateway.Org.ID, "") {
+			result = append(result, egw)
+		}
+	}
+	return result
+}
+
+// getNetworkTopologySource retrieves the VPN tunnels and BGP neighbors of Edge Gateways and the vApps of VDCs
+func getNetworkTopologySource(client *Client, edgeGateways []*NsxtEdgeGateway, networks []*OpenApiOrgVdcNetwork, vdcHrefs []string) (*networkTopologySource, error) {
+	source := &networkTopologySource{
+		ipSecVpnTunnels: make(map[string][]*types.NsxtIpSecVpnTunnel),
+		l2VpnTunnels:    make(map[string][]*types.NsxtL2VpnTunnel),
+		bgpNeighbors:    make(map[string][]*types.EdgeBgpNeighbor),
+	}
+
+	for _, egw := range edgeGateways {
+		id := egw.EdgeGateway.ID
+		source.edgeGateways = append(source.edgeGateways, egw.EdgeGateway)
+
+		ipSecVpnTunnels, err := egw.GetAllIpSecVpnTunnels(nil)
+		if err != nil {
+			return nil, fmt.Errorf("error retrieving IPsec VPN tunnels of Edge Gateway '%s': %s", egw.EdgeGateway.Name, err)
+		}
+		for _, tunnel := range ipSecVpnTunnels {
+			source.ipSecVpnTunnels[id] = append(source.ipSecVpnTunnels[id], tunnel.NsxtIpSecVpn)
+		}
+
+		l2VpnTunnels, err := egw.GetAllL2VpnTunnels(nil)
+		if err != nil {
+			return nil, fmt.Errorf("error retrieving L2 VPN tunnels of Edge Gateway '%s': %s", egw.EdgeGateway.Name, err)
+		}
+		for _, tunnel := range l2VpnTunnels {
+			source.l2VpnTunnels[id] = append(source.l2VpnTunnels[id], tunnel.NsxtL2VpnTunnel)
+		}
+
+		bgpNeighbors, err := egw.GetAllBgpNeighbors(nil)
+		if err != nil {
+			return nil, fmt.Errorf("error retrieving BGP neighbors of Edge Gateway '%s': %s", egw.EdgeGateway.Name, err)
+		}
+		for _, neighbor := range bgpNeighbors {
+			source.bgpNeighbors[id] = append(source.bgpNeighbors[id], neighbor.EdgeBgpNeighbor)
+		}
+	}
+
+	for _, network := range networks {
+		source.networks = append(source.networks, network.OpenApiOrgVdcNetwork)
+	}
+
+	for _, vdcHref := range vdcHrefs {
+		vdc := NewVdc(client)
+		var err error
+		vdc.Vdc, err = getVDCByHref(client, vdcHref)
+		if err != nil {
+			return nil, err
+		}
+		for _, vAppReference := range vdc.GetVappList() {
+			vApp, err := vdc.GetVAppByHref(vAppReference.HREF)
+			if err != nil {
+				return nil, fmt.Errorf("error retrieving vApp '%s': %s", vAppReference.Name, err)
+			}
+			source.vApps = append(source.vApps, vApp.VApp)
+		}
+	}
+	return source, nil
+}
+
+// buildNetworkTopology creates the graph from the retrieved entities
+func buildNetworkTopology(name string, source *networkTopologySource) *NetworkTopology {
+	topology := &NetworkTopology{Name: name}
+
+	for _, egw := range source.edgeGateways {
+		topology.addNode(NetworkTopologyNode{Id: egw.ID, Kind: NetworkTopologyNodeEdgeGateway, Name: egw.Name})
+		for _, uplink := range egw.EdgeGatewayUplinks {
+			kind := NetworkTopologyNodeProviderGateway
+			attributes := map[string]string{}
+			if uplink.BackingType != nil {
+				attributes["backingType"] = *uplink.BackingType
+				if *uplink.BackingType == types.ExternalNetworkBackingTypeNsxtSegment {
+					kind = NetworkTopologyNodeExternalNetwork
+				}
+			}
+			topology.addNode(NetworkTopologyNode{Id: uplink.UplinkID, Kind: kind, Name: uplink.UplinkName, Attributes: attributes})
+			topology.addEdge(egw.ID, uplink.UplinkID, NetworkTopologyEdgeUplink, "")
+		}
+		for _, tunnel := range source.ipSecVpnTunnels[egw.ID] {
+			peer := topology.addRemotePeer(tunnel.RemoteEndpoint.RemoteAddress)
+			topology.addEdge(egw.ID, peer, NetworkTopologyEdgeIpSecVpn, tunnel.Name)
+		}
+		for _, tunnel := range source.l2VpnTunnels[egw.ID] {
+			peer := topology.addRemotePeer(tunnel.RemoteEndpointIp)
+			topology.addEdge(egw.ID, peer, NetworkTopologyEdgeL2Vpn, tunnel.Name)
+		}
+		for _, neighbor := range source.bgpNeighbors[egw.ID] {
+			peer := topology.addRemotePeer(neighbor.NeighborAddress)
+			topology.addEdge(egw.ID, peer, NetworkTopologyEdgeBgpNeighbor, "AS "+neighbor.RemoteASNumber)
+		}
+	}
+
+	for _, network := range source.networks {
+		attributes := map[string]string{"networkType": network.NetworkType}
+		if network.OwnerRef != nil {
+			attributes["owner"] = network.OwnerRef.Name
+		}
+		if network.BackingNetworkType != "" {
+			attributes["backingNetworkType"] = network.BackingNetworkType
+		}
+		topology.addNode(NetworkTopologyNode{Id: network.ID, Kind: NetworkTopologyNodeOrgVdcNetwork, Name: network.Name, Attributes: attributes})
+		if network.Connection != nil && network.Connection.RouterRef.ID != "" {
+			// Edge Gateways outside of the scope, such as the ones of another VDC Group, are still shown
+			topology.addNode(NetworkTopologyNode{Id: network.Connection.RouterRef.ID, Kind: NetworkTopologyNodeEdgeGateway, Name: network.Connection.RouterRef.Name})
+			topology.addEdge(network.ID, network.Connection.RouterRef.ID, NetworkTopologyEdgeConnection, network.Connection.ConnectionTypeValue)
+		}
+	}
+
+	for _, vApp := range source.vApps {
+		// vAppNetworks maps the network names seen by VM NICs to node IDs
+		vAppNetworks := make(map[string]string)
+		if vApp.NetworkConfigSection != nil {
+			for _, networkConfig := range vApp.NetworkConfigSection.NetworkConfig {
+				if networkConfig.NetworkName == types.NoneNetwork || networkConfig.Configuration == nil {
+					continue
+				}
+				parentId := ""
+				if parent := networkConfig.Configuration.ParentNetwork; parent != nil {
+					parentId = "urn:vcloud:network:" + extractUuid(parent.HREF)
+					topology.addNode(NetworkTopologyNode{Id: parentId, Kind: NetworkTopologyNodeOrgVdcNetwork, Name: parent.Name})
+					// A vApp directly connected to an Org VDC network has a bridged configuration named after it
+					if networkConfig.Configuration.FenceMode == types.FenceModeBridged && networkConfig.NetworkName == parent.Name {
+						vAppNetworks[networkConfig.NetworkName] = parentId
+						continue
+					}
+				}
+				id := vApp.ID + "/" + networkConfig.NetworkName
+				topology.addNode(NetworkTopologyNode{
+					Id:         id,
+					Kind:       NetworkTopologyNodeVappNetwork,
+					Name:       networkConfig.NetworkName,
+					Attributes: map[string]string{"vApp": vApp.Name, "fenceMode": networkConfig.Configuration.FenceMode},
+				})
+				if parentId != "" {
+					topology.addEdge(id, parentId, NetworkTopologyEdgeParentNetwork, networkConfig.Configuration.FenceMode)
+				}
+				vAppNetworks[networkConfig.NetworkName] = id
+			}
+		}
+
+		if vApp.Children == nil {
+			continue
+		}
+		for _, vm := range vApp.Children.VM {
+			topology.addNode(NetworkTopologyNode{Id: vm.ID, Kind: NetworkTopologyNodeVm, Name: vm.Name, Attributes: map[string]string{"vApp": vApp.Name}})
+			if vm.NetworkConnectionSection == nil {
+				continue
+			}
+			for _, nic := range vm.NetworkConnectionSection.NetworkConnection {
+				networkId, found := vAppNetworks[nic.Network]
+				if !found {
+					continue
+				}
+				label := fmt.Sprintf("NIC %d", nic.NetworkConnectionIndex)
+				if nic.IPAddress != "" {
+					label += " " + nic.IPAddress
+				}
+				topology.addEdge(vm.ID, networkId, NetworkTopologyEdgeNic, label)
+			}
+		}
+	}
+	return topology
+}
+
+// addNode adds a node, unless a node with the same ID exists already
+func (topology *NetworkTopology) addNode(node NetworkTopologyNode) {
+	if topology.nodeIndex == nil {
+		topology.nodeIndex = make(map[string]int)
+	}
+	if _, found := topology.nodeIndex[node.Id]; found {
+		return
+	}
+	topology.nodeIndex[node.Id] = len(topology.Nodes)
+	topology.Nodes = append(topology.Nodes, node)
+}
+
+// addRemotePeer adds a node for an address outside of VCD and returns its ID
+func (topology *NetworkTopology) addRemotePeer(address string) string {
+	id := "peer:" + address
+	topology.addNode(NetworkTopologyNode{Id: id, Kind: NetworkTopologyNodeRemotePeer, Name: address})
+	return id
+}
+
+func (topology *NetworkTopology) addEdge(from, to, kind, label string) {
+	topology.Edges = append(topology.Edges, NetworkTopologyEdge{From: from, To: to, Kind: kind, Label: label})
+}
+
+// ToJson returns the topology as indented JSON
+func (topology *NetworkTopology) ToJson() ([]byte, error) {
+	return json.MarshalIndent(topology, "", "  ")
+}
+
+// ToDot returns the topology in Graphviz DOT format
+func (topology *NetworkTopology) ToDot() string {
+	shapes := map[string]string{
+		NetworkTopologyNodeProviderGateway: "doubleoctagon",
+		NetworkTopologyNodeExternalNetwork: "doubleoctagon",
+		NetworkTopologyNodeEdgeGateway:     "octagon",
+		NetworkTopologyNodeOrgVdcNetwork:   "ellipse",
+		NetworkTopologyNodeVappNetwork:     "ellipse",
+		NetworkTopologyNodeVm:              "box",
+		NetworkTopologyNodeRemotePeer:      "diamond",
+	}
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "digraph %s {\n", dotQuote(topology.Name))
+	builder.WriteString("  rankdir=LR;\n")
+	for _, node := range topology.Nodes {
+		style := ""
+		if node.Kind == NetworkTopologyNodeVappNetwork || node.Kind == NetworkTopologyNodeRemotePeer {
+			style = ", style=dashed"
+		}
+		fmt.Fprintf(&builder, "  %s [label=%s, shape=%s%s];\n", dotQuote(node.Id), dotQuote(node.Name), shapes[node.Kind], style)
+	}
+	for _, edge := range topology.Edges {
+		label := edge.Kind
+		if edge.Label != "" {
+			label += ": " + edge.Label
+		}
+		fmt.Fprintf(&builder, "  %s -> %s [label=%s];\n", dotQuote(edge.From), dotQuote(edge.To), dotQuote(label))
+	}
+	builder.WriteString("}\n")
+	return builder.String()
+}
+
+// ToMermaid returns the topology as a Mermaid flowchart
+func (topology *NetworkTopology) ToMermaid() string {
+	shapes := map[string][2]string{
+		NetworkTopologyNodeProviderGateway: {"{{", "}}"},
+		NetworkTopologyNodeExternalNetwork: {"{{", "}}"},
+		NetworkTopologyNodeEdgeGateway:     {"[/", "\\]"},
+		NetworkTopologyNodeOrgVdcNetwork:   {"(", ")"},
+		NetworkTopologyNodeVappNetwork:     {"([", "])"},
+		NetworkTopologyNodeVm:              {"[", "]"},
+		NetworkTopologyNodeRemotePeer:      {">", "]"},
+	}
+	// Mermaid node IDs cannot contain the characters found in VCD IDs, so nodes are numbered
+	ids := make(map[string]string)
+	var builder strings.Builder
+	builder.WriteString("flowchart LR\n")
+	for index, node := range topology.Nodes {
+		ids[node.Id] = fmt.Sprintf("n%d", index)
+		shape := shapes[node.Kind]
+		fmt.Fprintf(&builder, "  %s%s%s%s\n", ids[node.Id], shape[0], mermaidQuote(node.Name), shape[1])
+	}
+	for _, edge := range topology.Edges {
+		label := edge.Kind
+		if edge.Label != "" {
+			label += ": " + edge.Label
+		}
+		fmt.Fprintf(&builder, "  %s -->|%s| %s\n", ids[edge.From], mermaidQuote(label), ids[edge.To])
+	}
+	return builder.String()
+}
+
+func dotQuote(text string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(text) + `"`
+}
+
+func mermaidQuote(text string) string {
+	return `"` + strings.NewReplacer(`"`, "#quot;", "\n", " ").Replace(text) + `"`
+}
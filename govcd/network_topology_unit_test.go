@@ -0,0 +1,175 @@
+//go:build unit || ALL
+
+/*
+ * Copyright 2025 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+package govcd
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// Test_buildNetworkTopology checks nodes, edges and renderings of a network topology
+func Test_buildNetworkTopology(t *testing.T) {
+	const (
+		egwId     = "urn:vcloud:gateway:11111111-1111-1111-1111-111111111111"
+		routedId  = "urn:vcloud:network:22222222-2222-2222-2222-222222222222"
+		isolateId = "urn:vcloud:network:33333333-3333-3333-3333-333333333333"
+		vAppId    = "urn:vcloud:vapp:44444444-4444-4444-4444-444444444444"
+	)
+	source := &networkTopologySource{
+		edgeGateways: []*types.OpenAPIEdgeGateway{{
+			ID:   egwId,
+			Name: "edge",
+			EdgeGatewayUplinks: []types.EdgeGatewayUplinks{
+				{UplinkID: "urn:vcloud:network:t0", UplinkName: "tier0", BackingType: addrOf(types.ExternalNetworkBackingTypeNsxtTier0Router)},
+			},
+		}},
+		ipSecVpnTunnels: map[string][]*types.NsxtIpSecVpnTunnel{
+			egwId: {{Name: "to-dc", RemoteEndpoint: types.NsxtIpSecVpnTunnelRemoteEndpoint{RemoteAddress: "198.51.100.1"}}},
+		},
+		bgpNeighbors: map[string][]*types.EdgeBgpNeighbor{
+			egwId: {{NeighborAddress: "198.51.100.1", RemoteASNumber: "65001"}},
+		},
+		networks: []*types.OpenApiOrgVdcNetwork{
+			{ID: routedId, Name: "routed", NetworkType: types.OrgVdcNetworkTypeRouted,
+				Connection: &types.Connection{RouterRef: types.OpenApiReference{ID: egwId, Name: "edge"}, ConnectionTypeValue: "INTERNAL"}},
+			{ID: isolateId, Name: "isolated", NetworkType: types.OrgVdcNetworkTypeIsolated},
+		},
+		vApps: []*types.VApp{{
+			ID:   vAppId,
+			Name: "app",
+			NetworkConfigSection: &types.NetworkConfigSection{NetworkConfig: []types.VAppNetworkConfiguration{
+				{NetworkName: "routed", Configuration: &types.NetworkConfiguration{FenceMode: types.FenceModeBridged,
+					ParentNetwork: &types.Reference{HREF: "https://vcd/api/network/22222222-2222-2222-2222-222222222222", Name: "routed"}}},
+				{NetworkName: "app-net", Configuration: &types.NetworkConfiguration{FenceMode: types.FenceModeNAT,
+					ParentNetwork: &types.Reference{HREF: "https://vcd/api/network/33333333-3333-3333-3333-333333333333", Name: "isolated"}}},
+			}},
+			Children: &types.VAppChildren{VM: []*types.Vm{{
+				ID:   "urn:vcloud:vm:55555555-5555-5555-5555-555555555555",
+				Name: "web \"1\"",
+				NetworkConnectionSection: &types.NetworkConnectionSection{NetworkConnection: []*types.NetworkConnection{
+					{Network: "routed", NetworkConnectionIndex: 0, IPAddress: "10.0.0.10"},
+					{Network: "app-net", NetworkConnectionIndex: 1},
+					{Network: types.NoneNetwork, NetworkConnectionIndex: 2},
+				}},
+			}}},
+		}},
+	}
+
+	topology := buildNetworkTopology("org", source)
+
+	var nodeKinds []string
+	for _, node := range topology.Nodes {
+		nodeKinds = append(nodeKinds, node.Kind)
+	}
+	expectedNodeKinds := []string{
+		NetworkTopologyNodeEdgeGateway, NetworkTopologyNodeProviderGateway, NetworkTopologyNodeRemotePeer,
+		NetworkTopologyNodeOrgVdcNetwork, NetworkTopologyNodeOrgVdcNetwork, NetworkTopologyNodeVappNetwork,
+		NetworkTopologyNodeVm,
+	}
+	if !reflect.DeepEqual(nodeKinds, expectedNodeKinds) {
+		t.Fatalf("unexpected nodes: %+v", topology.Nodes)
+	}
+
+	expectedEdges := []NetworkTopologyEdge{
+		{From: egwId, To: "urn:vcloud:network:t0", Kind: NetworkTopologyEdgeUplink},
+		{From: egwId, To: "peer:198.51.100.1", Kind: NetworkTopologyEdgeIpSecVpn, Label: "to-dc"},
+		{From: egwId, To: "peer:198.51.100.1", Kind: NetworkTopologyEdgeBgpNeighbor, Label: "AS 65001"},
+		{From: routedId, To: egwId, Kind: NetworkTopologyEdgeConnection, Label: "INTERNAL"},
+		{From: vAppId + "/app-net", To: isolateId, Kind: NetworkTopologyEdgeParentNetwork, Label: types.FenceModeNAT},
+		{From: "urn:vcloud:vm:55555555-5555-5555-5555-555555555555", To: routedId, Kind: NetworkTopologyEdgeNic, Label: "NIC 0 10.0.0.10"},
+		{From: "urn:vcloud:vm:55555555-5555-5555-5555-555555555555", To: vAppId + "/app-net", Kind: NetworkTopologyEdgeNic, Label: "NIC 1"},
+	}
+	if !reflect.DeepEqual(topology.Edges, expectedEdges) {
+		t.Fatalf("unexpected edges:\ngot:  %+v\nwant: %+v", topology.Edges, expectedEdges)
+	}
+
+	dot := topology.ToDot()
+	for _, expected := range []string{
+		`digraph "org" {`,
+		`"urn:vcloud:vm:55555555-5555-5555-5555-555555555555" [label="web \"1\"", shape=box];`,
+		`"` + routedId + `" -> "` + egwId + `" [label="connection: INTERNAL"];`,
+	} {
+		if !strings.Contains(dot, expected) {
+			t.Fatalf("DOT output does not contain %q:\n%s", expected, dot)
+		}
+	}
+
+	mermaid := topology.ToMermaid()
+	for _, expected := range []string{
+		"flowchart LR\n",
+		`n0[/"edge"\]`,
+		`n6["web #quot;1#quot;"]`,
+		`n6 -->|"nic: NIC 1"| n5`,
+	} {
+		if !strings.Contains(mermaid, expected) {
+			t.Fatalf("Mermaid output does not contain %q:\n%s", expected, mermaid)
+		}
+	}
+
+	jsonText, err := topology.ToJson()
+	if err != nil {
+		t.Fatalf("error converting topology to JSON: %s", err)
+	}
+	var decoded NetworkTopology
+	err = json.Unmarshal(jsonText, &decoded)
+	if err != nil {
+		t.Fatalf("error parsing topology JSON: %s", err)
+	}
+	if !reflect.DeepEqual(decoded.Nodes, topology.Nodes) || !reflect.DeepEqual(decoded.Edges, topology.Edges) {
+		t.Fatalf("JSON round trip changed the topology:\n%s", jsonText)
+	}
+}
+
+// Test_filterNsxtEdgeGatewaysByOrg checks that Edge Gateways of other Orgs and their peers are excluded from the topology
+func Test_filterNsxtEdgeGatewaysByOrg(t *testing.T) {
+	const (
+		orgId      = "urn:vcloud:org:11111111-1111-1111-1111-111111111111"
+		otherOrgId = "urn:vcloud:org:22222222-2222-2222-2222-222222222222"
+		egwId      = "urn:vcloud:gateway:33333333-3333-3333-3333-333333333333"
+		otherEgwId = "urn:vcloud:gateway:44444444-4444-4444-4444-444444444444"
+	)
+	edgeGateways := []*NsxtEdgeGateway{
+		{EdgeGateway: &types.OpenAPIEdgeGateway{ID: egwId, Name: "edge", Org: &types.OpenApiReference{ID: orgId}}},
+		{EdgeGateway: &types.OpenAPIEdgeGateway{ID: otherEgwId, Name: "other-edge", Org: &types.OpenApiReference{ID: otherOrgId}}},
+		{EdgeGateway: &types.OpenAPIEdgeGateway{ID: "urn:vcloud:gateway:no-org", Name: "no-org"}},
+	}
+
+	source := &networkTopologySource{
+		ipSecVpnTunnels: map[string][]*types.NsxtIpSecVpnTunnel{
+			egwId:      {{Name: "to-dc", RemoteEndpoint: types.NsxtIpSecVpnTunnelRemoteEndpoint{RemoteAddress: "198.51.100.1"}}},
+			otherEgwId: {{Name: "other-vpn", RemoteEndpoint: types.NsxtIpSecVpnTunnelRemoteEndpoint{RemoteAddress: "203.0.113.99"}}},
+		},
+		bgpNeighbors: map[string][]*types.EdgeBgpNeighbor{
+			otherEgwId: {{NeighborAddress: "203.0.113.98", RemoteASNumber: "65099"}},
+		},
+	}
+	for _, egw := range filterNsxtEdgeGatewaysByOrg(edgeGateways, orgId) {
+		source.edgeGateways = append(source.edgeGateways, egw.EdgeGateway)
+	}
+	if len(source.edgeGateways) != 1 || source.edgeGateways[0].ID != egwId {
+		t.Fatalf("unexpected Edge Gateways %+v", source.edgeGateways)
+	}
+
+	topology := buildNetworkTopology("org", source)
+	for _, node := range topology.Nodes {
+		if strings.Contains(node.Id, otherEgwId) || strings.Contains(node.Name, "203.0.113.9") || node.Name == "other-edge" {
+			t.Fatalf("node of another Org in topology: %+v", node)
+		}
+	}
+	for _, edge := range topology.Edges {
+		if strings.Contains(edge.From, otherEgwId) || strings.Contains(edge.To, otherEgwId) {
+			t.Fatalf("edge of another Org in topology: %+v", edge)
+		}
+	}
+	if len(topology.Edges) == 0 {
+		t.Fatalf("expected the edges of the Org Edge Gateway to be kept")
+	}
+}
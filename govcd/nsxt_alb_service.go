@@ -0,0 +1,518 @@
+/*
+ * Copyright 2025 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+package govcd
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+	"github.com/vmware/go-vcloud-director/v3/util"
+)
+
+// AlbService is a declarative specification of an NSX-T ALB load balancer service on an NSX-T Edge Gateway. It is
+// applied with VCDClient.EnsureAlbService. Objects are matched by name, so that applying the same specification
+// again updates the existing objects instead of creating new ones.
+type AlbService struct {
+	// Settings are used to enable ALB on the Edge Gateway when it is not enabled yet. They are ignored when ALB is
+	// already enabled.
+	Settings *types.NsxtAlbConfig
+
+	// ServiceEngineGroupRef references the Service Engine Group that hosts the virtual service. The ID is mandatory.
+	// The Service Engine Group is assigned to the Edge Gateway if it is not assigned yet
+	ServiceEngineGroupRef types.OpenApiReference
+	// MinVirtualServices and MaxVirtualServices are used when assigning a SHARED Service Engine Group
+	MinVirtualServices *int
+	MaxVirtualServices *int
+
+	// Pool is created or updated by name. GatewayRef is set automatically
+	Pool types.NsxtAlbPool
+
+	// Certificate is added to the certificate library of the Edge Gateway Org unless a certificate with the same
+	// alias exists already. It is used by the virtual service
+	Certificate *types.CertificateLibraryItem
+
+	// VirtualService is created or updated by name. GatewayRef, LoadBalancerPoolRef, ServiceEngineGroupRef and,
+	// when Certificate is set, CertificateRef are set automatically
+	VirtualService types.NsxtAlbVirtualService
+
+	// HttpRequestRules, HttpResponseRules and HttpSecurityRules replace the HTTP policies of the virtual service
+	// when they are not nil
+	HttpRequestRules  *types.AlbVsHttpRequestRules
+	HttpResponseRules *types.AlbVsHttpResponseRules
+	HttpSecurityRules *types.AlbVsHttpSecurityRules
+
+	// NatRule is an optional NAT rule, created or updated by name
+	NatRule *types.NsxtNatRule
+
+	// FirewallRules are optional firewall rules. Rules with the same name as an existing rule replace it in place,
+	// other rules are added at the bottom of the user defined rules
+	FirewallRules []*types.NsxtFirewallRule
+}
+
+// AlbServiceResult contains the objects created or updated by VCDClient.EnsureAlbService
+type AlbServiceResult struct {
+	ServiceEngineGroupAssignment *NsxtAlbServiceEngineGroupAssignment
+	Certificate                  *Certificate
+	Pool                         *NsxtAlbPool
+	VirtualService               *NsxtAlbVirtualService
+	NatRule                      *NsxtNatRule
+	Firewall                     *NsxtFirewall
+}
+
+// EnsureAlbService creates or updates all the objects of an ALB service on the Edge Gateway: ALB enablement,
+// Service Engine Group assignment, certificate, pool, virtual service with its HTTP policies, NAT rule and
+// firewall rules.
+//
+// When a virtual service is created, the capacity of the Service Engine Group assignment is checked first. The
+// capacity of the Service Engine Group itself is only checked for System users, as tenants cannot read it.
+//
+// If any step fails, the changes made by the previous steps are rolled back: created objects are deleted and
+// updated objects are restored to their previous configuration. Errors during rollback are added to the returned
+// error.
+func (vcdClient *VCDClient) EnsureAlbService(egw *NsxtEdgeGateway, spec *AlbService) (*AlbServiceResult, error) {
+	if egw == nil || egw.EdgeGateway == nil || egw.EdgeGateway.ID == "" {
+		return nil, fmt.Errorf("cannot ensure ALB service on Edge Gateway without ID")
+	}
+	err := spec.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	rollback := &albServiceRollback{}
+	result, err := vcdClient.ensureAlbService(egw, spec, rollback)
+	if err != nil {
+		return nil, rollback.run(err)
+	}
+	return result, nil
+}
+
+// validate checks the mandatory fields of the specification
+func (spec *AlbService) validate() error {
+	if spec == nil {
+		return fmt.Errorf("ALB service specification cannot be nil")
+	}
+	if spec.ServiceEngineGroupRef.ID == "" {
+		return fmt.Errorf("ALB service requires a Service Engine Group ID")
+	}
+	if spec.Pool.Name == "" {
+		return fmt.Errorf("ALB service requires a pool name")
+	}
+	if spec.VirtualService.Name == "" {
+		return fmt.Errorf("ALB service requires a virtual service name")
+	}
+	if spec.Certificate != nil && spec.Certificate.Alias == "" {
+		return fmt.Errorf("ALB service certificate requires an alias")
+	}
+	if spec.NatRule != nil && spec.NatRule.Name == "" {
+		return fmt.Errorf("ALB service NAT rule requires a name")
+	}
+	names := make(map[string]bool)
+	for _, rule := range spec.FirewallRules {
+		if rule.Name == "" {
+			return fmt.Errorf("ALB service firewall rules require a name")
+		}
+		if names[rule.Name] {
+			return fmt.Errorf("ALB service firewall rule name '%s' is used more than once", rule.Name)
+		}
+		names[rule.Name] = true
+	}
+	return nil
+}
+
+func (vcdClient *VCDClient) ensureAlbService(egw *NsxtEdgeGateway, spec *AlbService, rollback *albServiceRollback) (*AlbServiceResult, error) {
+	result := &AlbServiceResult{}
+	gatewayRef := types.OpenApiReference{ID: egw.EdgeGateway.ID}
+
+	settings, err := egw.GetAlbSettings()
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving ALB settings: %s", err)
+	}
+	if !settings.Enabled {
+		if spec.Settings == nil {
+			return nil, fmt.Errorf("ALB is not enabled on Edge Gateway '%s' and no settings were given", egw.EdgeGateway.Name)
+		}
+		config := *spec.Settings
+		config.Enabled = true
+		_, err = egw.UpdateAlbSettings(&config)
+		if err != nil {
+			return nil, fmt.Errorf("error enabling ALB: %s", err)
+		}
+		rollback.add("disable ALB", egw.DisableAlb)
+	}
+
+	queryParams := url.Values{}
+	queryParams.Add("filter", fmt.Sprintf("gatewayRef.id==%s", egw.EdgeGateway.ID))
+	assignments, err := vcdClient.GetAllAlbServiceEngineGroupAssignments(queryParams)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving Service Engine Group assignments: %s", err)
+	}
+	for _, assignment := range assignments {
+		serviceEngineGroupRef := assignment.NsxtAlbServiceEngineGroupAssignment.ServiceEngineGroupRef
+		if serviceEngineGroupRef != nil && serviceEngineGroupRef.ID == spec.ServiceEngineGroupRef.ID {
+			result.ServiceEngineGroupAssignment = assignment
+		}
+	}
+
+	existingVirtualService, err := vcdClient.GetAlbVirtualServiceByName(egw.EdgeGateway.ID, spec.VirtualService.Name)
+	if err != nil && !ContainsNotFound(err) {
+		return nil, fmt.Errorf("error retrieving ALB virtual service '%s': %s", spec.VirtualService.Name, err)
+	}
+	if existingVirtualService == nil {
+		var serviceEngineGroup *types.NsxtAlbServiceEngineGroup
+		if vcdClient.Client.IsSysAdmin {
+			group, err := vcdClient.GetAlbServiceEngineGroupById(spec.ServiceEngineGroupRef.ID)
+			if err != nil {
+				return nil, fmt.Errorf("error retrieving Service Engine Group: %s", err)
+			}
+			serviceEngineGroup = group.NsxtAlbServiceEngineGroup
+		}
+		var assignment *types.NsxtAlbServiceEngineGroupAssignment
+		if result.ServiceEngineGroupAssignment != nil {
+			assignment = result.ServiceEngineGroupAssignment.NsxtAlbServiceEngineGroupAssignment
+		}
+		err = validateAlbServiceEngineGroupCapacity(serviceEngineGroup, assignment)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if result.ServiceEngineGroupAssignment == nil {
+		serviceEngineGroupRef := spec.ServiceEngineGroupRef
+		assignment, err := vcdClient.CreateAlbServiceEngineGroupAssignment(&types.NsxtAlbServiceEngineGroupAssignment{
+			GatewayRef:            &gatewayRef,
+			ServiceEngineGroupRef: &serviceEngineGroupRef,
+			MinVirtualServices:    spec.MinVirtualServices,
+			MaxVirtualServices:    spec.MaxVirtualServices,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error assigning Service Engine Group: %s", err)
+		}
+		rollback.add("remove Service Engine Group assignment", assignment.Delete)
+		result.ServiceEngineGroupAssignment = assignment
+	}
+
+	if spec.Certificate != nil {
+		result.Certificate, err = ensureAlbServiceCertificate(egw, spec.Certificate, rollback)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	poolConfig := spec.Pool
+	poolConfig.GatewayRef = gatewayRef
+	existingPool, err := vcdClient.GetAlbPoolByName(egw.EdgeGateway.ID, poolConfig.Name)
+	if err != nil && !ContainsNotFound(err) {
+		return nil, fmt.Errorf("error retrieving ALB pool '%s': %s", poolConfig.Name, err)
+	}
+	if existingPool == nil {
+		result.Pool, err = vcdClient.CreateNsxtAlbPool(&poolConfig)
+		if err != nil {
+			return nil, fmt.Errorf("error creating ALB pool '%s': %s", poolConfig.Name, err)
+		}
+		rollback.add("delete ALB pool", result.Pool.Delete)
+	} else {
+		previous := *existingPool.NsxtAlbPool
+		poolConfig.ID = previous.ID
+		result.Pool, err = existingPool.Update(&poolConfig)
+		if err != nil {
+			return nil, fmt.Errorf("error updating ALB pool '%s': %s", poolConfig.Name, err)
+		}
+		pool := result.Pool
+		rollback.add("restore ALB pool", func() error {
+			_, err := pool.Update(&previous)
+			return err
+		})
+	}
+
+	virtualServiceConfig := spec.VirtualService
+	virtualServiceConfig.GatewayRef = gatewayRef
+	virtualServiceConfig.LoadBalancerPoolRef = types.OpenApiReference{ID: result.Pool.NsxtAlbPool.ID}
+	virtualServiceConfig.ServiceEngineGroupRef = spec.ServiceEngineGroupRef
+	if result.Certificate != nil {
+		virtualServiceConfig.CertificateRef = &types.OpenApiReference{ID: result.Certificate.CertificateLibrary.Id}
+	}
+	if existingVirtualService == nil {
+		result.VirtualService, err = vcdClient.CreateNsxtAlbVirtualService(&virtualServiceConfig)
+		if err != nil {
+			return nil, fmt.Errorf("error creating ALB virtual service '%s': %s", virtualServiceConfig.Name, err)
+		}
+		// The virtual service must be removed before the pool it uses, which rollback guarantees by running in
+		// reverse order
+		rollback.add("delete ALB virtual service", result.VirtualService.Delete)
+	} else {
+		previous := *existingVirtualService.NsxtAlbVirtualService
+		virtualServiceConfig.ID = previous.ID
+		result.VirtualService, err = existingVirtualService.Update(&virtualServiceConfig)
+		if err != nil {
+			return nil, fmt.Errorf("error updating ALB virtual service '%s': %s", virtualServiceConfig.Name, err)
+		}
+		virtualService := result.VirtualService
+		rollback.add("restore ALB virtual service", func() error {
+			_, err := virtualService.Update(&previous)
+			return err
+		})
+	}
+
+	err = ensureAlbServiceHttpPolicies(result.VirtualService, spec, existingVirtualService != nil, rollback)
+	if err != nil {
+		return nil, err
+	}
+
+	if spec.NatRule != nil {
+		result.NatRule, err = ensureAlbServiceNatRule(egw, spec.NatRule, rollback)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(spec.FirewallRules) > 0 {
+		previous := make(map[string]*types.NsxtFirewallRule)
+		result.Firewall, err = egw.modifyNsxtFirewallRules(func(existing []*types.NsxtFirewallRule) ([]*types.NsxtFirewallRule, error) {
+			// The rules may be read more than once when they are changed concurrently
+			previous = make(map[string]*types.NsxtFirewallRule)
+			for _, rule := range existing {
+				previous[rule.Name] = rule
+			}
+			return upsertNsxtFirewallRules(existing, spec.FirewallRules)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error setting firewall rules: %s", err)
+		}
+		rollback.add("restore firewall rules", func() error {
+			_, err := egw.modifyNsxtFirewallRules(func(existing []*types.NsxtFirewallRule) ([]*types.NsxtFirewallRule, error) {
+				return restoreNsxtFirewallRules(existing, spec.FirewallRules, previous), nil
+			})
+			return err
+		})
+	}
+
+	return result, nil
+}
+
+// ensureAlbServiceCertificate returns the certificate with the alias of certificateConfig, adding it to the
+// library of the Edge Gateway Org when it does not exist
+func ensureAlbServiceCertificate(egw *NsxtEdgeGateway, certificateConfig *types.CertificateLibraryItem, rollback *albServiceRollback) (*Certificate, error) {
+	var additionalHeader map[string]string
+	if egw.EdgeGateway.Org != nil {
+		additionalHeader = getTenantContextHeader(&TenantContext{
+			OrgId:   extractUuid(egw.EdgeGateway.Org.ID),
+			OrgName: egw.EdgeGateway.Org.Name,
+		})
+	}
+	certificate, err := getCertificateFromLibraryByName(egw.client, certificateConfig.Alias, additionalHeader)
+	if err != nil && !ContainsNotFound(err) {
+		return nil, fmt.Errorf("error retrieving certificate '%s': %s", certificateConfig.Alias, err)
+	}
+	if certificate != nil {
+		// Certificates cannot be changed once uploaded, so a different one with the same alias cannot be reconciled
+		if strings.TrimSpace(certificate.CertificateLibrary.Certificate) != strings.TrimSpace(certificateConfig.Certificate) {
+			return nil, fmt.Errorf("certificate '%s' exists with different content", certificateConfig.Alias)
+		}
+		return certificate, nil
+	}
+	certificate, err = addCertificateToLibrary(egw.client, certificateConfig, additionalHeader)
+	if err != nil {
+		return nil, fmt.Errorf("error adding certificate '%s': %s", certificateConfig.Alias, err)
+	}
+	rollback.add("delete certificate", certificate.Delete)
+	return certificate, nil
+}
+
+// ensureAlbServiceHttpPolicies sets the HTTP policies of the virtual service. Previous policies are only read, to
+// be restored on rollback, when the virtual service existed already
+func ensureAlbServiceHttpPolicies(virtualService *NsxtAlbVirtualService, spec *AlbService, existed bool, rollback *albServiceRollback) error {
+	if spec.HttpRequestRules != nil {
+		previous := &types.AlbVsHttpRequestRules{}
+		if existed {
+			rules, err := virtualService.GetAllHttpRequestRules(nil)
+			if err != nil {
+				return fmt.Errorf("error retrieving HTTP request rules: %s", err)
+			}
+			for _, rule := range rules {
+				previous.Values = append(previous.Values, *rule)
+			}
+		}
+		_, err := virtualService.UpdateHttpRequestRules(spec.HttpRequestRules)
+		if err != nil {
+			return fmt.Errorf("error setting HTTP request rules: %s", err)
+		}
+		if existed {
+			rollback.add("restore HTTP request rules", func() error {
+				_, err := virtualService.UpdateHttpRequestRules(previous)
+				return err
+			})
+		}
+	}
+
+	if spec.HttpResponseRules != nil {
+		previous := &types.AlbVsHttpResponseRules{}
+		if existed {
+			rules, err := virtualService.GetAllHttpResponseRules(nil)
+			if err != nil {
+				return fmt.Errorf("error retrieving HTTP response rules: %s", err)
+			}
+			for _, rule := range rules {
+				previous.Values = append(previous.Values, *rule)
+			}
+		}
+		_, err := virtualService.UpdateHttpResponseRules(spec.HttpResponseRules)
+		if err != nil {
+			return fmt.Errorf("error setting HTTP response rules: %s", err)
+		}
+		if existed {
+			rollback.add("restore HTTP response rules", func() error {
+				_, err := virtualService.UpdateHttpResponseRules(previous)
+				return err
+			})
+		}
+	}
+
+	if spec.HttpSecurityRules != nil {
+		previous := &types.AlbVsHttpSecurityRules{}
+		if existed {
+			rules, err := virtualService.GetAllHttpSecurityRules(nil)
+			if err != nil {
+				return fmt.Errorf("error retrieving HTTP security rules: %s", err)
+			}
+			for _, rule := range rules {
+				previous.Values = append(previous.Values, *rule)
+			}
+		}
+		_, err := virtualService.UpdateHttpSecurityRules(spec.HttpSecurityRules)
+		if err != nil {
+			return fmt.Errorf("error setting HTTP security rules: %s", err)
+		}
+		if existed {
+			rollback.add("restore HTTP security rules", func() error {
+				_, err := virtualService.UpdateHttpSecurityRules(previous)
+				return err
+			})
+		}
+	}
+	return nil
+}
+
+// ensureAlbServiceNatRule creates or updates a NAT rule by name
+func ensureAlbServiceNatRule(egw *NsxtEdgeGateway, natRuleConfig *types.NsxtNatRule, rollback *albServiceRollback) (*NsxtNatRule, error) {
+	existing, err := egw.GetNatRuleByName(natRuleConfig.Name)
+	if err != nil && !ContainsNotFound(err) {
+		return nil, fmt.Errorf("error retrieving NAT rule '%s': %s", natRuleConfig.Name, err)
+	}
+	if existing == nil {
+		natRule, err := egw.CreateNatRule(natRuleConfig)
+		if err != nil {
+			return nil, fmt.Errorf("error creating NAT rule '%s': %s", natRuleConfig.Name, err)
+		}
+		rollback.add("delete NAT rule", natRule.Delete)
+		return natRule, nil
+	}
+
+	previous := *existing.NsxtNatRule
+	config := *natRuleConfig
+	config.ID = previous.ID
+	natRule, err := existing.Update(&config)
+	if err != nil {
+		return nil, fmt.Errorf("error updating NAT rule '%s': %s", natRuleConfig.Name, err)
+	}
+	rollback.add("restore NAT rule", func() error {
+		_, err := natRule.Update(&previous)
+		return err
+	})
+	return natRule, nil
+}
+
+// validateAlbServiceEngineGroupCapacity checks that one more virtual service can be placed in the Service Engine
+// Group and its Edge Gateway assignment. Any of them can be nil when unknown
+func validateAlbServiceEngineGroupCapacity(serviceEngineGroup *types.NsxtAlbServiceEngineGroup, assignment *types.NsxtAlbServiceEngineGroupAssignment) error {
+	if serviceEngineGroup != nil && serviceEngineGroup.MaxVirtualServices != nil && serviceEngineGroup.NumDeployedVirtualServices != nil &&
+		*serviceEngineGroup.NumDeployedVirtualServices >= *serviceEngineGroup.MaxVirtualServices {
+		return fmt.Errorf("no capacity left in Service Engine Group '%s': %d of %d virtual services deployed",
+			serviceEngineGroup.Name, *serviceEngineGroup.NumDeployedVirtualServices, *serviceEngineGroup.MaxVirtualServices)
+	}
+	if assignment != nil && assignment.MaxVirtualServices != nil && assignment.NumDeployedVirtualServices >= *assignment.MaxVirtualServices {
+		name := ""
+		if assignment.ServiceEngineGroupRef != nil {
+			name = assignment.ServiceEngineGroupRef.Name
+		}
+		return fmt.Errorf("no capacity left in assignment of Service Engine Group '%s': %d of %d virtual services deployed",
+			name, assignment.NumDeployedVirtualServices, *assignment.MaxVirtualServices)
+	}
+	return nil
+}
+
+// upsertNsxtFirewallRules returns a new slice where rules replace the existing rules with the same name, keeping
+// their ID and position. Rules without a match are added at the bottom
+func upsertNsxtFirewallRules(existing, rules []*types.NsxtFirewallRule) ([]*types.NsxtFirewallRule, error) {
+	result := make([]*types.NsxtFirewallRule, len(existing), len(existing)+len(rules))
+	copy(result, existing)
+	for _, rule := range rules {
+		index, err := findNsxtFirewallRuleIndex(existing, rule.Name)
+		if err != nil && !ContainsNotFound(err) {
+			return nil, err
+		}
+		newRule := *rule
+		if index < 0 {
+			newRule.ID = ""
+			result = append(result, &newRule)
+			continue
+		}
+		newRule.ID = existing[index].ID
+		result[index] = &newRule
+	}
+	return result, nil
+}
+
+// restoreNsxtFirewallRules reverts upsertNsxtFirewallRules: the rules named in rules are restored to their previous
+// version, or removed if they did not exist before
+func restoreNsxtFirewallRules(existing, rules []*types.NsxtFirewallRule, previous map[string]*types.NsxtFirewallRule) []*types.NsxtFirewallRule {
+	upserted := make(map[string]bool)
+	for _, rule := range rules {
+		upserted[rule.Name] = true
+	}
+	var result []*types.NsxtFirewallRule
+	for _, rule := range existing {
+		if !upserted[rule.Name] {
+			result = append(result, rule)
+			continue
+		}
+		if previousRule, found := previous[rule.Name]; found {
+			result = append(result, previousRule)
+		}
+	}
+	return result
+}
+
+// albServiceRollback keeps the operations that undo the steps of EnsureAlbService
+type albServiceRollback struct {
+	steps []albServiceRollbackStep
+}
+
+type albServiceRollbackStep struct {
+	description string
+	undo        func() error
+}
+
+func (rollback *albServiceRollback) add(description string, undo func() error) {
+	rollback.steps = append(rollback.steps, albServiceRollbackStep{description: description, undo: undo})
+}
+
+// run undoes all steps in reverse order and returns cause, together with any rollback error
+func (rollback *albServiceRollback) run(cause error) error {
+	var rollbackErrors []string
+	for index := len(rollback.steps) - 1; index >= 0; index-- {
+		step := rollback.steps[index]
+		util.Logger.Printf("[DEBUG] ALB service rollback: %s", step.description)
+		err := step.undo()
+		if err != nil {
+			rollbackErrors = append(rollbackErrors, fmt.Sprintf("%s: %s", step.description, err))
+		}
+	}
+	if len(rollbackErrors) > 0 {
+		return fmt.Errorf("%s. Rollback failed: %s", cause, strings.Join(rollbackErrors, "; "))
+	}
+	return cause
+}
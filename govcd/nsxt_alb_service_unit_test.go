@@ -0,0 +1,131 @@
+//go:build unit || ALL
+
+/*
+ * Copyright 2025 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+package govcd
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// Test_AlbServiceValidate checks the mandatory fields of an ALB service specification
+func Test_AlbServiceValidate(t *testing.T) {
+	valid := AlbService{
+		ServiceEngineGroupRef: types.OpenApiReference{ID: "urn:vcloud:serviceEngineGroup:1"},
+		Pool:                  types.NsxtAlbPool{Name: "pool"},
+		VirtualService:        types.NsxtAlbVirtualService{Name: "vs"},
+	}
+	err := valid.validate()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	invalid := map[string]func(spec *AlbService){
+		"missing Service Engine Group": func(spec *AlbService) { spec.ServiceEngineGroupRef.ID = "" },
+		"missing pool name":            func(spec *AlbService) { spec.Pool.Name = "" },
+		"missing virtual service name": func(spec *AlbService) { spec.VirtualService.Name = "" },
+		"missing certificate alias":    func(spec *AlbService) { spec.Certificate = &types.CertificateLibraryItem{} },
+		"missing NAT rule name":        func(spec *AlbService) { spec.NatRule = &types.NsxtNatRule{} },
+		"duplicate firewall rule": func(spec *AlbService) {
+			spec.FirewallRules = []*types.NsxtFirewallRule{{Name: "allow"}, {Name: "allow"}}
+		},
+	}
+	for name, change := range invalid {
+		spec := valid
+		change(&spec)
+		if spec.validate() == nil {
+			t.Fatalf("expected error for %s", name)
+		}
+	}
+}
+
+// Test_validateAlbServiceEngineGroupCapacity checks the capacity of Service Engine Groups and their assignments
+func Test_validateAlbServiceEngineGroupCapacity(t *testing.T) {
+	tests := []struct {
+		name       string
+		group      *types.NsxtAlbServiceEngineGroup
+		assignment *types.NsxtAlbServiceEngineGroupAssignment
+		expectErr  bool
+	}{
+		{"unknown", nil, nil, false},
+		{"group with capacity", &types.NsxtAlbServiceEngineGroup{MaxVirtualServices: addrOf(10), NumDeployedVirtualServices: addrOf(9)}, nil, false},
+		{"full group", &types.NsxtAlbServiceEngineGroup{MaxVirtualServices: addrOf(10), NumDeployedVirtualServices: addrOf(10)}, nil, true},
+		{"unlimited assignment", nil, &types.NsxtAlbServiceEngineGroupAssignment{NumDeployedVirtualServices: 50}, false},
+		{"full assignment", nil, &types.NsxtAlbServiceEngineGroupAssignment{MaxVirtualServices: addrOf(2), NumDeployedVirtualServices: 2}, true},
+	}
+	for _, test := range tests {
+		err := validateAlbServiceEngineGroupCapacity(test.group, test.assignment)
+		if (err != nil) != test.expectErr {
+			t.Fatalf("%s: unexpected result %v", test.name, err)
+		}
+	}
+}
+
+// Test_upsertNsxtFirewallRules checks that firewall rules are replaced by name and restored on rollback
+func Test_upsertNsxtFirewallRules(t *testing.T) {
+	existing := []*types.NsxtFirewallRule{{ID: "id-a", Name: "a"}, {ID: "id-web", Name: "web"}, {ID: "id-b", Name: "b"}}
+	rules := []*types.NsxtFirewallRule{{Name: "web", ActionValue: "ALLOW"}, {Name: "web-admin", ActionValue: "DROP"}}
+
+	result, err := upsertNsxtFirewallRules(existing, rules)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(nsxtFirewallRuleNames(result), []string{"a", "web", "b", "web-admin"}) {
+		t.Fatalf("unexpected rules %v", nsxtFirewallRuleNames(result))
+	}
+	if result[1].ID != "id-web" || result[1].ActionValue != "ALLOW" || existing[1].ActionValue != "" {
+		t.Fatalf("unexpected replaced rule %+v", result[1])
+	}
+
+	previous := make(map[string]*types.NsxtFirewallRule)
+	for _, rule := range existing {
+		previous[rule.Name] = rule
+	}
+	restored := restoreNsxtFirewallRules(result, rules, previous)
+	if !reflect.DeepEqual(restored, existing) {
+		t.Fatalf("unexpected restored rules %v", nsxtFirewallRuleNames(restored))
+	}
+
+	duplicated := []*types.NsxtFirewallRule{{ID: "id-web", Name: "web"}, {ID: "id-web", Name: "web"}}
+	_, err = upsertNsxtFirewallRules(duplicated, rules)
+	if err == nil {
+		t.Fatalf("expected error for ambiguous rule name")
+	}
+}
+
+// Test_albServiceRollback checks that rollback steps run in reverse order and report their errors
+func Test_albServiceRollback(t *testing.T) {
+	var executed []string
+	rollback := &albServiceRollback{}
+	for _, step := range []string{"first", "second", "third"} {
+		step := step
+		rollback.add(step, func() error {
+			executed = append(executed, step)
+			if step == "second" {
+				return fmt.Errorf("failure")
+			}
+			return nil
+		})
+	}
+
+	cause := fmt.Errorf("error creating ALB virtual service")
+	err := rollback.run(cause)
+	if !reflect.DeepEqual(executed, []string{"third", "second", "first"}) {
+		t.Fatalf("unexpected rollback order %v", executed)
+	}
+	if err == nil || !strings.Contains(err.Error(), cause.Error()) || !strings.Contains(err.Error(), "second: failure") {
+		t.Fatalf("unexpected rollback error: %v", err)
+	}
+
+	err = (&albServiceRollback{}).run(cause)
+	if err != cause {
+		t.Fatalf("expected the original error, got %v", err)
+	}
+}
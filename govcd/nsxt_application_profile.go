@@ -246,3 +246,26 @@ func getAllNsxtAppPortProfiles(client *Client, queryParameters url.Values) ([]*N
 
 	return wrappedResponses, nil
 }
+
+// getNsxtAppPortProfileReferenceByName looks up an Application Port Profile by name. When the same name exists
+// in multiple scopes, TENANT takes precedence over PROVIDER, which takes precedence over SYSTEM.
+// contextId, when not empty, limits the search to profiles available to a VDC or VDC Group
+func getNsxtAppPortProfileReferenceByName(client *Client, name, contextId string) (*types.OpenApiReference, error) {
+	queryParameters := url.Values{}
+	queryParameters.Add("filter", "name=="+name)
+	if contextId != "" {
+		queryParameters = queryParameterFilterAnd("_context=="+contextId, queryParameters)
+	}
+	profiles, err := getAllNsxtAppPortProfiles(client, queryParameters)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving Application Port Profile '%s': %s", name, err)
+	}
+	for _, scope := range []string{types.ApplicationPortProfileScopeTenant, types.ApplicationPortProfileScopeProvider, types.ApplicationPortProfileScopeSystem} {
+		for _, profile := range profiles {
+			if profile.NsxtAppPortProfile.Scope == scope {
+				return &types.OpenApiReference{ID: profile.NsxtAppPortProfile.ID, Name: name}, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("%s: Application Port Profile '%s'", ErrorEntityNotFound, name)
+}
@@ -0,0 +1,360 @@
+/*
+ * Copyright 2025 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+package govcd
+
+import (
+	"fmt"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// Severity of a BgpDiagnosticFinding
+const (
+	// BgpDiagnosticError marks a configuration that does not work as intended
+	BgpDiagnosticError = "ERROR"
+	// BgpDiagnosticWarning marks a configuration that works but is likely a mistake
+	BgpDiagnosticWarning = "WARNING"
+)
+
+// Kinds of BgpDiagnosticFinding
+const (
+	BgpFindingBgpDisabled               = "BGP_DISABLED"
+	BgpFindingMissingPrefixList         = "MISSING_PREFIX_LIST"
+	BgpFindingUnusedPrefixList          = "UNUSED_PREFIX_LIST"
+	BgpFindingInvalidPrefixList         = "INVALID_PREFIX_LIST"
+	BgpFindingUnownedAdvertisedSubnet   = "UNOWNED_ADVERTISED_SUBNET"
+	BgpFindingAggregateAdvertisedSubnet = "AGGREGATE_ADVERTISED_SUBNET"
+	BgpFindingInvalidAdvertisedSubnet   = "INVALID_ADVERTISED_SUBNET"
+	BgpFindingAsnConflict               = "ASN_CONFLICT"
+	BgpFindingDuplicateNeighbor         = "DUPLICATE_NEIGHBOR"
+	BgpFindingGracefulRestartMismatch   = "GRACEFUL_RESTART_MISMATCH"
+	BgpFindingTimerMismatch             = "TIMER_MISMATCH"
+	BgpFindingNeighborNotEstablished    = "NEIGHBOR_NOT_ESTABLISHED"
+	BgpFindingNeighborStatusUnavailable = "NEIGHBOR_STATUS_UNAVAILABLE"
+)
+
+// BgpDiagnosticFinding is a single problem found by NsxtEdgeGateway.DiagnoseBgp
+type BgpDiagnosticFinding struct {
+	Severity string
+	Kind     string
+	// Subject is the element the finding refers to, such as a neighbor address, prefix list or subnet
+	Subject string
+	Message string
+}
+
+// BgpNeighborDiagnostic holds a BGP neighbor with its connection state. Status is nil when the state could not be
+// retrieved, in which case StatusError holds the reason.
+type BgpNeighborDiagnostic struct {
+	Neighbor    *types.EdgeBgpNeighbor
+	Status      *types.EdgeBgpNeighborStatus
+	StatusError string
+}
+
+// BgpDiagnosticReport is the result of NsxtEdgeGateway.DiagnoseBgp
+type BgpDiagnosticReport struct {
+	EdgeGatewayId      string
+	EdgeGatewayName    string
+	Config             *types.EdgeBgpConfig
+	RouteAdvertisement *types.RouteAdvertisement
+	PrefixLists        []*types.EdgeBgpIpPrefixList
+	Neighbors          []*BgpNeighborDiagnostic
+	// OwnedSubnets are the subnets of routed Org VDC networks connected to the Edge Gateway
+	OwnedSubnets []string
+	Findings     []BgpDiagnosticFinding
+}
+
+// HasErrors returns true if any of the findings has BgpDiagnosticError severity
+func (report *BgpDiagnosticReport) HasErrors() bool {
+	for _, finding := range report.Findings {
+		if finding.Severity == BgpDiagnosticError {
+			return true
+		}
+	}
+	return false
+}
+
+func (report *BgpDiagnosticReport) addFinding(severity, kind, subject, format string, args ...interface{}) {
+	report.Findings = append(report.Findings, BgpDiagnosticFinding{
+		Severity: severity,
+		Kind:     kind,
+		Subject:  subject,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// DiagnoseBgp retrieves BGP configuration, BGP neighbors, BGP IP Prefix Lists and Route Advertisement of NSX-T Edge
+// Gateway and checks that they are consistent with each other. When the API supports it, the connection state of each
+// neighbor is retrieved as well. Problems are reported as findings in the returned report; an error is only returned
+// when the configuration cannot be retrieved.
+func (egw *NsxtEdgeGateway) DiagnoseBgp() (*BgpDiagnosticReport, error) {
+	report := &BgpDiagnosticReport{
+		EdgeGatewayId:   egw.EdgeGateway.ID,
+		EdgeGatewayName: egw.EdgeGateway.Name,
+	}
+
+	bgpConfig, err := egw.GetBgpConfiguration()
+	if err != nil {
+		return nil, err
+	}
+	report.Config = bgpConfig
+
+	neighbors, err := egw.GetAllBgpNeighbors(nil)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving NSX-T Edge Gateway BGP Neighbors: %s", err)
+	}
+	prefixLists, err := egw.GetAllBgpIpPrefixLists(nil)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving NSX-T Edge Gateway BGP IP Prefix Lists: %s", err)
+	}
+	for _, prefixList := range prefixLists {
+		report.PrefixLists = append(report.PrefixLists, prefixList.EdgeBgpIpPrefixList)
+	}
+
+	// Route Advertisement is only available for Edge Gateways with a dedicated external network
+	if len(egw.EdgeGateway.EdgeGatewayUplinks) > 0 && egw.EdgeGateway.EdgeGatewayUplinks[0].Dedicated {
+		report.RouteAdvertisement, err = egw.GetNsxtRouteAdvertisement()
+		if err != nil {
+			return nil, fmt.Errorf("error retrieving NSX-T Edge Gateway Route Advertisement: %s", err)
+		}
+	}
+
+	queryParams := url.Values{}
+	queryParams.Add("filter", "connection.routerRef.id=="+egw.EdgeGateway.ID)
+	networks, err := getAllOpenApiOrgVdcNetworks(egw.client, queryParams, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving Org VDC networks connected to NSX-T Edge Gateway: %s", err)
+	}
+	for _, network := range networks {
+		report.OwnedSubnets = append(report.OwnedSubnets, orgVdcNetworkPrefixes(network.OpenApiOrgVdcNetwork)...)
+	}
+
+	for _, neighbor := range neighbors {
+		diagnostic := &BgpNeighborDiagnostic{Neighbor: neighbor.EdgeBgpNeighbor}
+		diagnostic.Status, err = neighbor.GetStatus()
+		if err != nil {
+			diagnostic.Status = nil
+			diagnostic.StatusError = err.Error()
+		}
+		report.Neighbors = append(report.Neighbors, diagnostic)
+	}
+
+	analyzeBgpConfiguration(report)
+	return report, nil
+}
+
+// analyzeBgpConfiguration fills report findings using the retrieved configuration
+func analyzeBgpConfiguration(report *BgpDiagnosticReport) {
+	config := report.Config
+	if config == nil {
+		config = &types.EdgeBgpConfig{}
+	}
+	if !config.Enabled && len(report.Neighbors) > 0 {
+		report.addFinding(BgpDiagnosticWarning, BgpFindingBgpDisabled, report.EdgeGatewayName,
+			"BGP is disabled but %d neighbor(s) are configured", len(report.Neighbors))
+	}
+
+	analyzeBgpPrefixLists(report)
+	analyzeBgpAdvertisedSubnets(report)
+
+	localAsn, localAsnErr := parseBgpAsNumber(config.LocalASNumber)
+	if config.LocalASNumber != "" && localAsnErr != nil {
+		report.addFinding(BgpDiagnosticError, BgpFindingAsnConflict, config.LocalASNumber, "invalid local AS number: %s", localAsnErr)
+	}
+	edgeGracefulRestart := ""
+	if config.GracefulRestart != nil {
+		edgeGracefulRestart = config.GracefulRestart.Mode
+		if edgeGracefulRestart != "DISABLE" && config.GracefulRestart.StaleRouteTimer > 0 &&
+			config.GracefulRestart.StaleRouteTimer < config.GracefulRestart.RestartTimer {
+			report.addFinding(BgpDiagnosticWarning, BgpFindingTimerMismatch, report.EdgeGatewayName,
+				"stale route timer %d is shorter than restart timer %d", config.GracefulRestart.StaleRouteTimer, config.GracefulRestart.RestartTimer)
+		}
+	}
+
+	seenAddresses := make(map[string]bool)
+	for _, diagnostic := range report.Neighbors {
+		neighbor := diagnostic.Neighbor
+		address := neighbor.NeighborAddress
+		if parsed, err := netip.ParseAddr(address); err == nil {
+			address = parsed.String()
+		}
+		if seenAddresses[address] {
+			report.addFinding(BgpDiagnosticError, BgpFindingDuplicateNeighbor, neighbor.NeighborAddress,
+				"neighbor address is configured more than once")
+		}
+		seenAddresses[address] = true
+
+		remoteAsn, err := parseBgpAsNumber(neighbor.RemoteASNumber)
+		switch {
+		case err != nil:
+			report.addFinding(BgpDiagnosticError, BgpFindingAsnConflict, neighbor.NeighborAddress, "invalid remote AS number: %s", err)
+		case isReservedBgpAsNumber(remoteAsn):
+			report.addFinding(BgpDiagnosticError, BgpFindingAsnConflict, neighbor.NeighborAddress,
+				"remote AS number %s is reserved", neighbor.RemoteASNumber)
+		case localAsnErr == nil && remoteAsn == localAsn && !neighbor.AllowASIn:
+			report.addFinding(BgpDiagnosticWarning, BgpFindingAsnConflict, neighbor.NeighborAddress,
+				"remote AS number %s is the same as the local AS number, creating an iBGP session", neighbor.RemoteASNumber)
+		}
+
+		// An empty mode on the neighbor means it inherits the Edge Gateway setting
+		if neighbor.GracefulRestartMode != "" && edgeGracefulRestart != "" && neighbor.GracefulRestartMode != edgeGracefulRestart {
+			severity := BgpDiagnosticWarning
+			if edgeGracefulRestart == "DISABLE" && neighbor.GracefulRestartMode == "GRACEFUL_AND_HELPER" {
+				severity = BgpDiagnosticError
+			}
+			report.addFinding(severity, BgpFindingGracefulRestartMismatch, neighbor.NeighborAddress,
+				"neighbor graceful restart mode %s differs from Edge Gateway mode %s", neighbor.GracefulRestartMode, edgeGracefulRestart)
+		}
+		if neighbor.KeepAliveTimer > 0 && neighbor.HoldDownTimer > 0 && neighbor.HoldDownTimer < 3*neighbor.KeepAliveTimer {
+			report.addFinding(BgpDiagnosticWarning, BgpFindingTimerMismatch, neighbor.NeighborAddress,
+				"hold down timer %d is less than three times the keep alive timer %d", neighbor.HoldDownTimer, neighbor.KeepAliveTimer)
+		}
+
+		switch {
+		case diagnostic.Status == nil:
+			report.addFinding(BgpDiagnosticWarning, BgpFindingNeighborStatusUnavailable, neighbor.NeighborAddress,
+				"neighbor status could not be retrieved: %s", diagnostic.StatusError)
+		case config.Enabled && diagnostic.Status.ConnectionState != "ESTABLISHED":
+			report.addFinding(BgpDiagnosticError, BgpFindingNeighborNotEstablished, neighbor.NeighborAddress,
+				"BGP session is in state %s", diagnostic.Status.ConnectionState)
+		}
+	}
+}
+
+// analyzeBgpPrefixLists checks that prefix lists referenced by neighbors exist and that prefix lists are valid
+func analyzeBgpPrefixLists(report *BgpDiagnosticReport) {
+	prefixListsById := make(map[string]*types.EdgeBgpIpPrefixList)
+	for _, prefixList := range report.PrefixLists {
+		prefixListsById[prefixList.ID] = prefixList
+		for _, prefix := range prefixList.Prefixes {
+			network, err := netip.ParsePrefix(prefix.Network)
+			if err != nil {
+				report.addFinding(BgpDiagnosticError, BgpFindingInvalidPrefixList, prefixList.Name,
+					"invalid network %s: %s", prefix.Network, err)
+				continue
+			}
+			if prefix.GreaterThanEqualTo > 0 && prefix.GreaterThanEqualTo < network.Bits() ||
+				prefix.LessThanEqualTo > 0 && prefix.LessThanEqualTo < max(network.Bits(), prefix.GreaterThanEqualTo) {
+				report.addFinding(BgpDiagnosticError, BgpFindingInvalidPrefixList, prefixList.Name,
+					"prefix length range %d-%d of %s can never match", prefix.GreaterThanEqualTo, prefix.LessThanEqualTo, prefix.Network)
+			}
+		}
+	}
+
+	used := make(map[string]bool)
+	for _, diagnostic := range report.Neighbors {
+		for _, reference := range []*types.OpenApiReference{diagnostic.Neighbor.InRoutesFilterRef, diagnostic.Neighbor.OutRoutesFilterRef} {
+			if reference == nil || reference.ID == "" {
+				continue
+			}
+			used[reference.ID] = true
+			if prefixListsById[reference.ID] == nil {
+				report.addFinding(BgpDiagnosticError, BgpFindingMissingPrefixList, diagnostic.Neighbor.NeighborAddress,
+					"neighbor references prefix list '%s' (%s) which does not exist", reference.Name, reference.ID)
+			}
+		}
+	}
+	for _, prefixList := range report.PrefixLists {
+		if !used[prefixList.ID] {
+			report.addFinding(BgpDiagnosticWarning, BgpFindingUnusedPrefixList, prefixList.Name,
+				"prefix list is not used by any neighbor")
+		}
+	}
+}
+
+// analyzeBgpAdvertisedSubnets checks that advertised subnets are within the networks routed by the Edge Gateway.
+// Aggregates, which contain routed networks without being part of one, are reported as a warning
+func analyzeBgpAdvertisedSubnets(report *BgpDiagnosticReport) {
+	if report.RouteAdvertisement == nil || !report.RouteAdvertisement.Enable {
+		return
+	}
+	var owned []netip.Prefix
+	for _, subnet := range report.OwnedSubnets {
+		prefix, err := netip.ParsePrefix(subnet)
+		if err == nil {
+			owned = append(owned, prefix.Masked())
+		}
+	}
+
+	for _, subnet := range report.RouteAdvertisement.Subnets {
+		advertised, err := netip.ParsePrefix(subnet)
+		if err != nil {
+			report.addFinding(BgpDiagnosticError, BgpFindingInvalidAdvertisedSubnet, subnet, "invalid subnet: %s", err)
+			continue
+		}
+		switch {
+		case prefixCoveredBy(advertised.Masked(), owned):
+		case prefixContainsAny(advertised.Masked(), owned):
+			report.addFinding(BgpDiagnosticWarning, BgpFindingAggregateAdvertisedSubnet, subnet,
+				"advertised subnet aggregates networks routed by the Edge Gateway, but also covers addresses that are not routed")
+		default:
+			report.addFinding(BgpDiagnosticError, BgpFindingUnownedAdvertisedSubnet, subnet,
+				"advertised subnet is not part of any network routed by the Edge Gateway")
+		}
+	}
+}
+
+// prefixCoveredBy returns true if prefix is contained in one of the given networks
+func prefixCoveredBy(prefix netip.Prefix, networks []netip.Prefix) bool {
+	for _, network := range networks {
+		if network.Bits() <= prefix.Bits() && network.Contains(prefix.Addr()) {
+			return true
+		}
+	}
+	return false
+}
+
+// prefixContainsAny returns true if prefix contains at least one of the given networks
+func prefixContainsAny(prefix netip.Prefix, networks []netip.Prefix) bool {
+	for _, network := range networks {
+		if prefix.Bits() <= network.Bits() && prefix.Contains(network.Addr()) {
+			return true
+		}
+	}
+	return false
+}
+
+// orgVdcNetworkPrefixes returns the subnets of an Org VDC network in CIDR format
+func orgVdcNetworkPrefixes(network *types.OpenApiOrgVdcNetwork) []string {
+	var prefixes []string
+	for _, subnet := range network.Subnets.Values {
+		gateway, err := netip.ParseAddr(subnet.Gateway)
+		if err != nil {
+			continue
+		}
+		prefix, err := gateway.Prefix(subnet.PrefixLength)
+		if err != nil {
+			continue
+		}
+		prefixes = append(prefixes, prefix.String())
+	}
+	return prefixes
+}
+
+// parseBgpAsNumber parses a BGP AS number in ASPLAIN ('65546') or ASDOT ('1.10') format
+func parseBgpAsNumber(asNumber string) (uint32, error) {
+	high, low, isAsDot := strings.Cut(asNumber, ".")
+	if !isAsDot {
+		value, err := strconv.ParseUint(asNumber, 10, 32)
+		if err != nil {
+			return 0, fmt.Errorf("'%s' is not a valid AS number", asNumber)
+		}
+		return uint32(value), nil
+	}
+	highValue, errHigh := strconv.ParseUint(high, 10, 16)
+	lowValue, errLow := strconv.ParseUint(low, 10, 16)
+	if errHigh != nil || errLow != nil {
+		return 0, fmt.Errorf("'%s' is not a valid ASDOT AS number", asNumber)
+	}
+	return uint32(highValue<<16 | lowValue), nil
+}
+
+// isReservedBgpAsNumber returns true for AS numbers that cannot be used by a BGP speaker (RFC 7607, RFC 6793, RFC 7300)
+func isReservedBgpAsNumber(asNumber uint32) bool {
+	return asNumber == 0 || asNumber == 23456 || asNumber == 65535 || asNumber == 4294967295
+}
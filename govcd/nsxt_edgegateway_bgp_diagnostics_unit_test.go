@@ -0,0 +1,107 @@
+//go:build unit || ALL
+
+/*
+ * Copyright 2025 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+package govcd
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+func bgpFindingKinds(report *BgpDiagnosticReport) map[string][]string {
+	kinds := make(map[string][]string)
+	for _, finding := range report.Findings {
+		kinds[finding.Kind] = append(kinds[finding.Kind], finding.Subject)
+	}
+	for kind := range kinds {
+		sort.Strings(kinds[kind])
+	}
+	return kinds
+}
+
+// Test_analyzeBgpConfiguration checks cross-reference validation of BGP configuration
+func Test_analyzeBgpConfiguration(t *testing.T) {
+	established := &types.EdgeBgpNeighborStatus{ConnectionState: "ESTABLISHED"}
+	report := &BgpDiagnosticReport{
+		EdgeGatewayName: "edge",
+		Config: &types.EdgeBgpConfig{
+			Enabled:         true,
+			LocalASNumber:   "65000",
+			GracefulRestart: &types.EdgeBgpGracefulRestartConfig{Mode: "DISABLE", RestartTimer: 180, StaleRouteTimer: 600},
+		},
+		RouteAdvertisement: &types.RouteAdvertisement{Enable: true, Subnets: []string{"10.0.1.0/24", "10.0.0.0/16", "192.168.0.0/24", "bad"}},
+		PrefixLists: []*types.EdgeBgpIpPrefixList{
+			{ID: "pl-in", Name: "in"},
+			{ID: "pl-unused", Name: "unused", Prefixes: []types.EdgeBgpConfigPrefixListPrefixes{
+				{Network: "10.0.0.0/16", Action: "PERMIT", GreaterThanEqualTo: 8},
+			}},
+		},
+		Neighbors: []*BgpNeighborDiagnostic{
+			{Neighbor: &types.EdgeBgpNeighbor{NeighborAddress: "172.16.0.1", RemoteASNumber: "65001",
+				InRoutesFilterRef: &types.OpenApiReference{ID: "pl-in"}, OutRoutesFilterRef: &types.OpenApiReference{ID: "pl-gone", Name: "gone"}},
+				Status: established},
+			{Neighbor: &types.EdgeBgpNeighbor{NeighborAddress: "172.16.0.2", RemoteASNumber: "0.65000",
+				GracefulRestartMode: "GRACEFUL_AND_HELPER", KeepAliveTimer: 60, HoldDownTimer: 90},
+				Status: &types.EdgeBgpNeighborStatus{ConnectionState: "ACTIVE"}},
+			{Neighbor: &types.EdgeBgpNeighbor{NeighborAddress: "2001:db8::1", RemoteASNumber: "23456"}, StatusError: "not supported"},
+			{Neighbor: &types.EdgeBgpNeighbor{NeighborAddress: "2001:0db8::1", RemoteASNumber: "1.x"}, Status: established},
+		},
+		OwnedSubnets: []string{"10.0.1.0/24", "10.0.2.0/24"},
+	}
+
+	analyzeBgpConfiguration(report)
+	expected := map[string][]string{
+		BgpFindingMissingPrefixList:         {"172.16.0.1"},
+		BgpFindingUnusedPrefixList:          {"unused"},
+		BgpFindingInvalidPrefixList:         {"unused"},
+		BgpFindingUnownedAdvertisedSubnet:   {"192.168.0.0/24"},
+		BgpFindingAggregateAdvertisedSubnet: {"10.0.0.0/16"},
+		BgpFindingInvalidAdvertisedSubnet:   {"bad"},
+		BgpFindingAsnConflict:               {"172.16.0.2", "2001:0db8::1", "2001:db8::1"},
+		BgpFindingDuplicateNeighbor:         {"2001:0db8::1"},
+		BgpFindingGracefulRestartMismatch:   {"172.16.0.2"},
+		BgpFindingTimerMismatch:             {"172.16.0.2"},
+		BgpFindingNeighborNotEstablished:    {"172.16.0.2"},
+		BgpFindingNeighborStatusUnavailable: {"2001:db8::1"},
+	}
+	if kinds := bgpFindingKinds(report); !reflect.DeepEqual(kinds, expected) {
+		t.Fatalf("unexpected findings:\n%v\nexpected:\n%v", kinds, expected)
+	}
+	if !report.HasErrors() {
+		t.Fatalf("expected report to have errors")
+	}
+
+	clean := &BgpDiagnosticReport{
+		Config: &types.EdgeBgpConfig{Enabled: true, LocalASNumber: "65000"},
+		Neighbors: []*BgpNeighborDiagnostic{
+			{Neighbor: &types.EdgeBgpNeighbor{NeighborAddress: "172.16.0.1", RemoteASNumber: "65001"}, Status: established},
+		},
+	}
+	analyzeBgpConfiguration(clean)
+	if len(clean.Findings) != 0 || clean.HasErrors() {
+		t.Fatalf("unexpected findings: %+v", clean.Findings)
+	}
+}
+
+// Test_parseBgpAsNumber checks ASPLAIN and ASDOT AS number parsing
+func Test_parseBgpAsNumber(t *testing.T) {
+	tests := map[string]uint32{"65000": 65000, "1.10": 65546, "4294967294": 4294967294, "0.65000": 65000}
+	for input, expected := range tests {
+		value, err := parseBgpAsNumber(input)
+		if err != nil || value != expected {
+			t.Fatalf("%s: expected %d, got %d (error %v)", input, expected, value, err)
+		}
+	}
+	for _, input := range []string{"", "-1", "4294967296", "65536.1", "1.", "AS65000"} {
+		_, err := parseBgpAsNumber(input)
+		if err == nil {
+			t.Fatalf("%s: expected error", input)
+		}
+	}
+}
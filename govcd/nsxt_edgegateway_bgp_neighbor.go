@@ -237,3 +237,32 @@ func (bgpNeighbor *EdgeBgpNeighbor) Delete() error {
 
 	return nil
 }
+
+// GetStatus retrieves the connection state of BGP Neighbor. It uses the OpenAPI endpoint
+// GET /1.0.0/edgeGateways/{gatewayId}/routing/bgp/neighbors/{neighborId}/status, available since API version 36.0
+// (VCD 10.3)
+func (bgpNeighbor *EdgeBgpNeighbor) GetStatus() (*types.EdgeBgpNeighborStatus, error) {
+	client := bgpNeighbor.client
+	endpoint := types.OpenApiPathVersion1_0_0 + types.OpenApiEndpointEdgeBgpNeighborStatus
+	apiVersion, err := client.getOpenApiHighestElevatedVersion(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	if bgpNeighbor.EdgeBgpNeighbor.ID == "" {
+		return nil, fmt.Errorf("cannot get NSX-T Edge Gateway BGP Neighbor status without ID")
+	}
+
+	urlRef, err := client.OpenApiBuildEndpoint(fmt.Sprintf(endpoint, bgpNeighbor.edgeGatewayId, bgpNeighbor.EdgeBgpNeighbor.ID))
+	if err != nil {
+		return nil, err
+	}
+
+	bgpNeighborStatus := &types.EdgeBgpNeighborStatus{}
+	err = client.OpenApiGetItem(apiVersion, urlRef, nil, bgpNeighborStatus, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error getting NSX-T Edge Gateway BGP Neighbor status: %s", err)
+	}
+
+	return bgpNeighborStatus, nil
+}
@@ -18,11 +18,19 @@ type NsxtFirewall struct {
 	client                    *Client
 	// edgeGatewayId is stored for usage in NsxtFirewall receiver functions
 	edgeGatewayId string
+	// Etag is populated by NsxtEdgeGateway.GetNsxtFirewall and allows to detect concurrent changes
+	Etag string
 }
 
 // UpdateNsxtFirewall allows user to set new firewall rules or update existing ones. The API does not have POST endpoint
 // and always uses PUT endpoint for creating and updating.
 func (egw *NsxtEdgeGateway) UpdateNsxtFirewall(firewallRules *types.NsxtFirewallRuleContainer) (*NsxtFirewall, error) {
+	return egw.updateNsxtFirewall(firewallRules, "")
+}
+
+// updateNsxtFirewall sets firewall rules. When etag is not empty, the update is only performed if the rules were not
+// changed since they were retrieved
+func (egw *NsxtEdgeGateway) updateNsxtFirewall(firewallRules *types.NsxtFirewallRuleContainer, etag string) (*NsxtFirewall, error) {
 	client := egw.client
 	endpoint := types.OpenApiPathVersion1_0_0 + types.OpenApiEndpointNsxtFirewallRules
 	minimumApiVersion, err := client.checkOpenApiEndpointCompatibility(endpoint)
@@ -42,6 +50,20 @@ func (egw *NsxtEdgeGateway) UpdateNsxtFirewall(firewallRules *types.NsxtFirewall
 		edgeGatewayId:             egw.EdgeGateway.ID,
 	}
 
+	if etag != "" {
+		// The If-Match header must only be sent with the PUT request, as the rules retrieved after the task
+		// completes already have a new Etag
+		task, err := client.OpenApiPutItemAsync(minimumApiVersion, urlRef, nil, firewallRules, map[string]string{"If-Match": etag})
+		if err != nil {
+			return nil, fmt.Errorf("error setting NSX-T Firewall: %w", err)
+		}
+		err = task.WaitTaskCompletion()
+		if err != nil {
+			return nil, fmt.Errorf("error setting NSX-T Firewall: %s", err)
+		}
+		return egw.GetNsxtFirewall()
+	}
+
 	err = client.OpenApiPutItem(minimumApiVersion, urlRef, nil, firewallRules, returnObject.NsxtFirewallRuleContainer, nil)
 	if err != nil {
 		return nil, fmt.Errorf("error setting NSX-T Firewall: %s", err)
@@ -71,10 +93,11 @@ func (egw *NsxtEdgeGateway) GetNsxtFirewall() (*NsxtFirewall, error) {
 		edgeGatewayId:             egw.EdgeGateway.ID,
 	}
 
-	err = client.OpenApiGetItem(minimumApiVersion, urlRef, nil, returnObject.NsxtFirewallRuleContainer, nil)
+	headers, err := client.OpenApiGetItemAndHeaders(minimumApiVersion, urlRef, nil, returnObject.NsxtFirewallRuleContainer, nil)
 	if err != nil {
 		return nil, fmt.Errorf("error retrieving NSX-T Firewall rules: %s", err)
 	}
+	returnObject.Etag = headers.Get("Etag")
 
 	// Store Edge Gateway ID for later operations
 	returnObject.edgeGatewayId = egw.EdgeGateway.ID
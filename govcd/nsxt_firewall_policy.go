@@ -0,0 +1,982 @@
+/*
+ * Copyright 2025 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+package govcd
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// FirewallPolicyDocumentVersion is the version of the FirewallPolicyDocument format
+const FirewallPolicyDocumentVersion = "1"
+
+// Origins of a FirewallPolicyDocument
+const (
+	FirewallPolicyKindEdgeGateway = "edgeGateway"
+	FirewallPolicyKindDistributed = "distributed"
+)
+
+// Kinds of objects reported in FirewallPolicyChange
+const (
+	FirewallPolicyChangeIpSet          = "ipSet"
+	FirewallPolicyChangeSecurityGroup  = "securityGroup"
+	FirewallPolicyChangeAppPortProfile = "appPortProfile"
+	FirewallPolicyChangeRule           = "rule"
+)
+
+// Actions reported in FirewallPolicyChange
+const (
+	FirewallPolicyActionCreate = "create"
+	FirewallPolicyActionUpdate = "update"
+	FirewallPolicyActionDelete = "delete"
+	FirewallPolicyActionMove   = "move"
+)
+
+// FirewallPolicyDocument is a portable description of NSX-T Edge Gateway firewall or Distributed Firewall rules.
+// Firewall groups, Application Port Profiles and Network Context Profiles are referenced by name, so that the same
+// document can be imported into a different Edge Gateway or VDC Group.
+type FirewallPolicyDocument struct {
+	Version string `json:"version"`
+	// Kind is the origin of the document. One of FirewallPolicyKindEdgeGateway or FirewallPolicyKindDistributed
+	Kind           string                        `json:"kind"`
+	IpSets         []FirewallPolicyIpSet         `json:"ipSets,omitempty"`
+	SecurityGroups []FirewallPolicySecurityGroup `json:"securityGroups,omitempty"`
+	// AppPortProfiles contains the definition of TENANT scoped profiles used by the rules. PROVIDER and SYSTEM
+	// profiles are only referenced by name
+	AppPortProfiles []FirewallPolicyAppPortProfile `json:"appPortProfiles,omitempty"`
+	// Rules are evaluated in order
+	Rules []FirewallPolicyRule `json:"rules"`
+}
+
+// FirewallPolicyIpSet is an IP Set used by firewall rules
+type FirewallPolicyIpSet struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	IpAddresses []string `json:"ipAddresses,omitempty"`
+}
+
+// FirewallPolicySecurityGroup is a static Security Group, with Org VDC networks as members, or a dynamic Security
+// Group, with VM criteria
+type FirewallPolicySecurityGroup struct {
+	Name        string                              `json:"name"`
+	Description string                              `json:"description,omitempty"`
+	Networks    []string                            `json:"networks,omitempty"`
+	VmCriteria  []types.NsxtFirewallGroupVmCriteria `json:"vmCriteria,omitempty"`
+}
+
+// FirewallPolicyAppPortProfile is a TENANT scoped Application Port Profile
+type FirewallPolicyAppPortProfile struct {
+	Name        string                         `json:"name"`
+	Description string                         `json:"description,omitempty"`
+	Ports       []types.NsxtAppPortProfilePort `json:"ports"`
+}
+
+// FirewallPolicyRule is a firewall rule with name based references. Empty lists mean 'Any'
+type FirewallPolicyRule struct {
+	Name                    string   `json:"name"`
+	Action                  string   `json:"action"`
+	Enabled                 bool     `json:"enabled"`
+	Direction               string   `json:"direction"`
+	IpProtocol              string   `json:"ipProtocol"`
+	Logging                 bool     `json:"logging,omitempty"`
+	Sources                 []string `json:"sources,omitempty"`
+	Destinations            []string `json:"destinations,omitempty"`
+	SourcesExcluded         bool     `json:"sourcesExcluded,omitempty"`
+	DestinationsExcluded    bool     `json:"destinationsExcluded,omitempty"`
+	ApplicationPortProfiles []string `json:"applicationPortProfiles,omitempty"`
+	// The fields below are only supported by the Distributed Firewall
+	NetworkContextProfiles []string `json:"networkContextProfiles,omitempty"`
+	Description            string   `json:"description,omitempty"`
+	Comments               string   `json:"comments,omitempty"`
+}
+
+// FirewallPolicyChange is a single change made, or to be made, by a firewall policy import
+type FirewallPolicyChange struct {
+	Kind   string
+	Name   string
+	Action string
+	// Fields contains the names of the changed fields for updates
+	Fields []string
+}
+
+// ToYaml serializes the document to YAML
+func (doc *FirewallPolicyDocument) ToYaml() ([]byte, error) {
+	return yaml.Marshal(doc)
+}
+
+// ToJson serializes the document to indented JSON
+func (doc *FirewallPolicyDocument) ToJson() ([]byte, error) {
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// ParseFirewallPolicyDocument reads a FirewallPolicyDocument from YAML or JSON content
+func ParseFirewallPolicyDocument(content []byte) (*FirewallPolicyDocument, error) {
+	doc := &FirewallPolicyDocument{}
+	err := yaml.UnmarshalStrict(content, doc)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing firewall policy document: %s", err)
+	}
+	if doc.Version != FirewallPolicyDocumentVersion {
+		return nil, fmt.Errorf("unsupported firewall policy document version '%s'", doc.Version)
+	}
+	return doc, nil
+}
+
+// ExportFirewallPolicy exports the user defined firewall rules of the Edge Gateway, together with its firewall
+// groups and the TENANT scoped Application Port Profiles used by the rules
+func (egw *NsxtEdgeGateway) ExportFirewallPolicy() (*FirewallPolicyDocument, error) {
+	return egw.firewallPolicyTarget().export()
+}
+
+// PreviewFirewallPolicyImport returns the changes that ImportFirewallPolicy would make to the Edge Gateway
+func (egw *NsxtEdgeGateway) PreviewFirewallPolicyImport(doc *FirewallPolicyDocument) ([]FirewallPolicyChange, error) {
+	changes, _, err := egw.firewallPolicyTarget().preview(doc)
+	return changes, err
+}
+
+// ImportFirewallPolicy imports a firewall policy into the Edge Gateway. Missing IP Sets, Security Groups and TENANT
+// scoped Application Port Profiles are created and the ones that differ are updated. All firewall rules are then
+// replaced in a single update. Firewall groups and profiles are never deleted, as they may be in use elsewhere.
+// Names used by the rules are checked before anything is changed, and the rules are only replaced if nobody changed
+// them since they were read. It returns the changes that were made.
+func (egw *NsxtEdgeGateway) ImportFirewallPolicy(doc *FirewallPolicyDocument) ([]FirewallPolicyChange, error) {
+	return egw.firewallPolicyTarget().importPolicy(doc)
+}
+
+// ExportDistributedFirewallPolicy exports the Distributed Firewall rules of the VDC Group, together with its
+// firewall groups and the TENANT scoped Application Port Profiles used by the rules
+func (vdcGroup *VdcGroup) ExportDistributedFirewallPolicy() (*FirewallPolicyDocument, error) {
+	return vdcGroup.firewallPolicyTarget().export()
+}
+
+// PreviewDistributedFirewallPolicyImport returns the changes that ImportDistributedFirewallPolicy would make to the
+// VDC Group
+func (vdcGroup *VdcGroup) PreviewDistributedFirewallPolicyImport(doc *FirewallPolicyDocument) ([]FirewallPolicyChange, error) {
+	changes, _, err := vdcGroup.firewallPolicyTarget().preview(doc)
+	return changes, err
+}
+
+// ImportDistributedFirewallPolicy imports a firewall policy into the Distributed Firewall of the VDC Group. It
+// works like NsxtEdgeGateway.ImportFirewallPolicy
+func (vdcGroup *VdcGroup) ImportDistributedFirewallPolicy(doc *FirewallPolicyDocument) ([]FirewallPolicyChange, error) {
+	return vdcGroup.firewallPolicyTarget().importPolicy(doc)
+}
+
+// firewallPolicyTarget wraps an Edge Gateway or a VDC Group for firewall policy import and export
+type firewallPolicyTarget struct {
+	client *Client
+	kind   string
+	// ownerId is the ID of the Edge Gateway or VDC Group that owns the firewall groups
+	ownerId string
+	// contextId is the ID of the VDC or VDC Group used to look up networks and profiles
+	contextId string
+	orgId     string
+	egw       *NsxtEdgeGateway
+	vdcGroup  *VdcGroup
+}
+
+// firewallPolicyRuleState holds the current rules, in API format, indexed by their key in a document
+type firewallPolicyRuleState struct {
+	edgeRules        map[string]*types.NsxtFirewallRule
+	distributedRules map[string]*types.DistributedFirewallRule
+	// edgeEtag is the Etag of the Edge Gateway firewall rules, used to reject the update when the rules were
+	// changed by someone else after they were read
+	edgeEtag string
+}
+
+func (egw *NsxtEdgeGateway) firewallPolicyTarget() *firewallPolicyTarget {
+	target := &firewallPolicyTarget{
+		client:  egw.client,
+		kind:    FirewallPolicyKindEdgeGateway,
+		ownerId: egw.EdgeGateway.ID,
+		egw:     egw,
+	}
+	if egw.EdgeGateway.OwnerRef != nil {
+		target.contextId = egw.EdgeGateway.OwnerRef.ID
+	}
+	if egw.EdgeGateway.Org != nil {
+		target.orgId = egw.EdgeGateway.Org.ID
+	}
+	return target
+}
+
+func (vdcGroup *VdcGroup) firewallPolicyTarget() *firewallPolicyTarget {
+	return &firewallPolicyTarget{
+		client:    vdcGroup.client,
+		kind:      FirewallPolicyKindDistributed,
+		ownerId:   vdcGroup.VdcGroup.Id,
+		contextId: vdcGroup.VdcGroup.Id,
+		orgId:     vdcGroup.VdcGroup.OrgId,
+		vdcGroup:  vdcGroup,
+	}
+}
+
+// firewallGroups retrieves the firewall groups owned by the target
+func (target *firewallPolicyTarget) firewallGroups() ([]*NsxtFirewallGroup, error) {
+	if target.egw != nil {
+		return target.egw.GetAllNsxtFirewallGroups(nil, "")
+	}
+	queryParameters := queryParameterFilterAnd("ownerRef.id=="+target.ownerId, nil)
+	return getAllNsxtFirewallGroups(target.client, queryParameters)
+}
+
+// export builds a document from the current state of the target
+func (target *firewallPolicyTarget) export() (*FirewallPolicyDocument, error) {
+	doc := &FirewallPolicyDocument{Version: FirewallPolicyDocumentVersion, Kind: target.kind}
+	groups, err := target.firewallGroups()
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving firewall groups: %s", err)
+	}
+	for _, group := range groups {
+		ipSet, securityGroup := firewallPolicyGroupFromFirewallGroup(group)
+		if ipSet != nil {
+			doc.IpSets = append(doc.IpSets, *ipSet)
+		}
+		if securityGroup != nil {
+			doc.SecurityGroups = append(doc.SecurityGroups, *securityGroup)
+		}
+	}
+
+	doc.Rules, _, err = target.rules()
+	if err != nil {
+		return nil, err
+	}
+
+	exportedProfiles := make(map[string]bool)
+	for _, rule := range doc.Rules {
+		for _, profileName := range rule.ApplicationPortProfiles {
+			if exportedProfiles[profileName] {
+				continue
+			}
+			exportedProfiles[profileName] = true
+			profile, err := target.tenantAppPortProfile(profileName)
+			if err != nil {
+				return nil, err
+			}
+			if profile != nil {
+				doc.AppPortProfiles = append(doc.AppPortProfiles, firewallPolicyAppPortProfileFromProfile(profile.NsxtAppPortProfile))
+			}
+		}
+	}
+	doc.sort()
+	return doc, nil
+}
+
+// rules retrieves the current rules of the target in document format
+func (target *firewallPolicyTarget) rules() ([]FirewallPolicyRule, *firewallPolicyRuleState, error) {
+	state := &firewallPolicyRuleState{}
+	var rules []FirewallPolicyRule
+	keys := firewallPolicyRuleKeys{}
+	if target.egw != nil {
+		firewall, err := target.egw.GetNsxtFirewall()
+		if err != nil {
+			return nil, nil, fmt.Errorf("error retrieving firewall rules: %s", err)
+		}
+		state.edgeRules = make(map[string]*types.NsxtFirewallRule)
+		state.edgeEtag = firewall.Etag
+		for _, rule := range firewall.NsxtFirewallRuleContainer.UserDefinedRules {
+			state.edgeRules[keys.next(rule.Name)] = rule
+			rules = append(rules, firewallPolicyRuleFromEdgeRule(rule))
+		}
+		return rules, state, nil
+	}
+
+	firewall, err := target.vdcGroup.GetDistributedFirewall()
+	if err != nil {
+		return nil, nil, fmt.Errorf("error retrieving distributed firewall rules: %s", err)
+	}
+	state.distributedRules = make(map[string]*types.DistributedFirewallRule)
+	for _, rule := range firewall.DistributedFirewallRuleContainer.Values {
+		state.distributedRules[keys.next(rule.Name)] = rule
+		rules = append(rules, firewallPolicyRuleFromDistributedRule(rule))
+	}
+	return rules, state, nil
+}
+
+// tenantAppPortProfile returns the TENANT scoped Application Port Profile with the given name available to the
+// target, or nil if it does not exist
+func (target *firewallPolicyTarget) tenantAppPortProfile(name string) (*NsxtAppPortProfile, error) {
+	queryParameters := queryParameterFilterAnd("name=="+name, nil)
+	queryParameters = queryParameterFilterAnd("scope=="+types.ApplicationPortProfileScopeTenant, queryParameters)
+	if target.contextId != "" {
+		queryParameters = queryParameterFilterAnd("_context=="+target.contextId, queryParameters)
+	}
+	profiles, err := getAllNsxtAppPortProfiles(target.client, queryParameters)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving Application Port Profile '%s': %s", name, err)
+	}
+	if len(profiles) == 0 {
+		return nil, nil
+	}
+	return profiles[0], nil
+}
+
+// current builds a document describing the state of the objects that the desired document refers to
+func (target *firewallPolicyTarget) current(desired *FirewallPolicyDocument) (*FirewallPolicyDocument, *firewallPolicyRuleState, error) {
+	current := &FirewallPolicyDocument{Version: FirewallPolicyDocumentVersion, Kind: target.kind}
+	groups, err := target.firewallGroups()
+	if err != nil {
+		return nil, nil, fmt.Errorf("error retrieving firewall groups: %s", err)
+	}
+	for _, group := range groups {
+		ipSet, securityGroup := firewallPolicyGroupFromFirewallGroup(group)
+		if ipSet != nil {
+			current.IpSets = append(current.IpSets, *ipSet)
+		}
+		if securityGroup != nil {
+			current.SecurityGroups = append(current.SecurityGroups, *securityGroup)
+		}
+	}
+	for _, desiredProfile := range desired.AppPortProfiles {
+		profile, err := target.tenantAppPortProfile(desiredProfile.Name)
+		if err != nil {
+			return nil, nil, err
+		}
+		if profile != nil {
+			current.AppPortProfiles = append(current.AppPortProfiles, firewallPolicyAppPortProfileFromProfile(profile.NsxtAppPortProfile))
+		}
+	}
+	var state *firewallPolicyRuleState
+	current.Rules, state, err = target.rules()
+	if err != nil {
+		return nil, nil, err
+	}
+	return current, state, nil
+}
+
+// preview validates the desired document and computes the changes needed to apply it
+func (target *firewallPolicyTarget) preview(desired *FirewallPolicyDocument) ([]FirewallPolicyChange, *firewallPolicyRuleState, error) {
+	if desired == nil {
+		return nil, nil, fmt.Errorf("firewall policy document is nil")
+	}
+	if desired.Version != FirewallPolicyDocumentVersion {
+		return nil, nil, fmt.Errorf("unsupported firewall policy document version '%s'", desired.Version)
+	}
+	if target.kind == FirewallPolicyKindEdgeGateway {
+		for _, rule := range desired.Rules {
+			if len(rule.NetworkContextProfiles) > 0 || rule.SourcesExcluded || rule.DestinationsExcluded {
+				return nil, nil, fmt.Errorf("rule '%s' uses features that are only available in the Distributed Firewall", rule.Name)
+			}
+		}
+	}
+	current, state, err := target.current(desired)
+	if err != nil {
+		return nil, nil, err
+	}
+	return diffFirewallPolicy(current, desired), state, nil
+}
+
+// importPolicy applies the desired document: dependencies first, then all rules in a single update. All references
+// used by the rules are validated before anything is changed. Rule updates are rejected when the rules were changed
+// by someone else since they were read.
+func (target *firewallPolicyTarget) importPolicy(desired *FirewallPolicyDocument) ([]FirewallPolicyChange, error) {
+	changes, state, err := target.preview(desired)
+	if err != nil {
+		return nil, err
+	}
+
+	resolver, err := target.newReferenceResolver()
+	if err != nil {
+		return nil, err
+	}
+	err = resolver.validate(desired, changes)
+	if err != nil {
+		return nil, err
+	}
+
+	rulesChanged := false
+	groupsChanged := false
+	for _, change := range changes {
+		switch change.Kind {
+		case FirewallPolicyChangeIpSet, FirewallPolicyChangeSecurityGroup:
+			err = target.applyFirewallGroup(change, desired)
+			groupsChanged = true
+		case FirewallPolicyChangeAppPortProfile:
+			err = target.applyAppPortProfile(change, desired)
+		case FirewallPolicyChangeRule:
+			rulesChanged = true
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if !rulesChanged {
+		return changes, nil
+	}
+	if groupsChanged {
+		err = resolver.loadGroups()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	keys := firewallPolicyRuleKeys{}
+	if target.egw != nil {
+		container := &types.NsxtFirewallRuleContainer{}
+		for _, rule := range desired.Rules {
+			apiRule, err := resolver.edgeRule(rule)
+			if err != nil {
+				return nil, err
+			}
+			if currentRule, found := state.edgeRules[keys.next(rule.Name)]; found {
+				apiRule.ID = currentRule.ID
+				apiRule.Version = currentRule.Version
+			}
+			container.UserDefinedRules = append(container.UserDefinedRules, apiRule)
+		}
+		_, err = target.egw.updateNsxtFirewall(container, state.edgeEtag)
+		if err != nil {
+			return nil, err
+		}
+		return changes, nil
+	}
+
+	dfwRules := &types.DistributedFirewallRules{}
+	for _, rule := range desired.Rules {
+		apiRule, err := resolver.distributedRule(rule)
+		if err != nil {
+			return nil, err
+		}
+		if currentRule, found := state.distributedRules[keys.next(rule.Name)]; found {
+			apiRule.ID = currentRule.ID
+			apiRule.Version = currentRule.Version
+		}
+		dfwRules.Values = append(dfwRules.Values, apiRule)
+	}
+	_, err = target.vdcGroup.UpdateDistributedFirewall(dfwRules)
+	if err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// applyFirewallGroup creates or updates an IP Set or a Security Group
+func (target *firewallPolicyTarget) applyFirewallGroup(change FirewallPolicyChange, desired *FirewallPolicyDocument) error {
+	config := &types.NsxtFirewallGroup{
+		Name:     change.Name,
+		OwnerRef: &types.OpenApiReference{ID: target.ownerId},
+	}
+	if change.Kind == FirewallPolicyChangeIpSet {
+		for _, ipSet := range desired.IpSets {
+			if ipSet.Name == change.Name {
+				config.Description = ipSet.Description
+				config.IpAddresses = ipSet.IpAddresses
+			}
+		}
+		config.TypeValue = types.FirewallGroupTypeIpSet
+	} else {
+		for _, securityGroup := range desired.SecurityGroups {
+			if securityGroup.Name != change.Name {
+				continue
+			}
+			config.Description = securityGroup.Description
+			config.VmCriteria = securityGroup.VmCriteria
+			for _, networkName := range securityGroup.Networks {
+				network, err := target.network(networkName)
+				if err != nil {
+					return err
+				}
+				config.Members = append(config.Members, types.OpenApiReference{ID: network.OpenApiOrgVdcNetwork.ID, Name: networkName})
+			}
+		}
+		config.TypeValue = types.FirewallGroupTypeSecurityGroup
+		if len(config.VmCriteria) > 0 {
+			config.TypeValue = types.FirewallGroupTypeVmCriteria
+		}
+	}
+
+	if change.Action == FirewallPolicyActionCreate {
+		_, err := createNsxtFirewallGroup(target.client, config)
+		if err != nil {
+			return fmt.Errorf("error creating firewall group '%s': %s", change.Name, err)
+		}
+		return nil
+	}
+
+	groups, err := target.firewallGroups()
+	if err != nil {
+		return fmt.Errorf("error retrieving firewall groups: %s", err)
+	}
+	for _, group := range groups {
+		if group.NsxtFirewallGroup.Name != change.Name || group.IsIpSet() != (change.Kind == FirewallPolicyChangeIpSet) {
+			continue
+		}
+		config.ID = group.NsxtFirewallGroup.ID
+		config.OwnerRef = group.NsxtFirewallGroup.OwnerRef
+		_, err = group.Update(config)
+		if err != nil {
+			return fmt.Errorf("error updating firewall group '%s': %s", change.Name, err)
+		}
+		return nil
+	}
+	return fmt.Errorf("%s: firewall group '%s'", ErrorEntityNotFound, change.Name)
+}
+
+// applyAppPortProfile creates or updates a TENANT scoped Application Port Profile
+func (target *firewallPolicyTarget) applyAppPortProfile(change FirewallPolicyChange, desired *FirewallPolicyDocument) error {
+	config := &types.NsxtAppPortProfile{
+		Name:            change.Name,
+		OrgRef:          &types.OpenApiReference{ID: target.orgId},
+		ContextEntityId: target.contextId,
+		Scope:           types.ApplicationPortProfileScopeTenant,
+	}
+	for _, profile := range desired.AppPortProfiles {
+		if profile.Name == change.Name {
+			config.Description = profile.Description
+			config.ApplicationPorts = profile.Ports
+		}
+	}
+
+	if change.Action == FirewallPolicyActionCreate {
+		_, err := NewOrg(target.client).CreateNsxtAppPortProfile(config)
+		return err
+	}
+	profile, err := target.tenantAppPortProfile(change.Name)
+	if err != nil {
+		return err
+	}
+	if profile == nil {
+		return fmt.Errorf("%s: Application Port Profile '%s'", ErrorEntityNotFound, change.Name)
+	}
+	config.ID = profile.NsxtAppPortProfile.ID
+	_, err = profile.Update(config)
+	return err
+}
+
+// network looks up an Org VDC network by name in the context of the target
+func (target *firewallPolicyTarget) network(name string) (*OpenApiOrgVdcNetwork, error) {
+	queryParameters := queryParameterFilterAnd("name=="+name, nil)
+	if target.contextId != "" {
+		queryParameters = queryParameterFilterAnd("ownerRef.id=="+target.contextId, queryParameters)
+	}
+	networks, err := getAllOpenApiOrgVdcNetworks(target.client, queryParameters, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving Org VDC network '%s': %s", name, err)
+	}
+	return returnSingleOpenApiOrgVdcNetwork(name, networks)
+}
+
+// firewallPolicyReferenceResolver converts names into references, caching lookups
+type firewallPolicyReferenceResolver struct {
+	target   *firewallPolicyTarget
+	groups   map[string]string
+	profiles map[string]*types.OpenApiReference
+	contexts map[string]*types.OpenApiReference
+}
+
+func (target *firewallPolicyTarget) newReferenceResolver() (*firewallPolicyReferenceResolver, error) {
+	resolver := &firewallPolicyReferenceResolver{
+		target:   target,
+		profiles: make(map[string]*types.OpenApiReference),
+		contexts: make(map[string]*types.OpenApiReference),
+	}
+	err := resolver.loadGroups()
+	if err != nil {
+		return nil, err
+	}
+	return resolver, nil
+}
+
+// loadGroups reads the IDs of the firewall groups owned by the target
+func (resolver *firewallPolicyReferenceResolver) loadGroups() error {
+	groups, err := resolver.target.firewallGroups()
+	if err != nil {
+		return fmt.Errorf("error retrieving firewall groups: %s", err)
+	}
+	resolver.groups = make(map[string]string)
+	for _, group := range groups {
+		resolver.groups[group.NsxtFirewallGroup.Name] = group.NsxtFirewallGroup.ID
+	}
+	return nil
+}
+
+// validate checks that every name used by the desired rules and Security Groups refers to an existing object, or to
+// one that the document creates. Profiles and networks found here are cached for the import
+func (resolver *firewallPolicyReferenceResolver) validate(desired *FirewallPolicyDocument, changes []FirewallPolicyChange) error {
+	plannedGroups := make(map[string]bool)
+	for _, ipSet := range desired.IpSets {
+		plannedGroups[ipSet.Name] = true
+	}
+	for _, securityGroup := range desired.SecurityGroups {
+		plannedGroups[securityGroup.Name] = true
+	}
+	plannedProfiles := make(map[string]bool)
+	for _, profile := range desired.AppPortProfiles {
+		plannedProfiles[profile.Name] = true
+	}
+
+	var problems []string
+	for _, rule := range desired.Rules {
+		for _, name := range append(append([]string{}, rule.Sources...), rule.Destinations...) {
+			if _, found := resolver.groups[name]; !found && !plannedGroups[name] {
+				problems = append(problems, fmt.Sprintf("rule '%s': %s: firewall group '%s'", rule.Name, ErrorEntityNotFound, name))
+			}
+		}
+		for _, name := range rule.ApplicationPortProfiles {
+			if plannedProfiles[name] {
+				continue
+			}
+			_, err := resolver.profileReferences([]string{name})
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("rule '%s': %s", rule.Name, err))
+			}
+		}
+		_, err := resolver.contextProfileReferences(rule.NetworkContextProfiles)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("rule '%s': %s", rule.Name, err))
+		}
+	}
+
+	for _, change := range changes {
+		if change.Kind != FirewallPolicyChangeSecurityGroup {
+			continue
+		}
+		for _, securityGroup := range desired.SecurityGroups {
+			if securityGroup.Name != change.Name {
+				continue
+			}
+			for _, networkName := range securityGroup.Networks {
+				_, err := resolver.target.network(networkName)
+				if err != nil {
+					problems = append(problems, fmt.Sprintf("security group '%s': %s", securityGroup.Name, err))
+				}
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("firewall policy references objects that do not exist: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+func (resolver *firewallPolicyReferenceResolver) groupReferences(names []string) ([]types.OpenApiReference, error) {
+	var references []types.OpenApiReference
+	for _, name := range names {
+		id, found := resolver.groups[name]
+		if !found {
+			return nil, fmt.Errorf("%s: firewall group '%s'", ErrorEntityNotFound, name)
+		}
+		references = append(references, types.OpenApiReference{ID: id, Name: name})
+	}
+	return references, nil
+}
+
+func (resolver *firewallPolicyReferenceResolver) profileReferences(names []string) ([]types.OpenApiReference, error) {
+	var references []types.OpenApiReference
+	for _, name := range names {
+		reference, found := resolver.profiles[name]
+		if !found {
+			var err error
+			reference, err = getNsxtAppPortProfileReferenceByName(resolver.target.client, name, resolver.target.contextId)
+			if err != nil {
+				return nil, err
+			}
+			resolver.profiles[name] = reference
+		}
+		references = append(references, *reference)
+	}
+	return references, nil
+}
+
+func (resolver *firewallPolicyReferenceResolver) contextProfileReferences(names []string) ([]types.OpenApiReference, error) {
+	var references []types.OpenApiReference
+	for _, name := range names {
+		reference, found := resolver.contexts[name]
+		if !found {
+			var err error
+			reference, err = getNetworkContextProfileReferenceByName(resolver.target.client, name, resolver.target.contextId)
+			if err != nil {
+				return nil, err
+			}
+			resolver.contexts[name] = reference
+		}
+		references = append(references, *reference)
+	}
+	return references, nil
+}
+
+func (resolver *firewallPolicyReferenceResolver) edgeRule(rule FirewallPolicyRule) (*types.NsxtFirewallRule, error) {
+	sources, err := resolver.groupReferences(rule.Sources)
+	if err != nil {
+		return nil, err
+	}
+	destinations, err := resolver.groupReferences(rule.Destinations)
+	if err != nil {
+		return nil, err
+	}
+	profiles, err := resolver.profileReferences(rule.ApplicationPortProfiles)
+	if err != nil {
+		return nil, err
+	}
+	return &types.NsxtFirewallRule{
+		Name:                      rule.Name,
+		ActionValue:               rule.Action,
+		Enabled:                   rule.Enabled,
+		Direction:                 rule.Direction,
+		IpProtocol:                rule.IpProtocol,
+		Logging:                   rule.Logging,
+		SourceFirewallGroups:      sources,
+		DestinationFirewallGroups: destinations,
+		ApplicationPortProfiles:   profiles,
+	}, nil
+}
+
+func (resolver *firewallPolicyReferenceResolver) distributedRule(rule FirewallPolicyRule) (*types.DistributedFirewallRule, error) {
+	sources, err := resolver.groupReferences(rule.Sources)
+	if err != nil {
+		return nil, err
+	}
+	destinations, err := resolver.groupReferences(rule.Destinations)
+	if err != nil {
+		return nil, err
+	}
+	profiles, err := resolver.profileReferences(rule.ApplicationPortProfiles)
+	if err != nil {
+		return nil, err
+	}
+	contextProfiles, err := resolver.contextProfileReferences(rule.NetworkContextProfiles)
+	if err != nil {
+		return nil, err
+	}
+	apiRule := &types.DistributedFirewallRule{
+		Name:                      rule.Name,
+		ActionValue:               rule.Action,
+		Enabled:                   rule.Enabled,
+		Direction:                 rule.Direction,
+		IpProtocol:                rule.IpProtocol,
+		Logging:                   rule.Logging,
+		Description:               rule.Description,
+		Comments:                  rule.Comments,
+		SourceFirewallGroups:      sources,
+		DestinationFirewallGroups: destinations,
+		ApplicationPortProfiles:   profiles,
+		NetworkContextProfiles:    contextProfiles,
+	}
+	if rule.SourcesExcluded {
+		apiRule.SourceGroupsExcluded = addrOf(true)
+	}
+	if rule.DestinationsExcluded {
+		apiRule.DestinationGroupsExcluded = addrOf(true)
+	}
+	return apiRule, nil
+}
+
+// getNetworkContextProfileReferenceByName looks up a Network Context Profile by name in the given context (VDC or
+// VDC Group ID). When the same name exists in multiple scopes, TENANT takes precedence over PROVIDER, which takes
+// precedence over SYSTEM
+func getNetworkContextProfileReferenceByName(client *Client, name, contextId string) (*types.OpenApiReference, error) {
+	queryParameters := queryParameterFilterAnd("name=="+name, nil)
+	queryParameters = queryParameterFilterAnd("_context=="+contextId, queryParameters)
+	profiles, err := GetAllNetworkContextProfiles(client, queryParameters)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving Network Context Profile '%s': %s", name, err)
+	}
+	for _, scope := range []string{"TENANT", "PROVIDER", "SYSTEM"} {
+		for _, profile := range profiles {
+			if profile.Scope == scope {
+				return &types.OpenApiReference{ID: profile.ID, Name: name}, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("%s: Network Context Profile '%s'", ErrorEntityNotFound, name)
+}
+
+// diffFirewallPolicy computes the changes needed to turn the current document into the desired one. Firewall groups
+// and profiles are only created or updated. Rules are matched by name (and occurrence, for duplicate names) and are
+// created, updated, moved or deleted.
+func diffFirewallPolicy(current, desired *FirewallPolicyDocument) []FirewallPolicyChange {
+	var changes []FirewallPolicyChange
+	changes = append(changes, diffFirewallPolicyItems(FirewallPolicyChangeIpSet, current.IpSets, desired.IpSets,
+		func(item FirewallPolicyIpSet) string { return item.Name },
+		func(item FirewallPolicyIpSet) FirewallPolicyIpSet {
+			item.IpAddresses = sortedCopy(item.IpAddresses)
+			return item
+		})...)
+	changes = append(changes, diffFirewallPolicyItems(FirewallPolicyChangeSecurityGroup, current.SecurityGroups, desired.SecurityGroups,
+		func(item FirewallPolicySecurityGroup) string { return item.Name },
+		func(item FirewallPolicySecurityGroup) FirewallPolicySecurityGroup {
+			item.Networks = sortedCopy(item.Networks)
+			return item
+		})...)
+	changes = append(changes, diffFirewallPolicyItems(FirewallPolicyChangeAppPortProfile, current.AppPortProfiles, desired.AppPortProfiles,
+		func(item FirewallPolicyAppPortProfile) string { return item.Name }, nil)...)
+
+	currentKeys, desiredKeys := firewallPolicyRuleKeys{}, firewallPolicyRuleKeys{}
+	currentRules := make(map[string]FirewallPolicyRule)
+	var currentOrder []string
+	for _, rule := range current.Rules {
+		key := currentKeys.next(rule.Name)
+		currentRules[key] = rule
+		currentOrder = append(currentOrder, key)
+	}
+	desiredSet := make(map[string]bool)
+	var desiredOrder []string
+	for _, rule := range desired.Rules {
+		key := desiredKeys.next(rule.Name)
+		desiredSet[key] = true
+		currentRule, found := currentRules[key]
+		if !found {
+			changes = append(changes, FirewallPolicyChange{Kind: FirewallPolicyChangeRule, Name: rule.Name, Action: FirewallPolicyActionCreate})
+			continue
+		}
+		desiredOrder = append(desiredOrder, key)
+		fields := orgConfigChangedFields(currentRule, rule)
+		if len(fields) > 0 {
+			changes = append(changes, FirewallPolicyChange{Kind: FirewallPolicyChangeRule, Name: rule.Name, Action: FirewallPolicyActionUpdate, Fields: fields})
+		}
+	}
+
+	// Rules kept in both documents must keep their relative order, otherwise they are moved
+	var keptOrder []string
+	for _, key := range currentOrder {
+		if desiredSet[key] {
+			keptOrder = append(keptOrder, key)
+		}
+	}
+	for index, key := range desiredOrder {
+		if keptOrder[index] != key {
+			changes = append(changes, FirewallPolicyChange{Kind: FirewallPolicyChangeRule, Name: currentRules[key].Name, Action: FirewallPolicyActionMove})
+		}
+	}
+
+	for _, key := range currentOrder {
+		if !desiredSet[key] {
+			changes = append(changes, FirewallPolicyChange{Kind: FirewallPolicyChangeRule, Name: currentRules[key].Name, Action: FirewallPolicyActionDelete})
+		}
+	}
+	return changes
+}
+
+// diffFirewallPolicyItems returns create and update changes for named items. normalize, when not nil, is applied
+// before comparing items
+func diffFirewallPolicyItems[T any](kind string, current, desired []T, name func(T) string, normalize func(T) T) []FirewallPolicyChange {
+	if normalize == nil {
+		normalize = func(item T) T { return item }
+	}
+	currentByName := make(map[string]T)
+	for _, item := range current {
+		currentByName[name(item)] = item
+	}
+	var changes []FirewallPolicyChange
+	for _, item := range desired {
+		currentItem, found := currentByName[name(item)]
+		if !found {
+			changes = append(changes, FirewallPolicyChange{Kind: kind, Name: name(item), Action: FirewallPolicyActionCreate})
+			continue
+		}
+		fields := orgConfigChangedFields(normalize(currentItem), normalize(item))
+		if len(fields) > 0 {
+			changes = append(changes, FirewallPolicyChange{Kind: kind, Name: name(item), Action: FirewallPolicyActionUpdate, Fields: fields})
+		}
+	}
+	return changes
+}
+
+// firewallPolicyRuleKeys generates unique keys for rules, as the API does not enforce unique rule names. The second
+// rule named "web" gets the key "web#2"
+type firewallPolicyRuleKeys map[string]int
+
+func (keys firewallPolicyRuleKeys) next(name string) string {
+	keys[name]++
+	if keys[name] == 1 {
+		return name
+	}
+	return fmt.Sprintf("%s#%d", name, keys[name])
+}
+
+// sort orders groups and profiles by name. Rules are evaluated in order and are left untouched
+func (doc *FirewallPolicyDocument) sort() {
+	sort.SliceStable(doc.IpSets, func(i, j int) bool { return doc.IpSets[i].Name < doc.IpSets[j].Name })
+	sort.SliceStable(doc.SecurityGroups, func(i, j int) bool { return doc.SecurityGroups[i].Name < doc.SecurityGroups[j].Name })
+	sort.SliceStable(doc.AppPortProfiles, func(i, j int) bool { return doc.AppPortProfiles[i].Name < doc.AppPortProfiles[j].Name })
+}
+
+// firewallPolicyGroupFromFirewallGroup converts a firewall group into either an IP Set or a Security Group
+func firewallPolicyGroupFromFirewallGroup(group *NsxtFirewallGroup) (*FirewallPolicyIpSet, *FirewallPolicySecurityGroup) {
+	config := group.NsxtFirewallGroup
+	if group.IsIpSet() || config.TypeValue == types.FirewallGroupTypeIpSet {
+		return &FirewallPolicyIpSet{
+			Name:        config.Name,
+			Description: config.Description,
+			IpAddresses: sortedCopy(config.IpAddresses),
+		}, nil
+	}
+	securityGroup := &FirewallPolicySecurityGroup{
+		Name:        config.Name,
+		Description: config.Description,
+		VmCriteria:  config.VmCriteria,
+	}
+	for _, member := range config.Members {
+		securityGroup.Networks = append(securityGroup.Networks, member.Name)
+	}
+	sort.Strings(securityGroup.Networks)
+	return nil, securityGroup
+}
+
+func firewallPolicyAppPortProfileFromProfile(profile *types.NsxtAppPortProfile) FirewallPolicyAppPortProfile {
+	return FirewallPolicyAppPortProfile{
+		Name:        profile.Name,
+		Description: profile.Description,
+		Ports:       profile.ApplicationPorts,
+	}
+}
+
+func firewallPolicyRuleFromEdgeRule(rule *types.NsxtFirewallRule) FirewallPolicyRule {
+	action := rule.ActionValue
+	if action == "" {
+		action = rule.Action
+	}
+	return FirewallPolicyRule{
+		Name:                    rule.Name,
+		Action:                  action,
+		Enabled:                 rule.Enabled,
+		Direction:               rule.Direction,
+		IpProtocol:              rule.IpProtocol,
+		Logging:                 rule.Logging,
+		Sources:                 openApiReferenceNames(rule.SourceFirewallGroups),
+		Destinations:            openApiReferenceNames(rule.DestinationFirewallGroups),
+		ApplicationPortProfiles: openApiReferenceNames(rule.ApplicationPortProfiles),
+	}
+}
+
+func firewallPolicyRuleFromDistributedRule(rule *types.DistributedFirewallRule) FirewallPolicyRule {
+	action := rule.ActionValue
+	if action == "" {
+		action = rule.Action
+	}
+	return FirewallPolicyRule{
+		Name:                    rule.Name,
+		Action:                  action,
+		Enabled:                 rule.Enabled,
+		Direction:               rule.Direction,
+		IpProtocol:              rule.IpProtocol,
+		Logging:                 rule.Logging,
+		Sources:                 openApiReferenceNames(rule.SourceFirewallGroups),
+		Destinations:            openApiReferenceNames(rule.DestinationFirewallGroups),
+		SourcesExcluded:         rule.SourceGroupsExcluded != nil && *rule.SourceGroupsExcluded,
+		DestinationsExcluded:    rule.DestinationGroupsExcluded != nil && *rule.DestinationGroupsExcluded,
+		ApplicationPortProfiles: openApiReferenceNames(rule.ApplicationPortProfiles),
+		NetworkContextProfiles:  openApiReferenceNames(rule.NetworkContextProfiles),
+		Description:             rule.Description,
+		Comments:                rule.Comments,
+	}
+}
+
+// sortedCopy returns a sorted copy of the input, keeping nil as nil
+func sortedCopy(items []string) []string {
+	if items == nil {
+		return nil
+	}
+	result := append([]string{}, items...)
+	sort.Strings(result)
+	return result
+}
@@ -0,0 +1,132 @@
+//go:build unit || ALL
+
+/*
+ * Copyright 2025 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+package govcd
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// Test_FirewallPolicyDocument checks that documents survive a JSON and YAML round trip
+func Test_FirewallPolicyDocument(t *testing.T) {
+	doc := &FirewallPolicyDocument{
+		Version: FirewallPolicyDocumentVersion,
+		Kind:    FirewallPolicyKindDistributed,
+		IpSets:  []FirewallPolicyIpSet{{Name: "web", IpAddresses: []string{"10.0.0.10"}}},
+		SecurityGroups: []FirewallPolicySecurityGroup{
+			{Name: "app", Networks: []string{"net-a"}},
+		},
+		AppPortProfiles: []FirewallPolicyAppPortProfile{
+			{Name: "custom", Ports: []types.NsxtAppPortProfilePort{{Protocol: "TCP", DestinationPorts: []string{"8080"}}}},
+		},
+		Rules: []FirewallPolicyRule{
+			{Name: "allow-web", Action: "ALLOW", Enabled: true, Direction: "IN_OUT", IpProtocol: "IPV4",
+				Sources: []string{"app"}, Destinations: []string{"web"}, ApplicationPortProfiles: []string{"custom", "HTTPS"},
+				NetworkContextProfiles: []string{"HTTP"}, SourcesExcluded: true},
+		},
+	}
+
+	for _, serialize := range []func() ([]byte, error){doc.ToJson, doc.ToYaml} {
+		content, err := serialize()
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		parsed, err := ParseFirewallPolicyDocument(content)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if !reflect.DeepEqual(parsed, doc) {
+			t.Fatalf("round trip mismatch:\ngot:  %+v\nwant: %+v", parsed, doc)
+		}
+	}
+
+	_, err := ParseFirewallPolicyDocument([]byte("version: \"2\"\nrules: []\n"))
+	if err == nil {
+		t.Fatalf("expected error for unsupported version")
+	}
+	_, err = ParseFirewallPolicyDocument([]byte("version: \"1\"\nunknown: true\n"))
+	if err == nil {
+		t.Fatalf("expected error for unknown field")
+	}
+}
+
+// Test_diffFirewallPolicy checks the changes computed between two documents
+func Test_diffFirewallPolicy(t *testing.T) {
+	rule := func(name, action string) FirewallPolicyRule {
+		return FirewallPolicyRule{Name: name, Action: action, Enabled: true, Direction: "IN_OUT", IpProtocol: "IPV4"}
+	}
+	current := &FirewallPolicyDocument{
+		IpSets: []FirewallPolicyIpSet{
+			{Name: "web", IpAddresses: []string{"10.0.0.11", "10.0.0.10"}},
+			{Name: "db", IpAddresses: []string{"10.0.1.5"}},
+			{Name: "unused"},
+		},
+		SecurityGroups: []FirewallPolicySecurityGroup{{Name: "app", Networks: []string{"net-a"}}},
+		Rules: []FirewallPolicyRule{
+			rule("a", "ALLOW"), rule("b", "ALLOW"), rule("c", "ALLOW"), rule("dup", "ALLOW"), rule("dup", "DROP"),
+		},
+	}
+	desired := &FirewallPolicyDocument{
+		IpSets: []FirewallPolicyIpSet{
+			{Name: "web", IpAddresses: []string{"10.0.0.10", "10.0.0.11"}},
+			{Name: "db", IpAddresses: []string{"10.0.1.6"}},
+		},
+		SecurityGroups:  []FirewallPolicySecurityGroup{{Name: "app", Networks: []string{"net-a", "net-b"}}},
+		AppPortProfiles: []FirewallPolicyAppPortProfile{{Name: "custom"}},
+		Rules: []FirewallPolicyRule{
+			rule("b", "ALLOW"), rule("a", "DROP"), rule("dup", "ALLOW"), rule("new", "ALLOW"),
+		},
+	}
+
+	expected := []FirewallPolicyChange{
+		{Kind: FirewallPolicyChangeIpSet, Name: "db", Action: FirewallPolicyActionUpdate, Fields: []string{"ipAddresses"}},
+		{Kind: FirewallPolicyChangeSecurityGroup, Name: "app", Action: FirewallPolicyActionUpdate, Fields: []string{"networks"}},
+		{Kind: FirewallPolicyChangeAppPortProfile, Name: "custom", Action: FirewallPolicyActionCreate},
+		{Kind: FirewallPolicyChangeRule, Name: "a", Action: FirewallPolicyActionUpdate, Fields: []string{"action"}},
+		{Kind: FirewallPolicyChangeRule, Name: "new", Action: FirewallPolicyActionCreate},
+		{Kind: FirewallPolicyChangeRule, Name: "b", Action: FirewallPolicyActionMove},
+		{Kind: FirewallPolicyChangeRule, Name: "a", Action: FirewallPolicyActionMove},
+		{Kind: FirewallPolicyChangeRule, Name: "c", Action: FirewallPolicyActionDelete},
+		{Kind: FirewallPolicyChangeRule, Name: "dup", Action: FirewallPolicyActionDelete},
+	}
+	changes := diffFirewallPolicy(current, desired)
+	if !reflect.DeepEqual(changes, expected) {
+		t.Fatalf("unexpected changes:\ngot:  %+v\nwant: %+v", changes, expected)
+	}
+
+	if len(diffFirewallPolicy(current, current)) != 0 {
+		t.Fatalf("expected no changes between identical documents")
+	}
+}
+
+// Test_firewallPolicyReferenceValidation checks that unknown firewall groups are reported before any change
+func Test_firewallPolicyReferenceValidation(t *testing.T) {
+	resolver := &firewallPolicyReferenceResolver{
+		target: &firewallPolicyTarget{kind: FirewallPolicyKindEdgeGateway},
+		groups: map[string]string{"web": "urn:vcloud:firewallGroup:1"},
+	}
+	desired := &FirewallPolicyDocument{
+		IpSets:          []FirewallPolicyIpSet{{Name: "db"}},
+		AppPortProfiles: []FirewallPolicyAppPortProfile{{Name: "custom"}},
+		Rules: []FirewallPolicyRule{
+			{Name: "allow", Sources: []string{"web"}, Destinations: []string{"db"}, ApplicationPortProfiles: []string{"custom"}},
+		},
+	}
+	err := resolver.validate(desired, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	desired.Rules = append(desired.Rules, FirewallPolicyRule{Name: "deny", Sources: []string{"app"}})
+	err = resolver.validate(desired, nil)
+	if err == nil || !strings.Contains(err.Error(), "rule 'deny'") || !strings.Contains(err.Error(), "firewall group 'app'") {
+		t.Fatalf("expected error for unknown firewall group, got %v", err)
+	}
+}
@@ -0,0 +1,281 @@
+/*
+ * Copyright 2025 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+package govcd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+	"github.com/vmware/go-vcloud-director/v3/util"
+)
+
+// nsxtFirewallMaxRetries is the number of times a rule modification is retried when the rules were changed by
+// someone else between reading and writing them
+const nsxtFirewallMaxRetries = 5
+
+// NsxtFirewallRulePosition defines where rules are placed within the user defined firewall rules. Rules are
+// referenced by ID or by name, and a name must match a single rule. When no field is set, rules are placed at
+// the bottom of the list.
+type NsxtFirewallRulePosition struct {
+	// Top places the rules above all other user defined rules
+	Top bool
+	// BeforeRule places the rules right above the rule with this ID or name
+	BeforeRule string
+	// AfterRule places the rules right below the rule with this ID or name
+	AfterRule string
+}
+
+// NsxtFirewallRuleOwner limits rule operations to the rules owned by a given consumer, so that multiple tools can
+// share the same Edge Gateway firewall without overwriting each other's rules. Edge Gateway firewall rules have no
+// description, so ownership is defined by a name prefix.
+type NsxtFirewallRuleOwner struct {
+	NamePrefix string
+}
+
+// InsertNsxtFirewallRules adds rules at the given position of the user defined firewall rules.
+// When owner is not nil, all the new rules must be owned by it.
+//
+// Rules are read and written with an Etag, so that concurrent changes are not lost: if the rules change between
+// reading and writing, the operation is retried against the new rules.
+func (egw *NsxtEdgeGateway) InsertNsxtFirewallRules(rules []*types.NsxtFirewallRule, position NsxtFirewallRulePosition, owner *NsxtFirewallRuleOwner) (*NsxtFirewall, error) {
+	return egw.modifyNsxtFirewallRules(func(existing []*types.NsxtFirewallRule) ([]*types.NsxtFirewallRule, error) {
+		return insertNsxtFirewallRules(existing, rules, position, owner)
+	})
+}
+
+// MoveNsxtFirewallRule moves the rule with the given ID or name to a new position of the user defined firewall
+// rules. When owner is not nil, the moved rule must be owned by it.
+func (egw *NsxtEdgeGateway) MoveNsxtFirewallRule(rule string, position NsxtFirewallRulePosition, owner *NsxtFirewallRuleOwner) (*NsxtFirewall, error) {
+	return egw.modifyNsxtFirewallRules(func(existing []*types.NsxtFirewallRule) ([]*types.NsxtFirewallRule, error) {
+		return moveNsxtFirewallRule(existing, rule, position, owner)
+	})
+}
+
+// ReplaceNsxtFirewallRules replaces existing rules, keeping their position. Each given rule replaces the existing
+// rule with the same ID or, when the ID is empty, with the same name. When owner is not nil, all replaced rules must
+// be owned by it.
+func (egw *NsxtEdgeGateway) ReplaceNsxtFirewallRules(rules []*types.NsxtFirewallRule, owner *NsxtFirewallRuleOwner) (*NsxtFirewall, error) {
+	return egw.modifyNsxtFirewallRules(func(existing []*types.NsxtFirewallRule) ([]*types.NsxtFirewallRule, error) {
+		return replaceNsxtFirewallRules(existing, rules, owner)
+	})
+}
+
+// ReplaceOwnedNsxtFirewallRules replaces all the rules owned by owner with the given ones, leaving other rules
+// untouched. The new rules take the place of the first owned rule, or are added at the bottom if there are no owned
+// rules yet. Existing owned rules with the same name as a new rule keep their ID.
+func (egw *NsxtEdgeGateway) ReplaceOwnedNsxtFirewallRules(owner NsxtFirewallRuleOwner, rules []*types.NsxtFirewallRule) (*NsxtFirewall, error) {
+	return egw.modifyNsxtFirewallRules(func(existing []*types.NsxtFirewallRule) ([]*types.NsxtFirewallRule, error) {
+		return replaceOwnedNsxtFirewallRules(existing, rules, owner)
+	})
+}
+
+// modifyNsxtFirewallRules performs an Etag protected read-modify-write of the user defined firewall rules, retrying
+// when the rules were changed concurrently
+func (egw *NsxtEdgeGateway) modifyNsxtFirewallRules(modify func([]*types.NsxtFirewallRule) ([]*types.NsxtFirewallRule, error)) (*NsxtFirewall, error) {
+	for attempt := 1; ; attempt++ {
+		firewall, err := egw.GetNsxtFirewall()
+		if err != nil {
+			return nil, err
+		}
+		rules, err := modify(firewall.NsxtFirewallRuleContainer.UserDefinedRules)
+		if err != nil {
+			return nil, err
+		}
+		if firewall.Etag == "" {
+			util.Logger.Printf("[WARN] NSX-T Firewall rules of Edge Gateway '%s' were retrieved without Etag, concurrent changes cannot be detected", egw.EdgeGateway.Name)
+		}
+		updated, err := egw.updateNsxtFirewall(&types.NsxtFirewallRuleContainer{UserDefinedRules: rules}, firewall.Etag)
+		if err == nil {
+			return updated, nil
+		}
+		if !isEtagConflictError(err) || attempt >= nsxtFirewallMaxRetries {
+			return nil, err
+		}
+		util.Logger.Printf("[DEBUG] NSX-T Firewall rules of Edge Gateway '%s' changed during update (attempt %d). Trying again", egw.EdgeGateway.Name, attempt)
+	}
+}
+
+// isEtagConflictError returns true when err is caused by a failed If-Match precondition (HTTP 412)
+func isEtagConflictError(err error) bool {
+	return errors.Is(err, errOpenApiPreconditionFailed)
+}
+
+// owns returns true when the rule is owned. A nil owner owns all rules
+func (owner *NsxtFirewallRuleOwner) owns(rule *types.NsxtFirewallRule) bool {
+	return owner == nil || strings.HasPrefix(rule.Name, owner.NamePrefix)
+}
+
+// checkOwned returns an error if any of the rules is not owned
+func (owner *NsxtFirewallRuleOwner) checkOwned(rules ...*types.NsxtFirewallRule) error {
+	for _, rule := range rules {
+		if !owner.owns(rule) {
+			return fmt.Errorf("firewall rule '%s' is not owned by prefix '%s'", rule.Name, owner.NamePrefix)
+		}
+	}
+	return nil
+}
+
+// findNsxtFirewallRuleIndex returns the index of the rule with the given ID or, if no ID matches, with the given
+// name. It is an error if the name matches more than one rule
+func findNsxtFirewallRuleIndex(rules []*types.NsxtFirewallRule, idOrName string) (int, error) {
+	for index, rule := range rules {
+		if rule.ID != "" && rule.ID == idOrName {
+			return index, nil
+		}
+	}
+	found := -1
+	for index, rule := range rules {
+		if rule.Name != idOrName {
+			continue
+		}
+		if found >= 0 {
+			return -1, fmt.Errorf("firewall rule name '%s' matches more than one rule, use the ID instead", idOrName)
+		}
+		found = index
+	}
+	if found < 0 {
+		return -1, fmt.Errorf("%s: firewall rule '%s'", ErrorEntityNotFound, idOrName)
+	}
+	return found, nil
+}
+
+// index returns the slice index at which rules must be inserted
+func (position NsxtFirewallRulePosition) index(rules []*types.NsxtFirewallRule) (int, error) {
+	set := 0
+	for _, isSet := range []bool{position.Top, position.BeforeRule != "", position.AfterRule != ""} {
+		if isSet {
+			set++
+		}
+	}
+	switch {
+	case set > 1:
+		return -1, fmt.Errorf("only one of Top, BeforeRule and AfterRule can be set in a firewall rule position")
+	case position.Top:
+		return 0, nil
+	case position.BeforeRule != "":
+		return findNsxtFirewallRuleIndex(rules, position.BeforeRule)
+	case position.AfterRule != "":
+		index, err := findNsxtFirewallRuleIndex(rules, position.AfterRule)
+		if err != nil {
+			return -1, err
+		}
+		return index + 1, nil
+	}
+	return len(rules), nil
+}
+
+// insertNsxtFirewallRules returns a new slice with newRules inserted at the given position
+func insertNsxtFirewallRules(existing, newRules []*types.NsxtFirewallRule, position NsxtFirewallRulePosition, owner *NsxtFirewallRuleOwner) ([]*types.NsxtFirewallRule, error) {
+	err := owner.checkOwned(newRules...)
+	if err != nil {
+		return nil, err
+	}
+	index, err := position.index(existing)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*types.NsxtFirewallRule, 0, len(existing)+len(newRules))
+	result = append(result, existing[:index]...)
+	result = append(result, newRules...)
+	result = append(result, existing[index:]...)
+	return result, nil
+}
+
+// moveNsxtFirewallRule returns a new slice with the given rule moved to a new position
+func moveNsxtFirewallRule(existing []*types.NsxtFirewallRule, rule string, position NsxtFirewallRulePosition, owner *NsxtFirewallRuleOwner) ([]*types.NsxtFirewallRule, error) {
+	index, err := findNsxtFirewallRuleIndex(existing, rule)
+	if err != nil {
+		return nil, err
+	}
+	moved := existing[index]
+	err = owner.checkOwned(moved)
+	if err != nil {
+		return nil, err
+	}
+	for _, anchor := range []string{position.BeforeRule, position.AfterRule} {
+		if anchor != "" && (anchor == moved.ID || anchor == moved.Name) {
+			return nil, fmt.Errorf("firewall rule '%s' cannot be moved relative to itself", rule)
+		}
+	}
+	remaining := make([]*types.NsxtFirewallRule, 0, len(existing)-1)
+	remaining = append(remaining, existing[:index]...)
+	remaining = append(remaining, existing[index+1:]...)
+	return insertNsxtFirewallRules(remaining, []*types.NsxtFirewallRule{moved}, position, nil)
+}
+
+// replaceNsxtFirewallRules returns a new slice where the rules matching the given ones by ID or name are replaced
+func replaceNsxtFirewallRules(existing, rules []*types.NsxtFirewallRule, owner *NsxtFirewallRuleOwner) ([]*types.NsxtFirewallRule, error) {
+	result := make([]*types.NsxtFirewallRule, len(existing))
+	copy(result, existing)
+	replaced := make(map[int]bool)
+	for _, rule := range rules {
+		key := rule.ID
+		if key == "" {
+			key = rule.Name
+		}
+		index, err := findNsxtFirewallRuleIndex(existing, key)
+		if err != nil {
+			return nil, err
+		}
+		if replaced[index] {
+			return nil, fmt.Errorf("firewall rule '%s' is replaced more than once", key)
+		}
+		replaced[index] = true
+		err = owner.checkOwned(existing[index], rule)
+		if err != nil {
+			return nil, err
+		}
+		replacement := *rule
+		replacement.ID = existing[index].ID
+		result[index] = &replacement
+	}
+	return result, nil
+}
+
+// replaceOwnedNsxtFirewallRules returns a new slice where all owned rules are replaced by the given ones
+func replaceOwnedNsxtFirewallRules(existing, rules []*types.NsxtFirewallRule, owner NsxtFirewallRuleOwner) ([]*types.NsxtFirewallRule, error) {
+	if owner.NamePrefix == "" {
+		return nil, fmt.Errorf("owner name prefix cannot be empty")
+	}
+	err := owner.checkOwned(rules...)
+	if err != nil {
+		return nil, err
+	}
+
+	existingIds := make(map[string][]string)
+	insertAt := -1
+	var result []*types.NsxtFirewallRule
+	for _, rule := range existing {
+		if !owner.owns(rule) {
+			result = append(result, rule)
+			continue
+		}
+		if insertAt < 0 {
+			insertAt = len(result)
+		}
+		existingIds[rule.Name] = append(existingIds[rule.Name], rule.ID)
+	}
+	if insertAt < 0 {
+		insertAt = len(result)
+	}
+
+	newRules := make([]*types.NsxtFirewallRule, 0, len(rules))
+	for _, rule := range rules {
+		newRule := *rule
+		if ids := existingIds[rule.Name]; newRule.ID == "" && len(ids) > 0 {
+			newRule.ID = ids[0]
+			existingIds[rule.Name] = ids[1:]
+		}
+		newRules = append(newRules, &newRule)
+	}
+
+	final := make([]*types.NsxtFirewallRule, 0, len(result)+len(newRules))
+	final = append(final, result[:insertAt]...)
+	final = append(final, newRules...)
+	final = append(final, result[insertAt:]...)
+	return final, nil
+}
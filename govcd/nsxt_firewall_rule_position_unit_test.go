@@ -0,0 +1,160 @@
+//go:build unit || ALL
+
+/*
+ * Copyright 2025 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+package govcd
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+func nsxtFirewallRuleNames(rules []*types.NsxtFirewallRule) []string {
+	var names []string
+	for _, rule := range rules {
+		names = append(names, rule.Name)
+	}
+	return names
+}
+
+// Test_NsxtFirewallRulePosition checks insert and move operations
+func Test_NsxtFirewallRulePosition(t *testing.T) {
+	existing := []*types.NsxtFirewallRule{{ID: "id-a", Name: "a"}, {ID: "id-b", Name: "b"}, {ID: "id-c", Name: "c"}}
+	newRules := []*types.NsxtFirewallRule{{Name: "x"}, {Name: "y"}}
+
+	tests := []struct {
+		position NsxtFirewallRulePosition
+		expected []string
+	}{
+		{NsxtFirewallRulePosition{}, []string{"a", "b", "c", "x", "y"}},
+		{NsxtFirewallRulePosition{Top: true}, []string{"x", "y", "a", "b", "c"}},
+		{NsxtFirewallRulePosition{BeforeRule: "b"}, []string{"a", "x", "y", "b", "c"}},
+		{NsxtFirewallRulePosition{AfterRule: "id-c"}, []string{"a", "b", "c", "x", "y"}},
+	}
+	for _, test := range tests {
+		result, err := insertNsxtFirewallRules(existing, newRules, test.position, nil)
+		if err != nil {
+			t.Fatalf("unexpected error for position %+v: %s", test.position, err)
+		}
+		if !reflect.DeepEqual(nsxtFirewallRuleNames(result), test.expected) {
+			t.Fatalf("position %+v: expected %v, got %v", test.position, test.expected, nsxtFirewallRuleNames(result))
+		}
+	}
+	if !reflect.DeepEqual(nsxtFirewallRuleNames(existing), []string{"a", "b", "c"}) {
+		t.Fatalf("existing rules were modified: %v", nsxtFirewallRuleNames(existing))
+	}
+
+	_, err := insertNsxtFirewallRules(existing, newRules, NsxtFirewallRulePosition{Top: true, AfterRule: "a"}, nil)
+	if err == nil {
+		t.Fatalf("expected error for conflicting position")
+	}
+	_, err = insertNsxtFirewallRules(existing, newRules, NsxtFirewallRulePosition{BeforeRule: "missing"}, nil)
+	if err == nil {
+		t.Fatalf("expected error for unknown anchor rule")
+	}
+	duplicated := []*types.NsxtFirewallRule{{ID: "id-a", Name: "a"}, {ID: "id-a", Name: "a"}}
+	_, err = insertNsxtFirewallRules(duplicated, newRules, NsxtFirewallRulePosition{BeforeRule: "a"}, nil)
+	if err == nil {
+		t.Fatalf("expected error for ambiguous anchor rule")
+	}
+	_, err = insertNsxtFirewallRules(existing, newRules, NsxtFirewallRulePosition{}, &NsxtFirewallRuleOwner{NamePrefix: "app-"})
+	if err == nil {
+		t.Fatalf("expected error for rules that are not owned")
+	}
+
+	moved, err := moveNsxtFirewallRule(existing, "c", NsxtFirewallRulePosition{BeforeRule: "a"}, nil)
+	if err != nil || !reflect.DeepEqual(nsxtFirewallRuleNames(moved), []string{"c", "a", "b"}) {
+		t.Fatalf("unexpected move result %v (error %v)", nsxtFirewallRuleNames(moved), err)
+	}
+	moved, err = moveNsxtFirewallRule(existing, "id-a", NsxtFirewallRulePosition{AfterRule: "b"}, nil)
+	if err != nil || !reflect.DeepEqual(nsxtFirewallRuleNames(moved), []string{"b", "a", "c"}) {
+		t.Fatalf("unexpected move result %v (error %v)", nsxtFirewallRuleNames(moved), err)
+	}
+	_, err = moveNsxtFirewallRule(existing, "a", NsxtFirewallRulePosition{AfterRule: "a"}, nil)
+	if err == nil {
+		t.Fatalf("expected error when moving a rule relative to itself")
+	}
+}
+
+// Test_replaceNsxtFirewallRules checks in place and owned replacement of rules
+func Test_replaceNsxtFirewallRules(t *testing.T) {
+	existing := []*types.NsxtFirewallRule{
+		{ID: "id-a", Name: "a"},
+		{ID: "id-app-1", Name: "app-1"},
+		{ID: "id-b", Name: "b"},
+		{ID: "id-app-2", Name: "app-2"},
+		{ID: "id-c", Name: "c"},
+	}
+	owner := NsxtFirewallRuleOwner{NamePrefix: "app-"}
+
+	result, err := replaceNsxtFirewallRules(existing, []*types.NsxtFirewallRule{{Name: "app-2", ActionValue: "DROP"}}, &owner)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if result[3].ID != "id-app-2" || result[3].ActionValue != "DROP" || existing[3].ActionValue != "" {
+		t.Fatalf("unexpected replacement %+v", result[3])
+	}
+	_, err = replaceNsxtFirewallRules(existing, []*types.NsxtFirewallRule{{ID: "id-b", Name: "app-b"}}, &owner)
+	if err == nil {
+		t.Fatalf("expected error when replacing a rule that is not owned")
+	}
+
+	result, err = replaceOwnedNsxtFirewallRules(existing, []*types.NsxtFirewallRule{{Name: "app-2"}, {Name: "app-3"}}, owner)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(nsxtFirewallRuleNames(result), []string{"a", "app-2", "app-3", "b", "c"}) {
+		t.Fatalf("unexpected owned replacement %v", nsxtFirewallRuleNames(result))
+	}
+	if result[1].ID != "id-app-2" || result[2].ID != "" {
+		t.Fatalf("expected existing rule ID to be kept, got %+v and %+v", result[1], result[2])
+	}
+
+	result, err = replaceOwnedNsxtFirewallRules([]*types.NsxtFirewallRule{{ID: "id-a", Name: "a"}}, []*types.NsxtFirewallRule{{Name: "app-1"}}, owner)
+	if err != nil || !reflect.DeepEqual(nsxtFirewallRuleNames(result), []string{"a", "app-1"}) {
+		t.Fatalf("unexpected owned replacement %v (error %v)", nsxtFirewallRuleNames(result), err)
+	}
+	_, err = replaceOwnedNsxtFirewallRules(existing, nil, NsxtFirewallRuleOwner{})
+	if err == nil {
+		t.Fatalf("expected error for empty owner prefix")
+	}
+}
+
+// Test_isEtagConflictError checks that only HTTP 412 responses are retried, whatever their message
+func Test_isEtagConflictError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", types.JSONMime)
+		if r.Header.Get("If-Match") == "stale" {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			_, _ = w.Write([]byte(`{"minorErrorCode": "PRECONDITION_FAILED", "message": "Die Vorbedingung ist fehlgeschlagen"}`))
+			return
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"minorErrorCode": "BAD_REQUEST", "message": "invalid Etag precondition in rule name"}`))
+	}))
+	defer server.Close()
+
+	serverUrl, err := url.Parse(server.URL + "/cloudapi/1.0.0/edgeGateways/egw/firewall/rules")
+	if err != nil {
+		t.Fatalf("error parsing URL: %s", err)
+	}
+	client := &Client{Http: *server.Client(), APIVersion: "37.0"}
+
+	_, err = client.openApiPerformPostPut(http.MethodPut, "37.0", serverUrl, nil, struct{}{}, map[string]string{"If-Match": "stale"})
+	if err == nil || !isEtagConflictError(fmt.Errorf("error setting NSX-T Firewall: %w", err)) {
+		t.Fatalf("expected HTTP 412 to be detected as Etag conflict, got %v", err)
+	}
+
+	_, err = client.openApiPerformPostPut(http.MethodPut, "37.0", serverUrl, nil, struct{}{}, map[string]string{"If-Match": "current"})
+	if err == nil || isEtagConflictError(err) {
+		t.Fatalf("expected HTTP 400 not to be detected as Etag conflict, got %v", err)
+	}
+}
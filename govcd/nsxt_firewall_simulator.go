@@ -0,0 +1,723 @@
+/*
+ * Copyright 2025 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+package govcd
+
+import (
+	"fmt"
+	"net/netip"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// Kinds of FirewallRuleFinding
+const (
+	// FirewallFindingShadowed is a rule that can never match because an earlier rule with a different action
+	// matches all of its traffic
+	FirewallFindingShadowed = "shadowed"
+	// FirewallFindingRedundant is a rule that can never match because an earlier rule with the same action matches
+	// all of its traffic
+	FirewallFindingRedundant = "redundant"
+	// FirewallFindingUnreachable is a rule that can never match because its source or destination groups have no
+	// members
+	FirewallFindingUnreachable = "unreachable"
+)
+
+// Sections of NSX-T Edge Gateway firewall rules, evaluated in this order
+const (
+	FirewallSectionSystem      = "system"
+	FirewallSectionUserDefined = "userDefined"
+	FirewallSectionDefault     = "default"
+)
+
+// FirewallSimulatorRule is a firewall rule in a format that is common to NSX-T Edge Gateway firewall and
+// Distributed Firewall rules. Groups and profiles are referenced by ID.
+type FirewallSimulatorRule struct {
+	ID      string
+	Name    string
+	Section string // Only for NSX-T Edge Gateway firewall rules
+	// Action is one of ALLOW, DROP or REJECT
+	Action  string
+	Enabled bool
+	// Direction is one of IN_OUT, IN or OUT
+	Direction string
+	// IpProtocol is one of IPV4, IPV6 or IPV4_IPV6
+	IpProtocol string
+	// Empty lists mean 'Any'
+	Sources                 []string
+	Destinations            []string
+	ApplicationPortProfiles []string
+	NetworkContextProfiles  []string
+	SourcesExcluded         bool
+	DestinationsExcluded    bool
+}
+
+// FirewallPacket is the traffic evaluated by FirewallSimulator.Evaluate
+type FirewallPacket struct {
+	Source      string // IP address
+	Destination string // IP address
+	// Protocol is one of TCP, UDP, ICMPv4 or ICMPv6
+	Protocol string
+	// Port is the destination port for TCP and UDP
+	Port int
+	// Direction is IN or OUT. When empty, the direction of the rules is not considered
+	Direction string
+}
+
+// FirewallVerdict is the result of evaluating a FirewallPacket
+type FirewallVerdict struct {
+	Allowed bool
+	Action  string
+	// Rule is the first rule that matches the packet. It is nil when no rule matches and the default action applies
+	Rule *FirewallSimulatorRule
+	// Position is the index of Rule in the evaluation order, or -1 when no rule matches
+	Position int
+	// Layer7Rules contains the names of earlier rules that were not evaluated because they depend on network
+	// context profiles, which can't be simulated
+	Layer7Rules []string
+}
+
+// FirewallRuleFinding reports a rule that can never match
+type FirewallRuleFinding struct {
+	Kind     string
+	Position int
+	Rule     FirewallSimulatorRule
+	// ByPosition and ByRule refer to the earlier rule that shadows this one. They are not set for unreachable rules
+	ByPosition int
+	ByRule     *FirewallSimulatorRule
+	Reason     string
+}
+
+// FirewallSimulator evaluates firewall rules offline. It is created with NewFirewallSimulator or, with the current
+// groups and profiles retrieved from VCD, with NsxtEdgeGateway.NewFirewallSimulator and
+// VdcGroup.NewDistributedFirewallSimulator
+type FirewallSimulator struct {
+	// DefaultAction is applied when no rule matches. Defaults to DROP
+	DefaultAction string
+
+	rules []firewallSimulatorCompiledRule
+}
+
+// firewallSimulatorCompiledRule is a rule with all its references resolved
+type firewallSimulatorCompiledRule struct {
+	rule         FirewallSimulatorRule
+	sources      firewallAddressSet
+	destinations firewallAddressSet
+	services     []firewallService // nil means 'Any'
+}
+
+// NewFirewallSimulator compiles rules, given in evaluation order, for offline evaluation.
+// groups maps firewall group IDs to their members, which can be IP addresses, CIDRs or ranges (e.g.
+// "10.0.0.1-10.0.0.10"). profiles maps application port profile IDs to their ports.
+func NewFirewallSimulator(rules []FirewallSimulatorRule, groups map[string][]string, profiles map[string][]types.NsxtAppPortProfilePort) (*FirewallSimulator, error) {
+	simulator := &FirewallSimulator{DefaultAction: "DROP"}
+	for _, rule := range rules {
+		compiled := firewallSimulatorCompiledRule{rule: rule}
+		var err error
+		compiled.sources, err = newFirewallAddressSet(rule.Sources, groups)
+		if err != nil {
+			return nil, fmt.Errorf("error resolving sources of rule '%s': %s", rule.Name, err)
+		}
+		compiled.destinations, err = newFirewallAddressSet(rule.Destinations, groups)
+		if err != nil {
+			return nil, fmt.Errorf("error resolving destinations of rule '%s': %s", rule.Name, err)
+		}
+		for _, profileId := range rule.ApplicationPortProfiles {
+			ports, ok := profiles[profileId]
+			if !ok {
+				return nil, fmt.Errorf("rule '%s' refers to unknown application port profile '%s'", rule.Name, profileId)
+			}
+			services, err := newFirewallServices(ports)
+			if err != nil {
+				return nil, fmt.Errorf("error resolving application port profile '%s': %s", profileId, err)
+			}
+			compiled.services = append(compiled.services, services...)
+		}
+		simulator.rules = append(simulator.rules, compiled)
+	}
+	return simulator, nil
+}
+
+// Rules returns the rules of the simulator in evaluation order
+func (simulator *FirewallSimulator) Rules() []FirewallSimulatorRule {
+	rules := make([]FirewallSimulatorRule, len(simulator.rules))
+	for index, compiled := range simulator.rules {
+		rules[index] = compiled.rule
+	}
+	return rules
+}
+
+// Evaluate returns the first rule that matches the given packet and whether the packet is allowed
+func (simulator *FirewallSimulator) Evaluate(packet FirewallPacket) (*FirewallVerdict, error) {
+	source, err := netip.ParseAddr(packet.Source)
+	if err != nil {
+		return nil, fmt.Errorf("invalid source address: %s", err)
+	}
+	destination, err := netip.ParseAddr(packet.Destination)
+	if err != nil {
+		return nil, fmt.Errorf("invalid destination address: %s", err)
+	}
+	if source.Is4() != destination.Is4() {
+		return nil, fmt.Errorf("source and destination addresses must belong to the same IP family")
+	}
+
+	verdict := &FirewallVerdict{Position: -1}
+	for index := range simulator.rules {
+		compiled := &simulator.rules[index]
+		if !compiled.matches(packet, source, destination) {
+			continue
+		}
+		if len(compiled.rule.NetworkContextProfiles) > 0 {
+			verdict.Layer7Rules = append(verdict.Layer7Rules, compiled.rule.Name)
+			continue
+		}
+		rule := compiled.rule
+		verdict.Rule = &rule
+		verdict.Position = index
+		verdict.Action = rule.Action
+		verdict.Allowed = rule.Action == "ALLOW"
+		return verdict, nil
+	}
+	verdict.Action = simulator.DefaultAction
+	verdict.Allowed = simulator.DefaultAction == "ALLOW"
+	return verdict, nil
+}
+
+// Analyze reports enabled rules that can never match, either because an earlier enabled rule matches all of their
+// traffic or because their source or destination groups are empty
+func (simulator *FirewallSimulator) Analyze() []FirewallRuleFinding {
+	var findings []FirewallRuleFinding
+	for index, compiled := range simulator.rules {
+		if !compiled.rule.Enabled {
+			continue
+		}
+		if (compiled.sources.isEmpty() && !compiled.rule.SourcesExcluded) || (compiled.destinations.isEmpty() && !compiled.rule.DestinationsExcluded) {
+			findings = append(findings, FirewallRuleFinding{
+				Kind:     FirewallFindingUnreachable,
+				Position: index,
+				Rule:     compiled.rule,
+				Reason:   "source or destination firewall groups have no members",
+			})
+			continue
+		}
+		for earlierIndex := 0; earlierIndex < index; earlierIndex++ {
+			earlier := simulator.rules[earlierIndex]
+			if !earlier.rule.Enabled || !earlier.covers(compiled) {
+				continue
+			}
+			finding := FirewallRuleFinding{
+				Kind:       FirewallFindingRedundant,
+				Position:   index,
+				Rule:       compiled.rule,
+				ByPosition: earlierIndex,
+				ByRule:     &simulator.rules[earlierIndex].rule,
+				Reason:     fmt.Sprintf("rule '%s' matches all of its traffic with the same action", earlier.rule.Name),
+			}
+			if earlier.rule.Action != compiled.rule.Action {
+				finding.Kind = FirewallFindingShadowed
+				finding.Reason = fmt.Sprintf("rule '%s' matches all of its traffic with action %s", earlier.rule.Name, earlier.rule.Action)
+			}
+			findings = append(findings, finding)
+			break
+		}
+	}
+	return findings
+}
+
+// matches checks the layer 3 and 4 conditions of a rule against a packet
+func (compiled *firewallSimulatorCompiledRule) matches(packet FirewallPacket, source, destination netip.Addr) bool {
+	rule := compiled.rule
+	if !rule.Enabled {
+		return false
+	}
+	if packet.Direction != "" && rule.Direction != "" && rule.Direction != "IN_OUT" && rule.Direction != packet.Direction {
+		return false
+	}
+	switch rule.IpProtocol {
+	case "IPV4":
+		if !source.Is4() {
+			return false
+		}
+	case "IPV6":
+		if !source.Is6() {
+			return false
+		}
+	}
+	if compiled.sources.contains(source) == rule.SourcesExcluded && len(rule.Sources) > 0 {
+		return false
+	}
+	if compiled.destinations.contains(destination) == rule.DestinationsExcluded && len(rule.Destinations) > 0 {
+		return false
+	}
+	if compiled.services == nil {
+		return true
+	}
+	for _, service := range compiled.services {
+		if service.matches(packet.Protocol, packet.Port) {
+			return true
+		}
+	}
+	return false
+}
+
+// covers returns true if the receiver rule matches all the traffic that the other rule matches
+func (compiled *firewallSimulatorCompiledRule) covers(other firewallSimulatorCompiledRule) bool {
+	rule, otherRule := compiled.rule, other.rule
+	if rule.Direction != "" && rule.Direction != "IN_OUT" && rule.Direction != otherRule.Direction {
+		return false
+	}
+	if rule.IpProtocol != "" && rule.IpProtocol != "IPV4_IPV6" && rule.IpProtocol != otherRule.IpProtocol {
+		return false
+	}
+	if !coversAddresses(compiled.sources, rule.SourcesExcluded && len(rule.Sources) > 0, other.sources, otherRule.SourcesExcluded && len(otherRule.Sources) > 0) {
+		return false
+	}
+	if !coversAddresses(compiled.destinations, rule.DestinationsExcluded && len(rule.Destinations) > 0, other.destinations, otherRule.DestinationsExcluded && len(otherRule.Destinations) > 0) {
+		return false
+	}
+	if len(rule.NetworkContextProfiles) > 0 {
+		if len(otherRule.NetworkContextProfiles) == 0 {
+			return false
+		}
+		for _, profile := range otherRule.NetworkContextProfiles {
+			if !contains(profile, rule.NetworkContextProfiles) {
+				return false
+			}
+		}
+	}
+	if compiled.services == nil {
+		return true
+	}
+	if other.services == nil {
+		return false
+	}
+	for _, otherService := range other.services {
+		covered := false
+		for _, service := range compiled.services {
+			if service.covers(otherService) {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			return false
+		}
+	}
+	return true
+}
+
+// coversAddresses returns true if the first address condition matches all addresses matched by the second one.
+// Excluded sets match all the addresses that are not in the set
+func coversAddresses(set firewallAddressSet, excluded bool, other firewallAddressSet, otherExcluded bool) bool {
+	switch {
+	case set.any:
+		return true
+	case other.any:
+		return false
+	case !excluded && !otherExcluded:
+		return set.covers(other)
+	case excluded && otherExcluded:
+		return other.covers(set)
+	case excluded && !otherExcluded:
+		return !set.overlaps(other)
+	}
+	return false
+}
+
+// firewallAddressRange is an inclusive range of IP addresses
+type firewallAddressRange struct {
+	from netip.Addr
+	to   netip.Addr
+}
+
+// firewallAddressSet is a set of IP addresses. The zero value with any=false is an empty set
+type firewallAddressSet struct {
+	any    bool
+	ranges []firewallAddressRange
+}
+
+// newFirewallAddressSet builds the set of addresses of the given firewall groups. No groups means 'Any'
+func newFirewallAddressSet(groupIds []string, groups map[string][]string) (firewallAddressSet, error) {
+	if len(groupIds) == 0 {
+		return firewallAddressSet{any: true}, nil
+	}
+	var set firewallAddressSet
+	for _, groupId := range groupIds {
+		members, ok := groups[groupId]
+		if !ok {
+			return set, fmt.Errorf("unknown firewall group '%s'", groupId)
+		}
+		for _, member := range members {
+			addressRange, err := parseFirewallAddressRange(member)
+			if err != nil {
+				return set, fmt.Errorf("invalid member of firewall group '%s': %s", groupId, err)
+			}
+			set.ranges = append(set.ranges, addressRange)
+		}
+	}
+	set.normalise()
+	return set, nil
+}
+
+// parseFirewallAddressRange parses an IP address, a CIDR or a range of IP addresses
+func parseFirewallAddressRange(member string) (firewallAddressRange, error) {
+	member = strings.TrimSpace(member)
+	if from, to, isRange := strings.Cut(member, "-"); isRange {
+		start, err := netip.ParseAddr(strings.TrimSpace(from))
+		if err != nil {
+			return firewallAddressRange{}, err
+		}
+		end, err := netip.ParseAddr(strings.TrimSpace(to))
+		if err != nil {
+			return firewallAddressRange{}, err
+		}
+		if start.Is4() != end.Is4() || end.Less(start) {
+			return firewallAddressRange{}, fmt.Errorf("invalid IP range '%s'", member)
+		}
+		return firewallAddressRange{from: start, to: end}, nil
+	}
+	if strings.Contains(member, "/") {
+		prefix, err := netip.ParsePrefix(member)
+		if err != nil {
+			return firewallAddressRange{}, err
+		}
+		prefix = prefix.Masked()
+		return firewallAddressRange{from: prefix.Addr(), to: lastAddressOfPrefix(prefix)}, nil
+	}
+	address, err := netip.ParseAddr(member)
+	if err != nil {
+		return firewallAddressRange{}, err
+	}
+	return firewallAddressRange{from: address, to: address}, nil
+}
+
+// lastAddressOfPrefix returns the last address of the given masked prefix
+func lastAddressOfPrefix(prefix netip.Prefix) netip.Addr {
+	bytes := prefix.Addr().AsSlice()
+	for bit := prefix.Bits(); bit < len(bytes)*8; bit++ {
+		bytes[bit/8] |= 1 << (7 - bit%8)
+	}
+	address, _ := netip.AddrFromSlice(bytes)
+	return address
+}
+
+// normalise sorts the ranges and merges the ones that overlap or are adjacent
+func (set *firewallAddressSet) normalise() {
+	sort.Slice(set.ranges, func(i, j int) bool { return set.ranges[i].from.Less(set.ranges[j].from) })
+	var merged []firewallAddressRange
+	for _, current := range set.ranges {
+		if len(merged) > 0 {
+			last := &merged[len(merged)-1]
+			if last.to.Is4() == current.from.Is4() && (!last.to.Less(current.from) || last.to.Next() == current.from) {
+				if last.to.Less(current.to) {
+					last.to = current.to
+				}
+				continue
+			}
+		}
+		merged = append(merged, current)
+	}
+	set.ranges = merged
+}
+
+func (set firewallAddressSet) isEmpty() bool {
+	return !set.any && len(set.ranges) == 0
+}
+
+func (set firewallAddressSet) contains(address netip.Addr) bool {
+	if set.any {
+		return true
+	}
+	for _, addressRange := range set.ranges {
+		if !address.Less(addressRange.from) && !addressRange.to.Less(address) {
+			return true
+		}
+	}
+	return false
+}
+
+// covers returns true if all addresses of other are in the receiver set. Both sets must be normalised
+func (set firewallAddressSet) covers(other firewallAddressSet) bool {
+	if set.any {
+		return true
+	}
+	if other.any {
+		return false
+	}
+	for _, otherRange := range other.ranges {
+		covered := false
+		for _, addressRange := range set.ranges {
+			if !otherRange.from.Less(addressRange.from) && !addressRange.to.Less(otherRange.to) {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			return false
+		}
+	}
+	return true
+}
+
+// overlaps returns true if the two sets have at least one address in common
+func (set firewallAddressSet) overlaps(other firewallAddressSet) bool {
+	if set.isEmpty() || other.isEmpty() {
+		return false
+	}
+	if set.any || other.any {
+		return true
+	}
+	for _, addressRange := range set.ranges {
+		for _, otherRange := range other.ranges {
+			if !addressRange.to.Less(otherRange.from) && !otherRange.to.Less(addressRange.from) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// firewallService is a protocol with an inclusive range of destination ports
+type firewallService struct {
+	protocol string
+	fromPort int
+	toPort   int
+}
+
+// newFirewallServices converts the ports of an application port profile. Protocols without destination ports match
+// all ports
+func newFirewallServices(ports []types.NsxtAppPortProfilePort) ([]firewallService, error) {
+	var services []firewallService
+	for _, port := range ports {
+		protocol := strings.ToUpper(port.Protocol)
+		if len(port.DestinationPorts) == 0 {
+			services = append(services, firewallService{protocol: protocol, fromPort: 0, toPort: 65535})
+			continue
+		}
+		for _, destinationPort := range port.DestinationPorts {
+			from, to, isRange := strings.Cut(destinationPort, "-")
+			if !isRange {
+				to = from
+			}
+			fromPort, err := strconv.Atoi(strings.TrimSpace(from))
+			if err != nil {
+				return nil, fmt.Errorf("invalid port '%s': %s", destinationPort, err)
+			}
+			toPort, err := strconv.Atoi(strings.TrimSpace(to))
+			if err != nil {
+				return nil, fmt.Errorf("invalid port '%s': %s", destinationPort, err)
+			}
+			services = append(services, firewallService{protocol: protocol, fromPort: fromPort, toPort: toPort})
+		}
+	}
+	return services, nil
+}
+
+func (service firewallService) matches(protocol string, port int) bool {
+	if service.protocol != strings.ToUpper(protocol) {
+		return false
+	}
+	if service.protocol != "TCP" && service.protocol != "UDP" {
+		return true
+	}
+	return port >= service.fromPort && port <= service.toPort
+}
+
+func (service firewallService) covers(other firewallService) bool {
+	return service.protocol == other.protocol && service.fromPort <= other.fromPort && service.toPort >= other.toPort
+}
+
+// FirewallSimulatorRulesFromNsxtFirewall converts NSX-T Edge Gateway firewall rules into simulator rules, in
+// evaluation order: system, user defined and default rules
+func FirewallSimulatorRulesFromNsxtFirewall(container *types.NsxtFirewallRuleContainer) []FirewallSimulatorRule {
+	var rules []FirewallSimulatorRule
+	sections := []struct {
+		name  string
+		rules []*types.NsxtFirewallRule
+	}{
+		{FirewallSectionSystem, container.SystemRules},
+		{FirewallSectionUserDefined, container.UserDefinedRules},
+		{FirewallSectionDefault, container.DefaultRules},
+	}
+	for _, section := range sections {
+		for _, rule := range section.rules {
+			action := rule.ActionValue
+			if action == "" {
+				action = rule.Action
+			}
+			rules = append(rules, FirewallSimulatorRule{
+				ID:                      rule.ID,
+				Name:                    rule.Name,
+				Section:                 section.name,
+				Action:                  action,
+				Enabled:                 rule.Enabled,
+				Direction:               rule.Direction,
+				IpProtocol:              rule.IpProtocol,
+				Sources:                 openApiReferenceIds(rule.SourceFirewallGroups),
+				Destinations:            openApiReferenceIds(rule.DestinationFirewallGroups),
+				ApplicationPortProfiles: openApiReferenceIds(rule.ApplicationPortProfiles),
+			})
+		}
+	}
+	return rules
+}
+
+// FirewallSimulatorRulesFromDistributedFirewall converts Distributed Firewall rules into simulator rules
+func FirewallSimulatorRulesFromDistributedFirewall(dfwRules *types.DistributedFirewallRules) []FirewallSimulatorRule {
+	var rules []FirewallSimulatorRule
+	for _, rule := range dfwRules.Values {
+		action := rule.ActionValue
+		if action == "" {
+			action = rule.Action
+		}
+		rules = append(rules, FirewallSimulatorRule{
+			ID:                      rule.ID,
+			Name:                    rule.Name,
+			Action:                  action,
+			Enabled:                 rule.Enabled,
+			Direction:               rule.Direction,
+			IpProtocol:              rule.IpProtocol,
+			Sources:                 openApiReferenceIds(rule.SourceFirewallGroups),
+			Destinations:            openApiReferenceIds(rule.DestinationFirewallGroups),
+			ApplicationPortProfiles: openApiReferenceIds(rule.ApplicationPortProfiles),
+			NetworkContextProfiles:  openApiReferenceIds(rule.NetworkContextProfiles),
+			SourcesExcluded:         rule.SourceGroupsExcluded != nil && *rule.SourceGroupsExcluded,
+			DestinationsExcluded:    rule.DestinationGroupsExcluded != nil && *rule.DestinationGroupsExcluded,
+		})
+	}
+	return rules
+}
+
+// NewFirewallSimulator creates a FirewallSimulator for the Edge Gateway, resolving firewall groups and application
+// port profiles from VCD. When firewallRules is nil, the current rules of the Edge Gateway are used. Otherwise, the
+// given rules are simulated, which allows to check changes before applying them with UpdateNsxtFirewall.
+func (egw *NsxtEdgeGateway) NewFirewallSimulator(firewallRules *types.NsxtFirewallRuleContainer) (*FirewallSimulator, error) {
+	if firewallRules == nil {
+		firewall, err := egw.GetNsxtFirewall()
+		if err != nil {
+			return nil, err
+		}
+		firewallRules = firewall.NsxtFirewallRuleContainer
+	}
+	return newFirewallSimulatorFromVcd(egw.client, FirewallSimulatorRulesFromNsxtFirewall(firewallRules))
+}
+
+// NewDistributedFirewallSimulator creates a FirewallSimulator for the Distributed Firewall of the VDC Group,
+// resolving firewall groups and application port profiles from VCD. When dfwRules is nil, the current rules are
+// used. Otherwise, the given rules are simulated, which allows to check changes before applying them with
+// UpdateDistributedFirewall.
+func (vdcGroup *VdcGroup) NewDistributedFirewallSimulator(dfwRules *types.DistributedFirewallRules) (*FirewallSimulator, error) {
+	if dfwRules == nil {
+		firewall, err := vdcGroup.GetDistributedFirewall()
+		if err != nil {
+			return nil, err
+		}
+		dfwRules = firewall.DistributedFirewallRuleContainer
+	}
+	simulator, err := newFirewallSimulatorFromVcd(vdcGroup.client, FirewallSimulatorRulesFromDistributedFirewall(dfwRules))
+	if err != nil {
+		return nil, err
+	}
+	// Distributed Firewall rules end with a default rule, which is part of the rule list
+	simulator.DefaultAction = "ALLOW"
+	return simulator, nil
+}
+
+// newFirewallSimulatorFromVcd retrieves all firewall groups and application port profiles referenced by the rules
+func newFirewallSimulatorFromVcd(client *Client, rules []FirewallSimulatorRule) (*FirewallSimulator, error) {
+	groups := make(map[string][]string)
+	profiles := make(map[string][]types.NsxtAppPortProfilePort)
+	for _, rule := range rules {
+		for _, groupId := range append(append([]string{}, rule.Sources...), rule.Destinations...) {
+			if _, ok := groups[groupId]; ok {
+				continue
+			}
+			members, err := getFirewallGroupAddresses(client, groupId)
+			if err != nil {
+				return nil, err
+			}
+			groups[groupId] = members
+		}
+		for _, profileId := range rule.ApplicationPortProfiles {
+			if _, ok := profiles[profileId]; ok {
+				continue
+			}
+			profile, err := getNsxtAppPortProfileById(client, profileId)
+			if err != nil {
+				return nil, fmt.Errorf("error retrieving application port profile '%s': %s", profileId, err)
+			}
+			profiles[profileId] = profile.NsxtAppPortProfile.ApplicationPorts
+		}
+	}
+	return NewFirewallSimulator(rules, groups, profiles)
+}
+
+// getFirewallGroupAddresses returns the addresses of a firewall group. IP Sets contain addresses explicitly, while
+// the addresses of Security Groups are the ones of their associated VMs
+func getFirewallGroupAddresses(client *Client, groupId string) ([]string, error) {
+	group, err := getNsxtFirewallGroupById(client, groupId)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving firewall group '%s': %s", groupId, err)
+	}
+	if !group.IsSecurityGroup() && !group.IsDynamicSecurityGroup() {
+		return group.NsxtFirewallGroup.IpAddresses, nil
+	}
+
+	associatedVms, err := group.GetAssociatedVms()
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving VMs of firewall group '%s': %s", group.NsxtFirewallGroup.Name, err)
+	}
+	var vmRefs []*types.OpenApiReference
+	for _, associatedVm := range associatedVms {
+		if associatedVm.VmRef != nil {
+			vmRefs = append(vmRefs, associatedVm.VmRef)
+		}
+	}
+	// VMs are retrieved concurrently, and each one stores its addresses at its own index
+	vmAddresses := make([][]string, len(vmRefs))
+	items := make([]BatchItem, len(vmRefs))
+	for index, vmRef := range vmRefs {
+		index, vmRef := index, vmRef
+		items[index] = BatchItem{
+			Name: vmRef.Name,
+			Operation: func() (*Task, error) {
+				vm, err := client.GetVMById(vmRef.ID)
+				if err != nil {
+					return nil, err
+				}
+				if vm.VM.NetworkConnectionSection == nil {
+					return nil, nil
+				}
+				for _, connection := range vm.VM.NetworkConnectionSection.NetworkConnection {
+					if connection.IPAddress != "" {
+						vmAddresses[index] = append(vmAddresses[index], connection.IPAddress)
+					}
+				}
+				return nil, nil
+			},
+		}
+	}
+	err = RunBatch(items, nil).Err()
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving VMs of firewall group '%s': %s", group.NsxtFirewallGroup.Name, err)
+	}
+
+	var addresses []string
+	for _, vmAddress := range vmAddresses {
+		addresses = append(addresses, vmAddress...)
+	}
+	return addresses, nil
+}
+
+// openApiReferenceIds returns the IDs of the given references
+func openApiReferenceIds(references []types.OpenApiReference) []string {
+	var ids []string
+	for _, reference := range references {
+		ids = append(ids, reference.ID)
+	}
+	return ids
+}
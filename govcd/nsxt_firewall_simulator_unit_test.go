@@ -0,0 +1,134 @@
+//go:build unit || ALL
+
+/*
+ * Copyright 2025 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+package govcd
+
+import (
+	"testing"
+
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// Test_FirewallSimulator checks rule evaluation and the detection of rules that can never match
+func Test_FirewallSimulator(t *testing.T) {
+	groups := map[string][]string{
+		"web":     {"10.0.0.10", "10.0.0.11"},
+		"subnet":  {"10.0.0.0/24"},
+		"db":      {"10.0.1.5-10.0.1.9"},
+		"empty":   {},
+		"v6hosts": {"2001:db8::/64"},
+	}
+	profiles := map[string][]types.NsxtAppPortProfilePort{
+		"http":  {{Protocol: "TCP", DestinationPorts: []string{"80", "443"}}},
+		"web":   {{Protocol: "TCP", DestinationPorts: []string{"80-8080"}}},
+		"mysql": {{Protocol: "TCP", DestinationPorts: []string{"3306"}}},
+		"icmp":  {{Protocol: "ICMPv4"}},
+	}
+	rules := []FirewallSimulatorRule{
+		{Name: "allow-web", Action: "ALLOW", Enabled: true, Direction: "IN_OUT", IpProtocol: "IPV4_IPV6",
+			Destinations: []string{"subnet"}, ApplicationPortProfiles: []string{"web"}},
+		{Name: "allow-http", Action: "ALLOW", Enabled: true, Direction: "IN", IpProtocol: "IPV4",
+			Sources: []string{"db"}, Destinations: []string{"web"}, ApplicationPortProfiles: []string{"http"}},
+		{Name: "drop-http", Action: "DROP", Enabled: true, Direction: "IN_OUT", IpProtocol: "IPV4",
+			Destinations: []string{"web"}, ApplicationPortProfiles: []string{"http"}},
+		{Name: "allow-db", Action: "ALLOW", Enabled: true, Direction: "IN_OUT", IpProtocol: "IPV4_IPV6",
+			Sources: []string{"subnet"}, Destinations: []string{"db"}, ApplicationPortProfiles: []string{"mysql"}},
+		{Name: "nobody", Action: "ALLOW", Enabled: true, Direction: "IN_OUT", IpProtocol: "IPV4_IPV6",
+			Sources: []string{"empty"}},
+		{Name: "disabled", Action: "ALLOW", Enabled: false, Direction: "IN_OUT", IpProtocol: "IPV4_IPV6"},
+		{Name: "not-from-subnet", Action: "REJECT", Enabled: true, Direction: "IN_OUT", IpProtocol: "IPV4_IPV6",
+			Sources: []string{"subnet"}, SourcesExcluded: true, ApplicationPortProfiles: []string{"icmp"}},
+	}
+	simulator, err := NewFirewallSimulator(rules, groups, profiles)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	tests := []struct {
+		packet   FirewallPacket
+		rule     string
+		allowed  bool
+		position int
+	}{
+		{FirewallPacket{Source: "192.168.1.1", Destination: "10.0.0.10", Protocol: "TCP", Port: 443, Direction: "IN"}, "allow-web", true, 0},
+		{FirewallPacket{Source: "10.0.0.20", Destination: "10.0.1.7", Protocol: "tcp", Port: 3306}, "allow-db", true, 3},
+		{FirewallPacket{Source: "10.0.2.20", Destination: "10.0.1.7", Protocol: "TCP", Port: 3306}, "", false, -1},
+		{FirewallPacket{Source: "192.168.1.1", Destination: "10.0.1.7", Protocol: "ICMPv4"}, "not-from-subnet", false, 6},
+		{FirewallPacket{Source: "10.0.0.1", Destination: "10.0.1.7", Protocol: "ICMPv4"}, "", false, -1},
+	}
+	for _, test := range tests {
+		verdict, err := simulator.Evaluate(test.packet)
+		if err != nil {
+			t.Fatalf("unexpected error evaluating %+v: %s", test.packet, err)
+		}
+		ruleName := ""
+		if verdict.Rule != nil {
+			ruleName = verdict.Rule.Name
+		}
+		if ruleName != test.rule || verdict.Allowed != test.allowed || verdict.Position != test.position {
+			t.Fatalf("packet %+v: expected rule '%s' (allowed %t, position %d), got '%s' (allowed %t, position %d)",
+				test.packet, test.rule, test.allowed, test.position, ruleName, verdict.Allowed, verdict.Position)
+		}
+	}
+
+	_, err = simulator.Evaluate(FirewallPacket{Source: "10.0.0.1", Destination: "2001:db8::1"})
+	if err == nil {
+		t.Fatalf("expected error for mixed IP families")
+	}
+
+	findings := simulator.Analyze()
+	expected := map[string]string{
+		"allow-http": FirewallFindingRedundant,
+		"drop-http":  FirewallFindingShadowed,
+		"nobody":     FirewallFindingUnreachable,
+	}
+	if len(findings) != len(expected) {
+		t.Fatalf("expected %d findings, got %+v", len(expected), findings)
+	}
+	for _, finding := range findings {
+		if expected[finding.Rule.Name] != finding.Kind {
+			t.Fatalf("unexpected finding %s for rule '%s'", finding.Kind, finding.Rule.Name)
+		}
+		if finding.Kind != FirewallFindingUnreachable && finding.ByRule.Name != "allow-web" {
+			t.Fatalf("expected rule '%s' to be shadowed by 'allow-web', got '%s'", finding.Rule.Name, finding.ByRule.Name)
+		}
+	}
+
+	_, err = NewFirewallSimulator([]FirewallSimulatorRule{{Name: "x", Sources: []string{"unknown"}}}, groups, profiles)
+	if err == nil {
+		t.Fatalf("expected error for unknown firewall group")
+	}
+}
+
+// Test_firewallAddressSet checks address parsing, merging and coverage
+func Test_firewallAddressSet(t *testing.T) {
+	set, err := newFirewallAddressSet([]string{"a", "b"}, map[string][]string{
+		"a": {"10.0.0.0/25", "10.0.1.1"},
+		"b": {"10.0.0.128-10.0.0.255"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(set.ranges) != 2 {
+		t.Fatalf("expected adjacent ranges to be merged, got %v", set.ranges)
+	}
+	other, _ := newFirewallAddressSet([]string{"c"}, map[string][]string{"c": {"10.0.0.0/24"}})
+	if !set.covers(other) || other.covers(set) {
+		t.Fatalf("unexpected coverage between %v and %v", set.ranges, other.ranges)
+	}
+	if !set.overlaps(other) {
+		t.Fatalf("expected sets to overlap")
+	}
+
+	prefix, err := parseFirewallAddressRange("2001:db8::/126")
+	if err != nil || prefix.to.String() != "2001:db8::3" {
+		t.Fatalf("unexpected IPv6 prefix range %v (error %v)", prefix, err)
+	}
+	_, err = parseFirewallAddressRange("10.0.0.9-10.0.0.1")
+	if err == nil {
+		t.Fatalf("expected error for inverted range")
+	}
+}
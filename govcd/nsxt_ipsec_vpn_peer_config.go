@@ -0,0 +1,864 @@
+/*
+ * Copyright 2025 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+package govcd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// Remote peer devices supported by GenerateIpSecVpnPeerConfiguration
+const (
+	// IpSecVpnPeerStrongSwan renders a swanctl.conf connection
+	IpSecVpnPeerStrongSwan = "strongswan"
+	// IpSecVpnPeerLibreswan renders an ipsec.conf connection and its secret
+	IpSecVpnPeerLibreswan = "libreswan"
+	// IpSecVpnPeerPfSense renders the values of the pfSense IPsec tunnel settings pages
+	IpSecVpnPeerPfSense = "pfsense"
+	// IpSecVpnPeerCiscoAsa renders Cisco ASA CLI commands for a crypto map based tunnel
+	IpSecVpnPeerCiscoAsa = "cisco-asa"
+	// IpSecVpnPeerFortinet renders FortiOS CLI commands for an interface based tunnel
+	IpSecVpnPeerFortinet = "fortinet"
+	// IpSecVpnPeerAwsVgw renders a JSON document with AWS Site-to-Site VPN tunnel options
+	IpSecVpnPeerAwsVgw = "aws-vgw"
+)
+
+// Values used by NSX-T when the security profile of a tunnel is DEFAULT or a value is not set
+const (
+	ipSecVpnDefaultIkeVersion    = "IKE_V2"
+	ipSecVpnDefaultIkeEncryption = "AES_128"
+	ipSecVpnDefaultIkeDigest     = "SHA2_256"
+	ipSecVpnDefaultDhGroup       = "GROUP14"
+	ipSecVpnDefaultIkeLifetime   = 86400
+	ipSecVpnDefaultEspEncryption = "AES_GCM_128"
+	ipSecVpnDefaultEspLifetime   = 3600
+	ipSecVpnDefaultDpdInterval   = 60
+)
+
+// ipSecVpnPeerParameters is the tunnel configuration seen from the NSX-T side, with defaults applied
+type ipSecVpnPeerParameters struct {
+	name           string
+	localAddress   string
+	localId        string
+	localNetworks  []string
+	remoteAddress  string
+	remoteId       string
+	remoteNetworks []string
+	preSharedKey   string
+	certificate    bool
+	// respondOnly is true when NSX-T does not initiate the tunnel, so the peer must do it
+	respondOnly bool
+
+	ikeVersion string
+	// The algorithm lists are offered by NSX-T in order of preference. validateIpSecVpnPeerParameters sets the
+	// matching single values to the first algorithm of each list that the peer supports
+	ikeEncryptions []string
+	ikeDigests     []string
+	ikeDhGroups    []string
+	espEncryptions []string
+	espDigests     []string
+	espDhGroups    []string
+
+	ikeEncryption string
+	ikeDigest     string
+	ikeDhGroup    string
+	ikeLifetime   int
+	pfs           bool
+	espEncryption string
+	espDigest     string
+	espDhGroup    string
+	espLifetime   int
+	dpdInterval   int
+}
+
+// ipSecVpnPeerCapabilities lists the values a peer can negotiate
+type ipSecVpnPeerCapabilities struct {
+	ikeEncryption []string
+	espEncryption []string
+	digests       []string
+	// ikeV1Digests are the digests supported for IKEv1 phase 1, when they differ from digests
+	ikeV1Digests []string
+	dhGroups     []string
+	// ikeV1EspEncryption are the tunnel encryptions supported with IKEv1, when they differ from espEncryption
+	ikeV1EspEncryption []string
+	// ikeV1Gcm is true when AES-GCM can be used in IKEv1 phase 1
+	ikeV1Gcm       bool
+	certificate    bool
+	requirePfs     bool
+	maxIkeLifetime int
+	maxEspLifetime int
+}
+
+var (
+	ipSecVpnAllIkeEncryption = []string{"AES_128", "AES_256", "AES_GCM_128", "AES_GCM_192", "AES_GCM_256"}
+	ipSecVpnAllDigests       = []string{"SHA1", "SHA2_256", "SHA2_384", "SHA2_512"}
+	ipSecVpnAllDhGroups      = []string{"GROUP2", "GROUP5", "GROUP14", "GROUP15", "GROUP16", "GROUP19", "GROUP20", "GROUP21"}
+	ipSecVpnGcmEncryption    = []string{"AES_GCM_128", "AES_GCM_192", "AES_GCM_256"}
+)
+
+var ipSecVpnPeerCapabilitiesTable = map[string]ipSecVpnPeerCapabilities{
+	IpSecVpnPeerStrongSwan: {
+		ikeEncryption: ipSecVpnAllIkeEncryption,
+		espEncryption: append(append([]string{}, ipSecVpnAllIkeEncryption...), "NO_ENCRYPTION_AUTH_AES_GMAC_128",
+			"NO_ENCRYPTION_AUTH_AES_GMAC_192", "NO_ENCRYPTION_AUTH_AES_GMAC_256", "NO_ENCRYPTION"),
+		digests:     ipSecVpnAllDigests,
+		dhGroups:    ipSecVpnAllDhGroups,
+		certificate: true,
+	},
+	IpSecVpnPeerLibreswan: {
+		ikeEncryption: ipSecVpnAllIkeEncryption,
+		espEncryption: ipSecVpnAllIkeEncryption,
+		digests:       ipSecVpnAllDigests,
+		// MODP-1024 was removed from libreswan
+		dhGroups:    []string{"GROUP5", "GROUP14", "GROUP15", "GROUP16", "GROUP19", "GROUP20", "GROUP21"},
+		certificate: true,
+	},
+	IpSecVpnPeerPfSense: {
+		ikeEncryption: ipSecVpnAllIkeEncryption,
+		espEncryption: ipSecVpnAllIkeEncryption,
+		digests:       ipSecVpnAllDigests,
+		dhGroups:      ipSecVpnAllDhGroups,
+		certificate:   true,
+	},
+	IpSecVpnPeerCiscoAsa: {
+		ikeEncryption: ipSecVpnAllIkeEncryption,
+		espEncryption: append(append([]string{}, ipSecVpnAllIkeEncryption...), "NO_ENCRYPTION_AUTH_AES_GMAC_128",
+			"NO_ENCRYPTION_AUTH_AES_GMAC_192", "NO_ENCRYPTION_AUTH_AES_GMAC_256", "NO_ENCRYPTION"),
+		digests:      ipSecVpnAllDigests,
+		ikeV1Digests: []string{"SHA1"},
+		// crypto ipsec ikev1 transform-set does not support AES-GCM
+		ikeV1EspEncryption: []string{"AES_128", "AES_256", "NO_ENCRYPTION"},
+		// Groups 2 and 5 were removed from recent ASA releases
+		dhGroups:    []string{"GROUP14", "GROUP15", "GROUP16", "GROUP19", "GROUP20", "GROUP21"},
+		certificate: true,
+	},
+	IpSecVpnPeerFortinet: {
+		ikeEncryption: []string{"AES_128", "AES_256", "AES_GCM_128", "AES_GCM_256"},
+		espEncryption: []string{"AES_128", "AES_256", "AES_GCM_128", "AES_GCM_256", "NO_ENCRYPTION"},
+		digests:       ipSecVpnAllDigests,
+		dhGroups:      ipSecVpnAllDhGroups,
+		certificate:   true,
+	},
+	IpSecVpnPeerAwsVgw: {
+		ikeEncryption:  []string{"AES_128", "AES_256", "AES_GCM_128", "AES_GCM_256"},
+		espEncryption:  []string{"AES_128", "AES_256", "AES_GCM_128", "AES_GCM_256"},
+		digests:        ipSecVpnAllDigests,
+		dhGroups:       []string{"GROUP2", "GROUP14", "GROUP15", "GROUP16", "GROUP19", "GROUP20", "GROUP21"},
+		requirePfs:     true,
+		maxIkeLifetime: 28800,
+		maxEspLifetime: 3600,
+	},
+}
+
+// GeneratePeerConfiguration renders the configuration of the remote side of the tunnel for the given peer device,
+// which is one of the IpSecVpnPeer* constants. The security profile is read with GetTunnelConnectionProperties.
+func (ipSecVpn *NsxtIpSecVpnTunnel) GeneratePeerConfiguration(peer string) (string, error) {
+	profile, err := ipSecVpn.GetTunnelConnectionProperties()
+	if err != nil {
+		return "", fmt.Errorf("error retrieving security profile of IPsec VPN tunnel '%s': %s", ipSecVpn.NsxtIpSecVpn.Name, err)
+	}
+	return GenerateIpSecVpnPeerConfiguration(peer, ipSecVpn.NsxtIpSecVpn, profile)
+}
+
+// GenerateIpSecVpnPeerConfiguration renders the configuration of the remote side of an IPsec VPN tunnel for the given
+// peer device. The profile can be nil when the tunnel uses the DEFAULT security type. The result is meant as a
+// starting point: device specific settings, such as interface names, must be reviewed before applying it.
+// It returns an error when the security profile cannot be negotiated by the peer.
+func GenerateIpSecVpnPeerConfiguration(peer string, tunnel *types.NsxtIpSecVpnTunnel, profile *types.NsxtIpSecVpnTunnelSecurityProfile) (string, error) {
+	parameters, err := newIpSecVpnPeerParameters(tunnel, profile)
+	if err != nil {
+		return "", err
+	}
+	err = validateIpSecVpnPeerParameters(peer, parameters)
+	if err != nil {
+		return "", err
+	}
+
+	switch peer {
+	case IpSecVpnPeerStrongSwan:
+		return renderStrongSwanPeer(parameters), nil
+	case IpSecVpnPeerLibreswan:
+		return renderLibreswanPeer(parameters), nil
+	case IpSecVpnPeerPfSense:
+		return renderPfSensePeer(parameters), nil
+	case IpSecVpnPeerCiscoAsa:
+		return renderCiscoAsaPeer(parameters)
+	case IpSecVpnPeerFortinet:
+		return renderFortinetPeer(parameters)
+	}
+	return renderAwsVgwPeer(parameters)
+}
+
+// ValidateIpSecVpnPeerProfile checks that the security profile of the tunnel can be negotiated by the peer device.
+// The profile can be nil when the tunnel uses the DEFAULT security type
+func ValidateIpSecVpnPeerProfile(peer string, tunnel *types.NsxtIpSecVpnTunnel, profile *types.NsxtIpSecVpnTunnelSecurityProfile) error {
+	parameters, err := newIpSecVpnPeerParameters(tunnel, profile)
+	if err != nil {
+		return err
+	}
+	return validateIpSecVpnPeerParameters(peer, parameters)
+}
+
+// newIpSecVpnPeerParameters merges the tunnel and its security profile, applying NSX-T defaults
+func newIpSecVpnPeerParameters(tunnel *types.NsxtIpSecVpnTunnel, profile *types.NsxtIpSecVpnTunnelSecurityProfile) (*ipSecVpnPeerParameters, error) {
+	if tunnel == nil {
+		return nil, fmt.Errorf("IPsec VPN tunnel cannot be nil")
+	}
+	if tunnel.LocalEndpoint.LocalAddress == "" || tunnel.RemoteEndpoint.RemoteAddress == "" {
+		return nil, fmt.Errorf("IPsec VPN tunnel '%s' requires local and remote addresses", tunnel.Name)
+	}
+	if len(tunnel.LocalEndpoint.LocalNetworks) == 0 {
+		return nil, fmt.Errorf("IPsec VPN tunnel '%s' requires local networks", tunnel.Name)
+	}
+
+	parameters := &ipSecVpnPeerParameters{
+		name:           tunnel.Name,
+		localAddress:   tunnel.LocalEndpoint.LocalAddress,
+		localId:        firstNonEmpty(tunnel.LocalEndpoint.LocalId, tunnel.LocalEndpoint.LocalAddress),
+		localNetworks:  tunnel.LocalEndpoint.LocalNetworks,
+		remoteAddress:  tunnel.RemoteEndpoint.RemoteAddress,
+		remoteId:       firstNonEmpty(tunnel.RemoteEndpoint.RemoteId, tunnel.RemoteEndpoint.RemoteAddress),
+		remoteNetworks: tunnel.RemoteEndpoint.RemoteNetworks,
+		preSharedKey:   tunnel.PreSharedKey,
+		certificate:    tunnel.AuthenticationMode == "CERTIFICATE",
+		respondOnly:    tunnel.ConnectorInitiationMode == "RESPOND_ONLY",
+		ikeVersion:     ipSecVpnDefaultIkeVersion,
+		ikeEncryptions: []string{ipSecVpnDefaultIkeEncryption},
+		ikeDigests:     []string{ipSecVpnDefaultIkeDigest},
+		ikeDhGroups:    []string{ipSecVpnDefaultDhGroup},
+		ikeLifetime:    ipSecVpnDefaultIkeLifetime,
+		pfs:            true,
+		espEncryptions: []string{ipSecVpnDefaultEspEncryption},
+		espDhGroups:    []string{ipSecVpnDefaultDhGroup},
+		espLifetime:    ipSecVpnDefaultEspLifetime,
+		dpdInterval:    ipSecVpnDefaultDpdInterval,
+	}
+	if len(parameters.remoteNetworks) == 0 {
+		parameters.remoteNetworks = []string{"0.0.0.0/0"}
+	}
+	if parameters.preSharedKey == "" {
+		parameters.preSharedKey = "<pre-shared key>"
+	}
+
+	if profile == nil || profile.SecurityType == "DEFAULT" {
+		return parameters, nil
+	}
+	ike := profile.IkeConfiguration
+	parameters.ikeVersion = firstNonEmpty(ike.IkeVersion, parameters.ikeVersion)
+	parameters.ikeEncryptions = nonEmptyOr(ike.EncryptionAlgorithms, parameters.ikeEncryptions)
+	parameters.ikeDigests = nonEmptyOr(ike.DigestAlgorithms, parameters.ikeDigests)
+	parameters.ikeDhGroups = nonEmptyOr(ike.DhGroups, parameters.ikeDhGroups)
+	if ike.SaLifeTime != nil {
+		parameters.ikeLifetime = *ike.SaLifeTime
+	}
+	esp := profile.TunnelConfiguration
+	parameters.pfs = esp.PerfectForwardSecrecyEnabled
+	parameters.espEncryptions = nonEmptyOr(esp.EncryptionAlgorithms, parameters.espEncryptions)
+	parameters.espDigests = esp.DigestAlgorithms
+	parameters.espDhGroups = nonEmptyOr(esp.DhGroups, parameters.espDhGroups)
+	if esp.SaLifeTime != nil {
+		parameters.espLifetime = *esp.SaLifeTime
+	}
+	if profile.DpdConfiguration.ProbeInterval > 0 {
+		parameters.dpdInterval = profile.DpdConfiguration.ProbeInterval
+	}
+	return parameters, nil
+}
+
+// validateIpSecVpnPeerParameters selects, for each algorithm list, the first algorithm the peer supports. It returns an
+// error listing all the settings the peer cannot negotiate
+func validateIpSecVpnPeerParameters(peer string, parameters *ipSecVpnPeerParameters) error {
+	capabilities, found := ipSecVpnPeerCapabilitiesTable[peer]
+	if !found {
+		return fmt.Errorf("unknown IPsec VPN peer '%s'", peer)
+	}
+	var problems []string
+	negotiate := func(setting string, offered, supported []string) string {
+		for _, value := range offered {
+			if contains(value, supported) {
+				return value
+			}
+		}
+		if len(offered) > 0 {
+			problems = append(problems, fmt.Sprintf("%s %s is not supported (supported: %s)", setting,
+				strings.Join(offered, ", "), strings.Join(supported, ", ")))
+		}
+		return ""
+	}
+
+	ikeEncryptions := capabilities.ikeEncryption
+	ikeDigests := capabilities.digests
+	espEncryptions := capabilities.espEncryption
+	ikeEncryptionSetting, espEncryptionSetting := "IKE encryption", "tunnel encryption"
+	if parameters.ikeVersion == "IKE_V1" {
+		if !capabilities.ikeV1Gcm {
+			ikeEncryptionSetting = "IKE_V1 encryption"
+			ikeEncryptions = nil
+			for _, encryption := range capabilities.ikeEncryption {
+				if !contains(encryption, ipSecVpnGcmEncryption) {
+					ikeEncryptions = append(ikeEncryptions, encryption)
+				}
+			}
+		}
+		if capabilities.ikeV1Digests != nil {
+			ikeDigests = capabilities.ikeV1Digests
+		}
+		if capabilities.ikeV1EspEncryption != nil {
+			espEncryptionSetting = "IKE_V1 tunnel encryption"
+			espEncryptions = capabilities.ikeV1EspEncryption
+		}
+	}
+	parameters.ikeEncryption = negotiate(ikeEncryptionSetting, parameters.ikeEncryptions, ikeEncryptions)
+	parameters.ikeDigest = negotiate("IKE digest", parameters.ikeDigests, ikeDigests)
+	parameters.ikeDhGroup = negotiate("IKE Diffie-Hellman group", parameters.ikeDhGroups, capabilities.dhGroups)
+	parameters.espEncryption = negotiate(espEncryptionSetting, parameters.espEncryptions, espEncryptions)
+	parameters.espDigest = negotiate("tunnel digest", parameters.espDigests, capabilities.digests)
+	if parameters.pfs {
+		parameters.espDhGroup = negotiate("tunnel Diffie-Hellman group", parameters.espDhGroups, capabilities.dhGroups)
+	} else if capabilities.requirePfs {
+		problems = append(problems, "Perfect Forward Secrecy must be enabled")
+	}
+	if parameters.certificate && !capabilities.certificate {
+		problems = append(problems, "certificate authentication is not supported")
+	}
+	if capabilities.maxIkeLifetime > 0 && parameters.ikeLifetime > capabilities.maxIkeLifetime {
+		problems = append(problems, fmt.Sprintf("IKE SA lifetime %d exceeds the maximum of %d seconds", parameters.ikeLifetime, capabilities.maxIkeLifetime))
+	}
+	if capabilities.maxEspLifetime > 0 && parameters.espLifetime > capabilities.maxEspLifetime {
+		problems = append(problems, fmt.Sprintf("tunnel SA lifetime %d exceeds the maximum of %d seconds", parameters.espLifetime, capabilities.maxEspLifetime))
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("IPsec VPN tunnel '%s' cannot be negotiated by %s peer: %s", parameters.name, peer, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+func renderStrongSwanPeer(p *ipSecVpnPeerParameters) string {
+	encryption := map[string]string{
+		"AES_128": "aes128", "AES_256": "aes256", "AES_GCM_128": "aes128gcm16", "AES_GCM_192": "aes192gcm16",
+		"AES_GCM_256": "aes256gcm16", "NO_ENCRYPTION_AUTH_AES_GMAC_128": "aes128gmac",
+		"NO_ENCRYPTION_AUTH_AES_GMAC_192": "aes192gmac", "NO_ENCRYPTION_AUTH_AES_GMAC_256": "aes256gmac", "NO_ENCRYPTION": "null",
+	}
+	digest := map[string]string{"SHA1": "sha1", "SHA2_256": "sha256", "SHA2_384": "sha384", "SHA2_512": "sha512"}
+	dhGroup := map[string]string{
+		"GROUP2": "modp1024", "GROUP5": "modp1536", "GROUP14": "modp2048", "GROUP15": "modp3072", "GROUP16": "modp4096",
+		"GROUP19": "ecp256", "GROUP20": "ecp384", "GROUP21": "ecp521",
+	}
+
+	ikeProposal := encryption[p.ikeEncryption] + "-" + digest[p.ikeDigest]
+	if contains(p.ikeEncryption, ipSecVpnGcmEncryption) {
+		ikeProposal = encryption[p.ikeEncryption] + "-prf" + digest[p.ikeDigest]
+	}
+	ikeProposal += "-" + dhGroup[p.ikeDhGroup]
+	espProposal := encryption[p.espEncryption]
+	if p.espDigest != "" {
+		espProposal += "-" + digest[p.espDigest]
+	}
+	if p.pfs {
+		espProposal += "-" + dhGroup[p.espDhGroup]
+	}
+	auth := "psk"
+	if p.certificate {
+		auth = "pubkey"
+	}
+	version := "2"
+	if p.ikeVersion == "IKE_V1" {
+		version = "1"
+	}
+	startAction := "trap"
+	if p.respondOnly {
+		startAction = "start"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# strongSwan swanctl.conf for NSX-T IPsec VPN tunnel '%s'\n", p.name)
+	b.WriteString("connections {\n")
+	fmt.Fprintf(&b, "  %s {\n", strongSwanName(p.name))
+	fmt.Fprintf(&b, "    version = %s\n", version)
+	fmt.Fprintf(&b, "    local_addrs = %s\n", p.remoteAddress)
+	fmt.Fprintf(&b, "    remote_addrs = %s\n", p.localAddress)
+	fmt.Fprintf(&b, "    proposals = %s\n", ikeProposal)
+	fmt.Fprintf(&b, "    rekey_time = %ds\n", p.ikeLifetime)
+	fmt.Fprintf(&b, "    dpd_delay = %ds\n", p.dpdInterval)
+	b.WriteString("    local {\n")
+	fmt.Fprintf(&b, "      auth = %s\n", auth)
+	fmt.Fprintf(&b, "      id = %s\n", p.remoteId)
+	if p.certificate {
+		b.WriteString("      certs = <local certificate file>\n")
+	}
+	b.WriteString("    }\n    remote {\n")
+	fmt.Fprintf(&b, "      auth = %s\n", auth)
+	fmt.Fprintf(&b, "      id = %s\n", p.localId)
+	b.WriteString("    }\n    children {\n")
+	fmt.Fprintf(&b, "      %s {\n", strongSwanName(p.name))
+	fmt.Fprintf(&b, "        local_ts = %s\n", strings.Join(p.remoteNetworks, ","))
+	fmt.Fprintf(&b, "        remote_ts = %s\n", strings.Join(p.localNetworks, ","))
+	fmt.Fprintf(&b, "        esp_proposals = %s\n", espProposal)
+	fmt.Fprintf(&b, "        rekey_time = %ds\n", p.espLifetime)
+	b.WriteString("        dpd_action = restart\n")
+	fmt.Fprintf(&b, "        start_action = %s\n", startAction)
+	b.WriteString("      }\n    }\n  }\n}\n")
+	if !p.certificate {
+		b.WriteString("secrets {\n")
+		fmt.Fprintf(&b, "  ike-%s {\n", strongSwanName(p.name))
+		fmt.Fprintf(&b, "    id-local = %s\n", p.remoteId)
+		fmt.Fprintf(&b, "    id-remote = %s\n", p.localId)
+		fmt.Fprintf(&b, "    secret = %q\n", p.preSharedKey)
+		b.WriteString("  }\n}\n")
+	}
+	return b.String()
+}
+
+func renderLibreswanPeer(p *ipSecVpnPeerParameters) string {
+	encryption := map[string]string{
+		"AES_128": "aes128", "AES_256": "aes256", "AES_GCM_128": "aes_gcm128", "AES_GCM_192": "aes_gcm192", "AES_GCM_256": "aes_gcm256",
+	}
+	digest := map[string]string{"SHA1": "sha1", "SHA2_256": "sha2_256", "SHA2_384": "sha2_384", "SHA2_512": "sha2_512"}
+	dhGroup := map[string]string{
+		"GROUP5": "modp1536", "GROUP14": "modp2048", "GROUP15": "modp3072", "GROUP16": "modp4096",
+		"GROUP19": "dh19", "GROUP20": "dh20", "GROUP21": "dh21",
+	}
+
+	esp := encryption[p.espEncryption]
+	if p.espDigest != "" {
+		esp += "-" + digest[p.espDigest]
+	}
+	if p.pfs {
+		esp += ";" + dhGroup[p.espDhGroup]
+	}
+	name := strongSwanName(p.name)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# libreswan ipsec.conf for NSX-T IPsec VPN tunnel '%s'\n", p.name)
+	fmt.Fprintf(&b, "conn %s\n", name)
+	if p.certificate {
+		b.WriteString("    authby=rsasig\n    leftcert=<local certificate nickname>\n")
+	} else {
+		b.WriteString("    authby=secret\n")
+	}
+	b.WriteString("    auto=start\n")
+	fmt.Fprintf(&b, "    ikev2=%s\n", yesNo(p.ikeVersion != "IKE_V1"))
+	fmt.Fprintf(&b, "    left=%s\n", p.remoteAddress)
+	fmt.Fprintf(&b, "    leftid=%s\n", p.remoteId)
+	fmt.Fprintf(&b, "    leftsubnets={%s}\n", strings.Join(p.remoteNetworks, " "))
+	fmt.Fprintf(&b, "    right=%s\n", p.localAddress)
+	fmt.Fprintf(&b, "    rightid=%s\n", p.localId)
+	fmt.Fprintf(&b, "    rightsubnets={%s}\n", strings.Join(p.localNetworks, " "))
+	fmt.Fprintf(&b, "    ike=%s-%s;%s\n", encryption[p.ikeEncryption], digest[p.ikeDigest], dhGroup[p.ikeDhGroup])
+	fmt.Fprintf(&b, "    esp=%s\n", esp)
+	fmt.Fprintf(&b, "    pfs=%s\n", yesNo(p.pfs))
+	fmt.Fprintf(&b, "    ikelifetime=%ds\n", p.ikeLifetime)
+	fmt.Fprintf(&b, "    salifetime=%ds\n", p.espLifetime)
+	fmt.Fprintf(&b, "    dpddelay=%d\n", p.dpdInterval)
+	fmt.Fprintf(&b, "    dpdtimeout=%d\n", p.dpdInterval*4)
+	b.WriteString("    dpdaction=restart\n")
+	if !p.certificate {
+		fmt.Fprintf(&b, "\n# ipsec.secrets\n%s %s : PSK %q\n", p.remoteId, p.localId, p.preSharedKey)
+	}
+	return b.String()
+}
+
+func renderPfSensePeer(p *ipSecVpnPeerParameters) string {
+	encryption := map[string]string{
+		"AES_128": "AES, 128 bits", "AES_256": "AES, 256 bits", "AES_GCM_128": "AES128-GCM, 128 bits",
+		"AES_GCM_192": "AES192-GCM, 128 bits", "AES_GCM_256": "AES256-GCM, 128 bits",
+	}
+	digest := map[string]string{"SHA1": "SHA1", "SHA2_256": "SHA256", "SHA2_384": "SHA384", "SHA2_512": "SHA512"}
+	dhGroup := map[string]string{
+		"GROUP2": "2 (1024 bit)", "GROUP5": "5 (1536 bit)", "GROUP14": "14 (2048 bit)", "GROUP15": "15 (3072 bit)",
+		"GROUP16": "16 (4096 bit)", "GROUP19": "19 (nist ecp256)", "GROUP20": "20 (nist ecp384)", "GROUP21": "21 (nist ecp521)",
+	}
+	version := "IKEv2"
+	if p.ikeVersion == "IKE_V1" {
+		version = "IKEv1"
+	}
+	authentication := "Mutual PSK"
+	if p.certificate {
+		authentication = "Mutual RSA"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# pfSense VPN / IPsec settings for NSX-T IPsec VPN tunnel '%s'\n", p.name)
+	b.WriteString("[Phase 1]\n")
+	fmt.Fprintf(&b, "Description: %s\n", p.name)
+	fmt.Fprintf(&b, "Key Exchange version: %s\n", version)
+	fmt.Fprintf(&b, "Remote Gateway: %s\n", p.localAddress)
+	fmt.Fprintf(&b, "Authentication Method: %s\n", authentication)
+	fmt.Fprintf(&b, "My identifier: IP address / distinguished name: %s\n", p.remoteId)
+	fmt.Fprintf(&b, "Peer identifier: IP address / distinguished name: %s\n", p.localId)
+	if !p.certificate {
+		fmt.Fprintf(&b, "Pre-Shared Key: %s\n", p.preSharedKey)
+	}
+	fmt.Fprintf(&b, "Encryption Algorithm: %s\n", encryption[p.ikeEncryption])
+	fmt.Fprintf(&b, "Hash: %s\n", digest[p.ikeDigest])
+	fmt.Fprintf(&b, "DH Group: %s\n", dhGroup[p.ikeDhGroup])
+	fmt.Fprintf(&b, "Life Time: %d\n", p.ikeLifetime)
+	fmt.Fprintf(&b, "Dead Peer Detection: enabled, delay %d\n", p.dpdInterval)
+	index := 0
+	for _, localNetwork := range p.remoteNetworks {
+		for _, remoteNetwork := range p.localNetworks {
+			index++
+			fmt.Fprintf(&b, "\n[Phase 2 #%d]\n", index)
+			b.WriteString("Mode: Tunnel\n")
+			fmt.Fprintf(&b, "Local Network: %s\n", localNetwork)
+			fmt.Fprintf(&b, "Remote Network: %s\n", remoteNetwork)
+			b.WriteString("Protocol: ESP\n")
+			fmt.Fprintf(&b, "Encryption Algorithms: %s\n", encryption[p.espEncryption])
+			if p.espDigest != "" {
+				fmt.Fprintf(&b, "Hash Algorithms: %s\n", digest[p.espDigest])
+			}
+			if p.pfs {
+				fmt.Fprintf(&b, "PFS key group: %s\n", dhGroup[p.espDhGroup])
+			} else {
+				b.WriteString("PFS key group: off\n")
+			}
+			fmt.Fprintf(&b, "Life Time: %d\n", p.espLifetime)
+		}
+	}
+	return b.String()
+}
+
+func renderCiscoAsaPeer(p *ipSecVpnPeerParameters) (string, error) {
+	ikeV2Encryption := map[string]string{
+		"AES_128": "aes", "AES_256": "aes-256", "AES_GCM_128": "aes-gcm", "AES_GCM_192": "aes-gcm-192", "AES_GCM_256": "aes-gcm-256",
+	}
+	espV2Encryption := map[string]string{
+		"AES_128": "aes", "AES_256": "aes-256", "AES_GCM_128": "aes-gcm", "AES_GCM_192": "aes-gcm-192", "AES_GCM_256": "aes-gcm-256",
+		"NO_ENCRYPTION_AUTH_AES_GMAC_128": "aes-gmac", "NO_ENCRYPTION_AUTH_AES_GMAC_192": "aes-gmac-192",
+		"NO_ENCRYPTION_AUTH_AES_GMAC_256": "aes-gmac-256", "NO_ENCRYPTION": "null",
+	}
+	ikeV2Digest := map[string]string{"SHA1": "sha", "SHA2_256": "sha256", "SHA2_384": "sha384", "SHA2_512": "sha512"}
+	espV2Digest := map[string]string{"SHA1": "sha-1", "SHA2_256": "sha-256", "SHA2_384": "sha-384", "SHA2_512": "sha-512"}
+	espV1Encryption := map[string]string{"AES_128": "esp-aes", "AES_256": "esp-aes-256", "NO_ENCRYPTION": "esp-null"}
+	espV1Digest := map[string]string{"SHA1": "esp-sha-hmac", "SHA2_256": "esp-sha256-hmac", "SHA2_384": "esp-sha384-hmac", "SHA2_512": "esp-sha512-hmac"}
+	name := strongSwanName(p.name)
+	ikeV1 := p.ikeVersion == "IKE_V1"
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "! Cisco ASA configuration for NSX-T IPsec VPN tunnel '%s'\n", p.name)
+	fmt.Fprintf(&b, "object-group network %s-local\n", name)
+	for _, network := range p.remoteNetworks {
+		objectNetwork, err := ciscoAsaNetworkObject(network)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&b, " network-object %s\n", objectNetwork)
+	}
+	fmt.Fprintf(&b, "object-group network %s-remote\n", name)
+	for _, network := range p.localNetworks {
+		objectNetwork, err := ciscoAsaNetworkObject(network)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&b, " network-object %s\n", objectNetwork)
+	}
+	fmt.Fprintf(&b, "access-list %s-acl extended permit ip object-group %s-local object-group %s-remote\n", name, name, name)
+
+	if ikeV1 {
+		b.WriteString("crypto ikev1 policy 10\n")
+		if p.certificate {
+			b.WriteString(" authentication rsa-sig\n")
+		} else {
+			b.WriteString(" authentication pre-share\n")
+		}
+		fmt.Fprintf(&b, " encryption %s\n", ikeV2Encryption[p.ikeEncryption])
+		b.WriteString(" hash sha\n")
+		fmt.Fprintf(&b, " group %s\n", strings.TrimPrefix(p.ikeDhGroup, "GROUP"))
+		fmt.Fprintf(&b, " lifetime %d\n", p.ikeLifetime)
+		b.WriteString("crypto ikev1 enable outside\n")
+		fmt.Fprintf(&b, "crypto ipsec ikev1 transform-set %s %s", name, espV1Encryption[p.espEncryption])
+		if p.espDigest != "" {
+			fmt.Fprintf(&b, " %s", espV1Digest[p.espDigest])
+		}
+		b.WriteString("\n")
+	} else {
+		b.WriteString("crypto ikev2 policy 10\n")
+		fmt.Fprintf(&b, " encryption %s\n", ikeV2Encryption[p.ikeEncryption])
+		if contains(p.ikeEncryption, ipSecVpnGcmEncryption) {
+			b.WriteString(" integrity null\n")
+		} else {
+			fmt.Fprintf(&b, " integrity %s\n", ikeV2Digest[p.ikeDigest])
+		}
+		fmt.Fprintf(&b, " group %s\n", strings.TrimPrefix(p.ikeDhGroup, "GROUP"))
+		fmt.Fprintf(&b, " prf %s\n", ikeV2Digest[p.ikeDigest])
+		fmt.Fprintf(&b, " lifetime seconds %d\n", p.ikeLifetime)
+		b.WriteString("crypto ikev2 enable outside\n")
+		fmt.Fprintf(&b, "crypto ipsec ikev2 ipsec-proposal %s\n", name)
+		fmt.Fprintf(&b, " protocol esp encryption %s\n", espV2Encryption[p.espEncryption])
+		if p.espDigest != "" {
+			fmt.Fprintf(&b, " protocol esp integrity %s\n", espV2Digest[p.espDigest])
+		} else {
+			b.WriteString(" protocol esp integrity null\n")
+		}
+	}
+
+	fmt.Fprintf(&b, "crypto map outside_map 10 match address %s-acl\n", name)
+	fmt.Fprintf(&b, "crypto map outside_map 10 set peer %s\n", p.localAddress)
+	if ikeV1 {
+		fmt.Fprintf(&b, "crypto map outside_map 10 set ikev1 transform-set %s\n", name)
+	} else {
+		fmt.Fprintf(&b, "crypto map outside_map 10 set ikev2 ipsec-proposal %s\n", name)
+	}
+	if p.pfs {
+		fmt.Fprintf(&b, "crypto map outside_map 10 set pfs group%s\n", strings.TrimPrefix(p.espDhGroup, "GROUP"))
+	}
+	fmt.Fprintf(&b, "crypto map outside_map 10 set security-association lifetime seconds %d\n", p.espLifetime)
+	if p.certificate {
+		b.WriteString("crypto map outside_map 10 set trustpoint <trustpoint>\n")
+	}
+	b.WriteString("crypto map outside_map interface outside\n")
+
+	fmt.Fprintf(&b, "tunnel-group %s type ipsec-l2l\n", p.localAddress)
+	fmt.Fprintf(&b, "tunnel-group %s ipsec-attributes\n", p.localAddress)
+	switch {
+	case ikeV1 && p.certificate:
+		b.WriteString(" ikev1 trust-point <trustpoint>\n")
+	case ikeV1:
+		fmt.Fprintf(&b, " ikev1 pre-shared-key %s\n", p.preSharedKey)
+	case p.certificate:
+		b.WriteString(" ikev2 remote-authentication certificate\n")
+		b.WriteString(" ikev2 local-authentication certificate <trustpoint>\n")
+	default:
+		fmt.Fprintf(&b, " ikev2 remote-authentication pre-shared-key %s\n", p.preSharedKey)
+		fmt.Fprintf(&b, " ikev2 local-authentication pre-shared-key %s\n", p.preSharedKey)
+	}
+	fmt.Fprintf(&b, " isakmp keepalive threshold %d retry 2\n", p.dpdInterval)
+	return b.String(), nil
+}
+
+func renderFortinetPeer(p *ipSecVpnPeerParameters) (string, error) {
+	encryption := map[string]string{
+		"AES_128": "aes128", "AES_256": "aes256", "AES_GCM_128": "aes128gcm", "AES_GCM_256": "aes256gcm", "NO_ENCRYPTION": "null",
+	}
+	digest := map[string]string{"SHA1": "sha1", "SHA2_256": "sha256", "SHA2_384": "sha384", "SHA2_512": "sha512"}
+
+	ikeProposal := encryption[p.ikeEncryption] + "-" + digest[p.ikeDigest]
+	if contains(p.ikeEncryption, ipSecVpnGcmEncryption) {
+		ikeProposal = encryption[p.ikeEncryption] + "-prf" + digest[p.ikeDigest]
+	}
+	espProposal := encryption[p.espEncryption]
+	if p.espDigest != "" {
+		espProposal += "-" + digest[p.espDigest]
+	}
+	version := "2"
+	if p.ikeVersion == "IKE_V1" {
+		version = "1"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# FortiOS configuration for NSX-T IPsec VPN tunnel '%s'\n", p.name)
+	b.WriteString("config vpn ipsec phase1-interface\n")
+	fmt.Fprintf(&b, "    edit %q\n", p.name)
+	b.WriteString("        set interface \"wan1\"\n")
+	fmt.Fprintf(&b, "        set ike-version %s\n", version)
+	b.WriteString("        set peertype any\n")
+	fmt.Fprintf(&b, "        set proposal %s\n", ikeProposal)
+	fmt.Fprintf(&b, "        set dhgrp %s\n", strings.TrimPrefix(p.ikeDhGroup, "GROUP"))
+	fmt.Fprintf(&b, "        set remote-gw %s\n", p.localAddress)
+	fmt.Fprintf(&b, "        set localid %q\n", p.remoteId)
+	if p.certificate {
+		b.WriteString("        set authmethod signature\n")
+		b.WriteString("        set certificate \"<local certificate>\"\n")
+	} else {
+		fmt.Fprintf(&b, "        set psksecret %s\n", p.preSharedKey)
+	}
+	fmt.Fprintf(&b, "        set keylife %d\n", p.ikeLifetime)
+	b.WriteString("        set dpd on-idle\n")
+	fmt.Fprintf(&b, "        set dpd-retryinterval %d\n", p.dpdInterval)
+	b.WriteString("    next\nend\n")
+
+	b.WriteString("config vpn ipsec phase2-interface\n")
+	index := 0
+	for _, localNetwork := range p.remoteNetworks {
+		for _, remoteNetwork := range p.localNetworks {
+			index++
+			source, err := fortinetSubnet(localNetwork)
+			if err != nil {
+				return "", err
+			}
+			destination, err := fortinetSubnet(remoteNetwork)
+			if err != nil {
+				return "", err
+			}
+			fmt.Fprintf(&b, "    edit \"%s-%d\"\n", p.name, index)
+			fmt.Fprintf(&b, "        set phase1name %q\n", p.name)
+			fmt.Fprintf(&b, "        set proposal %s\n", espProposal)
+			if p.pfs {
+				b.WriteString("        set pfs enable\n")
+				fmt.Fprintf(&b, "        set dhgrp %s\n", strings.TrimPrefix(p.espDhGroup, "GROUP"))
+			} else {
+				b.WriteString("        set pfs disable\n")
+			}
+			fmt.Fprintf(&b, "        set keylifeseconds %d\n", p.espLifetime)
+			fmt.Fprintf(&b, "        set %s\n", source.setting("src"))
+			fmt.Fprintf(&b, "        set %s\n", destination.setting("dst"))
+			b.WriteString("    next\n")
+		}
+	}
+	b.WriteString("end\n")
+	return b.String(), nil
+}
+
+// awsVgwTunnelOptions follows the AWS Site-to-Site VPN VpnTunnelOptionsSpecification structure
+type awsVgwTunnelOptions struct {
+	PreSharedKey               string           `json:"PreSharedKey,omitempty"`
+	Phase1LifetimeSeconds      int              `json:"Phase1LifetimeSeconds"`
+	Phase2LifetimeSeconds      int              `json:"Phase2LifetimeSeconds"`
+	DPDTimeoutSeconds          int              `json:"DPDTimeoutSeconds"`
+	Phase1EncryptionAlgorithms []awsVgwValue    `json:"Phase1EncryptionAlgorithms"`
+	Phase2EncryptionAlgorithms []awsVgwValue    `json:"Phase2EncryptionAlgorithms"`
+	Phase1IntegrityAlgorithms  []awsVgwValue    `json:"Phase1IntegrityAlgorithms"`
+	Phase2IntegrityAlgorithms  []awsVgwValue    `json:"Phase2IntegrityAlgorithms,omitempty"`
+	Phase1DHGroupNumbers       []awsVgwIntValue `json:"Phase1DHGroupNumbers"`
+	Phase2DHGroupNumbers       []awsVgwIntValue `json:"Phase2DHGroupNumbers"`
+	IKEVersions                []awsVgwValue    `json:"IKEVersions"`
+	StartupAction              string           `json:"StartupAction"`
+}
+
+type awsVgwValue struct {
+	Value string `json:"Value"`
+}
+
+type awsVgwIntValue struct {
+	Value int `json:"Value"`
+}
+
+func renderAwsVgwPeer(p *ipSecVpnPeerParameters) (string, error) {
+	encryption := map[string]string{"AES_128": "AES128", "AES_256": "AES256", "AES_GCM_128": "AES128-GCM-16", "AES_GCM_256": "AES256-GCM-16"}
+	digest := map[string]string{"SHA1": "SHA1", "SHA2_256": "SHA2-256", "SHA2_384": "SHA2-384", "SHA2_512": "SHA2-512"}
+	dhGroupNumber := func(group string) int {
+		var number int
+		_, _ = fmt.Sscanf(group, "GROUP%d", &number)
+		return number
+	}
+
+	options := awsVgwTunnelOptions{
+		Phase1LifetimeSeconds:      p.ikeLifetime,
+		Phase2LifetimeSeconds:      p.espLifetime,
+		DPDTimeoutSeconds:          max(30, p.dpdInterval*3),
+		Phase1EncryptionAlgorithms: []awsVgwValue{{encryption[p.ikeEncryption]}},
+		Phase2EncryptionAlgorithms: []awsVgwValue{{encryption[p.espEncryption]}},
+		Phase1IntegrityAlgorithms:  []awsVgwValue{{digest[p.ikeDigest]}},
+		Phase1DHGroupNumbers:       []awsVgwIntValue{{dhGroupNumber(p.ikeDhGroup)}},
+		Phase2DHGroupNumbers:       []awsVgwIntValue{{dhGroupNumber(p.espDhGroup)}},
+		IKEVersions:                []awsVgwValue{{"ikev2"}},
+		StartupAction:              "add",
+	}
+	if !p.certificate {
+		options.PreSharedKey = p.preSharedKey
+	}
+	if p.espDigest != "" {
+		options.Phase2IntegrityAlgorithms = []awsVgwValue{{digest[p.espDigest]}}
+	}
+	if p.ikeVersion == "IKE_V1" {
+		options.IKEVersions = []awsVgwValue{{"ikev1"}}
+	}
+	if p.respondOnly {
+		options.StartupAction = "start"
+	}
+
+	document := struct {
+		Description                       string              `json:"Description"`
+		CustomerGatewayIpAddress          string              `json:"CustomerGatewayIpAddress"`
+		StaticRoutesDestinationCidrBlocks []string            `json:"StaticRoutesDestinationCidrBlocks"`
+		TunnelOptions                     awsVgwTunnelOptions `json:"TunnelOptions"`
+	}{
+		Description:                       fmt.Sprintf("NSX-T IPsec VPN tunnel '%s'", p.name),
+		CustomerGatewayIpAddress:          p.localAddress,
+		StaticRoutesDestinationCidrBlocks: p.localNetworks,
+		TunnelOptions:                     options,
+	}
+	text, err := json.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(text) + "\n", nil
+}
+
+// ciscoAsaNetworkObject converts a CIDR into an ASA network object, using a netmask for IPv4
+func ciscoAsaNetworkObject(network string) (string, error) {
+	prefix, err := netip.ParsePrefix(network)
+	if err != nil {
+		return "", fmt.Errorf("error parsing network '%s': %s", network, err)
+	}
+	if prefix.Addr().Is6() {
+		return prefix.Masked().String(), nil
+	}
+	return prefix.Masked().Addr().String() + " " + ipv4Netmask(prefix.Bits()), nil
+}
+
+// fortinetAddress is a FortiOS phase 2 selector
+type fortinetAddress struct {
+	ipv6  bool
+	value string
+}
+
+func (address fortinetAddress) setting(direction string) string {
+	if address.ipv6 {
+		return fmt.Sprintf("%s-addr-type subnet6\n        set %s-subnet6 %s", direction, direction, address.value)
+	}
+	return fmt.Sprintf("%s-subnet %s", direction, address.value)
+}
+
+func fortinetSubnet(network string) (fortinetAddress, error) {
+	prefix, err := netip.ParsePrefix(network)
+	if err != nil {
+		return fortinetAddress{}, fmt.Errorf("error parsing network '%s': %s", network, err)
+	}
+	if prefix.Addr().Is6() {
+		return fortinetAddress{ipv6: true, value: prefix.Masked().String()}, nil
+	}
+	return fortinetAddress{value: prefix.Masked().Addr().String() + " " + ipv4Netmask(prefix.Bits())}, nil
+}
+
+func ipv4Netmask(bits int) string {
+	mask := uint32(0)
+	if bits > 0 {
+		mask = ^uint32(0) << (32 - bits)
+	}
+	return fmt.Sprintf("%d.%d.%d.%d", mask>>24, (mask>>16)&0xff, (mask>>8)&0xff, mask&0xff)
+}
+
+// strongSwanName converts a tunnel name into an identifier accepted by configuration files
+func strongSwanName(name string) string {
+	result := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' {
+			return r
+		}
+		return '-'
+	}, name)
+	if result == "" {
+		return "nsxt"
+	}
+	return result
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+func nonEmptyOr(values, defaultValues []string) []string {
+	if len(values) == 0 {
+		return defaultValues
+	}
+	return values
+}
+
+func yesNo(value bool) string {
+	if value {
+		return "yes"
+	}
+	return "no"
+}
@@ -0,0 +1,209 @@
+//go:build unit || ALL
+
+/*
+ * Copyright 2025 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+package govcd
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// buildIpSecVpnTunnel returns a PSK tunnel with one local and two remote networks
+func buildIpSecVpnTunnel() *types.NsxtIpSecVpnTunnel {
+	return &types.NsxtIpSecVpnTunnel{
+		Name:               "site a",
+		Enabled:            true,
+		PreSharedKey:       "secret",
+		SecurityType:       "DEFAULT",
+		AuthenticationMode: "PSK",
+		LocalEndpoint: types.NsxtIpSecVpnTunnelLocalEndpoint{
+			LocalAddress:  "192.0.2.10",
+			LocalNetworks: []string{"10.10.10.0/24"},
+		},
+		RemoteEndpoint: types.NsxtIpSecVpnTunnelRemoteEndpoint{
+			RemoteAddress:  "198.51.100.20",
+			RemoteNetworks: []string{"172.16.0.0/16", "2001:db8::/64"},
+		},
+	}
+}
+
+// buildIpSecVpnSecurityProfile returns a custom IKEv2 security profile supported by all peers
+func buildIpSecVpnSecurityProfile() *types.NsxtIpSecVpnTunnelSecurityProfile {
+	return &types.NsxtIpSecVpnTunnelSecurityProfile{
+		SecurityType: "CUSTOM",
+		IkeConfiguration: types.NsxtIpSecVpnTunnelProfileIkeConfiguration{
+			IkeVersion:           "IKE_V2",
+			EncryptionAlgorithms: []string{"AES_256"},
+			DigestAlgorithms:     []string{"SHA2_384"},
+			DhGroups:             []string{"GROUP20"},
+			SaLifeTime:           addrOf(28800),
+		},
+		TunnelConfiguration: types.NsxtIpSecVpnTunnelProfileTunnelConfiguration{
+			PerfectForwardSecrecyEnabled: true,
+			EncryptionAlgorithms:         []string{"AES_GCM_256"},
+			DhGroups:                     []string{"GROUP20"},
+			SaLifeTime:                   addrOf(3600),
+		},
+		DpdConfiguration: types.NsxtIpSecVpnTunnelProfileDpdConfiguration{ProbeInterval: 30},
+	}
+}
+
+// Test_GenerateIpSecVpnPeerConfiguration checks that the remote side configuration mirrors the tunnel
+func Test_GenerateIpSecVpnPeerConfiguration(t *testing.T) {
+	tests := []struct {
+		peer     string
+		expected []string
+	}{
+		{IpSecVpnPeerStrongSwan, []string{"local_addrs = 198.51.100.20", "remote_addrs = 192.0.2.10",
+			"proposals = aes256-sha384-ecp384", "esp_proposals = aes256gcm16-ecp384", "remote_ts = 10.10.10.0/24",
+			"local_ts = 172.16.0.0/16,2001:db8::/64", "site-a {", `secret = "secret"`}},
+		{IpSecVpnPeerLibreswan, []string{"left=198.51.100.20", "right=192.0.2.10", "ike=aes256-sha2_384;dh20",
+			"esp=aes_gcm256;dh20", "ikelifetime=28800s", `198.51.100.20 192.0.2.10 : PSK "secret"`}},
+		{IpSecVpnPeerPfSense, []string{"Remote Gateway: 192.0.2.10", "Hash: SHA384", "DH Group: 20 (nist ecp384)",
+			"Encryption Algorithms: AES256-GCM, 128 bits", "[Phase 2 #2]", "Local Network: 2001:db8::/64"}},
+		{IpSecVpnPeerCiscoAsa, []string{"network-object 172.16.0.0 255.255.0.0", "network-object 2001:db8::/64",
+			"network-object 10.10.10.0 255.255.255.0", "encryption aes-256", "integrity sha384", "group 20",
+			"protocol esp encryption aes-gcm-256", "set peer 192.0.2.10", "set pfs group20",
+			"ikev2 remote-authentication pre-shared-key secret"}},
+		{IpSecVpnPeerFortinet, []string{"set proposal aes256-sha384", "set proposal aes256gcm", "set remote-gw 192.0.2.10",
+			"set src-subnet 172.16.0.0 255.255.0.0", "set dst-subnet 10.10.10.0 255.255.255.0",
+			"set src-subnet6 2001:db8::/64", `edit "site a-2"`}},
+	}
+	for _, test := range tests {
+		config, err := GenerateIpSecVpnPeerConfiguration(test.peer, buildIpSecVpnTunnel(), buildIpSecVpnSecurityProfile())
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", test.peer, err)
+		}
+		for _, expected := range test.expected {
+			if !strings.Contains(config, expected) {
+				t.Fatalf("%s: expected '%s' in configuration:\n%s", test.peer, expected, config)
+			}
+		}
+	}
+
+	// Each pair of networks is a separate phase 2 entry
+	tunnel := buildIpSecVpnTunnel()
+	tunnel.LocalEndpoint.LocalNetworks = append(tunnel.LocalEndpoint.LocalNetworks, "10.10.20.0/24")
+	config, err := GenerateIpSecVpnPeerConfiguration(IpSecVpnPeerPfSense, tunnel, buildIpSecVpnSecurityProfile())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if strings.Count(config, "[Phase 2 #") != 4 || strings.Count(config, "[Phase 2 #2]") != 1 || !strings.Contains(config, "[Phase 2 #4]") {
+		t.Fatalf("expected 4 numbered phase 2 entries in configuration:\n%s", config)
+	}
+
+	config, err = GenerateIpSecVpnPeerConfiguration(IpSecVpnPeerAwsVgw, buildIpSecVpnTunnel(), buildIpSecVpnSecurityProfile())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	var document struct {
+		CustomerGatewayIpAddress string
+		TunnelOptions            awsVgwTunnelOptions
+	}
+	err = json.Unmarshal([]byte(config), &document)
+	if err != nil {
+		t.Fatalf("error parsing AWS configuration: %s", err)
+	}
+	options := document.TunnelOptions
+	if document.CustomerGatewayIpAddress != "192.0.2.10" || options.Phase1LifetimeSeconds != 28800 ||
+		options.Phase1EncryptionAlgorithms[0].Value != "AES256" || options.Phase2EncryptionAlgorithms[0].Value != "AES256-GCM-16" ||
+		options.Phase1DHGroupNumbers[0].Value != 20 || options.Phase2IntegrityAlgorithms != nil || options.DPDTimeoutSeconds != 90 {
+		t.Fatalf("unexpected AWS configuration:\n%s", config)
+	}
+}
+
+// Test_ValidateIpSecVpnPeerProfile checks that unsupported security profiles are reported for each peer
+func Test_ValidateIpSecVpnPeerProfile(t *testing.T) {
+	// NSX-T defaults can be negotiated by all peers, except for the IKE lifetime on AWS
+	for peer := range ipSecVpnPeerCapabilitiesTable {
+		err := ValidateIpSecVpnPeerProfile(peer, buildIpSecVpnTunnel(), nil)
+		if peer == IpSecVpnPeerAwsVgw {
+			if err == nil || !strings.Contains(err.Error(), "IKE SA lifetime 86400") {
+				t.Fatalf("expected lifetime error for %s, got %v", peer, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("unexpected error for %s: %s", peer, err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		peer     string
+		change   func(profile *types.NsxtIpSecVpnTunnelSecurityProfile, tunnel *types.NsxtIpSecVpnTunnel)
+		expected string
+	}{
+		{"libreswan MODP-1024", IpSecVpnPeerLibreswan, func(p *types.NsxtIpSecVpnTunnelSecurityProfile, _ *types.NsxtIpSecVpnTunnel) {
+			p.IkeConfiguration.DhGroups = []string{"GROUP2"}
+		}, "IKE Diffie-Hellman group GROUP2"},
+		{"ASA IKEv1 digest", IpSecVpnPeerCiscoAsa, func(p *types.NsxtIpSecVpnTunnelSecurityProfile, _ *types.NsxtIpSecVpnTunnel) {
+			p.IkeConfiguration.IkeVersion = "IKE_V1"
+		}, "IKE digest SHA2_384"},
+		{"IKEv1 GCM", IpSecVpnPeerStrongSwan, func(p *types.NsxtIpSecVpnTunnelSecurityProfile, _ *types.NsxtIpSecVpnTunnel) {
+			p.IkeConfiguration.IkeVersion = "IKE_V1"
+			p.IkeConfiguration.EncryptionAlgorithms = []string{"AES_GCM_128"}
+		}, "IKE_V1 encryption AES_GCM_128 is not supported"},
+		{"Fortinet GCM 192", IpSecVpnPeerFortinet, func(p *types.NsxtIpSecVpnTunnelSecurityProfile, _ *types.NsxtIpSecVpnTunnel) {
+			p.TunnelConfiguration.EncryptionAlgorithms = []string{"AES_GCM_192"}
+		}, "tunnel encryption AES_GCM_192"},
+		{"no supported DH group", IpSecVpnPeerCiscoAsa, func(p *types.NsxtIpSecVpnTunnelSecurityProfile, _ *types.NsxtIpSecVpnTunnel) {
+			p.IkeConfiguration.DhGroups = []string{"GROUP2", "GROUP5"}
+		}, "IKE Diffie-Hellman group GROUP2, GROUP5"},
+		{"AWS without PFS", IpSecVpnPeerAwsVgw, func(p *types.NsxtIpSecVpnTunnelSecurityProfile, _ *types.NsxtIpSecVpnTunnel) {
+			p.TunnelConfiguration.PerfectForwardSecrecyEnabled = false
+		}, "Perfect Forward Secrecy"},
+		{"AWS certificate", IpSecVpnPeerAwsVgw, func(_ *types.NsxtIpSecVpnTunnelSecurityProfile, tunnel *types.NsxtIpSecVpnTunnel) {
+			tunnel.AuthenticationMode = "CERTIFICATE"
+		}, "certificate authentication"},
+		{"unknown peer", "juniper", func(*types.NsxtIpSecVpnTunnelSecurityProfile, *types.NsxtIpSecVpnTunnel) {}, "unknown IPsec VPN peer"},
+	}
+	for _, test := range tests {
+		profile, tunnel := buildIpSecVpnSecurityProfile(), buildIpSecVpnTunnel()
+		test.change(profile, tunnel)
+		err := ValidateIpSecVpnPeerProfile(test.peer, tunnel, profile)
+		if err == nil || !strings.Contains(err.Error(), test.expected) {
+			t.Fatalf("%s: expected error containing '%s', got %v", test.name, test.expected, err)
+		}
+		_, err = GenerateIpSecVpnPeerConfiguration(test.peer, tunnel, profile)
+		if err == nil {
+			t.Fatalf("%s: expected configuration to be refused", test.name)
+		}
+	}
+}
+
+// Test_GenerateIpSecVpnPeerConfigurationNegotiation checks that the first algorithm of each list that the peer
+// supports is used, even when it is not the first one offered
+func Test_GenerateIpSecVpnPeerConfigurationNegotiation(t *testing.T) {
+	profile := buildIpSecVpnSecurityProfile()
+	profile.IkeConfiguration.IkeVersion = "IKE_V1"
+	profile.IkeConfiguration.EncryptionAlgorithms = []string{"AES_GCM_256", "AES_256"}
+	profile.IkeConfiguration.DigestAlgorithms = []string{"SHA2_384", "SHA1"}
+	profile.IkeConfiguration.DhGroups = []string{"GROUP2", "GROUP20"}
+	profile.TunnelConfiguration.EncryptionAlgorithms = []string{"AES_GCM_192", "AES_GCM_256", "AES_256"}
+	profile.TunnelConfiguration.DhGroups = []string{"GROUP5", "GROUP19"}
+
+	config, err := GenerateIpSecVpnPeerConfiguration(IpSecVpnPeerCiscoAsa, buildIpSecVpnTunnel(), profile)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	for _, expected := range []string{"encryption aes-256", "hash sha", "group 20", "esp-aes-256", "set pfs group19"} {
+		if !strings.Contains(config, expected) {
+			t.Fatalf("expected '%s' in configuration:\n%s", expected, config)
+		}
+	}
+
+	config, err = GenerateIpSecVpnPeerConfiguration(IpSecVpnPeerFortinet, buildIpSecVpnTunnel(), profile)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !strings.Contains(config, "set proposal aes256gcm") {
+		t.Fatalf("expected AES-GCM 256 tunnel proposal in configuration:\n%s", config)
+	}
+}
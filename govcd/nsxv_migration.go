@@ -0,0 +1,719 @@
+/*
+ * Copyright 2025 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+package govcd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// nsxvMigrationPrefix is used in the names of objects generated by the migration planner
+const nsxvMigrationPrefix = "migrated-"
+
+// Kinds of NSX-V items reported in NsxvMigrationIssue
+const (
+	NsxvMigrationKindFirewallRule    = "firewallRule"
+	NsxvMigrationKindNatRule         = "natRule"
+	NsxvMigrationKindLbPool          = "lbPool"
+	NsxvMigrationKindLbVirtualServer = "lbVirtualServer"
+	NsxvMigrationKindDhcpRelay       = "dhcpRelay"
+)
+
+// NsxvMigrationSource holds the NSX-V Edge Gateway configuration used to plan a migration to NSX-T
+type NsxvMigrationSource struct {
+	IpSets           []*types.EdgeIpSet
+	FirewallRules    []*types.EdgeFirewallRule
+	NatRules         []*types.EdgeNatRule
+	LbMonitors       []*types.LbMonitor
+	LbAppProfiles    []*types.LbAppProfile
+	LbPools          []*types.LbPool
+	LbVirtualServers []*types.LbVirtualServer
+	DhcpRelay        *types.EdgeDhcpRelay
+}
+
+// NsxvMigrationPlan holds the NSX-T equivalents of an NSX-V Edge Gateway configuration.
+// Objects that depend on each other are referenced by name, as their IDs are only known when the plan is applied.
+type NsxvMigrationPlan struct {
+	// Firewall contains the firewall rules, together with the IP Sets and Application Port Profiles used by the
+	// firewall and NAT rules
+	Firewall *FirewallPolicyDocument
+	// NatRules reference their Application Port Profile by name
+	NatRules []*types.NsxtNatRule
+	// AlbPools are created without Edge Gateway reference, which is set when the plan is applied
+	AlbPools []*types.NsxtAlbPool
+	// AlbVirtualServices reference their pool by name. Edge Gateway and Service Engine Group references are set when
+	// the plan is applied
+	AlbVirtualServices []*types.NsxtAlbVirtualService
+	DhcpForwarder      *types.NsxtEdgeGatewayDhcpForwarder
+	// Issues lists the items that could not be migrated, or were migrated with reduced functionality
+	Issues []NsxvMigrationIssue
+}
+
+// NsxvMigrationIssue describes an NSX-V item that cannot be fully mapped to NSX-T
+type NsxvMigrationIssue struct {
+	Kind   string
+	Id     string
+	Name   string
+	Reason string
+	// Skipped is true when the item is not part of the plan. Otherwise, it is migrated without the features
+	// described in Reason
+	Skipped bool
+}
+
+// NsxvMigrationApplyOptions contains the settings required to apply an NsxvMigrationPlan
+type NsxvMigrationApplyOptions struct {
+	// ServiceEngineGroupRef is the Service Engine Group used by ALB Virtual Services. It is required only when the
+	// plan contains Virtual Services
+	ServiceEngineGroupRef *types.OpenApiReference
+}
+
+// GetNsxvMigrationSource reads the configuration of the NSX-V Edge Gateway that can be migrated to NSX-T.
+// IP Sets are defined in the VDC, which must be the parent of the Edge Gateway.
+func (egw *EdgeGateway) GetNsxvMigrationSource(vdc *Vdc) (*NsxvMigrationSource, error) {
+	if !egw.HasAdvancedNetworking() {
+		return nil, fmt.Errorf("only advanced edge gateways can be migrated")
+	}
+	source := &NsxvMigrationSource{}
+	var err error
+
+	source.IpSets, err = vdc.GetAllNsxvIpSets()
+	if err != nil && !ContainsNotFound(err) {
+		return nil, fmt.Errorf("error retrieving NSX-V IP sets: %s", err)
+	}
+	source.FirewallRules, err = egw.GetAllNsxvFirewallRules()
+	if err != nil && !ContainsNotFound(err) {
+		return nil, fmt.Errorf("error retrieving NSX-V firewall rules: %s", err)
+	}
+	source.NatRules, err = egw.GetNsxvNatRules()
+	if err != nil && !ContainsNotFound(err) {
+		return nil, fmt.Errorf("error retrieving NSX-V NAT rules: %s", err)
+	}
+	source.LbMonitors, err = egw.GetLbServiceMonitors()
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving load balancer service monitors: %s", err)
+	}
+	source.LbAppProfiles, err = egw.GetLbAppProfiles()
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving load balancer application profiles: %s", err)
+	}
+	source.LbPools, err = egw.GetLbServerPools()
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving load balancer server pools: %s", err)
+	}
+	source.LbVirtualServers, err = egw.GetLbVirtualServers()
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving load balancer virtual servers: %s", err)
+	}
+	source.DhcpRelay, err = egw.GetDhcpRelay()
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving DHCP relay: %s", err)
+	}
+	return source, nil
+}
+
+// PlanNsxvMigration translates an NSX-V Edge Gateway configuration into NSX-T objects. It does not contact VCD.
+// Only user defined firewall and NAT rules are migrated, as system rules are created by NSX-T itself.
+func PlanNsxvMigration(source *NsxvMigrationSource) *NsxvMigrationPlan {
+	planner := &nsxvMigrationPlanner{
+		source: source,
+		plan: &NsxvMigrationPlan{
+			Firewall: &FirewallPolicyDocument{Version: FirewallPolicyDocumentVersion, Kind: FirewallPolicyKindEdgeGateway},
+		},
+		ipSets:   make(map[string]bool),
+		profiles: make(map[string]bool),
+	}
+	for _, rule := range source.FirewallRules {
+		planner.firewallRule(rule)
+	}
+	for _, rule := range source.NatRules {
+		planner.natRule(rule)
+	}
+	for _, pool := range source.LbPools {
+		planner.lbPool(pool)
+	}
+	for _, virtualServer := range source.LbVirtualServers {
+		planner.lbVirtualServer(virtualServer)
+	}
+	planner.dhcpRelay(source.DhcpRelay)
+	planner.plan.Firewall.sort()
+	return planner.plan
+}
+
+// ApplyNsxvMigrationPlan creates the objects of the plan in the NSX-T Edge Gateway egw. The firewall is applied
+// with NsxtEdgeGateway.ImportFirewallPolicy, which replaces all user defined firewall rules: the migration is refused
+// when the Edge Gateway has firewall rules that are not part of the plan, or NAT rules with the same names as the
+// ones in the plan. All references between objects are resolved before anything is changed.
+func (vcdClient *VCDClient) ApplyNsxvMigrationPlan(egw *NsxtEdgeGateway, plan *NsxvMigrationPlan, options NsxvMigrationApplyOptions) error {
+	if len(plan.AlbVirtualServices) > 0 && options.ServiceEngineGroupRef == nil {
+		return fmt.Errorf("a Service Engine Group is required to migrate load balancer virtual servers")
+	}
+
+	firewall, err := egw.GetNsxtFirewall()
+	if err != nil {
+		return err
+	}
+	natRules, err := egw.GetAllNatRules(nil)
+	if err != nil {
+		return fmt.Errorf("error retrieving NAT rules: %s", err)
+	}
+	var firewallRuleNames, natRuleNames []string
+	for _, rule := range firewall.NsxtFirewallRuleContainer.UserDefinedRules {
+		firewallRuleNames = append(firewallRuleNames, rule.Name)
+	}
+	for _, rule := range natRules {
+		natRuleNames = append(natRuleNames, rule.NsxtNatRule.Name)
+	}
+	missingProfiles, err := validateNsxvMigrationTarget(plan, firewallRuleNames, natRuleNames)
+	if err != nil {
+		return err
+	}
+
+	contextId := ""
+	if egw.EdgeGateway.OwnerRef != nil {
+		contextId = egw.EdgeGateway.OwnerRef.ID
+	}
+	// Profiles that are not created by the plan must already exist
+	for _, profileName := range missingProfiles {
+		_, err := getNsxtAppPortProfileReferenceByName(egw.client, profileName, contextId)
+		if err != nil {
+			return fmt.Errorf("error resolving Application Port Profile '%s': %s", profileName, err)
+		}
+	}
+
+	if plan.Firewall != nil {
+		_, err := egw.ImportFirewallPolicy(plan.Firewall)
+		if err != nil {
+			return fmt.Errorf("error applying firewall policy: %s", err)
+		}
+	}
+
+	for _, natRule := range plan.NatRules {
+		config := *natRule
+		if config.ApplicationPortProfile != nil {
+			reference, err := getNsxtAppPortProfileReferenceByName(egw.client, config.ApplicationPortProfile.Name, contextId)
+			if err != nil {
+				return fmt.Errorf("error resolving Application Port Profile for NAT rule '%s': %s", config.Name, err)
+			}
+			config.ApplicationPortProfile = reference
+		}
+		// Type replaces RuleType in API V36.0
+		if egw.client.APIClientVersionIs(">= 36.0") {
+			config.Type = config.RuleType
+			config.RuleType = ""
+		}
+		_, err := egw.CreateNatRule(&config)
+		if err != nil {
+			return fmt.Errorf("error creating NAT rule '%s': %s", config.Name, err)
+		}
+	}
+
+	gatewayRef := types.OpenApiReference{ID: egw.EdgeGateway.ID}
+	poolIds := make(map[string]string)
+	for _, pool := range plan.AlbPools {
+		config := *pool
+		config.GatewayRef = gatewayRef
+		createdPool, err := vcdClient.CreateNsxtAlbPool(&config)
+		if err != nil {
+			return fmt.Errorf("error creating ALB pool '%s': %s", config.Name, err)
+		}
+		poolIds[config.Name] = createdPool.NsxtAlbPool.ID
+	}
+	for _, virtualService := range plan.AlbVirtualServices {
+		config := *virtualService
+		poolId, found := poolIds[config.LoadBalancerPoolRef.Name]
+		if !found {
+			return fmt.Errorf("ALB pool '%s' of virtual service '%s' was not created", config.LoadBalancerPoolRef.Name, config.Name)
+		}
+		config.GatewayRef = gatewayRef
+		config.LoadBalancerPoolRef = types.OpenApiReference{ID: poolId}
+		config.ServiceEngineGroupRef = *options.ServiceEngineGroupRef
+		_, err := vcdClient.CreateNsxtAlbVirtualService(&config)
+		if err != nil {
+			return fmt.Errorf("error creating ALB virtual service '%s': %s", config.Name, err)
+		}
+	}
+
+	if plan.DhcpForwarder != nil {
+		_, err := egw.UpdateDhcpForwarder(plan.DhcpForwarder)
+		if err != nil {
+			return fmt.Errorf("error setting DHCP forwarder: %s", err)
+		}
+	}
+	return nil
+}
+
+// validateNsxvMigrationTarget checks that the plan can be applied to an Edge Gateway with the given firewall and NAT
+// rules, and that the references between objects of the plan are consistent. It returns the Application Port Profiles
+// used by NAT rules that are not defined in the plan, which must already exist
+func validateNsxvMigrationTarget(plan *NsxvMigrationPlan, firewallRules, natRules []string) ([]string, error) {
+	var problems []string
+	plannedRules := make(map[string]bool)
+	plannedProfiles := make(map[string]bool)
+	if plan.Firewall != nil {
+		for _, rule := range plan.Firewall.Rules {
+			plannedRules[rule.Name] = true
+		}
+		for _, profile := range plan.Firewall.AppPortProfiles {
+			plannedProfiles[profile.Name] = true
+		}
+	}
+	for _, name := range firewallRules {
+		if !plannedRules[name] {
+			problems = append(problems, fmt.Sprintf("firewall rule '%s' would be removed by the migration", name))
+		}
+	}
+
+	var missingProfiles []string
+	for _, natRule := range plan.NatRules {
+		if contains(natRule.Name, natRules) {
+			problems = append(problems, fmt.Sprintf("NAT rule '%s' already exists", natRule.Name))
+		}
+		if natRule.ApplicationPortProfile != nil && !plannedProfiles[natRule.ApplicationPortProfile.Name] &&
+			!contains(natRule.ApplicationPortProfile.Name, missingProfiles) {
+			missingProfiles = append(missingProfiles, natRule.ApplicationPortProfile.Name)
+		}
+	}
+
+	plannedPools := make(map[string]bool)
+	for _, pool := range plan.AlbPools {
+		plannedPools[pool.Name] = true
+	}
+	for _, virtualService := range plan.AlbVirtualServices {
+		if !plannedPools[virtualService.LoadBalancerPoolRef.Name] {
+			problems = append(problems, fmt.Sprintf("ALB pool '%s' of virtual service '%s' is not part of the plan",
+				virtualService.LoadBalancerPoolRef.Name, virtualService.Name))
+		}
+	}
+
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("NSX-V migration plan cannot be applied: %s", strings.Join(problems, "; "))
+	}
+	return missingProfiles, nil
+}
+
+// nsxvMigrationPlanner keeps track of the objects added to a plan
+type nsxvMigrationPlanner struct {
+	source   *NsxvMigrationSource
+	plan     *NsxvMigrationPlan
+	ipSets   map[string]bool
+	profiles map[string]bool
+}
+
+func (planner *nsxvMigrationPlanner) issue(kind, id, name string, skipped bool, format string, args ...any) {
+	planner.plan.Issues = append(planner.plan.Issues, NsxvMigrationIssue{
+		Kind:    kind,
+		Id:      id,
+		Name:    name,
+		Reason:  fmt.Sprintf(format, args...),
+		Skipped: skipped,
+	})
+}
+
+// ipSetById finds an NSX-V IP set by its full ID ('vdc-uuid:ipset-1') or by its short ID ('ipset-1')
+func (planner *nsxvMigrationPlanner) ipSetById(id string) *types.EdgeIpSet {
+	for _, ipSet := range planner.source.IpSets {
+		if ipSet.ID == id || strings.HasSuffix(ipSet.ID, ":"+id) {
+			return ipSet
+		}
+	}
+	return nil
+}
+
+// addIpSet adds an IP Set to the firewall document, unless it was already added
+func (planner *nsxvMigrationPlanner) addIpSet(name, description string, addresses []string) {
+	if planner.ipSets[name] {
+		return
+	}
+	planner.ipSets[name] = true
+	planner.plan.Firewall.IpSets = append(planner.plan.Firewall.IpSets, FirewallPolicyIpSet{
+		Name:        name,
+		Description: description,
+		IpAddresses: addresses,
+	})
+}
+
+// addAppPortProfile adds a TENANT Application Port Profile to the firewall document, unless it was already added
+func (planner *nsxvMigrationPlanner) addAppPortProfile(name string, ports []types.NsxtAppPortProfilePort) {
+	if planner.profiles[name] {
+		return
+	}
+	planner.profiles[name] = true
+	planner.plan.Firewall.AppPortProfiles = append(planner.plan.Firewall.AppPortProfiles, FirewallPolicyAppPortProfile{
+		Name:        name,
+		Description: "Migrated from NSX-V",
+		Ports:       ports,
+	})
+}
+
+// firewallEndpoint converts an NSX-V firewall rule source or destination into IP Set names
+func (planner *nsxvMigrationPlanner) firewallEndpoint(rule *types.EdgeFirewallRule, endpoint types.EdgeFirewallEndpoint, side string) ([]string, error) {
+	if endpoint.Exclude {
+		return nil, fmt.Errorf("excluded %s is not supported by NSX-T Edge Gateway firewall", side)
+	}
+	if len(endpoint.VnicGroupIds) > 0 {
+		return nil, fmt.Errorf("%s interface groups %v have no NSX-T equivalent", side, endpoint.VnicGroupIds)
+	}
+	var names []string
+	for _, id := range endpoint.GroupingObjectIds {
+		ipSet := planner.ipSetById(id)
+		if ipSet == nil {
+			return nil, fmt.Errorf("%s grouping object '%s' is not an IP set", side, id)
+		}
+		planner.addIpSet(ipSet.Name, ipSet.Description, splitNsxvAddresses(ipSet.IPAddresses))
+		names = append(names, ipSet.Name)
+	}
+	var addresses []string
+	for _, address := range endpoint.IpAddresses {
+		if !strings.EqualFold(address, "any") {
+			addresses = append(addresses, address)
+		}
+	}
+	if len(addresses) > 0 {
+		name := fmt.Sprintf("%srule-%s-%s", nsxvMigrationPrefix, rule.ID, side)
+		planner.addIpSet(name, fmt.Sprintf("Addresses of NSX-V firewall rule '%s'", rule.Name), addresses)
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+func (planner *nsxvMigrationPlanner) firewallRule(rule *types.EdgeFirewallRule) {
+	if rule.RuleType != "" && rule.RuleType != "user" {
+		return
+	}
+	name := rule.Name
+	if name == "" {
+		name = "rule-" + rule.ID
+	}
+	action, found := map[string]string{"accept": "ALLOW", "deny": "DROP", "reject": "REJECT"}[strings.ToLower(rule.Action)]
+	if !found {
+		planner.issue(NsxvMigrationKindFirewallRule, rule.ID, name, true, "unknown action '%s'", rule.Action)
+		return
+	}
+	direction, found := map[string]string{"": "IN_OUT", "in": "IN", "out": "OUT"}[strings.ToLower(rule.Direction)]
+	if !found {
+		planner.issue(NsxvMigrationKindFirewallRule, rule.ID, name, true, "unknown direction '%s'", rule.Direction)
+		return
+	}
+	sources, err := planner.firewallEndpoint(rule, rule.Source, "source")
+	if err == nil {
+		var destinations []string
+		destinations, err = planner.firewallEndpoint(rule, rule.Destination, "destination")
+		if err == nil {
+			var profiles []string
+			profiles, err = planner.firewallApplication(rule)
+			if err == nil {
+				planner.plan.Firewall.Rules = append(planner.plan.Firewall.Rules, FirewallPolicyRule{
+					Name:                    name,
+					Action:                  action,
+					Enabled:                 rule.Enabled,
+					Direction:               direction,
+					IpProtocol:              "IPV4_IPV6",
+					Logging:                 rule.LoggingEnabled,
+					Sources:                 sources,
+					Destinations:            destinations,
+					ApplicationPortProfiles: profiles,
+				})
+				if rule.MatchTranslated != nil && *rule.MatchTranslated {
+					planner.issue(NsxvMigrationKindFirewallRule, rule.ID, name, false, "matching translated addresses is not supported")
+				}
+				return
+			}
+		}
+	}
+	planner.issue(NsxvMigrationKindFirewallRule, rule.ID, name, true, "%s", err)
+}
+
+// firewallApplication converts the services of an NSX-V firewall rule into an Application Port Profile
+func (planner *nsxvMigrationPlanner) firewallApplication(rule *types.EdgeFirewallRule) ([]string, error) {
+	if rule.Application.ID != "" {
+		return nil, fmt.Errorf("application '%s' cannot be mapped, only services defined in the rule are supported", rule.Application.ID)
+	}
+	if len(rule.Application.Services) == 0 {
+		return nil, nil
+	}
+	var ports []types.NsxtAppPortProfilePort
+	for _, service := range rule.Application.Services {
+		port, err := nsxvServiceToAppPort(service.Protocol, service.Port, service.SourcePort)
+		if err != nil {
+			return nil, err
+		}
+		if port == nil {
+			// One of the services allows any traffic
+			return nil, nil
+		}
+		ports = append(ports, *port)
+	}
+	name := fmt.Sprintf("%srule-%s", nsxvMigrationPrefix, rule.ID)
+	planner.addAppPortProfile(name, ports)
+	return []string{name}, nil
+}
+
+func (planner *nsxvMigrationPlanner) natRule(rule *types.EdgeNatRule) {
+	if rule.RuleType != "" && rule.RuleType != "user" {
+		return
+	}
+	// NSX-V descriptions are often repeated, while NSX-T NAT rules are looked up by name, so the name comes from the
+	// rule ID, which is unique within the Edge Gateway
+	name := fmt.Sprintf("%snat-%s", nsxvMigrationPrefix, rule.ID)
+	natRule := &types.NsxtNatRule{
+		Name:        name,
+		Description: rule.Description,
+		Enabled:     rule.Enabled,
+		Logging:     rule.LoggingEnabled,
+	}
+	if rule.IcmpType != "" && !strings.EqualFold(rule.IcmpType, "any") {
+		planner.issue(NsxvMigrationKindNatRule, rule.ID, name, true, "ICMP type '%s' cannot be mapped", rule.IcmpType)
+		return
+	}
+
+	switch strings.ToLower(rule.Action) {
+	case "dnat":
+		natRule.RuleType = types.NsxtNatRuleTypeDnat
+		natRule.ExternalAddresses = rule.OriginalAddress
+		natRule.InternalAddresses = rule.TranslatedAddress
+		if !isAnyNsxvPort(rule.OriginalPort) {
+			natRule.DnatExternalPort = rule.OriginalPort
+		}
+		translatedPort := rule.TranslatedPort
+		if isAnyNsxvPort(translatedPort) {
+			translatedPort = rule.OriginalPort
+		}
+		port, err := nsxvServiceToAppPort(rule.Protocol, translatedPort, "")
+		if err != nil {
+			planner.issue(NsxvMigrationKindNatRule, rule.ID, name, true, "%s", err)
+			return
+		}
+		if port != nil {
+			profileName := fmt.Sprintf("%snat-%s", nsxvMigrationPrefix, rule.ID)
+			planner.addAppPortProfile(profileName, []types.NsxtAppPortProfilePort{*port})
+			natRule.ApplicationPortProfile = &types.OpenApiReference{Name: profileName}
+		}
+	case "snat":
+		natRule.RuleType = types.NsxtNatRuleTypeSnat
+		natRule.InternalAddresses = rule.OriginalAddress
+		natRule.ExternalAddresses = rule.TranslatedAddress
+		if !isAnyNsxvPort(rule.OriginalPort) || !isAnyNsxvPort(rule.TranslatedPort) {
+			planner.issue(NsxvMigrationKindNatRule, rule.ID, name, false, "ports of SNAT rules are not supported")
+		}
+	default:
+		planner.issue(NsxvMigrationKindNatRule, rule.ID, name, true, "unknown action '%s'", rule.Action)
+		return
+	}
+	planner.plan.NatRules = append(planner.plan.NatRules, natRule)
+}
+
+func (planner *nsxvMigrationPlanner) lbPool(pool *types.LbPool) {
+	algorithm, found := map[string]string{
+		"round-robin": "ROUND_ROBIN",
+		"leastconn":   "LEAST_CONNECTIONS",
+		"ip-hash":     "CONSISTENT_HASH",
+	}[strings.ToLower(pool.Algorithm)]
+	if !found {
+		algorithm = "LEAST_CONNECTIONS"
+		planner.issue(NsxvMigrationKindLbPool, pool.ID, pool.Name, false, "algorithm '%s' is replaced by LEAST_CONNECTIONS", pool.Algorithm)
+	}
+	if pool.Transparent {
+		planner.issue(NsxvMigrationKindLbPool, pool.ID, pool.Name, false, "transparent mode is not supported")
+	}
+
+	albPool := &types.NsxtAlbPool{
+		Name:        pool.Name,
+		Description: pool.Description,
+		Enabled:     addrOf(true),
+		Algorithm:   algorithm,
+	}
+	for _, member := range pool.Members {
+		albMember := types.NsxtAlbPoolMember{
+			Enabled:   !strings.EqualFold(member.Condition, "disabled"),
+			IpAddress: member.IpAddress,
+			Port:      member.Port,
+		}
+		if member.Weight > 0 {
+			albMember.Ratio = addrOf(member.Weight)
+		}
+		albPool.Members = append(albPool.Members, albMember)
+	}
+	if pool.MonitorId != "" {
+		monitor := planner.lbMonitor(pool.MonitorId)
+		monitorType := ""
+		if monitor != nil {
+			monitorType = map[string]string{"http": "HTTP", "https": "HTTPS", "tcp": "TCP", "udp": "UDP", "icmp": "PING"}[strings.ToLower(monitor.Type)]
+		}
+		switch {
+		case monitorType == "":
+			planner.issue(NsxvMigrationKindLbPool, pool.ID, pool.Name, false, "health monitor '%s' cannot be mapped", pool.MonitorId)
+		default:
+			albPool.HealthMonitors = []types.NsxtAlbPoolHealthMonitor{{Type: monitorType}}
+			if monitor.URL != "" || monitor.Send != "" || monitor.Receive != "" || monitor.Expected != "" {
+				planner.issue(NsxvMigrationKindLbPool, pool.ID, pool.Name, false,
+					"health monitor '%s' is replaced by a system %s monitor without custom requests", monitor.Name, monitorType)
+			}
+		}
+	}
+	planner.plan.AlbPools = append(planner.plan.AlbPools, albPool)
+}
+
+func (planner *nsxvMigrationPlanner) lbMonitor(id string) *types.LbMonitor {
+	for _, monitor := range planner.source.LbMonitors {
+		if monitor.ID == id {
+			return monitor
+		}
+	}
+	return nil
+}
+
+func (planner *nsxvMigrationPlanner) lbVirtualServer(virtualServer *types.LbVirtualServer) {
+	skip := func(format string, args ...any) {
+		planner.issue(NsxvMigrationKindLbVirtualServer, virtualServer.ID, virtualServer.Name, true, format, args...)
+	}
+	var poolName string
+	for _, pool := range planner.source.LbPools {
+		if pool.ID == virtualServer.DefaultPoolId {
+			poolName = pool.Name
+		}
+	}
+	if poolName == "" {
+		skip("default pool is required")
+		return
+	}
+
+	var profile *types.LbAppProfile
+	for _, appProfile := range planner.source.LbAppProfiles {
+		if appProfile.ID == virtualServer.ApplicationProfileId {
+			profile = appProfile
+		}
+	}
+	protocol := strings.ToLower(virtualServer.Protocol)
+	if profile != nil && profile.Template != "" {
+		protocol = strings.ToLower(profile.Template)
+	}
+
+	servicePort := types.NsxtAlbVirtualServicePort{PortStart: addrOf(virtualServer.Port)}
+	applicationProfile := types.NsxtAlbVirtualServiceApplicationProfile{SystemDefined: true}
+	switch {
+	case protocol == "http":
+		applicationProfile.Type = "HTTP"
+	case protocol == "https" && profile != nil && profile.SslPassthrough:
+		applicationProfile.Type = "L4"
+		servicePort.TcpUdpProfile = &types.NsxtAlbVirtualServicePortTcpUdpProfile{SystemDefined: true, Type: "TCP_FAST_PATH"}
+	case protocol == "https":
+		skip("HTTPS termination requires a certificate, which must be configured manually")
+		return
+	case protocol == "tcp":
+		applicationProfile.Type = "L4"
+		servicePort.TcpUdpProfile = &types.NsxtAlbVirtualServicePortTcpUdpProfile{SystemDefined: true, Type: "TCP_PROXY"}
+	case protocol == "udp":
+		applicationProfile.Type = "L4"
+		servicePort.TcpUdpProfile = &types.NsxtAlbVirtualServicePortTcpUdpProfile{SystemDefined: true, Type: "UDP_FAST_PATH"}
+	default:
+		skip("protocol '%s' cannot be mapped", virtualServer.Protocol)
+		return
+	}
+	if len(virtualServer.ApplicationRuleIds) > 0 {
+		planner.issue(NsxvMigrationKindLbVirtualServer, virtualServer.ID, virtualServer.Name, false, "application rules are not supported")
+	}
+	if virtualServer.ConnectionLimit > 0 || virtualServer.ConnectionRateLimit > 0 {
+		planner.issue(NsxvMigrationKindLbVirtualServer, virtualServer.ID, virtualServer.Name, false, "connection limits are not supported")
+	}
+
+	planner.plan.AlbVirtualServices = append(planner.plan.AlbVirtualServices, &types.NsxtAlbVirtualService{
+		Name:                virtualServer.Name,
+		Description:         virtualServer.Description,
+		Enabled:             addrOf(virtualServer.Enabled),
+		ApplicationProfile:  applicationProfile,
+		LoadBalancerPoolRef: types.OpenApiReference{Name: poolName},
+		ServicePorts:        []types.NsxtAlbVirtualServicePort{servicePort},
+		VirtualIpAddress:    virtualServer.IpAddress,
+	})
+}
+
+func (planner *nsxvMigrationPlanner) dhcpRelay(relay *types.EdgeDhcpRelay) {
+	if relay == nil || relay.RelayServer == nil {
+		return
+	}
+	servers := append([]string{}, relay.RelayServer.IpAddress...)
+	for _, id := range relay.RelayServer.GroupingObjectId {
+		ipSet := planner.ipSetById(id)
+		if ipSet == nil {
+			planner.issue(NsxvMigrationKindDhcpRelay, id, "", false, "relay server '%s' is not an IP set", id)
+			continue
+		}
+		for _, address := range splitNsxvAddresses(ipSet.IPAddresses) {
+			if strings.ContainsAny(address, "/-") {
+				planner.issue(NsxvMigrationKindDhcpRelay, ipSet.ID, ipSet.Name, false, "relay server range '%s' is not supported", address)
+				continue
+			}
+			servers = append(servers, address)
+		}
+	}
+	if len(relay.RelayServer.Fqdns) > 0 {
+		planner.issue(NsxvMigrationKindDhcpRelay, "", "", false, "relay servers by FQDN %v are not supported", relay.RelayServer.Fqdns)
+	}
+	if len(servers) == 0 {
+		return
+	}
+	if relay.RelayAgents != nil && len(relay.RelayAgents.Agents) > 0 {
+		planner.issue(NsxvMigrationKindDhcpRelay, "", "", false, "relay agents are not migrated, networks must be set to DHCP RELAY mode")
+	}
+	planner.plan.DhcpForwarder = &types.NsxtEdgeGatewayDhcpForwarder{Enabled: true, DhcpServers: servers}
+}
+
+// nsxvServiceToAppPort converts an NSX-V protocol and ports into an Application Port Profile port. It returns nil
+// when any traffic is allowed
+func nsxvServiceToAppPort(protocol, port, sourcePort string) (*types.NsxtAppPortProfilePort, error) {
+	appPort := &types.NsxtAppPortProfilePort{}
+	switch strings.ToLower(protocol) {
+	case "", "any":
+		if !isAnyNsxvPort(port) {
+			return nil, fmt.Errorf("port '%s' requires a protocol", port)
+		}
+		return nil, nil
+	case "tcp", "udp":
+		appPort.Protocol = strings.ToUpper(protocol)
+	case "icmp":
+		appPort.Protocol = "ICMPv4"
+		return appPort, nil
+	default:
+		return nil, fmt.Errorf("protocol '%s' cannot be mapped", protocol)
+	}
+	if !isAnyNsxvPort(sourcePort) {
+		return nil, fmt.Errorf("source port '%s' is not supported by Application Port Profiles", sourcePort)
+	}
+	if !isAnyNsxvPort(port) {
+		appPort.DestinationPorts = splitNsxvPorts(port)
+	}
+	return appPort, nil
+}
+
+func isAnyNsxvPort(port string) bool {
+	return port == "" || strings.EqualFold(port, "any")
+}
+
+// splitNsxvPorts converts NSX-V port lists ('80,443,8000-8080') into a list of ports and ranges
+func splitNsxvPorts(ports string) []string {
+	var result []string
+	for _, port := range strings.Split(ports, ",") {
+		port = strings.TrimSpace(port)
+		if _, err := strconv.Atoi(port); err == nil || strings.Contains(port, "-") {
+			result = append(result, port)
+		}
+	}
+	return result
+}
+
+// splitNsxvAddresses converts the comma separated addresses of an NSX-V IP set into a list
+func splitNsxvAddresses(addresses string) []string {
+	var result []string
+	for _, address := range strings.Split(addresses, ",") {
+		address = strings.TrimSpace(address)
+		if address != "" {
+			result = append(result, address)
+		}
+	}
+	return result
+}
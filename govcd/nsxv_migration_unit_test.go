@@ -0,0 +1,192 @@
+//go:build unit || ALL
+
+/*
+ * Copyright 2025 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+package govcd
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// Test_PlanNsxvMigration checks the translation of NSX-V Edge Gateway services into NSX-T objects
+func Test_PlanNsxvMigration(t *testing.T) {
+	source := &NsxvMigrationSource{
+		IpSets: []*types.EdgeIpSet{
+			{ID: "vdc-1:ipset-1", Name: "web-servers", IPAddresses: "10.0.0.10,10.0.0.11"},
+			{ID: "vdc-1:ipset-2", Name: "dhcp-servers", IPAddresses: "10.0.5.1, 10.0.6.0/24"},
+		},
+		FirewallRules: []*types.EdgeFirewallRule{
+			{ID: "1", Name: "firewall", RuleType: "internal_high", Action: "accept"},
+			{ID: "2", Name: "web", RuleType: "user", Action: "accept", Enabled: true,
+				Source:      types.EdgeFirewallEndpoint{IpAddresses: []string{"192.168.1.0/24"}},
+				Destination: types.EdgeFirewallEndpoint{GroupingObjectIds: []string{"ipset-1"}},
+				Application: types.EdgeFirewallApplication{Services: []types.EdgeFirewallApplicationService{{Protocol: "tcp", Port: "80,443"}}}},
+			{ID: "3", Name: "excluded", RuleType: "user", Action: "deny",
+				Source: types.EdgeFirewallEndpoint{Exclude: true, IpAddresses: []string{"10.0.0.1"}}},
+			{ID: "4", Name: "vnic", RuleType: "user", Action: "deny", Direction: "in",
+				Source: types.EdgeFirewallEndpoint{VnicGroupIds: []string{"internal"}}},
+		},
+		NatRules: []*types.EdgeNatRule{
+			{ID: "10", RuleType: "user", Action: "dnat", Enabled: true, OriginalAddress: "203.0.113.10", OriginalPort: "8080",
+				TranslatedAddress: "10.0.0.10", TranslatedPort: "80", Protocol: "tcp"},
+			{ID: "11", RuleType: "user", Action: "snat", Description: "outbound", OriginalAddress: "10.0.0.0/24", TranslatedAddress: "203.0.113.1"},
+		},
+		LbMonitors: []*types.LbMonitor{{ID: "monitor-1", Name: "web-check", Type: "http", URL: "/health"}},
+		LbAppProfiles: []*types.LbAppProfile{
+			{ID: "profile-1", Template: "HTTP"},
+			{ID: "profile-2", Template: "HTTPS"},
+		},
+		LbPools: []*types.LbPool{
+			{ID: "pool-1", Name: "web-pool", Algorithm: "round-robin", MonitorId: "monitor-1",
+				Members: types.LbPoolMembers{{IpAddress: "10.0.0.10", Port: 80, Weight: 2}, {IpAddress: "10.0.0.11", Port: 80, Condition: "disabled"}}},
+			{ID: "pool-2", Name: "uri-pool", Algorithm: "uri"},
+		},
+		LbVirtualServers: []*types.LbVirtualServer{
+			{ID: "vs-1", Name: "web", Enabled: true, IpAddress: "203.0.113.20", Protocol: "http", Port: 80,
+				ApplicationProfileId: "profile-1", DefaultPoolId: "pool-1"},
+			{ID: "vs-2", Name: "secure", IpAddress: "203.0.113.21", Protocol: "https", Port: 443,
+				ApplicationProfileId: "profile-2", DefaultPoolId: "pool-1"},
+		},
+		DhcpRelay: &types.EdgeDhcpRelay{RelayServer: &types.EdgeDhcpRelayServer{
+			IpAddress:        []string{"10.0.9.1"},
+			GroupingObjectId: []string{"vdc-1:ipset-2"},
+		}},
+	}
+
+	plan := PlanNsxvMigration(source)
+
+	expectedRules := []FirewallPolicyRule{
+		{Name: "web", Action: "ALLOW", Enabled: true, Direction: "IN_OUT", IpProtocol: "IPV4_IPV6",
+			Sources: []string{"migrated-rule-2-source"}, Destinations: []string{"web-servers"},
+			ApplicationPortProfiles: []string{"migrated-rule-2"}},
+	}
+	if !reflect.DeepEqual(plan.Firewall.Rules, expectedRules) {
+		t.Fatalf("unexpected firewall rules:\ngot:  %+v\nwant: %+v", plan.Firewall.Rules, expectedRules)
+	}
+	if len(plan.Firewall.IpSets) != 2 || plan.Firewall.IpSets[1].Name != "web-servers" ||
+		!reflect.DeepEqual(plan.Firewall.IpSets[1].IpAddresses, []string{"10.0.0.10", "10.0.0.11"}) {
+		t.Fatalf("unexpected IP sets: %+v", plan.Firewall.IpSets)
+	}
+	expectedProfiles := map[string][]types.NsxtAppPortProfilePort{
+		"migrated-nat-10": {{Protocol: "TCP", DestinationPorts: []string{"80"}}},
+		"migrated-rule-2": {{Protocol: "TCP", DestinationPorts: []string{"80", "443"}}},
+	}
+	for _, profile := range plan.Firewall.AppPortProfiles {
+		if !reflect.DeepEqual(profile.Ports, expectedProfiles[profile.Name]) {
+			t.Fatalf("unexpected ports for profile '%s': %+v", profile.Name, profile.Ports)
+		}
+	}
+	if len(plan.Firewall.AppPortProfiles) != len(expectedProfiles) {
+		t.Fatalf("unexpected profiles: %+v", plan.Firewall.AppPortProfiles)
+	}
+
+	if len(plan.NatRules) != 2 {
+		t.Fatalf("expected 2 NAT rules, got %d", len(plan.NatRules))
+	}
+	dnat := plan.NatRules[0]
+	if dnat.RuleType != types.NsxtNatRuleTypeDnat || dnat.ExternalAddresses != "203.0.113.10" || dnat.InternalAddresses != "10.0.0.10" ||
+		dnat.DnatExternalPort != "8080" || dnat.ApplicationPortProfile == nil || dnat.ApplicationPortProfile.Name != "migrated-nat-10" {
+		t.Fatalf("unexpected DNAT rule: %+v", dnat)
+	}
+	snat := plan.NatRules[1]
+	if snat.Name != "migrated-nat-11" || snat.Description != "outbound" || snat.RuleType != types.NsxtNatRuleTypeSnat || snat.InternalAddresses != "10.0.0.0/24" {
+		t.Fatalf("unexpected SNAT rule: %+v", snat)
+	}
+
+	if len(plan.AlbPools) != 2 {
+		t.Fatalf("expected 2 ALB pools, got %d", len(plan.AlbPools))
+	}
+	pool := plan.AlbPools[0]
+	if pool.Algorithm != "ROUND_ROBIN" || len(pool.Members) != 2 || pool.Members[0].Ratio == nil || *pool.Members[0].Ratio != 2 ||
+		pool.Members[1].Enabled || len(pool.HealthMonitors) != 1 || pool.HealthMonitors[0].Type != "HTTP" {
+		t.Fatalf("unexpected ALB pool: %+v", pool)
+	}
+	if len(plan.AlbVirtualServices) != 1 || plan.AlbVirtualServices[0].ApplicationProfile.Type != "HTTP" ||
+		plan.AlbVirtualServices[0].LoadBalancerPoolRef.Name != "web-pool" {
+		t.Fatalf("unexpected ALB virtual services: %+v", plan.AlbVirtualServices)
+	}
+
+	if plan.DhcpForwarder == nil || !reflect.DeepEqual(plan.DhcpForwarder.DhcpServers, []string{"10.0.9.1", "10.0.5.1"}) {
+		t.Fatalf("unexpected DHCP forwarder: %+v", plan.DhcpForwarder)
+	}
+
+	type issueKey struct {
+		kind, id string
+		skipped  bool
+	}
+	expectedIssues := map[issueKey]bool{
+		{NsxvMigrationKindFirewallRule, "3", true}:           true,
+		{NsxvMigrationKindFirewallRule, "4", true}:           true,
+		{NsxvMigrationKindLbPool, "pool-1", false}:           true,
+		{NsxvMigrationKindLbPool, "pool-2", false}:           true,
+		{NsxvMigrationKindLbVirtualServer, "vs-2", true}:     true,
+		{NsxvMigrationKindDhcpRelay, "vdc-1:ipset-2", false}: true,
+	}
+	if len(plan.Issues) != len(expectedIssues) {
+		t.Fatalf("unexpected issues: %+v", plan.Issues)
+	}
+	for _, issue := range plan.Issues {
+		if !expectedIssues[issueKey{issue.Kind, issue.Id, issue.Skipped}] {
+			t.Fatalf("unexpected issue: %+v", issue)
+		}
+	}
+}
+
+// Test_nsxvServiceToAppPort checks the conversion of NSX-V services
+func Test_nsxvServiceToAppPort(t *testing.T) {
+	port, err := nsxvServiceToAppPort("any", "any", "")
+	if err != nil || port != nil {
+		t.Fatalf("expected no port for any traffic, got %+v (error %v)", port, err)
+	}
+	port, err = nsxvServiceToAppPort("udp", "53, 5000-5010", "any")
+	if err != nil || !reflect.DeepEqual(port, &types.NsxtAppPortProfilePort{Protocol: "UDP", DestinationPorts: []string{"53", "5000-5010"}}) {
+		t.Fatalf("unexpected port %+v (error %v)", port, err)
+	}
+	for _, invalid := range [][3]string{{"tcp", "80", "1024"}, {"gre", "", ""}, {"any", "80", ""}} {
+		_, err = nsxvServiceToAppPort(invalid[0], invalid[1], invalid[2])
+		if err == nil {
+			t.Fatalf("expected error for %v", invalid)
+		}
+	}
+}
+
+// Test_validateNsxvMigrationTarget checks that a plan is refused when it would remove or clash with existing rules
+func Test_validateNsxvMigrationTarget(t *testing.T) {
+	plan := &NsxvMigrationPlan{
+		Firewall: &FirewallPolicyDocument{
+			AppPortProfiles: []FirewallPolicyAppPortProfile{{Name: "migrated-nat-10"}},
+			Rules:           []FirewallPolicyRule{{Name: "migrated-rule-1"}},
+		},
+		NatRules: []*types.NsxtNatRule{
+			{Name: "migrated-nat-10", ApplicationPortProfile: &types.OpenApiReference{Name: "migrated-nat-10"}},
+			{Name: "migrated-nat-11", ApplicationPortProfile: &types.OpenApiReference{Name: "HTTPS"}},
+		},
+		AlbPools:           []*types.NsxtAlbPool{{Name: "web-pool"}},
+		AlbVirtualServices: []*types.NsxtAlbVirtualService{{Name: "web", LoadBalancerPoolRef: types.OpenApiReference{Name: "web-pool"}}},
+	}
+
+	missingProfiles, err := validateNsxvMigrationTarget(plan, []string{"migrated-rule-1"}, []string{"other"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(missingProfiles, []string{"HTTPS"}) {
+		t.Fatalf("expected profile HTTPS to be resolved, got %v", missingProfiles)
+	}
+
+	plan.AlbVirtualServices[0].LoadBalancerPoolRef.Name = "api-pool"
+	_, err = validateNsxvMigrationTarget(plan, []string{"migrated-rule-1", "allow-ssh"}, []string{"migrated-nat-11"})
+	if err == nil {
+		t.Fatalf("expected error for conflicting Edge Gateway")
+	}
+	for _, expected := range []string{"'allow-ssh' would be removed", "NAT rule 'migrated-nat-11' already exists", "ALB pool 'api-pool'"} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Fatalf("expected error to contain %q, got: %s", expected, err)
+		}
+	}
+}
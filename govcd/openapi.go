@@ -7,6 +7,7 @@ package govcd
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -21,6 +22,10 @@ import (
 	"github.com/vmware/go-vcloud-director/v3/util"
 )
 
+// errOpenApiPreconditionFailed is wrapped into the errors of OpenAPI PUT and POST requests rejected with HTTP 412
+// (Precondition Failed)
+var errOpenApiPreconditionFailed = errors.New("precondition failed")
+
 // This file contains generalised low level methods to interact with VCD OpenAPI REST endpoints as documented in
 // https://{VCD_HOST}/docs. In addition to this there are OpenAPI browser endpoints for tenant and provider
 // respectively https://{VCD_HOST}/api-explorer/tenant/tenant-name and https://{VCD_HOST}/api-explorer/provider .
@@ -609,6 +614,14 @@ func (client *Client) openApiPerformPostPut(httpMethod string, apiVersion string
 		return nil, err
 	}
 
+	// HTTP 412: Precondition Failed - is returned when the If-Match header does not match the current Etag of the
+	// entity. The error wraps errOpenApiPreconditionFailed, so that callers can detect it and retry
+	if resp.StatusCode == http.StatusPreconditionFailed {
+		err := ParseErr(types.BodyTypeJSON, resp, &types.OpenApiError{})
+		closeErr := resp.Body.Close()
+		return nil, fmt.Errorf("error in HTTP %s request: %w: %s [body close error: %s]", httpMethod, errOpenApiPreconditionFailed, err, closeErr)
+	}
+
 	// resp is ignored below because it is the same the one above
 	_, err = checkRespWithErrType(types.BodyTypeJSON, resp, err, &types.OpenApiError{})
 	if err != nil {
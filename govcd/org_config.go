@@ -0,0 +1,779 @@
+/*
+ * Copyright 2025 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+package govcd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+	"github.com/vmware/go-vcloud-director/v3/util"
+	"sigs.k8s.io/yaml"
+)
+
+// OrgConfigDocumentVersion is the schema version of OrgConfigDocument produced by ExportOrgConfig
+const OrgConfigDocumentVersion = "1"
+
+// OrgConfigDocument is a declarative snapshot of an Organization and the resources it owns.
+//
+// All cross-references (VDC owner of a network, edge gateway of a network, firewall groups used in
+// rules, role of a user, etc.) are stored by name instead of ID or HREF so that documents
+// exported from different environments can be compared. Lists are sorted by name, with the
+// exception of firewall rules that keep their evaluation order.
+type OrgConfigDocument struct {
+	Version      string                  `json:"version"`
+	Org          OrgConfigSettings       `json:"org"`
+	Vdcs         []OrgConfigVdc          `json:"vdcs,omitempty"`
+	Catalogs     []OrgConfigCatalog      `json:"catalogs,omitempty"`
+	Roles        []OrgConfigRole         `json:"roles,omitempty"`
+	Users        []OrgConfigUser         `json:"users,omitempty"`
+	EdgeGateways []OrgConfigEdgeGateway  `json:"edgeGateways,omitempty"`
+	Networks     []OrgConfigNetwork      `json:"networks,omitempty"`
+	VApps        []OrgConfigVApp         `json:"vApps,omitempty"`
+	Skipped      []OrgConfigSkippedEntry `json:"skipped,omitempty"`
+}
+
+// OrgConfigSettings holds the general settings of an Organization
+type OrgConfigSettings struct {
+	Name                 string          `json:"name"`
+	FullName             string          `json:"fullName,omitempty"`
+	Description          string          `json:"description,omitempty"`
+	Enabled              bool            `json:"enabled"`
+	CanPublishCatalogs   bool            `json:"canPublishCatalogs"`
+	CanPublishExternally bool            `json:"canPublishExternally"`
+	CanSubscribe         bool            `json:"canSubscribe"`
+	DeployedVmQuota      int             `json:"deployedVmQuota"`
+	StoredVmQuota        int             `json:"storedVmQuota"`
+	VAppLease            *OrgConfigLease `json:"vAppLease,omitempty"`
+	VAppTemplateLease    *OrgConfigLease `json:"vAppTemplateLease,omitempty"`
+}
+
+// OrgConfigLease holds lease settings for vApps or vApp templates
+type OrgConfigLease struct {
+	DeploymentLeaseSeconds           *int  `json:"deploymentLeaseSeconds,omitempty"`
+	StorageLeaseSeconds              *int  `json:"storageLeaseSeconds,omitempty"`
+	DeleteOnStorageLeaseExpiration   *bool `json:"deleteOnStorageLeaseExpiration,omitempty"`
+	PowerOffOnRuntimeLeaseExpiration *bool `json:"powerOffOnRuntimeLeaseExpiration,omitempty"`
+}
+
+// OrgConfigVdc describes an Org VDC
+type OrgConfigVdc struct {
+	Name                 string             `json:"name"`
+	Description          string             `json:"description,omitempty"`
+	Enabled              bool               `json:"enabled"`
+	AllocationModel      string             `json:"allocationModel"`
+	ProviderVdc          string             `json:"providerVdc,omitempty"`
+	NetworkPool          string             `json:"networkPool,omitempty"`
+	Cpu                  *OrgConfigCapacity `json:"cpu,omitempty"`
+	Memory               *OrgConfigCapacity `json:"memory,omitempty"`
+	NicQuota             int                `json:"nicQuota"`
+	NetworkQuota         int                `json:"networkQuota"`
+	VmQuota              int                `json:"vmQuota"`
+	StorageProfiles      []string           `json:"storageProfiles,omitempty"`
+	DefaultComputePolicy string             `json:"defaultComputePolicy,omitempty"`
+	ThinProvisioning     *bool              `json:"thinProvisioning,omitempty"`
+	FastProvisioning     *bool              `json:"fastProvisioning,omitempty"`
+}
+
+// OrgConfigCapacity describes CPU or memory capacity of an Org VDC
+type OrgConfigCapacity struct {
+	Units     string `json:"units"`
+	Allocated int64  `json:"allocated"`
+	Limit     int64  `json:"limit"`
+}
+
+// OrgConfigCatalog describes a catalog
+type OrgConfigCatalog struct {
+	Name            string   `json:"name"`
+	Description     string   `json:"description,omitempty"`
+	Published       bool     `json:"published"`
+	StorageProfiles []string `json:"storageProfiles,omitempty"`
+}
+
+// OrgConfigRole describes a tenant role with its rights
+type OrgConfigRole struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	ReadOnly    bool     `json:"readOnly,omitempty"`
+	Rights      []string `json:"rights,omitempty"`
+}
+
+// OrgConfigUser describes an Org user. Passwords are never exported.
+type OrgConfigUser struct {
+	Name            string `json:"name"`
+	FullName        string `json:"fullName,omitempty"`
+	Description     string `json:"description,omitempty"`
+	EmailAddress    string `json:"emailAddress,omitempty"`
+	Telephone       string `json:"telephone,omitempty"`
+	Enabled         bool   `json:"enabled"`
+	External        bool   `json:"external,omitempty"`
+	ProviderType    string `json:"providerType,omitempty"`
+	Role            string `json:"role,omitempty"`
+	StoredVmQuota   int    `json:"storedVmQuota"`
+	DeployedVmQuota int    `json:"deployedVmQuota"`
+}
+
+// OrgConfigEdgeGateway describes an NSX-T Edge Gateway together with its IP Sets, NAT and
+// firewall rules
+type OrgConfigEdgeGateway struct {
+	Name           string                       `json:"name"`
+	Description    string                       `json:"description,omitempty"`
+	Owner          string                       `json:"owner"`
+	OwnerType      string                       `json:"ownerType"`
+	DeploymentMode string                       `json:"deploymentMode,omitempty"`
+	Uplinks        []OrgConfigEdgeGatewayUplink `json:"uplinks,omitempty"`
+	IpSets         []OrgConfigIpSet             `json:"ipSets,omitempty"`
+	NatRules       []OrgConfigNatRule           `json:"natRules,omitempty"`
+	FirewallRules  []OrgConfigFirewallRule      `json:"firewallRules,omitempty"`
+}
+
+// OrgConfigEdgeGatewayUplink describes an uplink of an Edge Gateway
+type OrgConfigEdgeGatewayUplink struct {
+	ExternalNetwork string            `json:"externalNetwork"`
+	Dedicated       bool              `json:"dedicated,omitempty"`
+	Subnets         []OrgConfigSubnet `json:"subnets,omitempty"`
+}
+
+// OrgConfigSubnet describes a subnet of an Edge Gateway uplink or an Org VDC network. IP ranges are
+// stored as "start-end" strings (or a single address when start and end are equal)
+type OrgConfigSubnet struct {
+	Gateway      string   `json:"gateway"`
+	PrefixLength int      `json:"prefixLength"`
+	PrimaryIp    string   `json:"primaryIp,omitempty"`
+	DnsServer1   string   `json:"dnsServer1,omitempty"`
+	DnsServer2   string   `json:"dnsServer2,omitempty"`
+	DnsSuffix    string   `json:"dnsSuffix,omitempty"`
+	IpRanges     []string `json:"ipRanges,omitempty"`
+}
+
+// OrgConfigIpSet describes an NSX-T Firewall Group of type IP Set
+type OrgConfigIpSet struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	IpAddresses []string `json:"ipAddresses,omitempty"`
+}
+
+// OrgConfigNatRule describes an NSX-T NAT rule
+type OrgConfigNatRule struct {
+	Name                     string `json:"name"`
+	Description              string `json:"description,omitempty"`
+	Enabled                  bool   `json:"enabled"`
+	Type                     string `json:"type"`
+	ExternalAddresses        string `json:"externalAddresses,omitempty"`
+	InternalAddresses        string `json:"internalAddresses,omitempty"`
+	ApplicationPortProfile   string `json:"applicationPortProfile,omitempty"`
+	InternalPort             string `json:"internalPort,omitempty"`
+	DnatExternalPort         string `json:"dnatExternalPort,omitempty"`
+	SnatDestinationAddresses string `json:"snatDestinationAddresses,omitempty"`
+	Logging                  bool   `json:"logging,omitempty"`
+	FirewallMatch            string `json:"firewallMatch,omitempty"`
+	Priority                 *int   `json:"priority,omitempty"`
+}
+
+// OrgConfigFirewallRule describes a user defined NSX-T Edge Gateway firewall rule. Empty source,
+// destination or application port profile lists mean 'Any'
+type OrgConfigFirewallRule struct {
+	Name                    string   `json:"name"`
+	Action                  string   `json:"action"`
+	Enabled                 bool     `json:"enabled"`
+	Direction               string   `json:"direction"`
+	IpProtocol              string   `json:"ipProtocol"`
+	Logging                 bool     `json:"logging,omitempty"`
+	Sources                 []string `json:"sources,omitempty"`
+	Destinations            []string `json:"destinations,omitempty"`
+	ApplicationPortProfiles []string `json:"applicationPortProfiles,omitempty"`
+}
+
+// OrgConfigNetwork describes an Org VDC network backed by OpenAPI
+type OrgConfigNetwork struct {
+	Name           string            `json:"name"`
+	Description    string            `json:"description,omitempty"`
+	Owner          string            `json:"owner"`
+	OwnerType      string            `json:"ownerType"`
+	NetworkType    string            `json:"networkType"`
+	EdgeGateway    string            `json:"edgeGateway,omitempty"`
+	ConnectionType string            `json:"connectionType,omitempty"`
+	Shared         *bool             `json:"shared,omitempty"`
+	DualStack      *bool             `json:"dualStack,omitempty"`
+	Subnets        []OrgConfigSubnet `json:"subnets,omitempty"`
+}
+
+// OrgConfigVApp describes a vApp by name and the networks it is connected to. It is a placeholder
+// that allows reconciliation to know which vApps are expected to exist in a VDC.
+type OrgConfigVApp struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Vdc         string   `json:"vdc"`
+	Networks    []string `json:"networks,omitempty"`
+}
+
+// OrgConfigSkippedEntry records a resource that could not be exported, together with the reason
+type OrgConfigSkippedEntry struct {
+	Kind   string `json:"kind"`
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// Owner types used in OrgConfigEdgeGateway.OwnerType and OrgConfigNetwork.OwnerType
+const (
+	OrgConfigOwnerTypeVdc      = "vdc"
+	OrgConfigOwnerTypeVdcGroup = "vdcGroup"
+)
+
+// OrgConfigExportOptions allows to exclude parts of an Organization from the export
+type OrgConfigExportOptions struct {
+	SkipCatalogs   bool
+	SkipRoles      bool
+	SkipUsers      bool
+	SkipNetworking bool
+	SkipVApps      bool
+	// SkipRoleRights exports roles without their rights, which saves one API call per role
+	SkipRoleRights bool
+}
+
+// ToYaml serializes the document to YAML
+func (doc *OrgConfigDocument) ToYaml() ([]byte, error) {
+	return yaml.Marshal(doc)
+}
+
+// ToJson serializes the document to indented JSON
+func (doc *OrgConfigDocument) ToJson() ([]byte, error) {
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// ParseOrgConfigDocument reads an OrgConfigDocument from YAML or JSON content. Unknown fields are
+// rejected so that typos do not silently drop configuration.
+func ParseOrgConfigDocument(content []byte) (*OrgConfigDocument, error) {
+	doc := &OrgConfigDocument{}
+	err := yaml.UnmarshalStrict(content, doc)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing Org configuration document: %s", err)
+	}
+	if doc.Version != OrgConfigDocumentVersion {
+		return nil, fmt.Errorf("unsupported Org configuration document version '%s'", doc.Version)
+	}
+	return doc, nil
+}
+
+// ExportOrgConfig walks the Organization and returns an OrgConfigDocument describing its VDCs,
+// catalogs, roles, users, NSX-T Edge Gateways (with IP Sets, NAT and firewall rules), Org VDC
+// networks and vApps. 'options' can be nil to export everything.
+//
+// Resources that cannot be retrieved (e.g. due to missing rights) do not fail the export, but are
+// listed in OrgConfigDocument.Skipped
+func (adminOrg *AdminOrg) ExportOrgConfig(options *OrgConfigExportOptions) (*OrgConfigDocument, error) {
+	if options == nil {
+		options = &OrgConfigExportOptions{}
+	}
+	err := adminOrg.Refresh()
+	if err != nil {
+		return nil, fmt.Errorf("error refreshing Org '%s': %s", adminOrg.AdminOrg.Name, err)
+	}
+
+	doc := &OrgConfigDocument{
+		Version: OrgConfigDocumentVersion,
+		Org:     orgConfigSettingsFromAdminOrg(adminOrg.AdminOrg),
+	}
+
+	err = adminOrg.exportOrgConfigVdcs(doc, options)
+	if err != nil {
+		return nil, err
+	}
+
+	if !options.SkipCatalogs {
+		adminOrg.exportOrgConfigCatalogs(doc)
+	}
+
+	if !options.SkipRoles {
+		err = adminOrg.exportOrgConfigRoles(doc, options)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if !options.SkipUsers {
+		adminOrg.exportOrgConfigUsers(doc)
+	}
+
+	if !options.SkipNetworking {
+		err = adminOrg.exportOrgConfigNetworking(doc)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	doc.sort()
+	return doc, nil
+}
+
+func (adminOrg *AdminOrg) exportOrgConfigVdcs(doc *OrgConfigDocument, options *OrgConfigExportOptions) error {
+	if adminOrg.AdminOrg.Vdcs == nil {
+		return nil
+	}
+	for _, vdcRef := range adminOrg.AdminOrg.Vdcs.Vdcs {
+		adminVdc, err := adminOrg.GetAdminVDCByName(vdcRef.Name, false)
+		if err != nil {
+			return fmt.Errorf("error retrieving VDC '%s': %s", vdcRef.Name, err)
+		}
+		doc.Vdcs = append(doc.Vdcs, orgConfigVdcFromAdminVdc(adminVdc.AdminVdc))
+
+		if options.SkipVApps {
+			continue
+		}
+		vdc, err := adminOrg.GetVDCByName(vdcRef.Name, false)
+		if err != nil {
+			return fmt.Errorf("error retrieving VDC '%s': %s", vdcRef.Name, err)
+		}
+		for _, vappRef := range vdc.GetVappList() {
+			vapp, err := vdc.GetVAppByHref(vappRef.HREF)
+			if err != nil {
+				doc.skip("vApp", vappRef.Name, err)
+				continue
+			}
+			doc.VApps = append(doc.VApps, orgConfigVAppFromVApp(vapp.VApp, vdc.Vdc.Name))
+		}
+	}
+	return nil
+}
+
+func (adminOrg *AdminOrg) exportOrgConfigCatalogs(doc *OrgConfigDocument) {
+	if adminOrg.AdminOrg.Catalogs == nil {
+		return
+	}
+	for _, catalogRef := range adminOrg.AdminOrg.Catalogs.Catalog {
+		catalog, err := adminOrg.GetAdminCatalogByHref(catalogRef.HREF)
+		if err != nil {
+			doc.skip("catalog", catalogRef.Name, err)
+			continue
+		}
+		doc.Catalogs = append(doc.Catalogs, orgConfigCatalogFromAdminCatalog(catalog.AdminCatalog))
+	}
+}
+
+func (adminOrg *AdminOrg) exportOrgConfigRoles(doc *OrgConfigDocument, options *OrgConfigExportOptions) error {
+	roles, err := adminOrg.GetAllRoles(nil)
+	if err != nil {
+		return fmt.Errorf("error retrieving roles: %s", err)
+	}
+	for _, role := range roles {
+		configRole := OrgConfigRole{
+			Name:        role.Role.Name,
+			Description: role.Role.Description,
+			ReadOnly:    role.Role.ReadOnly,
+		}
+		if !options.SkipRoleRights {
+			rights, err := role.GetRights(nil)
+			if err != nil {
+				doc.skip("role", role.Role.Name, err)
+				continue
+			}
+			for _, right := range rights {
+				configRole.Rights = append(configRole.Rights, right.Name)
+			}
+		}
+		doc.Roles = append(doc.Roles, configRole)
+	}
+	return nil
+}
+
+func (adminOrg *AdminOrg) exportOrgConfigUsers(doc *OrgConfigDocument) {
+	if adminOrg.AdminOrg.Users == nil {
+		return
+	}
+	for _, userRef := range adminOrg.AdminOrg.Users.User {
+		user, err := adminOrg.GetUserByHref(userRef.HREF)
+		if err != nil {
+			doc.skip("user", userRef.Name, err)
+			continue
+		}
+		doc.Users = append(doc.Users, orgConfigUserFromUser(user.User))
+	}
+}
+
+func (adminOrg *AdminOrg) exportOrgConfigNetworking(doc *OrgConfigDocument) error {
+	orgFilter := url.Values{}
+	orgFilter.Set("filter", "orgRef.id=="+adminOrg.AdminOrg.ID)
+
+	edgeGateways, err := adminOrg.GetAllNsxtEdgeGateways(orgFilter)
+	if err != nil {
+		return fmt.Errorf("error retrieving NSX-T Edge Gateways: %s", err)
+	}
+
+	for _, egw := range edgeGateways {
+		configEgw := orgConfigEdgeGatewayFromEdgeGateway(egw.EdgeGateway)
+
+		ipSets, err := egw.GetAllNsxtFirewallGroups(nil, types.FirewallGroupTypeIpSet)
+		if err != nil {
+			doc.skip("ipSets", egw.EdgeGateway.Name, err)
+		}
+		for _, ipSet := range ipSets {
+			configEgw.IpSets = append(configEgw.IpSets, orgConfigIpSetFromFirewallGroup(ipSet.NsxtFirewallGroup))
+		}
+
+		natRules, err := egw.GetAllNatRules(nil)
+		if err != nil {
+			doc.skip("natRules", egw.EdgeGateway.Name, err)
+		}
+		for _, natRule := range natRules {
+			configEgw.NatRules = append(configEgw.NatRules, orgConfigNatRuleFromNatRule(natRule.NsxtNatRule))
+		}
+
+		firewall, err := egw.GetNsxtFirewall()
+		if err != nil {
+			doc.skip("firewallRules", egw.EdgeGateway.Name, err)
+		} else {
+			configEgw.FirewallRules = orgConfigFirewallRulesFromContainer(firewall.NsxtFirewallRuleContainer)
+		}
+
+		doc.EdgeGateways = append(doc.EdgeGateways, configEgw)
+	}
+
+	networks, err := adminOrg.GetAllOpenApiOrgVdcNetworks(orgFilter, false)
+	if err != nil {
+		return fmt.Errorf("error retrieving Org VDC networks: %s", err)
+	}
+	for _, network := range networks {
+		doc.Networks = append(doc.Networks, orgConfigNetworkFromNetwork(network.OpenApiOrgVdcNetwork))
+	}
+
+	return nil
+}
+
+// skip records a resource that could not be exported
+func (doc *OrgConfigDocument) skip(kind, name string, err error) {
+	util.Logger.Printf("[TRACE] skipping %s '%s' in Org configuration export: %s", kind, name, err)
+	doc.Skipped = append(doc.Skipped, OrgConfigSkippedEntry{Kind: kind, Name: name, Reason: err.Error()})
+}
+
+// sort orders all lists by name so that exports of the same configuration are byte-identical.
+// Firewall rules are evaluated in order and are therefore left untouched.
+func (doc *OrgConfigDocument) sort() {
+	sort.SliceStable(doc.Vdcs, func(i, j int) bool { return doc.Vdcs[i].Name < doc.Vdcs[j].Name })
+	sort.SliceStable(doc.Catalogs, func(i, j int) bool { return doc.Catalogs[i].Name < doc.Catalogs[j].Name })
+	sort.SliceStable(doc.Roles, func(i, j int) bool { return doc.Roles[i].Name < doc.Roles[j].Name })
+	sort.SliceStable(doc.Users, func(i, j int) bool { return doc.Users[i].Name < doc.Users[j].Name })
+	sort.SliceStable(doc.Networks, func(i, j int) bool { return doc.Networks[i].Name < doc.Networks[j].Name })
+	sort.SliceStable(doc.VApps, func(i, j int) bool {
+		if doc.VApps[i].Vdc != doc.VApps[j].Vdc {
+			return doc.VApps[i].Vdc < doc.VApps[j].Vdc
+		}
+		return doc.VApps[i].Name < doc.VApps[j].Name
+	})
+	sort.SliceStable(doc.EdgeGateways, func(i, j int) bool { return doc.EdgeGateways[i].Name < doc.EdgeGateways[j].Name })
+	for index := range doc.EdgeGateways {
+		egw := &doc.EdgeGateways[index]
+		sort.SliceStable(egw.IpSets, func(i, j int) bool { return egw.IpSets[i].Name < egw.IpSets[j].Name })
+		sort.SliceStable(egw.NatRules, func(i, j int) bool { return egw.NatRules[i].Name < egw.NatRules[j].Name })
+	}
+	for index := range doc.Roles {
+		sort.Strings(doc.Roles[index].Rights)
+	}
+}
+
+func orgConfigSettingsFromAdminOrg(org *types.AdminOrg) OrgConfigSettings {
+	settings := OrgConfigSettings{
+		Name:        org.Name,
+		FullName:    org.FullName,
+		Description: org.Description,
+		Enabled:     org.IsEnabled,
+	}
+	if org.OrgSettings == nil {
+		return settings
+	}
+	if general := org.OrgSettings.OrgGeneralSettings; general != nil {
+		settings.CanPublishCatalogs = general.CanPublishCatalogs
+		settings.CanPublishExternally = general.CanPublishExternally
+		settings.CanSubscribe = general.CanSubscribe
+		settings.DeployedVmQuota = general.DeployedVMQuota
+		settings.StoredVmQuota = general.StoredVMQuota
+	}
+	if lease := org.OrgSettings.OrgVAppLeaseSettings; lease != nil {
+		settings.VAppLease = &OrgConfigLease{
+			DeploymentLeaseSeconds:           lease.DeploymentLeaseSeconds,
+			StorageLeaseSeconds:              lease.StorageLeaseSeconds,
+			DeleteOnStorageLeaseExpiration:   lease.DeleteOnStorageLeaseExpiration,
+			PowerOffOnRuntimeLeaseExpiration: lease.PowerOffOnRuntimeLeaseExpiration,
+		}
+	}
+	if lease := org.OrgSettings.OrgVAppTemplateSettings; lease != nil {
+		settings.VAppTemplateLease = &OrgConfigLease{
+			StorageLeaseSeconds:            lease.StorageLeaseSeconds,
+			DeleteOnStorageLeaseExpiration: lease.DeleteOnStorageLeaseExpiration,
+		}
+	}
+	return settings
+}
+
+func orgConfigVdcFromAdminVdc(adminVdc *types.AdminVdc) OrgConfigVdc {
+	vdc := OrgConfigVdc{
+		Name:             adminVdc.Name,
+		Description:      adminVdc.Description,
+		Enabled:          adminVdc.IsEnabled,
+		AllocationModel:  adminVdc.AllocationModel,
+		NicQuota:         adminVdc.NicQuota,
+		NetworkQuota:     adminVdc.NetworkQuota,
+		VmQuota:          adminVdc.VMQuota,
+		ThinProvisioning: adminVdc.IsThinProvision,
+		FastProvisioning: adminVdc.UsesFastProvisioning,
+	}
+	if adminVdc.ProviderVdcReference != nil {
+		vdc.ProviderVdc = adminVdc.ProviderVdcReference.Name
+	}
+	if adminVdc.NetworkPoolReference != nil {
+		vdc.NetworkPool = adminVdc.NetworkPoolReference.Name
+	}
+	if adminVdc.DefaultComputePolicy != nil {
+		vdc.DefaultComputePolicy = adminVdc.DefaultComputePolicy.Name
+	}
+	if len(adminVdc.ComputeCapacity) > 0 && adminVdc.ComputeCapacity[0] != nil {
+		vdc.Cpu = orgConfigCapacity(adminVdc.ComputeCapacity[0].CPU)
+		vdc.Memory = orgConfigCapacity(adminVdc.ComputeCapacity[0].Memory)
+	}
+	if adminVdc.VdcStorageProfiles != nil {
+		vdc.StorageProfiles = referenceNames(adminVdc.VdcStorageProfiles.VdcStorageProfile)
+	}
+	return vdc
+}
+
+func orgConfigCapacity(capacity *types.CapacityWithUsage) *OrgConfigCapacity {
+	if capacity == nil {
+		return nil
+	}
+	return &OrgConfigCapacity{
+		Units:     capacity.Units,
+		Allocated: capacity.Allocated,
+		Limit:     capacity.Limit,
+	}
+}
+
+func orgConfigCatalogFromAdminCatalog(catalog *types.AdminCatalog) OrgConfigCatalog {
+	configCatalog := OrgConfigCatalog{
+		Name:        catalog.Name,
+		Description: catalog.Description,
+		Published:   catalog.IsPublished,
+	}
+	if catalog.CatalogStorageProfiles != nil {
+		configCatalog.StorageProfiles = referenceNames(catalog.CatalogStorageProfiles.VdcStorageProfile)
+	}
+	return configCatalog
+}
+
+func orgConfigUserFromUser(user *types.User) OrgConfigUser {
+	configUser := OrgConfigUser{
+		Name:            user.Name,
+		FullName:        user.FullName,
+		Description:     user.Description,
+		EmailAddress:    user.EmailAddress,
+		Telephone:       user.Telephone,
+		Enabled:         user.IsEnabled,
+		External:        user.IsExternal,
+		ProviderType:    user.ProviderType,
+		StoredVmQuota:   user.StoredVmQuota,
+		DeployedVmQuota: user.DeployedVmQuota,
+	}
+	if user.Role != nil {
+		configUser.Role = user.Role.Name
+	}
+	return configUser
+}
+
+func orgConfigVAppFromVApp(vapp *types.VApp, vdcName string) OrgConfigVApp {
+	configVApp := OrgConfigVApp{
+		Name:        vapp.Name,
+		Description: vapp.Description,
+		Vdc:         vdcName,
+	}
+	if vapp.NetworkConfigSection != nil {
+		for _, networkConfig := range vapp.NetworkConfigSection.NetworkConfig {
+			configVApp.Networks = append(configVApp.Networks, networkConfig.NetworkName)
+		}
+		sort.Strings(configVApp.Networks)
+	}
+	return configVApp
+}
+
+func orgConfigEdgeGatewayFromEdgeGateway(egw *types.OpenAPIEdgeGateway) OrgConfigEdgeGateway {
+	configEgw := OrgConfigEdgeGateway{
+		Name:           egw.Name,
+		Description:    egw.Description,
+		DeploymentMode: egw.DeploymentMode,
+	}
+	configEgw.Owner, configEgw.OwnerType = orgConfigOwner(egw.OwnerRef)
+	for _, uplink := range egw.EdgeGatewayUplinks {
+		configUplink := OrgConfigEdgeGatewayUplink{
+			ExternalNetwork: uplink.UplinkName,
+			Dedicated:       uplink.Dedicated,
+		}
+		for _, subnet := range uplink.Subnets.Values {
+			configSubnet := OrgConfigSubnet{
+				Gateway:      subnet.Gateway,
+				PrefixLength: subnet.PrefixLength,
+				PrimaryIp:    subnet.PrimaryIP,
+			}
+			if subnet.IPRanges != nil {
+				configSubnet.IpRanges = orgConfigIpRanges(subnet.IPRanges.Values)
+			}
+			configUplink.Subnets = append(configUplink.Subnets, configSubnet)
+		}
+		configEgw.Uplinks = append(configEgw.Uplinks, configUplink)
+	}
+	return configEgw
+}
+
+func orgConfigIpSetFromFirewallGroup(group *types.NsxtFirewallGroup) OrgConfigIpSet {
+	ipSet := OrgConfigIpSet{
+		Name:        group.Name,
+		Description: group.Description,
+		IpAddresses: append([]string{}, group.IpAddresses...),
+	}
+	sort.Strings(ipSet.IpAddresses)
+	return ipSet
+}
+
+func orgConfigNatRuleFromNatRule(rule *types.NsxtNatRule) OrgConfigNatRule {
+	configRule := OrgConfigNatRule{
+		Name:                     rule.Name,
+		Description:              rule.Description,
+		Enabled:                  rule.Enabled,
+		Type:                     rule.Type,
+		ExternalAddresses:        rule.ExternalAddresses,
+		InternalAddresses:        rule.InternalAddresses,
+		InternalPort:             rule.InternalPort,
+		DnatExternalPort:         rule.DnatExternalPort,
+		SnatDestinationAddresses: rule.SnatDestinationAddresses,
+		Logging:                  rule.Logging,
+		FirewallMatch:            rule.FirewallMatch,
+		Priority:                 rule.Priority,
+	}
+	if configRule.Type == "" {
+		configRule.Type = rule.RuleType
+	}
+	if rule.ApplicationPortProfile != nil {
+		configRule.ApplicationPortProfile = rule.ApplicationPortProfile.Name
+	}
+	return configRule
+}
+
+// orgConfigFirewallRulesFromContainer converts user defined rules only, as system and default
+// rules are not managed by tenants
+func orgConfigFirewallRulesFromContainer(container *types.NsxtFirewallRuleContainer) []OrgConfigFirewallRule {
+	if container == nil {
+		return nil
+	}
+	var rules []OrgConfigFirewallRule
+	for _, rule := range container.UserDefinedRules {
+		action := rule.ActionValue
+		if action == "" {
+			action = rule.Action
+		}
+		rules = append(rules, OrgConfigFirewallRule{
+			Name:                    rule.Name,
+			Action:                  action,
+			Enabled:                 rule.Enabled,
+			Direction:               rule.Direction,
+			IpProtocol:              rule.IpProtocol,
+			Logging:                 rule.Logging,
+			Sources:                 openApiReferenceNames(rule.SourceFirewallGroups),
+			Destinations:            openApiReferenceNames(rule.DestinationFirewallGroups),
+			ApplicationPortProfiles: openApiReferenceNames(rule.ApplicationPortProfiles),
+		})
+	}
+	return rules
+}
+
+func orgConfigNetworkFromNetwork(network *types.OpenApiOrgVdcNetwork) OrgConfigNetwork {
+	configNetwork := OrgConfigNetwork{
+		Name:        network.Name,
+		Description: network.Description,
+		NetworkType: network.NetworkType,
+		Shared:      network.Shared,
+		DualStack:   network.EnableDualSubnetNetwork,
+	}
+	owner := network.OwnerRef
+	if owner == nil {
+		owner = network.OrgVdc
+	}
+	configNetwork.Owner, configNetwork.OwnerType = orgConfigOwner(owner)
+	if network.Connection != nil {
+		configNetwork.EdgeGateway = network.Connection.RouterRef.Name
+		configNetwork.ConnectionType = network.Connection.ConnectionTypeValue
+		if configNetwork.ConnectionType == "" {
+			configNetwork.ConnectionType = network.Connection.ConnectionType
+		}
+	}
+	for _, subnet := range network.Subnets.Values {
+		configNetwork.Subnets = append(configNetwork.Subnets, OrgConfigSubnet{
+			Gateway:      subnet.Gateway,
+			PrefixLength: subnet.PrefixLength,
+			DnsServer1:   subnet.DNSServer1,
+			DnsServer2:   subnet.DNSServer2,
+			DnsSuffix:    subnet.DNSSuffix,
+			IpRanges:     orgConfigIpRanges(subnet.IPRanges.Values),
+		})
+	}
+	return configNetwork
+}
+
+// orgConfigOwner returns the name and owner type (VDC or VDC Group) of an owner reference
+func orgConfigOwner(ownerRef *types.OpenApiReference) (string, string) {
+	if ownerRef == nil {
+		return "", ""
+	}
+	if strings.HasPrefix(ownerRef.ID, "urn:vcloud:vdcGroup:") {
+		return ownerRef.Name, OrgConfigOwnerTypeVdcGroup
+	}
+	return ownerRef.Name, OrgConfigOwnerTypeVdc
+}
+
+func orgConfigIpRanges(ranges []types.OpenApiIPRangeValues) []string {
+	var result []string
+	for _, ipRange := range ranges {
+		if ipRange.StartAddress == ipRange.EndAddress || ipRange.EndAddress == "" {
+			result = append(result, ipRange.StartAddress)
+			continue
+		}
+		result = append(result, ipRange.StartAddress+"-"+ipRange.EndAddress)
+	}
+	return result
+}
+
+// orgConfigParseIpRanges is the reverse of orgConfigIpRanges
+func orgConfigParseIpRanges(ranges []string) []types.OpenApiIPRangeValues {
+	var result []types.OpenApiIPRangeValues
+	for _, ipRange := range ranges {
+		start, end, found := strings.Cut(ipRange, "-")
+		if !found {
+			end = start
+		}
+		result = append(result, types.OpenApiIPRangeValues{
+			StartAddress: strings.TrimSpace(start),
+			EndAddress:   strings.TrimSpace(end),
+		})
+	}
+	return result
+}
+
+func referenceNames(references []*types.Reference) []string {
+	var names []string
+	for _, reference := range references {
+		if reference != nil {
+			names = append(names, reference.Name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// openApiReferenceNames keeps the original order, as it may be meaningful
+func openApiReferenceNames(references []types.OpenApiReference) []string {
+	var names []string
+	for _, reference := range references {
+		names = append(names, reference.Name)
+	}
+	return names
+}
@@ -0,0 +1,1139 @@
+/*
+ * Copyright 2025 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+package govcd
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+	"github.com/vmware/go-vcloud-director/v3/util"
+)
+
+// Resource kinds handled by the Org configuration reconciliation engine. The order of the list
+// defines the dependency order in which creations and updates are applied. Deletions are applied
+// afterwards, in reverse order.
+const (
+	OrgConfigKindVdc           = "vdc"
+	OrgConfigKindEdgeGateway   = "edgeGateway"
+	OrgConfigKindIpSet         = "ipSet"
+	OrgConfigKindNetwork       = "network"
+	OrgConfigKindNatRule       = "natRule"
+	OrgConfigKindFirewallRules = "firewallRules"
+	OrgConfigKindVApp          = "vApp"
+)
+
+var orgConfigKindOrder = []string{
+	OrgConfigKindVdc,
+	OrgConfigKindEdgeGateway,
+	OrgConfigKindIpSet,
+	OrgConfigKindNetwork,
+	OrgConfigKindNatRule,
+	OrgConfigKindFirewallRules,
+	OrgConfigKindVApp,
+}
+
+// Actions that can be planned for a resource
+const (
+	OrgConfigActionCreate = "create"
+	OrgConfigActionUpdate = "update"
+	OrgConfigActionDelete = "delete"
+	// OrgConfigActionSkip is planned when a change is needed but not allowed (e.g. deletion without
+	// OrgConfigApplyOptions.AllowDelete) or when the current state could not be read
+	OrgConfigActionSkip = "skip"
+)
+
+// Statuses of an applied change in OrgConfigApplyResult
+const (
+	OrgConfigStatusApplied = "applied"
+	OrgConfigStatusFailed  = "failed"
+	OrgConfigStatusSkipped = "skipped"
+	OrgConfigStatusPlanned = "planned"
+)
+
+// OrgConfigChange describes a single change needed to bring an Organization to the state described
+// in an OrgConfigDocument
+type OrgConfigChange struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+	// Parent is the VDC name for vApps and the Edge Gateway name for IP Sets, NAT and firewall rules
+	Parent string `json:"parent,omitempty"`
+	Action string `json:"action"`
+	// Fields lists the changed fields for updates
+	Fields []string `json:"fields,omitempty"`
+	// Reason explains why a change was skipped
+	Reason string `json:"reason,omitempty"`
+
+	current any
+	desired any
+}
+
+// OrgConfigPlan is an ordered list of changes
+type OrgConfigPlan struct {
+	Changes []OrgConfigChange `json:"changes"`
+}
+
+// OrgConfigApplyOptions controls the behavior of ApplyOrgConfig
+type OrgConfigApplyOptions struct {
+	// AllowDelete enables removal of resources that exist in VCD but not in the document.
+	// When false, such resources are reported with action OrgConfigActionSkip
+	AllowDelete bool
+	// DryRun only computes the plan, without changing anything
+	DryRun bool
+	// StopOnError stops at the first failed change. Remaining changes are reported as skipped
+	StopOnError bool
+}
+
+// OrgConfigApplyResult holds the outcome of a single change
+type OrgConfigApplyResult struct {
+	OrgConfigChange
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// OrgConfigApplyReport holds the outcome of all changes, in the order they were applied
+type OrgConfigApplyReport struct {
+	Results []OrgConfigApplyResult `json:"results"`
+}
+
+// Failed returns the results of changes that could not be applied
+func (report *OrgConfigApplyReport) Failed() []OrgConfigApplyResult {
+	var failed []OrgConfigApplyResult
+	for _, result := range report.Results {
+		if result.Status == OrgConfigStatusFailed {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
+
+// PlanOrgConfig compares the Organization named in 'desired' with the document and returns the
+// ordered list of changes that ApplyOrgConfig would perform
+func (vcdClient *VCDClient) PlanOrgConfig(desired *OrgConfigDocument, options *OrgConfigApplyOptions) (*OrgConfigPlan, error) {
+	_, plan, err := vcdClient.planOrgConfig(desired, options)
+	return plan, err
+}
+
+// ApplyOrgConfig reconciles an Organization toward the state described in 'desired'. The
+// Organization itself must exist and is found by OrgConfigDocument.Org.Name.
+//
+// Supported resources are VDCs, NSX-T Edge Gateways, IP Sets, Org VDC networks, NAT rules, Edge
+// Gateway firewall rules and vApps (creation and removal of empty vApps and attachment of Org VDC
+// networks). Creations and updates are applied in dependency order (VDC, Edge Gateway, IP Sets,
+// networks, NAT, firewall, vApps), deletions in reverse order after that. Catalogs, roles and users
+// in the document are not reconciled. The allocation model, Provider VDC and network pool of an
+// existing VDC cannot be changed: documents that change them are rejected when computing the plan.
+//
+// The returned report contains one entry per change. An error is returned only when the plan cannot
+// be computed; failures of individual changes are recorded in the report.
+func (vcdClient *VCDClient) ApplyOrgConfig(desired *OrgConfigDocument, options *OrgConfigApplyOptions) (*OrgConfigApplyReport, error) {
+	if options == nil {
+		options = &OrgConfigApplyOptions{}
+	}
+	adminOrg, plan, err := vcdClient.planOrgConfig(desired, options)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &OrgConfigApplyReport{}
+	applier := &orgConfigApplier{vcdClient: vcdClient, adminOrg: adminOrg}
+	stop := false
+	for _, change := range plan.Changes {
+		result := OrgConfigApplyResult{OrgConfigChange: change}
+		switch {
+		case change.Action == OrgConfigActionSkip:
+			result.Status = OrgConfigStatusSkipped
+		case options.DryRun:
+			result.Status = OrgConfigStatusPlanned
+		case stop:
+			result.Status = OrgConfigStatusSkipped
+			result.Reason = "previous change failed"
+		default:
+			err = applier.apply(change)
+			if err != nil {
+				util.Logger.Printf("[ERROR] Org configuration: %s %s '%s' failed: %s", change.Action, change.Kind, change.Name, err)
+				result.Status = OrgConfigStatusFailed
+				result.Error = err.Error()
+				stop = options.StopOnError
+			} else {
+				result.Status = OrgConfigStatusApplied
+			}
+		}
+		report.Results = append(report.Results, result)
+	}
+	return report, nil
+}
+
+func (vcdClient *VCDClient) planOrgConfig(desired *OrgConfigDocument, options *OrgConfigApplyOptions) (*AdminOrg, *OrgConfigPlan, error) {
+	if desired == nil {
+		return nil, nil, fmt.Errorf("empty Org configuration document")
+	}
+	if options == nil {
+		options = &OrgConfigApplyOptions{}
+	}
+	adminOrg, err := vcdClient.GetAdminOrgByName(desired.Org.Name)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error retrieving Org '%s': %s", desired.Org.Name, err)
+	}
+	current, err := adminOrg.ExportOrgConfig(&OrgConfigExportOptions{
+		SkipCatalogs:   true,
+		SkipRoles:      true,
+		SkipUsers:      true,
+		SkipRoleRights: true,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("error reading current configuration of Org '%s': %s", desired.Org.Name, err)
+	}
+	plan, err := diffOrgConfig(current, desired, options.AllowDelete)
+	if err != nil {
+		return nil, nil, err
+	}
+	return adminOrg, plan, nil
+}
+
+// diffOrgConfig computes the ordered list of changes that transform 'current' into 'desired'
+func diffOrgConfig(current, desired *OrgConfigDocument, allowDelete bool) (*OrgConfigPlan, error) {
+	var changes []OrgConfigChange
+
+	vdcChanges := diffOrgConfigItems(OrgConfigKindVdc, "", current.Vdcs, normalizeOrgConfigVdcs(current.Vdcs, desired.Vdcs),
+		func(v OrgConfigVdc) string { return v.Name }, nil)
+	for _, change := range vdcChanges {
+		var immutable []string
+		for _, field := range change.Fields {
+			if contains(field, orgConfigVdcImmutableFields) {
+				immutable = append(immutable, field)
+			}
+		}
+		if len(immutable) > 0 {
+			return nil, fmt.Errorf("VDC '%s' cannot be updated in place: %s can only be set at creation",
+				change.Name, strings.Join(immutable, ", "))
+		}
+	}
+	changes = append(changes, vdcChanges...)
+
+	egwStrip := func(egw OrgConfigEdgeGateway) OrgConfigEdgeGateway {
+		egw.IpSets, egw.NatRules, egw.FirewallRules = nil, nil, nil
+		return egw
+	}
+	changes = append(changes, diffOrgConfigItems(OrgConfigKindEdgeGateway, "", current.EdgeGateways, desired.EdgeGateways,
+		func(egw OrgConfigEdgeGateway) string { return egw.Name }, egwStrip)...)
+
+	currentEgws := make(map[string]OrgConfigEdgeGateway)
+	for _, egw := range current.EdgeGateways {
+		currentEgws[egw.Name] = egw
+	}
+	desiredEgws := make(map[string]bool)
+	for _, egw := range desired.EdgeGateways {
+		desiredEgws[egw.Name] = true
+		currentEgw := currentEgws[egw.Name]
+
+		if reason := current.skippedReason(OrgConfigKindIpSet+"s", egw.Name); reason != "" {
+			changes = append(changes, OrgConfigChange{Kind: OrgConfigKindIpSet, Name: "*", Parent: egw.Name, Action: OrgConfigActionSkip, Reason: reason})
+		} else {
+			changes = append(changes, diffOrgConfigItems(OrgConfigKindIpSet, egw.Name, currentEgw.IpSets, egw.IpSets,
+				func(ipSet OrgConfigIpSet) string { return ipSet.Name }, nil)...)
+		}
+
+		if reason := current.skippedReason(OrgConfigKindNatRule+"s", egw.Name); reason != "" {
+			changes = append(changes, OrgConfigChange{Kind: OrgConfigKindNatRule, Name: "*", Parent: egw.Name, Action: OrgConfigActionSkip, Reason: reason})
+		} else {
+			changes = append(changes, diffOrgConfigItems(OrgConfigKindNatRule, egw.Name, currentEgw.NatRules, egw.NatRules,
+				func(rule OrgConfigNatRule) string { return rule.Name }, nil)...)
+		}
+
+		if reason := current.skippedReason(OrgConfigKindFirewallRules, egw.Name); reason != "" {
+			changes = append(changes, OrgConfigChange{Kind: OrgConfigKindFirewallRules, Name: egw.Name, Parent: egw.Name, Action: OrgConfigActionSkip, Reason: reason})
+		} else if !reflect.DeepEqual(normalizeFirewallRules(currentEgw.FirewallRules), normalizeFirewallRules(egw.FirewallRules)) {
+			action := OrgConfigActionUpdate
+			if len(egw.FirewallRules) == 0 {
+				action = OrgConfigActionDelete
+			}
+			changes = append(changes, OrgConfigChange{
+				Kind:    OrgConfigKindFirewallRules,
+				Name:    egw.Name,
+				Parent:  egw.Name,
+				Action:  action,
+				current: currentEgw.FirewallRules,
+				desired: egw.FirewallRules,
+			})
+		}
+	}
+
+	changes = append(changes, diffOrgConfigItems(OrgConfigKindNetwork, "", current.Networks, desired.Networks,
+		func(network OrgConfigNetwork) string { return network.Owner + "/" + network.Name }, nil)...)
+
+	changes = append(changes, diffOrgConfigItems(OrgConfigKindVApp, "", current.VApps, desired.VApps,
+		func(vapp OrgConfigVApp) string { return vapp.Vdc + "/" + vapp.Name }, nil)...)
+
+	plan := &OrgConfigPlan{}
+	var deletions []OrgConfigChange
+	for _, change := range changes {
+		if change.Action != OrgConfigActionDelete {
+			plan.Changes = append(plan.Changes, change)
+			continue
+		}
+		// Children of a removed Edge Gateway are removed together with it
+		if change.Parent != "" && (change.Kind == OrgConfigKindIpSet || change.Kind == OrgConfigKindNatRule ||
+			change.Kind == OrgConfigKindFirewallRules) && !desiredEgws[change.Parent] {
+			continue
+		}
+		if !allowDelete {
+			change.Action = OrgConfigActionSkip
+			change.Reason = "deletion not allowed"
+		}
+		deletions = append(deletions, change)
+	}
+
+	sort.SliceStable(plan.Changes, func(i, j int) bool {
+		return orgConfigKindIndex(plan.Changes[i].Kind) < orgConfigKindIndex(plan.Changes[j].Kind)
+	})
+	sort.SliceStable(deletions, func(i, j int) bool {
+		return orgConfigKindIndex(deletions[i].Kind) > orgConfigKindIndex(deletions[j].Kind)
+	})
+	plan.Changes = append(plan.Changes, deletions...)
+	return plan, nil
+}
+
+// orgConfigVdcImmutableFields are the VDC fields that VCD does not allow to change after creation
+var orgConfigVdcImmutableFields = []string{"allocationModel", "providerVdc", "networkPool"}
+
+// normalizeOrgConfigVdcs returns a copy of the desired VDCs that compares equal to an export when
+// they describe the same state. Storage profiles are compared as a set, as the export sorts them, and
+// settings omitted in the document keep their current value
+func normalizeOrgConfigVdcs(current, desired []OrgConfigVdc) []OrgConfigVdc {
+	currentByName := make(map[string]OrgConfigVdc)
+	for _, vdc := range current {
+		currentByName[vdc.Name] = vdc
+	}
+	normalized := make([]OrgConfigVdc, len(desired))
+	for index, vdc := range desired {
+		if currentVdc, found := currentByName[vdc.Name]; found {
+			if vdc.AllocationModel == "" {
+				vdc.AllocationModel = currentVdc.AllocationModel
+			}
+			if vdc.ProviderVdc == "" {
+				vdc.ProviderVdc = currentVdc.ProviderVdc
+			}
+			if vdc.NetworkPool == "" {
+				vdc.NetworkPool = currentVdc.NetworkPool
+			}
+			if vdc.DefaultComputePolicy == "" {
+				vdc.DefaultComputePolicy = currentVdc.DefaultComputePolicy
+			}
+			if vdc.ThinProvisioning == nil {
+				vdc.ThinProvisioning = currentVdc.ThinProvisioning
+			}
+			if vdc.FastProvisioning == nil {
+				vdc.FastProvisioning = currentVdc.FastProvisioning
+			}
+			if vdc.Cpu == nil {
+				vdc.Cpu = currentVdc.Cpu
+			}
+			if vdc.Memory == nil {
+				vdc.Memory = currentVdc.Memory
+			}
+			if len(vdc.StorageProfiles) == 0 {
+				vdc.StorageProfiles = currentVdc.StorageProfiles
+			} else {
+				vdc.StorageProfiles = append([]string{}, vdc.StorageProfiles...)
+				sort.Strings(vdc.StorageProfiles)
+			}
+		}
+		normalized[index] = vdc
+	}
+	return normalized
+}
+
+// diffOrgConfigItems compares two lists of items identified by 'key'. 'strip' optionally removes
+// nested data that is compared separately. The Name and Parent of the changes are derived from the
+// key, which may be in the form "parent/name"
+func diffOrgConfigItems[T any](kind, parent string, current, desired []T, key func(T) string, strip func(T) T) []OrgConfigChange {
+	if strip == nil {
+		strip = func(item T) T { return item }
+	}
+	split := func(itemKey string) (string, string) {
+		if parent != "" {
+			return parent, itemKey
+		}
+		if p, n, found := strings.Cut(itemKey, "/"); found {
+			return p, n
+		}
+		return "", itemKey
+	}
+
+	currentByKey := make(map[string]T)
+	for _, item := range current {
+		currentByKey[key(item)] = item
+	}
+
+	var changes []OrgConfigChange
+	seen := make(map[string]bool)
+	for _, desiredItem := range desired {
+		itemKey := key(desiredItem)
+		seen[itemKey] = true
+		itemParent, itemName := split(itemKey)
+		currentItem, found := currentByKey[itemKey]
+		if !found {
+			changes = append(changes, OrgConfigChange{Kind: kind, Name: itemName, Parent: itemParent,
+				Action: OrgConfigActionCreate, desired: desiredItem})
+			continue
+		}
+		fields := orgConfigChangedFields(strip(currentItem), strip(desiredItem))
+		if len(fields) > 0 {
+			changes = append(changes, OrgConfigChange{Kind: kind, Name: itemName, Parent: itemParent,
+				Action: OrgConfigActionUpdate, Fields: fields, current: currentItem, desired: desiredItem})
+		}
+	}
+	for _, currentItem := range current {
+		itemKey := key(currentItem)
+		if seen[itemKey] {
+			continue
+		}
+		itemParent, itemName := split(itemKey)
+		changes = append(changes, OrgConfigChange{Kind: kind, Name: itemName, Parent: itemParent,
+			Action: OrgConfigActionDelete, current: currentItem})
+	}
+	return changes
+}
+
+// orgConfigChangedFields returns the JSON names of top level fields that differ between two
+// structures of the same type
+func orgConfigChangedFields(current, desired any) []string {
+	currentValue := reflect.ValueOf(current)
+	desiredValue := reflect.ValueOf(desired)
+	var fields []string
+	for i := 0; i < currentValue.NumField(); i++ {
+		if reflect.DeepEqual(currentValue.Field(i).Interface(), desiredValue.Field(i).Interface()) {
+			continue
+		}
+		field := currentValue.Type().Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" {
+			name = field.Name
+		}
+		fields = append(fields, name)
+	}
+	return fields
+}
+
+// normalizeFirewallRules makes nil and empty lists equal, so that a document read from YAML
+// compares equal to an export
+func normalizeFirewallRules(rules []OrgConfigFirewallRule) []OrgConfigFirewallRule {
+	if len(rules) == 0 {
+		return nil
+	}
+	return rules
+}
+
+func orgConfigKindIndex(kind string) int {
+	for index, k := range orgConfigKindOrder {
+		if k == kind {
+			return index
+		}
+	}
+	return len(orgConfigKindOrder)
+}
+
+// skippedReason returns the reason why a kind of resources could not be exported for a given parent
+func (doc *OrgConfigDocument) skippedReason(kind, name string) string {
+	for _, skipped := range doc.Skipped {
+		if skipped.Kind == kind && skipped.Name == name {
+			return skipped.Reason
+		}
+	}
+	return ""
+}
+
+// orgConfigApplier performs the changes of a plan using the regular govcd CRUD functions
+type orgConfigApplier struct {
+	vcdClient *VCDClient
+	adminOrg  *AdminOrg
+}
+
+func (applier *orgConfigApplier) apply(change OrgConfigChange) error {
+	switch change.Kind {
+	case OrgConfigKindVdc:
+		return applier.applyVdc(change)
+	case OrgConfigKindEdgeGateway:
+		return applier.applyEdgeGateway(change)
+	case OrgConfigKindIpSet:
+		return applier.applyIpSet(change)
+	case OrgConfigKindNetwork:
+		return applier.applyNetwork(change)
+	case OrgConfigKindNatRule:
+		return applier.applyNatRule(change)
+	case OrgConfigKindFirewallRules:
+		return applier.applyFirewallRules(change)
+	case OrgConfigKindVApp:
+		return applier.applyVApp(change)
+	}
+	return fmt.Errorf("unsupported resource kind '%s'", change.Kind)
+}
+
+func (applier *orgConfigApplier) applyVdc(change OrgConfigChange) error {
+	adminOrg := applier.adminOrg
+	switch change.Action {
+	case OrgConfigActionCreate:
+		desired := change.desired.(OrgConfigVdc)
+		vdcConfiguration, err := applier.vdcConfiguration(desired)
+		if err != nil {
+			return err
+		}
+		_, err = adminOrg.CreateOrgVdc(vdcConfiguration)
+		if err != nil {
+			return err
+		}
+		return adminOrg.Refresh()
+	case OrgConfigActionUpdate:
+		desired := change.desired.(OrgConfigVdc)
+		adminVdc, err := adminOrg.GetAdminVDCByName(change.Name, true)
+		if err != nil {
+			return err
+		}
+		if len(change.Fields) > 1 || !contains("storageProfiles", change.Fields) {
+			adminVdc.AdminVdc.Description = desired.Description
+			adminVdc.AdminVdc.IsEnabled = desired.Enabled
+			adminVdc.AdminVdc.NicQuota = desired.NicQuota
+			adminVdc.AdminVdc.NetworkQuota = desired.NetworkQuota
+			adminVdc.AdminVdc.VMQuota = desired.VmQuota
+			adminVdc.AdminVdc.IsThinProvision = desired.ThinProvisioning
+			adminVdc.AdminVdc.UsesFastProvisioning = desired.FastProvisioning
+			if len(adminVdc.AdminVdc.ComputeCapacity) > 0 {
+				setOrgConfigCapacity(adminVdc.AdminVdc.ComputeCapacity[0].CPU, desired.Cpu)
+				setOrgConfigCapacity(adminVdc.AdminVdc.ComputeCapacity[0].Memory, desired.Memory)
+			}
+			if contains("defaultComputePolicy", change.Fields) {
+				adminVdc.AdminVdc.DefaultComputePolicy, err = applier.assignedComputePolicyReference(adminVdc, desired.DefaultComputePolicy)
+				if err != nil {
+					return err
+				}
+			}
+			updatedVdc, err := adminVdc.Update()
+			if err != nil {
+				return err
+			}
+			adminVdc = &updatedVdc
+		}
+		if contains("storageProfiles", change.Fields) {
+			current := change.current.(OrgConfigVdc)
+			return applier.updateVdcStorageProfiles(adminVdc, current.StorageProfiles, desired.StorageProfiles)
+		}
+		return nil
+	case OrgConfigActionDelete:
+		vdc, err := adminOrg.GetVDCByName(change.Name, true)
+		if err != nil {
+			return err
+		}
+		err = vdc.DeleteWait(true, true)
+		if err != nil {
+			return err
+		}
+		return adminOrg.Refresh()
+	}
+	return fmt.Errorf("unsupported action '%s'", change.Action)
+}
+
+// vdcConfiguration resolves provider level names of an OrgConfigVdc into a creation payload
+func (applier *orgConfigApplier) vdcConfiguration(desired OrgConfigVdc) (*types.VdcConfiguration, error) {
+	providerVdc, err := applier.vcdClient.GetProviderVdcByName(desired.ProviderVdc)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving Provider VDC '%s': %s", desired.ProviderVdc, err)
+	}
+
+	vdcConfiguration := &types.VdcConfiguration{
+		Name:                 desired.Name,
+		Xmlns:                types.XMLNamespaceVCloud,
+		Description:          desired.Description,
+		AllocationModel:      desired.AllocationModel,
+		NicQuota:             desired.NicQuota,
+		NetworkQuota:         desired.NetworkQuota,
+		VmQuota:              desired.VmQuota,
+		IsEnabled:            desired.Enabled,
+		ProviderVdcReference: &types.Reference{HREF: providerVdc.ProviderVdc.HREF},
+		IsThinProvision:      desired.ThinProvisioning != nil && *desired.ThinProvisioning,
+		UsesFastProvisioning: desired.FastProvisioning != nil && *desired.FastProvisioning,
+		ComputeCapacity: []*types.ComputeCapacity{
+			{
+				CPU:    &types.CapacityWithUsage{Units: "MHz"},
+				Memory: &types.CapacityWithUsage{Units: "MB"},
+			},
+		},
+	}
+	setOrgConfigCapacity(vdcConfiguration.ComputeCapacity[0].CPU, desired.Cpu)
+	setOrgConfigCapacity(vdcConfiguration.ComputeCapacity[0].Memory, desired.Memory)
+
+	if desired.NetworkPool != "" {
+		networkPools, err := QueryNetworkPoolByName(applier.vcdClient, desired.NetworkPool)
+		if err != nil {
+			return nil, fmt.Errorf("error retrieving network pool '%s': %s", desired.NetworkPool, err)
+		}
+		if len(networkPools) != 1 {
+			return nil, fmt.Errorf("expected one network pool with name '%s', found %d", desired.NetworkPool, len(networkPools))
+		}
+		vdcConfiguration.NetworkPoolReference = &types.Reference{HREF: networkPools[0].HREF}
+	}
+
+	for index, storageProfileName := range desired.StorageProfiles {
+		storageProfile, err := applier.vcdClient.QueryProviderVdcStorageProfileByName(storageProfileName, providerVdc.ProviderVdc.HREF)
+		if err != nil {
+			return nil, fmt.Errorf("error retrieving storage profile '%s': %s", storageProfileName, err)
+		}
+		vdcConfiguration.VdcStorageProfile = append(vdcConfiguration.VdcStorageProfile, &types.VdcStorageProfileConfiguration{
+			Enabled:                   addrOf(true),
+			Units:                     "MB",
+			Default:                   index == 0,
+			ProviderVdcStorageProfile: &types.Reference{HREF: storageProfile.HREF},
+		})
+	}
+	return vdcConfiguration, nil
+}
+
+// assignedComputePolicyReference returns a reference to a compute policy assigned to the VDC, to be
+// used as default compute policy
+func (applier *orgConfigApplier) assignedComputePolicyReference(adminVdc *AdminVdc, name string) (*types.Reference, error) {
+	policies, err := adminVdc.GetAllAssignedVdcComputePoliciesV2(nil)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving compute policies of VDC '%s': %s", adminVdc.AdminVdc.Name, err)
+	}
+	for _, policy := range policies {
+		if policy.VdcComputePolicyV2.Name != name {
+			continue
+		}
+		href, err := adminVdc.client.OpenApiBuildEndpoint(types.OpenApiPathVersion2_0_0+types.OpenApiEndpointVdcComputePolicies, policy.VdcComputePolicyV2.ID)
+		if err != nil {
+			return nil, err
+		}
+		return &types.Reference{HREF: href.String(), ID: policy.VdcComputePolicyV2.ID, Name: name}, nil
+	}
+	return nil, fmt.Errorf("compute policy '%s' is not assigned to VDC '%s'", name, adminVdc.AdminVdc.Name)
+}
+
+// updateVdcStorageProfiles adds the missing storage profiles before removing the ones that are no
+// longer wanted, so that the VDC always keeps at least one
+func (applier *orgConfigApplier) updateVdcStorageProfiles(adminVdc *AdminVdc, current, desired []string) error {
+	if adminVdc.AdminVdc.ProviderVdcReference == nil {
+		return fmt.Errorf("VDC '%s' has no Provider VDC reference", adminVdc.AdminVdc.Name)
+	}
+	for _, name := range desired {
+		if contains(name, current) {
+			continue
+		}
+		storageProfile, err := applier.vcdClient.QueryProviderVdcStorageProfileByName(name, adminVdc.AdminVdc.ProviderVdcReference.HREF)
+		if err != nil {
+			return fmt.Errorf("error retrieving storage profile '%s': %s", name, err)
+		}
+		err = adminVdc.AddStorageProfileWait(&types.VdcStorageProfileConfiguration{
+			Enabled:                   addrOf(true),
+			Units:                     "MB",
+			ProviderVdcStorageProfile: &types.Reference{HREF: storageProfile.HREF, Name: name},
+		}, "")
+		if err != nil {
+			return fmt.Errorf("error adding storage profile '%s' to VDC '%s': %s", name, adminVdc.AdminVdc.Name, err)
+		}
+	}
+	for _, name := range current {
+		if contains(name, desired) {
+			continue
+		}
+		err := adminVdc.RemoveStorageProfileWait(name)
+		if err != nil {
+			return fmt.Errorf("error removing storage profile '%s' from VDC '%s': %s", name, adminVdc.AdminVdc.Name, err)
+		}
+	}
+	return nil
+}
+
+func setOrgConfigCapacity(capacity *types.CapacityWithUsage, desired *OrgConfigCapacity) {
+	if capacity == nil || desired == nil {
+		return
+	}
+	if desired.Units != "" {
+		capacity.Units = desired.Units
+	}
+	capacity.Allocated = desired.Allocated
+	capacity.Limit = desired.Limit
+}
+
+func (applier *orgConfigApplier) applyEdgeGateway(change OrgConfigChange) error {
+	switch change.Action {
+	case OrgConfigActionCreate:
+		desired := change.desired.(OrgConfigEdgeGateway)
+		ownerRef, err := applier.ownerReference(desired.Owner, desired.OwnerType)
+		if err != nil {
+			return err
+		}
+		uplinks, err := applier.edgeGatewayUplinks(desired.Uplinks)
+		if err != nil {
+			return err
+		}
+		_, err = applier.adminOrg.CreateNsxtEdgeGateway(&types.OpenAPIEdgeGateway{
+			Name:               desired.Name,
+			Description:        desired.Description,
+			OwnerRef:           ownerRef,
+			DeploymentMode:     desired.DeploymentMode,
+			EdgeGatewayUplinks: uplinks,
+		})
+		return err
+	case OrgConfigActionUpdate:
+		desired := change.desired.(OrgConfigEdgeGateway)
+		egw, err := applier.edgeGateway(change.Name)
+		if err != nil {
+			return err
+		}
+		egw.EdgeGateway.Description = desired.Description
+		if desired.DeploymentMode != "" {
+			egw.EdgeGateway.DeploymentMode = desired.DeploymentMode
+		}
+		if contains("uplinks", change.Fields) {
+			current := change.current.(OrgConfigEdgeGateway)
+			uplinks, err := applier.mergeEdgeGatewayUplinks(egw.EdgeGateway.EdgeGatewayUplinks, current.Uplinks, desired.Uplinks)
+			if err != nil {
+				return err
+			}
+			egw.EdgeGateway.EdgeGatewayUplinks = uplinks
+		}
+		_, err = egw.Update(egw.EdgeGateway)
+		return err
+	case OrgConfigActionDelete:
+		egw, err := applier.edgeGateway(change.Name)
+		if err != nil {
+			return err
+		}
+		return egw.Delete()
+	}
+	return fmt.Errorf("unsupported action '%s'", change.Action)
+}
+
+func (applier *orgConfigApplier) edgeGatewayUplinks(configUplinks []OrgConfigEdgeGatewayUplink) ([]types.EdgeGatewayUplinks, error) {
+	var uplinks []types.EdgeGatewayUplinks
+	for _, configUplink := range configUplinks {
+		externalNetwork, err := GetExternalNetworkV2ByName(applier.vcdClient, configUplink.ExternalNetwork)
+		if err != nil {
+			return nil, fmt.Errorf("error retrieving external network '%s': %s", configUplink.ExternalNetwork, err)
+		}
+		uplink := types.EdgeGatewayUplinks{
+			UplinkID:  externalNetwork.ExternalNetwork.ID,
+			Connected: true,
+		}
+		mergeOrgConfigEdgeGatewayUplink(&uplink, configUplink)
+		uplinks = append(uplinks, uplink)
+	}
+	return uplinks, nil
+}
+
+// mergeEdgeGatewayUplinks applies the document uplinks to the live ones, matching them by external
+// network. Live uplinks that did not change in the document are kept as they are, so that settings
+// not modeled in the document (e.g. IP Space usage or additional subnets) survive the update
+func (applier *orgConfigApplier) mergeEdgeGatewayUplinks(live []types.EdgeGatewayUplinks, current, desired []OrgConfigEdgeGatewayUplink) ([]types.EdgeGatewayUplinks, error) {
+	currentByNetwork := make(map[string]OrgConfigEdgeGatewayUplink)
+	for _, configUplink := range current {
+		currentByNetwork[configUplink.ExternalNetwork] = configUplink
+	}
+
+	var uplinks []types.EdgeGatewayUplinks
+	for _, configUplink := range desired {
+		externalNetwork, err := GetExternalNetworkV2ByName(applier.vcdClient, configUplink.ExternalNetwork)
+		if err != nil {
+			return nil, fmt.Errorf("error retrieving external network '%s': %s", configUplink.ExternalNetwork, err)
+		}
+		var liveUplink *types.EdgeGatewayUplinks
+		for index := range live {
+			if live[index].UplinkID == externalNetwork.ExternalNetwork.ID {
+				liveUplink = &live[index]
+				break
+			}
+		}
+		if liveUplink == nil {
+			uplink := types.EdgeGatewayUplinks{UplinkID: externalNetwork.ExternalNetwork.ID, Connected: true}
+			mergeOrgConfigEdgeGatewayUplink(&uplink, configUplink)
+			uplinks = append(uplinks, uplink)
+			continue
+		}
+		uplink := *liveUplink
+		if currentUplink, found := currentByNetwork[configUplink.ExternalNetwork]; !found || !reflect.DeepEqual(currentUplink, configUplink) {
+			mergeOrgConfigEdgeGatewayUplink(&uplink, configUplink)
+		}
+		uplinks = append(uplinks, uplink)
+	}
+	return uplinks, nil
+}
+
+// mergeOrgConfigEdgeGatewayUplink sets the fields modeled by OrgConfigEdgeGatewayUplink into an
+// uplink. Subnets are matched by gateway and prefix length and other subnet settings are preserved
+func mergeOrgConfigEdgeGatewayUplink(uplink *types.EdgeGatewayUplinks, configUplink OrgConfigEdgeGatewayUplink) {
+	uplink.Dedicated = configUplink.Dedicated
+	for _, configSubnet := range configUplink.Subnets {
+		ipRanges := orgConfigParseIpRanges(configSubnet.IpRanges)
+		found := false
+		for index := range uplink.Subnets.Values {
+			subnet := &uplink.Subnets.Values[index]
+			if subnet.Gateway != configSubnet.Gateway || subnet.PrefixLength != configSubnet.PrefixLength {
+				continue
+			}
+			found = true
+			subnet.PrimaryIP = configSubnet.PrimaryIp
+			if subnet.IPRanges == nil {
+				subnet.IPRanges = &types.OpenApiIPRanges{}
+			}
+			subnet.IPRanges.Values = ipRanges
+		}
+		if !found {
+			uplink.Subnets.Values = append(uplink.Subnets.Values, types.OpenAPIEdgeGatewaySubnetValue{
+				Gateway:      configSubnet.Gateway,
+				PrefixLength: configSubnet.PrefixLength,
+				PrimaryIP:    configSubnet.PrimaryIp,
+				Enabled:      true,
+				IPRanges:     &types.OpenApiIPRanges{Values: ipRanges},
+			})
+		}
+	}
+}
+
+// ownerReference resolves the name of a VDC or VDC Group into a reference
+func (applier *orgConfigApplier) ownerReference(name, ownerType string) (*types.OpenApiReference, error) {
+	if ownerType == OrgConfigOwnerTypeVdcGroup {
+		vdcGroup, err := applier.adminOrg.GetVdcGroupByName(name)
+		if err != nil {
+			return nil, fmt.Errorf("error retrieving VDC Group '%s': %s", name, err)
+		}
+		return &types.OpenApiReference{ID: vdcGroup.VdcGroup.Id}, nil
+	}
+	vdc, err := applier.adminOrg.GetVDCByName(name, true)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving VDC '%s': %s", name, err)
+	}
+	return &types.OpenApiReference{ID: vdc.Vdc.ID}, nil
+}
+
+// edgeGateway retrieves an NSX-T Edge Gateway by name within the Org being reconciled
+func (applier *orgConfigApplier) edgeGateway(name string) (*NsxtEdgeGateway, error) {
+	queryParameters := url.Values{}
+	queryParameters.Add("filter", fmt.Sprintf("name==%s;orgRef.id==%s", name, applier.adminOrg.AdminOrg.ID))
+	allEdges, err := applier.adminOrg.GetAllNsxtEdgeGateways(queryParameters)
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve Edge Gateway by name '%s': %s", name, err)
+	}
+	return returnSingleNsxtEdgeGateway(name, filterOnlyNsxtEdges(allEdges))
+}
+
+func (applier *orgConfigApplier) applyIpSet(change OrgConfigChange) error {
+	egw, err := applier.edgeGateway(change.Parent)
+	if err != nil {
+		return err
+	}
+	switch change.Action {
+	case OrgConfigActionCreate:
+		desired := change.desired.(OrgConfigIpSet)
+		_, err = egw.CreateNsxtFirewallGroup(&types.NsxtFirewallGroup{
+			Name:        desired.Name,
+			Description: desired.Description,
+			IpAddresses: desired.IpAddresses,
+			OwnerRef:    &types.OpenApiReference{ID: egw.EdgeGateway.ID},
+			TypeValue:   types.FirewallGroupTypeIpSet,
+		})
+		return err
+	case OrgConfigActionUpdate:
+		desired := change.desired.(OrgConfigIpSet)
+		ipSet, err := egw.GetNsxtFirewallGroupByName(change.Name, types.FirewallGroupTypeIpSet)
+		if err != nil {
+			return err
+		}
+		ipSet.NsxtFirewallGroup.Description = desired.Description
+		ipSet.NsxtFirewallGroup.IpAddresses = desired.IpAddresses
+		_, err = ipSet.Update(ipSet.NsxtFirewallGroup)
+		return err
+	case OrgConfigActionDelete:
+		ipSet, err := egw.GetNsxtFirewallGroupByName(change.Name, types.FirewallGroupTypeIpSet)
+		if err != nil {
+			return err
+		}
+		return ipSet.Delete()
+	}
+	return fmt.Errorf("unsupported action '%s'", change.Action)
+}
+
+func (applier *orgConfigApplier) applyNetwork(change OrgConfigChange) error {
+	switch change.Action {
+	case OrgConfigActionCreate:
+		desired := change.desired.(OrgConfigNetwork)
+		networkConfig, err := applier.networkConfiguration(desired)
+		if err != nil {
+			return err
+		}
+		_, err = createOpenApiOrgVdcNetwork(applier.adminOrg.client, networkConfig)
+		return err
+	case OrgConfigActionUpdate:
+		desired := change.desired.(OrgConfigNetwork)
+		network, err := applier.network(change.Name, desired.Owner, desired.OwnerType)
+		if err != nil {
+			return err
+		}
+		networkConfig, err := applier.networkConfiguration(desired)
+		if err != nil {
+			return err
+		}
+		networkConfig.ID = network.OpenApiOrgVdcNetwork.ID
+		_, err = network.Update(networkConfig)
+		return err
+	case OrgConfigActionDelete:
+		current := change.current.(OrgConfigNetwork)
+		network, err := applier.network(change.Name, current.Owner, current.OwnerType)
+		if err != nil {
+			return err
+		}
+		return network.Delete()
+	}
+	return fmt.Errorf("unsupported action '%s'", change.Action)
+}
+
+func (applier *orgConfigApplier) network(name, owner, ownerType string) (*OpenApiOrgVdcNetwork, error) {
+	ownerRef, err := applier.ownerReference(owner, ownerType)
+	if err != nil {
+		return nil, err
+	}
+	queryParameters := queryParameterFilterAnd(fmt.Sprintf("name==%s;ownerRef.id==%s", name, ownerRef.ID), nil)
+	networks, err := getAllOpenApiOrgVdcNetworks(applier.adminOrg.client, queryParameters, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve Org VDC network '%s': %s", name, err)
+	}
+	return returnSingleOpenApiOrgVdcNetwork(name, networks)
+}
+
+func (applier *orgConfigApplier) networkConfiguration(desired OrgConfigNetwork) (*types.OpenApiOrgVdcNetwork, error) {
+	ownerRef, err := applier.ownerReference(desired.Owner, desired.OwnerType)
+	if err != nil {
+		return nil, err
+	}
+	networkConfig := &types.OpenApiOrgVdcNetwork{
+		Name:                    desired.Name,
+		Description:             desired.Description,
+		OwnerRef:                ownerRef,
+		NetworkType:             desired.NetworkType,
+		Shared:                  desired.Shared,
+		EnableDualSubnetNetwork: desired.DualStack,
+	}
+	if desired.EdgeGateway != "" {
+		egw, err := applier.edgeGateway(desired.EdgeGateway)
+		if err != nil {
+			return nil, err
+		}
+		networkConfig.Connection = &types.Connection{
+			RouterRef:           types.OpenApiReference{ID: egw.EdgeGateway.ID},
+			ConnectionTypeValue: desired.ConnectionType,
+		}
+	}
+	for _, subnet := range desired.Subnets {
+		networkConfig.Subnets.Values = append(networkConfig.Subnets.Values, types.OrgVdcNetworkSubnetValues{
+			Gateway:      subnet.Gateway,
+			PrefixLength: subnet.PrefixLength,
+			DNSServer1:   subnet.DnsServer1,
+			DNSServer2:   subnet.DnsServer2,
+			DNSSuffix:    subnet.DnsSuffix,
+			IPRanges:     types.OrgVdcNetworkSubnetIPRanges{Values: orgConfigParseIpRanges(subnet.IpRanges)},
+		})
+	}
+	return networkConfig, nil
+}
+
+func (applier *orgConfigApplier) applyNatRule(change OrgConfigChange) error {
+	egw, err := applier.edgeGateway(change.Parent)
+	if err != nil {
+		return err
+	}
+	switch change.Action {
+	case OrgConfigActionCreate:
+		natRuleConfig, err := applier.natRuleConfiguration(change.desired.(OrgConfigNatRule))
+		if err != nil {
+			return err
+		}
+		_, err = egw.CreateNatRule(natRuleConfig)
+		return err
+	case OrgConfigActionUpdate:
+		natRule, err := egw.GetNatRuleByName(change.Name)
+		if err != nil {
+			return err
+		}
+		natRuleConfig, err := applier.natRuleConfiguration(change.desired.(OrgConfigNatRule))
+		if err != nil {
+			return err
+		}
+		natRuleConfig.ID = natRule.NsxtNatRule.ID
+		_, err = natRule.Update(natRuleConfig)
+		return err
+	case OrgConfigActionDelete:
+		natRule, err := egw.GetNatRuleByName(change.Name)
+		if err != nil {
+			return err
+		}
+		return natRule.Delete()
+	}
+	return fmt.Errorf("unsupported action '%s'", change.Action)
+}
+
+func (applier *orgConfigApplier) natRuleConfiguration(desired OrgConfigNatRule) (*types.NsxtNatRule, error) {
+	natRule := &types.NsxtNatRule{
+		Name:                     desired.Name,
+		Description:              desired.Description,
+		Enabled:                  desired.Enabled,
+		Type:                     desired.Type,
+		ExternalAddresses:        desired.ExternalAddresses,
+		InternalAddresses:        desired.InternalAddresses,
+		InternalPort:             desired.InternalPort,
+		DnatExternalPort:         desired.DnatExternalPort,
+		SnatDestinationAddresses: desired.SnatDestinationAddresses,
+		Logging:                  desired.Logging,
+		FirewallMatch:            desired.FirewallMatch,
+		Priority:                 desired.Priority,
+	}
+	if desired.ApplicationPortProfile != "" {
+		profileRef, err := getNsxtAppPortProfileReferenceByName(applier.adminOrg.client, desired.ApplicationPortProfile, "")
+		if err != nil {
+			return nil, err
+		}
+		natRule.ApplicationPortProfile = profileRef
+	}
+	return natRule, nil
+}
+
+func (applier *orgConfigApplier) applyFirewallRules(change OrgConfigChange) error {
+	egw, err := applier.edgeGateway(change.Parent)
+	if err != nil {
+		return err
+	}
+	if change.Action == OrgConfigActionDelete {
+		firewall, err := egw.GetNsxtFirewall()
+		if err != nil {
+			return err
+		}
+		return firewall.DeleteAllRules()
+	}
+
+	firewallGroups, err := egw.GetAllNsxtFirewallGroups(nil, "")
+	if err != nil {
+		return fmt.Errorf("error retrieving firewall groups of Edge Gateway '%s': %s", change.Parent, err)
+	}
+	groupIds := make(map[string]string)
+	for _, group := range firewallGroups {
+		groupIds[group.NsxtFirewallGroup.Name] = group.NsxtFirewallGroup.ID
+	}
+	groupReferences := func(names []string) ([]types.OpenApiReference, error) {
+		var references []types.OpenApiReference
+		for _, name := range names {
+			id, found := groupIds[name]
+			if !found {
+				return nil, fmt.Errorf("%s: firewall group '%s'", ErrorEntityNotFound, name)
+			}
+			references = append(references, types.OpenApiReference{ID: id, Name: name})
+		}
+		return references, nil
+	}
+
+	container := &types.NsxtFirewallRuleContainer{}
+	for _, rule := range change.desired.([]OrgConfigFirewallRule) {
+		sources, err := groupReferences(rule.Sources)
+		if err != nil {
+			return err
+		}
+		destinations, err := groupReferences(rule.Destinations)
+		if err != nil {
+			return err
+		}
+		var profiles []types.OpenApiReference
+		for _, profileName := range rule.ApplicationPortProfiles {
+			profileRef, err := getNsxtAppPortProfileReferenceByName(applier.adminOrg.client, profileName, "")
+			if err != nil {
+				return err
+			}
+			profiles = append(profiles, *profileRef)
+		}
+		container.UserDefinedRules = append(container.UserDefinedRules, &types.NsxtFirewallRule{
+			Name:                      rule.Name,
+			ActionValue:               rule.Action,
+			Enabled:                   rule.Enabled,
+			Direction:                 rule.Direction,
+			IpProtocol:                rule.IpProtocol,
+			Logging:                   rule.Logging,
+			SourceFirewallGroups:      sources,
+			DestinationFirewallGroups: destinations,
+			ApplicationPortProfiles:   profiles,
+		})
+	}
+	_, err = egw.UpdateNsxtFirewall(container)
+	return err
+}
+
+func (applier *orgConfigApplier) applyVApp(change OrgConfigChange) error {
+	vdc, err := applier.adminOrg.GetVDCByName(change.Parent, true)
+	if err != nil {
+		return err
+	}
+	switch change.Action {
+	case OrgConfigActionCreate:
+		desired := change.desired.(OrgConfigVApp)
+		vapp, err := vdc.CreateRawVApp(desired.Name, desired.Description)
+		if err != nil {
+			return err
+		}
+		return attachOrgConfigVAppNetworks(vdc, vapp, nil, desired.Networks)
+	case OrgConfigActionUpdate:
+		current := change.current.(OrgConfigVApp)
+		desired := change.desired.(OrgConfigVApp)
+		vapp, err := vdc.GetVAppByName(change.Name, true)
+		if err != nil {
+			return err
+		}
+		if current.Description != desired.Description {
+			err = vapp.UpdateNameDescription(desired.Name, desired.Description)
+			if err != nil {
+				return err
+			}
+		}
+		return attachOrgConfigVAppNetworks(vdc, vapp, current.Networks, desired.Networks)
+	case OrgConfigActionDelete:
+		vapp, err := vdc.GetVAppByName(change.Name, true)
+		if err != nil {
+			return err
+		}
+		// An undeployed vApp can be removed directly, while undeploying it would fail
+		if vapp.VApp.Deployed {
+			task, err := vapp.Undeploy()
+			if err != nil {
+				return fmt.Errorf("error undeploying vApp '%s': %s", change.Name, err)
+			}
+			err = task.WaitTaskCompletion()
+			if err != nil {
+				return fmt.Errorf("error undeploying vApp '%s': %s", change.Name, err)
+			}
+		}
+		task, err := vapp.Delete()
+		if err != nil {
+			return err
+		}
+		return task.WaitTaskCompletion()
+	}
+	return fmt.Errorf("unsupported action '%s'", change.Action)
+}
+
+// attachOrgConfigVAppNetworks connects Org VDC networks that are in 'desired' but not in 'current'.
+// Networks are never detached, as that could disconnect running VMs
+func attachOrgConfigVAppNetworks(vdc *Vdc, vapp *VApp, current, desired []string) error {
+	for _, networkName := range desired {
+		if contains(networkName, current) {
+			continue
+		}
+		orgNetwork, err := vdc.GetOrgVdcNetworkByName(networkName, false)
+		if err != nil {
+			return fmt.Errorf("error retrieving Org VDC network '%s': %s", networkName, err)
+		}
+		_, err = vapp.AddOrgNetwork(&VappNetworkSettings{}, orgNetwork.OrgVDCNetwork, false)
+		if err != nil {
+			return fmt.Errorf("error attaching network '%s' to vApp '%s': %s", networkName, vapp.VApp.Name, err)
+		}
+	}
+	return nil
+}
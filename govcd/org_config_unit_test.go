@@ -0,0 +1,299 @@
+//go:build unit || ALL
+
+/*
+ * Copyright 2025 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+package govcd
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// Test_OrgConfigDocumentRoundTrip checks that a document survives YAML and JSON serialization and
+// that sorting keeps firewall rule order intact
+func Test_OrgConfigDocumentRoundTrip(t *testing.T) {
+	doc := &OrgConfigDocument{
+		Version: OrgConfigDocumentVersion,
+		Org:     OrgConfigSettings{Name: "org1", Enabled: true},
+		Vdcs: []OrgConfigVdc{
+			{Name: "vdc-b", AllocationModel: "Flex"},
+			{Name: "vdc-a", AllocationModel: "AllocationVApp", StorageProfiles: []string{"*"}},
+		},
+		EdgeGateways: []OrgConfigEdgeGateway{
+			{
+				Name:      "egw",
+				Owner:     "vdc-a",
+				OwnerType: OrgConfigOwnerTypeVdc,
+				IpSets: []OrgConfigIpSet{
+					{Name: "web", IpAddresses: []string{"10.0.0.1"}},
+					{Name: "db", IpAddresses: []string{"10.0.0.2"}},
+				},
+				FirewallRules: []OrgConfigFirewallRule{
+					{Name: "z-first", Action: "ALLOW", Sources: []string{"web"}},
+					{Name: "a-second", Action: "DROP"},
+				},
+			},
+		},
+	}
+	doc.sort()
+
+	if doc.Vdcs[0].Name != "vdc-a" {
+		t.Fatalf("expected VDCs to be sorted, got %s first", doc.Vdcs[0].Name)
+	}
+	if doc.EdgeGateways[0].IpSets[0].Name != "db" {
+		t.Fatalf("expected IP Sets to be sorted, got %s first", doc.EdgeGateways[0].IpSets[0].Name)
+	}
+	if doc.EdgeGateways[0].FirewallRules[0].Name != "z-first" {
+		t.Fatalf("firewall rule order must not change, got %s first", doc.EdgeGateways[0].FirewallRules[0].Name)
+	}
+
+	yamlContent, err := doc.ToYaml()
+	if err != nil {
+		t.Fatalf("error marshalling YAML: %s", err)
+	}
+	fromYaml, err := ParseOrgConfigDocument(yamlContent)
+	if err != nil {
+		t.Fatalf("error parsing YAML: %s", err)
+	}
+	if !reflect.DeepEqual(doc, fromYaml) {
+		t.Fatalf("YAML round trip mismatch:\n%s", yamlContent)
+	}
+
+	jsonContent, err := doc.ToJson()
+	if err != nil {
+		t.Fatalf("error marshalling JSON: %s", err)
+	}
+	fromJson, err := ParseOrgConfigDocument(jsonContent)
+	if err != nil {
+		t.Fatalf("error parsing JSON: %s", err)
+	}
+	if !reflect.DeepEqual(doc, fromJson) {
+		t.Fatalf("JSON round trip mismatch:\n%s", jsonContent)
+	}
+
+	_, err = ParseOrgConfigDocument([]byte("version: \"1\"\norg:\n  name: x\n  unknownField: y\n"))
+	if err == nil {
+		t.Fatalf("expected error for unknown field")
+	}
+	_, err = ParseOrgConfigDocument([]byte("version: \"99\"\n"))
+	if err == nil {
+		t.Fatalf("expected error for unsupported version")
+	}
+}
+
+// Test_orgConfigConversions checks that API structures are converted to name based references
+func Test_orgConfigConversions(t *testing.T) {
+	network := &types.OpenApiOrgVdcNetwork{
+		Name:        "net1",
+		NetworkType: types.OrgVdcNetworkTypeRouted,
+		OwnerRef:    &types.OpenApiReference{Name: "group1", ID: "urn:vcloud:vdcGroup:6b0c1d2e-1111-2222-3333-444455556666"},
+		Connection: &types.Connection{
+			RouterRef:           types.OpenApiReference{Name: "egw1", ID: "urn:vcloud:gateway:1"},
+			ConnectionTypeValue: "INTERNAL",
+		},
+		Subnets: types.OrgVdcNetworkSubnets{Values: []types.OrgVdcNetworkSubnetValues{
+			{
+				Gateway:      "10.10.10.1",
+				PrefixLength: 24,
+				IPRanges: types.OrgVdcNetworkSubnetIPRanges{Values: []types.OrgVdcNetworkSubnetIPRangeValues{
+					{StartAddress: "10.10.10.10", EndAddress: "10.10.10.20"},
+					{StartAddress: "10.10.10.30", EndAddress: "10.10.10.30"},
+				}},
+			},
+		}},
+	}
+	configNetwork := orgConfigNetworkFromNetwork(network)
+	expectedNetwork := OrgConfigNetwork{
+		Name:           "net1",
+		Owner:          "group1",
+		OwnerType:      OrgConfigOwnerTypeVdcGroup,
+		NetworkType:    types.OrgVdcNetworkTypeRouted,
+		EdgeGateway:    "egw1",
+		ConnectionType: "INTERNAL",
+		Subnets: []OrgConfigSubnet{
+			{Gateway: "10.10.10.1", PrefixLength: 24, IpRanges: []string{"10.10.10.10-10.10.10.20", "10.10.10.30"}},
+		},
+	}
+	if !reflect.DeepEqual(configNetwork, expectedNetwork) {
+		t.Fatalf("network conversion mismatch:\ngot:  %#v\nwant: %#v", configNetwork, expectedNetwork)
+	}
+
+	ranges := orgConfigParseIpRanges(configNetwork.Subnets[0].IpRanges)
+	if !reflect.DeepEqual(ranges, network.Subnets.Values[0].IPRanges.Values) {
+		t.Fatalf("IP range parsing mismatch: %#v", ranges)
+	}
+
+	natRule := orgConfigNatRuleFromNatRule(&types.NsxtNatRule{
+		ID:                     "rule-id",
+		Name:                   "dnat",
+		RuleType:               types.NsxtNatRuleTypeDnat,
+		ExternalAddresses:      "1.1.1.1",
+		InternalAddresses:      "10.10.10.10",
+		ApplicationPortProfile: &types.OpenApiReference{Name: "HTTP", ID: "urn:vcloud:applicationPortProfile:1"},
+	})
+	if natRule.Type != types.NsxtNatRuleTypeDnat || natRule.ApplicationPortProfile != "HTTP" {
+		t.Fatalf("NAT rule conversion mismatch: %#v", natRule)
+	}
+
+	firewallRules := orgConfigFirewallRulesFromContainer(&types.NsxtFirewallRuleContainer{
+		SystemRules: []*types.NsxtFirewallRule{{Name: "system"}},
+		UserDefinedRules: []*types.NsxtFirewallRule{
+			{
+				Name:                 "allow-web",
+				ActionValue:          "ALLOW",
+				SourceFirewallGroups: []types.OpenApiReference{{Name: "web", ID: "urn:vcloud:firewallGroup:1"}},
+			},
+		},
+	})
+	if len(firewallRules) != 1 || firewallRules[0].Sources[0] != "web" || firewallRules[0].Action != "ALLOW" {
+		t.Fatalf("firewall rule conversion mismatch: %#v", firewallRules)
+	}
+}
+
+// Test_diffOrgConfig checks the computed changes and their dependency order
+func Test_diffOrgConfig(t *testing.T) {
+	current := &OrgConfigDocument{
+		Version: OrgConfigDocumentVersion,
+		Vdcs:    []OrgConfigVdc{{Name: "vdc1", VmQuota: 10}, {Name: "old-vdc"}},
+		EdgeGateways: []OrgConfigEdgeGateway{
+			{
+				Name:     "egw1",
+				Owner:    "vdc1",
+				IpSets:   []OrgConfigIpSet{{Name: "web", IpAddresses: []string{"10.0.0.1"}}},
+				NatRules: []OrgConfigNatRule{{Name: "stale", Type: "DNAT"}},
+			},
+			{Name: "old-egw", NatRules: []OrgConfigNatRule{{Name: "gone"}}},
+		},
+		Networks: []OrgConfigNetwork{{Name: "net1", Owner: "vdc1", EdgeGateway: "egw1"}},
+		VApps:    []OrgConfigVApp{{Name: "app", Vdc: "vdc1"}},
+	}
+	desired := &OrgConfigDocument{
+		Version: OrgConfigDocumentVersion,
+		Vdcs:    []OrgConfigVdc{{Name: "vdc1", VmQuota: 20}},
+		EdgeGateways: []OrgConfigEdgeGateway{
+			{
+				Name:          "egw1",
+				Owner:         "vdc1",
+				IpSets:        []OrgConfigIpSet{{Name: "web", IpAddresses: []string{"10.0.0.1"}}, {Name: "db"}},
+				FirewallRules: []OrgConfigFirewallRule{{Name: "allow", Action: "ALLOW", Sources: []string{"db"}}},
+			},
+		},
+		Networks: []OrgConfigNetwork{
+			{Name: "net1", Owner: "vdc1", EdgeGateway: "egw1"},
+			{Name: "net2", Owner: "vdc1", EdgeGateway: "egw1"},
+		},
+		VApps: []OrgConfigVApp{{Name: "app", Vdc: "vdc1", Networks: []string{"net2"}}},
+	}
+
+	type change struct{ kind, name, parent, action string }
+	summarize := func(plan *OrgConfigPlan) []change {
+		var result []change
+		for _, c := range plan.Changes {
+			result = append(result, change{c.Kind, c.Name, c.Parent, c.Action})
+		}
+		return result
+	}
+
+	expected := []change{
+		{OrgConfigKindVdc, "vdc1", "", OrgConfigActionUpdate},
+		{OrgConfigKindIpSet, "db", "egw1", OrgConfigActionCreate},
+		{OrgConfigKindNetwork, "net2", "vdc1", OrgConfigActionCreate},
+		{OrgConfigKindFirewallRules, "egw1", "egw1", OrgConfigActionUpdate},
+		{OrgConfigKindVApp, "app", "vdc1", OrgConfigActionUpdate},
+		{OrgConfigKindNatRule, "stale", "egw1", OrgConfigActionDelete},
+		{OrgConfigKindEdgeGateway, "old-egw", "", OrgConfigActionDelete},
+		{OrgConfigKindVdc, "old-vdc", "", OrgConfigActionDelete},
+	}
+	plan, err := diffOrgConfig(current, desired, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	got := summarize(plan)
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("unexpected plan:\ngot:  %v\nwant: %v", got, expected)
+	}
+
+	plan, err = diffOrgConfig(current, desired, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	for _, c := range plan.Changes {
+		if c.Action == OrgConfigActionDelete {
+			t.Fatalf("deletion of %s '%s' planned without AllowDelete", c.Kind, c.Name)
+		}
+	}
+	if plan.Changes[0].Fields[0] != "vmQuota" {
+		t.Fatalf("expected changed field 'vmQuota', got %v", plan.Changes[0].Fields)
+	}
+
+	plan, err = diffOrgConfig(desired, desired, true)
+	if err != nil || len(plan.Changes) != 0 {
+		t.Fatalf("expected no changes when comparing a document with itself")
+	}
+}
+
+// Test_diffOrgConfigVdc checks storage profile changes, omitted settings and fields that cannot be
+// changed after creation
+func Test_diffOrgConfigVdc(t *testing.T) {
+	current := &OrgConfigDocument{Vdcs: []OrgConfigVdc{{Name: "vdc1", AllocationModel: "Flex", ProviderVdc: "pvdc1",
+		NetworkPool: "pool1", StorageProfiles: []string{"gold", "silver"}, ThinProvisioning: addrOf(true)}}}
+
+	desired := &OrgConfigDocument{Vdcs: []OrgConfigVdc{{Name: "vdc1", StorageProfiles: []string{"silver", "gold"}}}}
+	plan, err := diffOrgConfig(current, desired, true)
+	if err != nil || len(plan.Changes) != 0 {
+		t.Fatalf("expected no changes for omitted settings and reordered storage profiles, got %v %v", plan, err)
+	}
+
+	desired.Vdcs[0].StorageProfiles = []string{"bronze", "gold"}
+	plan, err = diffOrgConfig(current, desired, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(plan.Changes) != 1 || !reflect.DeepEqual(plan.Changes[0].Fields, []string{"storageProfiles"}) {
+		t.Fatalf("expected a storage profile update, got %+v", plan.Changes)
+	}
+	if profiles := plan.Changes[0].desired.(OrgConfigVdc).StorageProfiles; !reflect.DeepEqual(profiles, []string{"bronze", "gold"}) {
+		t.Fatalf("unexpected desired storage profiles %v", profiles)
+	}
+
+	desired.Vdcs[0].AllocationModel = "AllocationVApp"
+	desired.Vdcs[0].NetworkPool = "pool2"
+	_, err = diffOrgConfig(current, desired, true)
+	if err == nil || !strings.Contains(err.Error(), "allocationModel, networkPool") {
+		t.Fatalf("expected error for fields that cannot be changed, got %v", err)
+	}
+}
+
+// Test_mergeOrgConfigEdgeGatewayUplink checks that settings not modeled in the document are preserved
+func Test_mergeOrgConfigEdgeGatewayUplink(t *testing.T) {
+	uplink := types.EdgeGatewayUplinks{
+		UplinkID:                 "urn:vcloud:network:1",
+		UsingIpSpace:             addrOf(false),
+		QuickAddAllocatedIPCount: 2,
+		Subnets: types.OpenAPIEdgeGatewaySubnets{Values: []types.OpenAPIEdgeGatewaySubnetValue{
+			{Gateway: "10.0.0.1", PrefixLength: 24, Enabled: true, TotalIPCount: addrOf(10),
+				IPRanges: &types.OpenApiIPRanges{Values: []types.OpenApiIPRangeValues{{StartAddress: "10.0.0.10", EndAddress: "10.0.0.19"}}}},
+			{Gateway: "10.0.1.1", PrefixLength: 24, Enabled: true},
+		}},
+	}
+	mergeOrgConfigEdgeGatewayUplink(&uplink, OrgConfigEdgeGatewayUplink{
+		Dedicated: true,
+		Subnets: []OrgConfigSubnet{
+			{Gateway: "10.0.0.1", PrefixLength: 24, PrimaryIp: "10.0.0.10", IpRanges: []string{"10.0.0.10-10.0.0.29"}},
+			{Gateway: "10.0.2.1", PrefixLength: 24},
+		},
+	})
+
+	if !uplink.Dedicated || uplink.UsingIpSpace == nil || uplink.QuickAddAllocatedIPCount != 2 || len(uplink.Subnets.Values) != 3 {
+		t.Fatalf("unexpected merged uplink %+v", uplink)
+	}
+	subnet := uplink.Subnets.Values[0]
+	if subnet.PrimaryIP != "10.0.0.10" || subnet.TotalIPCount == nil || subnet.IPRanges.Values[0].EndAddress != "10.0.0.29" {
+		t.Fatalf("unexpected merged subnet %+v", subnet)
+	}
+}
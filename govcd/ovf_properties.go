@@ -0,0 +1,539 @@
+/*
+ * Copyright 2025 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+package govcd
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// This file describes the OVF properties declared by product sections and binds their values to Go values, using
+// the "ovf" struct tag:
+//
+//	type AppProperties struct {
+//		Hostname string `ovf:"guestinfo.hostname"`
+//		Port     int    `ovf:"app.port"`
+//		Debug    *bool  `ovf:"app.debug"`
+//		Ignored  string `ovf:"-"`
+//	}
+//
+// The tag holds the property key. Supported field types are string, bool, signed and unsigned integers, floats and
+// pointers to any of them. Pointer fields are optional: they are not written when nil. A map[string]any or a
+// map[string]string can be used instead of a struct.
+
+// ovfPropertyTagName is the name of the struct tag used for OVF property binding
+const ovfPropertyTagName = "ovf"
+
+// OvfProperty is an OVF property declared by a product section, with its qualifiers parsed
+type OvfProperty struct {
+	Key              string
+	Type             string // OVF type, such as string, boolean, uint16, sint32 or real64
+	Label            string
+	Description      string
+	DefaultValue     string
+	UserConfigurable bool
+	Password         bool
+	// Qualifiers holds the raw qualifiers. The known ones are parsed into the fields below
+	Qualifiers    string
+	MinLength     *int     // MinLen(n)
+	MaxLength     *int     // MaxLen(n)
+	MinValue      *float64 // MinValue(n)
+	MaxValue      *float64 // MaxValue(n)
+	AllowedValues []string // ValueMap{"a","b"}
+	// Required is true for user configurable properties without a default value, unless they are strings that
+	// accept an empty value
+	Required bool
+}
+
+// OvfPropertyValidationError is returned when values don't match the OVF properties they are bound to
+type OvfPropertyValidationError struct {
+	Missing  []string // Keys of required properties without a value
+	Problems []string // Invalid values, unknown keys and values for properties that are not user configurable
+}
+
+func (err *OvfPropertyValidationError) Error() string {
+	var messages []string
+	if len(err.Missing) > 0 {
+		messages = append(messages, fmt.Sprintf("required OVF properties are missing: %s", strings.Join(err.Missing, ", ")))
+	}
+	messages = append(messages, err.Problems...)
+	return strings.Join(messages, "; ")
+}
+
+var (
+	ovfQualifierRegexp = regexp.MustCompile(`(\w+)\s*(?:\(([^)]*)\)|\{([^}]*)\})`)
+	ovfValueMapRegexp  = regexp.MustCompile(`"([^"]*)"|([^,\s]+)`)
+)
+
+// GetOvfProperties retrieves the OVF properties declared by the vApp template
+func (vAppTemplate *VAppTemplate) GetOvfProperties() ([]*OvfProperty, error) {
+	productSectionList, err := getProductSectionList(vAppTemplate.client, vAppTemplate.VAppTemplate.HREF)
+	if err != nil {
+		return nil, err
+	}
+	return OvfPropertiesFromProductSection(productSectionList.ProductSection)
+}
+
+// OvfPropertiesFromProductSection returns the OVF properties declared by the given product section, sorted by key
+func OvfPropertiesFromProductSection(productSection *types.ProductSection) ([]*OvfProperty, error) {
+	var properties []*OvfProperty
+	if productSection == nil {
+		return properties, nil
+	}
+	for _, property := range productSection.Property {
+		ovfProperty := &OvfProperty{
+			Key:              property.Key,
+			Type:             property.Type,
+			Label:            property.Label,
+			Description:      property.Description,
+			DefaultValue:     property.DefaultValue,
+			UserConfigurable: property.UserConfigurable,
+			Password:         property.Password,
+			Qualifiers:       property.Qualifiers,
+		}
+		err := parseOvfQualifiers(ovfProperty)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing qualifiers of OVF property '%s': %s", property.Key, err)
+		}
+		ovfProperty.Required = ovfProperty.UserConfigurable && ovfProperty.DefaultValue == "" &&
+			(ovfBaseType(ovfProperty.Type) != "string" || (ovfProperty.MinLength != nil && *ovfProperty.MinLength > 0))
+		properties = append(properties, ovfProperty)
+	}
+	sort.SliceStable(properties, func(i, j int) bool { return properties[i].Key < properties[j].Key })
+	return properties, nil
+}
+
+// ValidateOvfPropertyValues checks the given values, indexed by property key, against the OVF properties. It
+// returns an *OvfPropertyValidationError listing missing required properties and invalid values.
+func ValidateOvfPropertyValues(properties []*OvfProperty, values map[string]string) error {
+	byKey := make(map[string]*OvfProperty)
+	for _, property := range properties {
+		byKey[property.Key] = property
+	}
+
+	var keys []string
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	validationError := &OvfPropertyValidationError{}
+	for _, key := range keys {
+		property, found := byKey[key]
+		if !found {
+			validationError.Problems = append(validationError.Problems, fmt.Sprintf("OVF property '%s' is not declared", key))
+			continue
+		}
+		if !property.UserConfigurable {
+			validationError.Problems = append(validationError.Problems, fmt.Sprintf("OVF property '%s' is not user configurable", key))
+			continue
+		}
+		err := property.ValidateValue(values[key])
+		if err != nil {
+			validationError.Problems = append(validationError.Problems, fmt.Sprintf("OVF property '%s': %s", key, err))
+		}
+	}
+	for _, property := range properties {
+		if _, found := values[property.Key]; property.Required && !found {
+			validationError.Missing = append(validationError.Missing, property.Key)
+		}
+	}
+
+	if len(validationError.Missing) > 0 || len(validationError.Problems) > 0 {
+		return validationError
+	}
+	return nil
+}
+
+// ValidateValue checks a single value against the type and qualifiers of the property
+func (property *OvfProperty) ValidateValue(value string) error {
+	if property.Required && value == "" {
+		return fmt.Errorf("a value is required")
+	}
+
+	switch ovfBaseType(property.Type) {
+	case "boolean":
+		_, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("'%s' is not a boolean", value)
+		}
+	case "string":
+		length := utf8.RuneCountInString(value)
+		if property.MinLength != nil && length < *property.MinLength {
+			return fmt.Errorf("value is shorter than %d characters", *property.MinLength)
+		}
+		if property.MaxLength != nil && length > *property.MaxLength {
+			return fmt.Errorf("value is longer than %d characters", *property.MaxLength)
+		}
+	default:
+		number, err := parseOvfNumber(property.Type, value)
+		if err != nil {
+			return fmt.Errorf("'%s' is not a valid %s", value, property.Type)
+		}
+		if property.MinValue != nil && number < *property.MinValue {
+			return fmt.Errorf("value %s is lower than %s", value, strconv.FormatFloat(*property.MinValue, 'f', -1, 64))
+		}
+		if property.MaxValue != nil && number > *property.MaxValue {
+			return fmt.Errorf("value %s is greater than %s", value, strconv.FormatFloat(*property.MaxValue, 'f', -1, 64))
+		}
+	}
+
+	if len(property.AllowedValues) > 0 && !contains(value, property.AllowedValues) {
+		return fmt.Errorf("'%s' is not one of %s", value, strings.Join(property.AllowedValues, ", "))
+	}
+	return nil
+}
+
+// OvfPropertyValues returns the values of source indexed by property key. Source can be a struct, or pointer to
+// struct, with "ovf" tags, a map[string]any or a map[string]string
+func OvfPropertyValues(source any) (map[string]string, error) {
+	values := make(map[string]string)
+	switch typed := source.(type) {
+	case map[string]string:
+		for key, value := range typed {
+			values[key] = value
+		}
+		return values, nil
+	case map[string]any:
+		for key, value := range typed {
+			if value == nil {
+				continue
+			}
+			text, err := ovfValueText(reflect.ValueOf(value))
+			if err != nil {
+				return nil, fmt.Errorf("OVF property '%s': %s", key, err)
+			}
+			values[key] = text
+		}
+		return values, nil
+	}
+
+	value, err := ovfStructValue(source, false)
+	if err != nil {
+		return nil, err
+	}
+	fields, err := parseOvfFields(value.Type())
+	if err != nil {
+		return nil, err
+	}
+	for _, field := range fields {
+		fieldValue := value.Field(field.index)
+		if fieldValue.Kind() == reflect.Pointer {
+			if fieldValue.IsNil() {
+				continue
+			}
+			fieldValue = fieldValue.Elem()
+		}
+		values[field.key], _ = ovfValueText(fieldValue)
+	}
+	return values, nil
+}
+
+// BuildOvfProductSection validates the values of source against the OVF properties and returns a product section
+// with those values, usable in types.InstantiationParams or with SetProductSectionList. Properties without a value
+// in source keep their default. Source is handled as in OvfPropertyValues.
+func BuildOvfProductSection(properties []*OvfProperty, source any) (*types.ProductSection, error) {
+	values, err := OvfPropertyValues(source)
+	if err != nil {
+		return nil, err
+	}
+	err = ValidateOvfPropertyValues(properties, values)
+	if err != nil {
+		return nil, err
+	}
+
+	productSection := &types.ProductSection{Info: "Custom properties"}
+	for _, property := range properties {
+		sectionProperty := &types.Property{
+			Key:              property.Key,
+			Label:            property.Label,
+			Description:      property.Description,
+			DefaultValue:     property.DefaultValue,
+			Type:             property.Type,
+			UserConfigurable: property.UserConfigurable,
+			Qualifiers:       property.Qualifiers,
+			Password:         property.Password,
+		}
+		if value, found := values[property.Key]; found {
+			sectionProperty.Value = &types.Value{Value: value}
+		}
+		productSection.Property = append(productSection.Property, sectionProperty)
+	}
+	return productSection, nil
+}
+
+// ReadOvfPropertiesInto sets target from the values of the product section. The value of a property is its Value
+// when set, and its default value otherwise. Target can be a pointer to a struct with "ovf" tags, or a
+// map[string]any, which receives values converted according to the property types.
+func ReadOvfPropertiesInto(productSection *types.ProductSection, target any) error {
+	values := make(map[string]string)
+	propertyTypes := make(map[string]string)
+	if productSection != nil {
+		for _, property := range productSection.Property {
+			values[property.Key] = property.DefaultValue
+			if property.Value != nil {
+				values[property.Key] = property.Value.Value
+			}
+			propertyTypes[property.Key] = property.Type
+		}
+	}
+
+	if targetMap, ok := target.(map[string]any); ok {
+		for key, text := range values {
+			value, err := ovfTypedValue(propertyTypes[key], text)
+			if err != nil {
+				return fmt.Errorf("OVF property '%s': %s", key, err)
+			}
+			targetMap[key] = value
+		}
+		return nil
+	}
+
+	value, err := ovfStructValue(target, true)
+	if err != nil {
+		return err
+	}
+	fields, err := parseOvfFields(value.Type())
+	if err != nil {
+		return err
+	}
+	for _, field := range fields {
+		fieldValue := value.Field(field.index)
+		text, found := values[field.key]
+		if !found || (text == "" && fieldValue.Kind() != reflect.String) {
+			fieldValue.SetZero()
+			continue
+		}
+		err = setOvfFieldValue(fieldValue, text)
+		if err != nil {
+			return fmt.Errorf("error setting field %s from OVF property '%s': %s", field.name, field.key, err)
+		}
+	}
+	return nil
+}
+
+// parseOvfQualifiers parses the known qualifiers of the property
+func parseOvfQualifiers(property *OvfProperty) error {
+	for _, match := range ovfQualifierRegexp.FindAllStringSubmatch(property.Qualifiers, -1) {
+		name, argument, list := match[1], strings.TrimSpace(match[2]), match[3]
+		switch name {
+		case "MinLen", "MaxLen":
+			length, err := strconv.Atoi(argument)
+			if err != nil {
+				return fmt.Errorf("invalid %s qualifier '%s'", name, match[0])
+			}
+			if name == "MinLen" {
+				property.MinLength = &length
+			} else {
+				property.MaxLength = &length
+			}
+		case "MinValue", "MaxValue":
+			number, err := strconv.ParseFloat(argument, 64)
+			if err != nil {
+				return fmt.Errorf("invalid %s qualifier '%s'", name, match[0])
+			}
+			if name == "MinValue" {
+				property.MinValue = &number
+			} else {
+				property.MaxValue = &number
+			}
+		case "ValueMap":
+			for _, item := range ovfValueMapRegexp.FindAllStringSubmatch(list, -1) {
+				property.AllowedValues = append(property.AllowedValues, item[1]+item[2])
+			}
+		}
+	}
+	return nil
+}
+
+// ovfBaseType reduces an OVF type to one of "string", "boolean", "sint", "uint" or "real". Unknown types are
+// handled as strings
+func ovfBaseType(ovfType string) string {
+	switch {
+	case ovfType == "boolean":
+		return "boolean"
+	case ovfType == "int" || strings.HasPrefix(ovfType, "sint"):
+		return "sint"
+	case strings.HasPrefix(ovfType, "uint"):
+		return "uint"
+	case strings.HasPrefix(ovfType, "real"):
+		return "real"
+	}
+	return "string"
+}
+
+// parseOvfNumber parses a numeric value of the given OVF type, checking that it fits in the size of the type
+func parseOvfNumber(ovfType, value string) (float64, error) {
+	bits := 64
+	if size, err := strconv.Atoi(strings.TrimLeft(ovfType, "sinuteal")); err == nil {
+		bits = size
+	} else if ovfType == "int" {
+		bits = 32
+	}
+	switch ovfBaseType(ovfType) {
+	case "sint":
+		number, err := strconv.ParseInt(value, 10, bits)
+		return float64(number), err
+	case "uint":
+		number, err := strconv.ParseUint(value, 10, bits)
+		return float64(number), err
+	}
+	return strconv.ParseFloat(value, bits)
+}
+
+// ovfTypedValue converts the text of a value to a Go value according to the OVF type
+func ovfTypedValue(ovfType, text string) (any, error) {
+	baseType := ovfBaseType(ovfType)
+	if baseType == "string" {
+		return text, nil
+	}
+	if text == "" {
+		return nil, nil
+	}
+	switch baseType {
+	case "boolean":
+		return strconv.ParseBool(text)
+	case "sint":
+		return strconv.ParseInt(text, 10, 64)
+	case "uint":
+		return strconv.ParseUint(text, 10, 64)
+	}
+	return strconv.ParseFloat(text, 64)
+}
+
+// ovfValueText returns the text of a value bound to an OVF property
+func ovfValueText(value reflect.Value) (string, error) {
+	switch value.Kind() {
+	case reflect.String:
+		return value.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(value.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(value.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(value.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(value.Float(), 'f', -1, value.Type().Bits()), nil
+	}
+	return "", fmt.Errorf("unsupported value type %s", value.Type())
+}
+
+// ovfField is a struct field bound to an OVF property
+type ovfField struct {
+	index int
+	name  string
+	key   string
+}
+
+// parseOvfFields returns the fields of the given struct type that have an "ovf" tag
+func parseOvfFields(structType reflect.Type) ([]ovfField, error) {
+	var fields []ovfField
+	seen := make(map[string]string)
+	for index := 0; index < structType.NumField(); index++ {
+		structField := structType.Field(index)
+		key, ok := structField.Tag.Lookup(ovfPropertyTagName)
+		if !ok || key == "-" {
+			continue
+		}
+		if !structField.IsExported() {
+			return nil, fmt.Errorf("field %s has an %s tag but is not exported", structField.Name, ovfPropertyTagName)
+		}
+		if !isSupportedOvfFieldType(structField.Type) {
+			return nil, fmt.Errorf("field %s has unsupported type %s for OVF property binding", structField.Name, structField.Type)
+		}
+		if key == "" {
+			return nil, fmt.Errorf("field %s has an empty OVF property key", structField.Name)
+		}
+		if previous, found := seen[key]; found {
+			return nil, fmt.Errorf("fields %s and %s are bound to the same OVF property '%s'", previous, structField.Name, key)
+		}
+		seen[key] = structField.Name
+		fields = append(fields, ovfField{index: index, name: structField.Name, key: key})
+	}
+	return fields, nil
+}
+
+// isSupportedOvfFieldType returns true if the given type can be bound to an OVF property
+func isSupportedOvfFieldType(fieldType reflect.Type) bool {
+	if fieldType.Kind() == reflect.Pointer {
+		fieldType = fieldType.Elem()
+	}
+	switch fieldType.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// ovfStructValue returns the struct value behind the given input. If settable is true, the input must be a
+// non-nil pointer to a struct
+func ovfStructValue(input any, settable bool) (reflect.Value, error) {
+	value := reflect.ValueOf(input)
+	if value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return reflect.Value{}, fmt.Errorf("OVF property binding target is a nil pointer")
+		}
+		value = value.Elem()
+	} else if settable {
+		return reflect.Value{}, fmt.Errorf("OVF property binding target must be a pointer to a struct or a map, got %T", input)
+	}
+	if value.Kind() != reflect.Struct {
+		return reflect.Value{}, fmt.Errorf("OVF property binding requires a struct or a map, got %T", input)
+	}
+	return value, nil
+}
+
+// setOvfFieldValue parses the text of a property value into the given field
+func setOvfFieldValue(field reflect.Value, text string) error {
+	if field.Kind() == reflect.Pointer {
+		pointer := reflect.New(field.Type().Elem())
+		err := setOvfFieldValue(pointer.Elem(), text)
+		if err != nil {
+			return err
+		}
+		field.Set(pointer)
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(text)
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(text)
+		if err != nil {
+			return err
+		}
+		field.SetBool(parsed)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		parsed, err := strconv.ParseInt(text, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(parsed)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		parsed, err := strconv.ParseUint(text, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(parsed)
+	case reflect.Float32, reflect.Float64:
+		parsed, err := strconv.ParseFloat(text, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(parsed)
+	}
+	return nil
+}
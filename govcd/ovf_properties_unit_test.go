@@ -0,0 +1,122 @@
+//go:build unit || ALL
+
+/*
+ * Copyright 2025 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+package govcd
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// makeQualifiedProductSection returns a product section with typed properties, some of them restricted by qualifiers
+func makeQualifiedProductSection() *types.ProductSection {
+	return &types.ProductSection{Property: []*types.Property{
+		{Key: "guestinfo.hostname", Type: "string", UserConfigurable: true, Qualifiers: "MinLen(1) MaxLen(15)"},
+		{Key: "app.port", Type: "uint16", UserConfigurable: true, DefaultValue: "8080", Qualifiers: "MinValue(1024)"},
+		{Key: "app.debug", Type: "boolean", UserConfigurable: true, DefaultValue: "false"},
+		{Key: "app.mode", Type: "string", UserConfigurable: true, DefaultValue: "prod", Qualifiers: `ValueMap{"prod","test", dev}`},
+		{Key: "app.replicas", Type: "sint8", UserConfigurable: true},
+		{Key: "app.note", Type: "string", UserConfigurable: true},
+		{Key: "vendor.build", Type: "string", DefaultValue: "42"},
+	}}
+}
+
+type testOvfValues struct {
+	Hostname string `ovf:"guestinfo.hostname"`
+	Port     *int   `ovf:"app.port"`
+	Debug    bool   `ovf:"app.debug"`
+	Mode     string `ovf:"app.mode"`
+	Replicas int    `ovf:"app.replicas"`
+	Build    string `ovf:"-"`
+}
+
+// Test_OvfPropertiesFromProductSection checks parsing of OVF property qualifiers
+func Test_OvfPropertiesFromProductSection(t *testing.T) {
+	properties, err := OvfPropertiesFromProductSection(makeQualifiedProductSection())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	byKey := make(map[string]*OvfProperty)
+	var required []string
+	for _, property := range properties {
+		byKey[property.Key] = property
+		if property.Required {
+			required = append(required, property.Key)
+		}
+	}
+	if !reflect.DeepEqual(required, []string{"app.replicas", "guestinfo.hostname"}) {
+		t.Fatalf("unexpected required properties %v", required)
+	}
+	hostname := byKey["guestinfo.hostname"]
+	if *hostname.MinLength != 1 || *hostname.MaxLength != 15 {
+		t.Fatalf("unexpected length qualifiers %+v", hostname)
+	}
+	if *byKey["app.port"].MinValue != 1024 || byKey["app.port"].MaxValue != nil {
+		t.Fatalf("unexpected value qualifiers %+v", byKey["app.port"])
+	}
+	if !reflect.DeepEqual(byKey["app.mode"].AllowedValues, []string{"prod", "test", "dev"}) {
+		t.Fatalf("unexpected allowed values %v", byKey["app.mode"].AllowedValues)
+	}
+}
+
+// Test_BuildOvfProductSection checks validation and binding of struct and map values
+func Test_BuildOvfProductSection(t *testing.T) {
+	properties, err := OvfPropertiesFromProductSection(makeQualifiedProductSection())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	productSection, err := BuildOvfProductSection(properties, testOvfValues{Hostname: "web01", Debug: true, Mode: "test", Replicas: 3})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	values := make(map[string]any)
+	err = ReadOvfPropertiesInto(productSection, values)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := map[string]any{"guestinfo.hostname": "web01", "app.port": uint64(8080), "app.debug": true, "app.mode": "test",
+		"app.replicas": int64(3), "app.note": "", "vendor.build": "42"}
+	if !reflect.DeepEqual(values, expected) {
+		t.Fatalf("unexpected values %v", values)
+	}
+
+	var read testOvfValues
+	err = ReadOvfPropertiesInto(productSection, &read)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if read.Hostname != "web01" || read.Port == nil || *read.Port != 8080 || !read.Debug || read.Replicas != 3 || read.Build != "" {
+		t.Fatalf("unexpected struct values %+v", read)
+	}
+
+	_, err = BuildOvfProductSection(properties, map[string]any{"app.port": 80, "app.mode": "qa", "app.replicas": 200,
+		"vendor.build": "43", "unknown": "x", "guestinfo.hostname": "a-very-long-host-name"})
+	var validationError *OvfPropertyValidationError
+	if !errors.As(err, &validationError) {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+	expectedProblems := []string{
+		"OVF property 'app.mode': 'qa' is not one of prod, test, dev",
+		"OVF property 'app.port': value 80 is lower than 1024",
+		"OVF property 'app.replicas': '200' is not a valid sint8",
+		"OVF property 'guestinfo.hostname': value is longer than 15 characters",
+		"OVF property 'unknown' is not declared",
+		"OVF property 'vendor.build' is not user configurable",
+	}
+	if !reflect.DeepEqual(validationError.Problems, expectedProblems) || len(validationError.Missing) != 0 {
+		t.Fatalf("unexpected validation error %+v", validationError)
+	}
+
+	err = ValidateOvfPropertyValues(properties, map[string]string{"app.note": ""})
+	if !errors.As(err, &validationError) || !reflect.DeepEqual(validationError.Missing, []string{"app.replicas", "guestinfo.hostname"}) {
+		t.Fatalf("expected missing properties, got %v", err)
+	}
+}
@@ -199,6 +199,12 @@ func addResults(queryType string, cumulativeResults, newResults Results) (Result
 	case types.QtOrgVdcTemplate:
 		cumulativeResults.Results.OrgVdcTemplateRecord = append(cumulativeResults.Results.OrgVdcTemplateRecord, newResults.Results.OrgVdcTemplateRecord...)
 		size = len(newResults.Results.OrgVdcTemplateRecord)
+	case types.QtDisk:
+		cumulativeResults.Results.DiskRecord = append(cumulativeResults.Results.DiskRecord, newResults.Results.DiskRecord...)
+		size = len(newResults.Results.DiskRecord)
+	case types.QtAdminDisk:
+		cumulativeResults.Results.AdminDiskRecord = append(cumulativeResults.Results.AdminDiskRecord, newResults.Results.AdminDiskRecord...)
+		size = len(newResults.Results.AdminDiskRecord)
 	default:
 		return Results{}, 0, fmt.Errorf("query type %s not supported", queryType)
 	}
@@ -242,6 +248,8 @@ func (client *Client) cumulativeQueryWithHeaders(queryType string, params, notEn
 		types.QtOrg,
 		types.QtOrgVdcTemplate,
 		types.QtAdminOrgVdcTemplate,
+		types.QtDisk,
+		types.QtAdminDisk,
 	}
 	// Make sure the query type is supported
 	// We need to check early, as queries that would return less than 25 items (default page size) would succeed,
@@ -0,0 +1,281 @@
+/*
+ * Copyright 2025 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+package govcd
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// VAppMoveParams defines how the resources used by a vApp are mapped to the target VDC by VApp.MoveToVdc. Resources
+// that are not in a map are looked up by their current name in the target VDC.
+type VAppMoveParams struct {
+	// NetworkMap maps names of Org VDC networks used by the vApp to names of networks available in the target VDC
+	NetworkMap map[string]string
+	// StorageProfileMap maps names of storage profiles used by VMs to names of storage profiles of the target VDC
+	StorageProfileMap map[string]string
+	// ComputePolicyMap maps names of sizing and placement policies used by VMs to names of policies assigned to the
+	// target VDC. Policies that are assigned to both VDCs are kept without mapping.
+	ComputePolicyMap map[string]string
+}
+
+// VAppMoveIssue describes a vApp network or VM that cannot be moved to the target VDC
+type VAppMoveIssue struct {
+	// Subject is the name of the vApp network or VM
+	Subject string
+	Message string
+}
+
+// VAppMovePlan is the result of VApp.PlanMoveToVdc
+type VAppMovePlan struct {
+	// NetworkConfigSection holds the vApp networks, connected to networks of the target VDC
+	NetworkConfigSection *types.NetworkConfigSection
+	// Vms holds network connections, storage profile and compute policy of each VM in the target VDC
+	Vms []*types.SourcedCompositionItemParam
+	// Issues lists what prevents the vApp from being moved. The move can only start when it is empty
+	Issues []VAppMoveIssue
+}
+
+// vAppMoveTarget holds the resources of the target VDC that can be used by a moved vApp
+type vAppMoveTarget struct {
+	href            string
+	networks        []*types.Reference
+	storageProfiles []*types.Reference
+	computePolicies []*types.Reference
+}
+
+// PlanMoveToVdc checks that the vApp can be moved to the target VDC and computes the settings of its networks and VMs
+// in the target VDC. It is run by MoveToVdc, but can be used on its own to report incompatible networks, storage
+// profiles and compute policies before starting the move.
+func (vapp *VApp) PlanMoveToVdc(targetVdc *Vdc, params VAppMoveParams) (*VAppMovePlan, error) {
+	if targetVdc == nil || targetVdc.Vdc == nil || targetVdc.Vdc.HREF == "" {
+		return nil, fmt.Errorf("target VDC cannot be empty")
+	}
+	err := vapp.Refresh()
+	if err != nil {
+		return nil, fmt.Errorf("error refreshing vApp: %s", err)
+	}
+	networkConfig, err := vapp.GetNetworkConfig()
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving vApp network configuration: %s", err)
+	}
+
+	target := vAppMoveTarget{href: targetVdc.Vdc.HREF}
+	for _, availableNetworks := range targetVdc.Vdc.AvailableNetworks {
+		target.networks = append(target.networks, availableNetworks.Network...)
+	}
+	if targetVdc.Vdc.VdcStorageProfiles != nil {
+		target.storageProfiles = targetVdc.Vdc.VdcStorageProfiles.VdcStorageProfile
+	}
+	if vAppUsesComputePolicies(vapp.VApp) {
+		policies, err := getAllAssignedVdcComputePoliciesV2(targetVdc.client, targetVdc.Vdc.ID, nil)
+		if err != nil {
+			return nil, fmt.Errorf("error retrieving compute policies of VDC '%s': %s", targetVdc.Vdc.Name, err)
+		}
+		for _, policy := range policies {
+			policyHref, err := vapp.client.OpenApiBuildEndpoint(types.OpenApiPathVersion1_0_0, types.OpenApiEndpointVdcComputePolicies, policy.VdcComputePolicyV2.ID)
+			if err != nil {
+				return nil, fmt.Errorf("error constructing HREF for compute policy: %s", err)
+			}
+			target.computePolicies = append(target.computePolicies, &types.Reference{
+				HREF: policyHref.String(),
+				ID:   policy.VdcComputePolicyV2.ID,
+				Name: policy.VdcComputePolicyV2.Name,
+			})
+		}
+	}
+
+	return buildVAppMovePlan(vapp.VApp, networkConfig, target, params), nil
+}
+
+// MoveToVdc moves the vApp and its VMs to another VDC of the same organization. Networks, storage profiles and compute
+// policies are mapped following params. The move is not started if PlanMoveToVdc reports any issue.
+func (vapp *VApp) MoveToVdc(targetVdc *Vdc, params VAppMoveParams) (Task, error) {
+	plan, err := vapp.PlanMoveToVdc(targetVdc, params)
+	if err != nil {
+		return Task{}, err
+	}
+	if len(plan.Issues) > 0 {
+		var issues []string
+		for _, issue := range plan.Issues {
+			issues = append(issues, fmt.Sprintf("%s: %s", issue.Subject, issue.Message))
+		}
+		return Task{}, fmt.Errorf("vApp '%s' cannot be moved to VDC '%s': %s", vapp.VApp.Name, targetVdc.Vdc.Name, strings.Join(issues, "; "))
+	}
+
+	moveParams := &types.MoveVAppParams{
+		Xmlns:       types.XMLNamespaceVCloud,
+		Ovf:         types.XMLNamespaceOVF,
+		Source:      &types.Reference{HREF: vapp.VApp.HREF},
+		Sections:    &types.MoveVAppSections{NetworkConfigSection: plan.NetworkConfigSection},
+		SourcedItem: plan.Vms,
+	}
+
+	return vapp.client.ExecuteTaskRequest(targetVdc.Vdc.HREF+"/action/moveVApp", http.MethodPost,
+		types.MimeMoveVapp, "error moving vApp: %s", moveParams)
+}
+
+// buildVAppMovePlan maps vApp networks and VM settings to the resources of the target VDC
+func buildVAppMovePlan(vapp *types.VApp, networkConfig *types.NetworkConfigSection, target vAppMoveTarget, params VAppMoveParams) *VAppMovePlan {
+	plan := &VAppMovePlan{
+		NetworkConfigSection: &types.NetworkConfigSection{Info: "Configuration parameters for logical networks"},
+	}
+	addIssue := func(subject, format string, args ...interface{}) {
+		plan.Issues = append(plan.Issues, VAppMoveIssue{Subject: subject, Message: fmt.Sprintf(format, args...)})
+	}
+
+	for _, link := range vapp.Link {
+		if link.Rel == "up" && (link.Type == types.MimeVDC || link.Type == types.MimeAdminVDC) && link.HREF == target.href {
+			addIssue(vapp.Name, "vApp is already in the target VDC")
+		}
+	}
+
+	// Direct vApp networks are named after their parent network and are renamed along with it
+	renamedNetworks := make(map[string]string)
+	vAppNetworks := []string{types.NoneNetwork}
+	if networkConfig != nil {
+		for _, sourceNetwork := range networkConfig.NetworkConfig {
+			vAppNetwork := types.VAppNetworkConfiguration{
+				NetworkName: sourceNetwork.NetworkName,
+				Description: sourceNetwork.Description,
+			}
+			if sourceNetwork.Configuration != nil {
+				configuration := *sourceNetwork.Configuration
+				vAppNetwork.Configuration = &configuration
+			}
+			if vAppNetwork.Configuration != nil && vAppNetwork.Configuration.ParentNetwork != nil {
+				sourceParent := vAppNetwork.Configuration.ParentNetwork.Name
+				targetParent := findReferenceByName(target.networks, mappedName(params.NetworkMap, sourceParent))
+				if targetParent == nil {
+					addIssue(sourceNetwork.NetworkName, "network '%s' is not available in the target VDC", mappedName(params.NetworkMap, sourceParent))
+				} else {
+					vAppNetwork.Configuration.ParentNetwork = &types.Reference{HREF: targetParent.HREF, Name: targetParent.Name}
+					if vAppNetwork.Configuration.FenceMode == types.FenceModeBridged && vAppNetwork.NetworkName == sourceParent {
+						vAppNetwork.NetworkName = targetParent.Name
+						renamedNetworks[sourceNetwork.NetworkName] = targetParent.Name
+					}
+				}
+			}
+			vAppNetworks = append(vAppNetworks, vAppNetwork.NetworkName)
+			plan.NetworkConfigSection.NetworkConfig = append(plan.NetworkConfigSection.NetworkConfig, vAppNetwork)
+		}
+	}
+
+	if vapp.Children == nil {
+		return plan
+	}
+	for _, vm := range vapp.Children.VM {
+		item := &types.SourcedCompositionItemParam{
+			Source: &types.Reference{HREF: vm.HREF, Name: vm.Name},
+		}
+
+		if vm.NetworkConnectionSection != nil {
+			connections := &types.NetworkConnectionSection{
+				Info:                          "Network connection section",
+				PrimaryNetworkConnectionIndex: vm.NetworkConnectionSection.PrimaryNetworkConnectionIndex,
+			}
+			for _, sourceConnection := range vm.NetworkConnectionSection.NetworkConnection {
+				connection := *sourceConnection
+				if renamed, found := renamedNetworks[connection.Network]; found {
+					connection.Network = renamed
+				}
+				if !contains(connection.Network, vAppNetworks) {
+					addIssue(vm.Name, "NIC %d is connected to network '%s' which is not a vApp network", connection.NetworkConnectionIndex, connection.Network)
+				}
+				connections.NetworkConnection = append(connections.NetworkConnection, &connection)
+			}
+			item.InstantiationParams = &types.InstantiationParams{NetworkConnectionSection: connections}
+		}
+
+		// VMs without a storage profile use the default storage profile of the target VDC
+		if vm.StorageProfile != nil && vm.StorageProfile.Name != "" {
+			targetName := mappedName(params.StorageProfileMap, vm.StorageProfile.Name)
+			storageProfile := findReferenceByName(target.storageProfiles, targetName)
+			if storageProfile == nil {
+				addIssue(vm.Name, "storage profile '%s' is not available in the target VDC", targetName)
+			} else {
+				item.StorageProfile = &types.Reference{HREF: storageProfile.HREF, Name: storageProfile.Name}
+			}
+		}
+
+		if vm.ComputePolicy != nil {
+			computePolicy := &types.ComputePolicy{}
+			for _, policy := range []struct {
+				kind   string
+				source *types.Reference
+				target **types.Reference
+			}{
+				{"sizing", vm.ComputePolicy.VmSizingPolicy, &computePolicy.VmSizingPolicy},
+				{"placement", vm.ComputePolicy.VmPlacementPolicy, &computePolicy.VmPlacementPolicy},
+			} {
+				if policy.source == nil || (policy.source.ID == "" && policy.source.Name == "") {
+					continue
+				}
+				targetPolicy := findComputePolicyReference(target.computePolicies, policy.source, params.ComputePolicyMap)
+				if targetPolicy == nil {
+					addIssue(vm.Name, "%s policy '%s' is not assigned to the target VDC",
+						policy.kind, firstNonEmpty(mappedName(params.ComputePolicyMap, policy.source.Name), policy.source.ID))
+					continue
+				}
+				*policy.target = &types.Reference{HREF: targetPolicy.HREF, ID: targetPolicy.ID, Name: targetPolicy.Name}
+			}
+			if computePolicy.VmSizingPolicy != nil || computePolicy.VmPlacementPolicy != nil {
+				item.ComputePolicy = computePolicy
+			}
+		}
+
+		plan.Vms = append(plan.Vms, item)
+	}
+	return plan
+}
+
+// vAppUsesComputePolicies returns true if any VM of the vApp has a sizing or placement policy
+func vAppUsesComputePolicies(vapp *types.VApp) bool {
+	if vapp.Children == nil {
+		return false
+	}
+	for _, vm := range vapp.Children.VM {
+		if vm.ComputePolicy != nil && (vm.ComputePolicy.VmSizingPolicy != nil || vm.ComputePolicy.VmPlacementPolicy != nil) {
+			return true
+		}
+	}
+	return false
+}
+
+// findComputePolicyReference looks up a compute policy by ID, as the same policy can be assigned to several VDCs, and
+// then by (mapped) name
+func findComputePolicyReference(policies []*types.Reference, source *types.Reference, nameMap map[string]string) *types.Reference {
+	if source.ID != "" {
+		for _, policy := range policies {
+			if policy.ID == source.ID {
+				return policy
+			}
+		}
+	}
+	if source.Name == "" {
+		return nil
+	}
+	return findReferenceByName(policies, mappedName(nameMap, source.Name))
+}
+
+func findReferenceByName(references []*types.Reference, name string) *types.Reference {
+	for _, reference := range references {
+		if reference.Name == name {
+			return reference
+		}
+	}
+	return nil
+}
+
+// mappedName returns the value of name in nameMap, or name itself when it is not mapped
+func mappedName(nameMap map[string]string, name string) string {
+	if mapped, found := nameMap[name]; found {
+		return mapped
+	}
+	return name
+}
@@ -0,0 +1,165 @@
+//go:build unit || ALL
+
+/*
+ * Copyright 2025 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+package govcd
+
+import (
+	"encoding/xml"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// makeMoveSourceVApp returns a vApp with a bridged, a NAT routed and an isolated network, and VMs using them
+func makeMoveSourceVApp() (*types.VApp, *types.NetworkConfigSection) {
+	vapp := &types.VApp{
+		Name: "app",
+		HREF: "https://vcd.example.com/api/vApp/vapp-1",
+		Link: types.LinkList{{Rel: "up", Type: types.MimeVDC, HREF: "https://vcd.example.com/api/vdc/source"}},
+		Children: &types.VAppChildren{VM: []*types.Vm{
+			{
+				Name:           "db",
+				HREF:           "https://vcd.example.com/api/vApp/vm-1",
+				StorageProfile: &types.Reference{Name: "gold"},
+				ComputePolicy: &types.ComputePolicy{
+					VmSizingPolicy:    &types.Reference{ID: "urn:vcloud:vdcComputePolicy:large", Name: "large"},
+					VmPlacementPolicy: &types.Reference{ID: "urn:vcloud:vdcComputePolicy:rack-a", Name: "rack-a"},
+				},
+				NetworkConnectionSection: &types.NetworkConnectionSection{NetworkConnection: []*types.NetworkConnection{
+					{Network: "backend", NetworkConnectionIndex: 0, IPAddressAllocationMode: "POOL"},
+					{Network: "isolated", NetworkConnectionIndex: 1},
+				}},
+			},
+			{
+				Name: "web",
+				HREF: "https://vcd.example.com/api/vApp/vm-2",
+				NetworkConnectionSection: &types.NetworkConnectionSection{NetworkConnection: []*types.NetworkConnection{
+					{Network: "frontend", NetworkConnectionIndex: 0},
+					{Network: types.NoneNetwork, NetworkConnectionIndex: 1},
+				}},
+			},
+		}},
+	}
+	networkConfig := &types.NetworkConfigSection{NetworkConfig: []types.VAppNetworkConfiguration{
+		{NetworkName: "backend", HREF: "https://vcd.example.com/api/network/backend", Configuration: &types.NetworkConfiguration{
+			FenceMode: types.FenceModeBridged, ParentNetwork: &types.Reference{Name: "backend", HREF: "https://vcd.example.com/api/admin/network/1"}}},
+		{NetworkName: "frontend", Configuration: &types.NetworkConfiguration{
+			FenceMode: types.FenceModeNAT, ParentNetwork: &types.Reference{Name: "dmz", HREF: "https://vcd.example.com/api/admin/network/2"}}},
+		{NetworkName: "isolated", Configuration: &types.NetworkConfiguration{FenceMode: types.FenceModeIsolated}},
+	}}
+	return vapp, networkConfig
+}
+
+// makeMoveTargetVdc returns a target VDC that offers only some of the networks and compute policies of the vApp
+func makeMoveTargetVdc() vAppMoveTarget {
+	return vAppMoveTarget{
+		href: "https://vcd.example.com/api/vdc/target",
+		networks: []*types.Reference{
+			{Name: "backend-b", HREF: "https://vcd.example.com/api/admin/network/3"},
+			{Name: "dmz", HREF: "https://vcd.example.com/api/admin/network/4"},
+		},
+		storageProfiles: []*types.Reference{{Name: "gold", HREF: "https://vcd.example.com/api/vdcStorageProfile/gold-b"}},
+		computePolicies: []*types.Reference{
+			{ID: "urn:vcloud:vdcComputePolicy:large", Name: "large", HREF: "https://vcd.example.com/cloudapi/1.0.0/vdcComputePolicies/large"},
+			{ID: "urn:vcloud:vdcComputePolicy:rack-b", Name: "rack-b", HREF: "https://vcd.example.com/cloudapi/1.0.0/vdcComputePolicies/rack-b"},
+		},
+	}
+}
+
+// Test_buildVAppMovePlan checks mapping of networks, storage profiles and compute policies to the target VDC
+func Test_buildVAppMovePlan(t *testing.T) {
+	vapp, networkConfig := makeMoveSourceVApp()
+	params := VAppMoveParams{
+		NetworkMap:       map[string]string{"backend": "backend-b"},
+		ComputePolicyMap: map[string]string{"rack-a": "rack-b"},
+	}
+
+	plan := buildVAppMovePlan(vapp, networkConfig, makeMoveTargetVdc(), params)
+	if len(plan.Issues) > 0 {
+		t.Fatalf("unexpected issues: %+v", plan.Issues)
+	}
+
+	var networkNames []string
+	for _, network := range plan.NetworkConfigSection.NetworkConfig {
+		networkNames = append(networkNames, network.NetworkName)
+		if network.HREF != "" {
+			t.Fatalf("network HREF of source vApp should not be sent: %+v", network)
+		}
+	}
+	if !reflect.DeepEqual(networkNames, []string{"backend-b", "frontend", "isolated"}) {
+		t.Fatalf("unexpected vApp networks %v", networkNames)
+	}
+	if parent := plan.NetworkConfigSection.NetworkConfig[1].Configuration.ParentNetwork; parent.HREF != "https://vcd.example.com/api/admin/network/4" {
+		t.Fatalf("unexpected parent network %+v", parent)
+	}
+	if networkConfig.NetworkConfig[0].Configuration.ParentNetwork.Name != "backend" {
+		t.Fatalf("source network configuration was modified")
+	}
+
+	db := plan.Vms[0]
+	if db.Source.HREF != "https://vcd.example.com/api/vApp/vm-1" || db.StorageProfile.HREF != "https://vcd.example.com/api/vdcStorageProfile/gold-b" ||
+		db.ComputePolicy.VmSizingPolicy.ID != "urn:vcloud:vdcComputePolicy:large" || db.ComputePolicy.VmPlacementPolicy.Name != "rack-b" {
+		t.Fatalf("unexpected VM settings %+v", db)
+	}
+	if network := db.InstantiationParams.NetworkConnectionSection.NetworkConnection[0]; network.Network != "backend-b" || network.IPAddressAllocationMode != "POOL" {
+		t.Fatalf("unexpected network connection %+v", network)
+	}
+	if vapp.Children.VM[0].NetworkConnectionSection.NetworkConnection[0].Network != "backend" {
+		t.Fatalf("source VM network connection was modified")
+	}
+	if web := plan.Vms[1]; web.StorageProfile != nil || web.ComputePolicy != nil {
+		t.Fatalf("unexpected settings for VM without storage profile and policies: %+v", web)
+	}
+
+	moveParams := &types.MoveVAppParams{
+		Xmlns:       types.XMLNamespaceVCloud,
+		Ovf:         types.XMLNamespaceOVF,
+		Source:      &types.Reference{HREF: vapp.HREF},
+		Sections:    &types.MoveVAppSections{NetworkConfigSection: plan.NetworkConfigSection},
+		SourcedItem: plan.Vms,
+	}
+	output, err := xml.Marshal(moveParams)
+	if err != nil {
+		t.Fatalf("error marshalling move parameters: %s", err)
+	}
+	for _, expected := range []string{`<MoveVAppParams xmlns:ovf=`, `<Sections><NetworkConfigSection>`, `<SourcedItem><Source href="https://vcd.example.com/api/vApp/vm-1"`} {
+		if !strings.Contains(string(output), expected) {
+			t.Fatalf("expected '%s' in:\n%s", expected, output)
+		}
+	}
+}
+
+// Test_buildVAppMovePlanIssues checks that incompatible resources are reported
+func Test_buildVAppMovePlanIssues(t *testing.T) {
+	vapp, networkConfig := makeMoveSourceVApp()
+	vapp.Children.VM[1].NetworkConnectionSection.NetworkConnection[0].Network = "unknown"
+	target := makeMoveTargetVdc()
+	target.storageProfiles = nil
+
+	plan := buildVAppMovePlan(vapp, networkConfig, target, VAppMoveParams{})
+	var issues []string
+	for _, issue := range plan.Issues {
+		issues = append(issues, issue.Subject+": "+issue.Message)
+	}
+	expected := []string{
+		"backend: network 'backend' is not available in the target VDC",
+		"db: storage profile 'gold' is not available in the target VDC",
+		"db: placement policy 'rack-a' is not assigned to the target VDC",
+		"web: NIC 0 is connected to network 'unknown' which is not a vApp network",
+	}
+	if !reflect.DeepEqual(issues, expected) {
+		t.Fatalf("unexpected issues:\n%s", strings.Join(issues, "\n"))
+	}
+
+	target = makeMoveTargetVdc()
+	target.href = "https://vcd.example.com/api/vdc/source"
+	plan = buildVAppMovePlan(vapp, networkConfig, target, VAppMoveParams{NetworkMap: map[string]string{"backend": "backend-b"}})
+	if len(plan.Issues) == 0 || plan.Issues[0].Message != "vApp is already in the target VDC" {
+		t.Fatalf("expected issue for same VDC, got %+v", plan.Issues)
+	}
+}
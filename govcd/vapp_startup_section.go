@@ -0,0 +1,170 @@
+/*
+ * Copyright 2025 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+package govcd
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// GetStartupSection retrieves the startup section of the vApp, which defines the order and delays used to start
+// and stop its VMs
+func (vapp *VApp) GetStartupSection() (*types.StartupSection, error) {
+	if vapp.VApp.HREF == "" {
+		return nil, fmt.Errorf("cannot retrieve startup section: vApp HREF is empty")
+	}
+
+	startupSection := &types.StartupSection{}
+	_, err := vapp.client.ExecuteRequest(vapp.VApp.HREF+"/startupSection/", http.MethodGet,
+		types.MimeStartupSection, "error retrieving vApp startup section: %s", nil, startupSection)
+	if err != nil {
+		return nil, err
+	}
+
+	return startupSection, nil
+}
+
+// UpdateStartupSection replaces the startup section of the vApp and returns the updated one
+func (vapp *VApp) UpdateStartupSection(startupSection *types.StartupSection) (*types.StartupSection, error) {
+	if vapp.VApp.HREF == "" {
+		return nil, fmt.Errorf("cannot update startup section: vApp HREF is empty")
+	}
+	if startupSection == nil {
+		return nil, fmt.Errorf("startup section cannot be nil")
+	}
+
+	payload := *startupSection
+	payload.Link = nil
+	payload.Type = types.MimeStartupSection
+	if payload.Info == "" {
+		payload.Info = "VApp startup section"
+	}
+
+	task, err := vapp.client.ExecuteTaskRequest(vapp.VApp.HREF+"/startupSection/", http.MethodPut,
+		types.MimeStartupSection, "error updating vApp startup section: %s", &payload)
+	if err != nil {
+		return nil, err
+	}
+	err = task.WaitTaskCompletion()
+	if err != nil {
+		return nil, fmt.Errorf("task for updating vApp startup section failed: %s", err)
+	}
+
+	return vapp.GetStartupSection()
+}
+
+// SetStartupOrder sets the startup order of the vApp VMs by name. Each element of tiers is a group of VM names that
+// start together, and tiers are started one after the other. For example, SetStartupOrder([]string{"db"},
+// []string{"app1", "app2"}) starts "db" first, then "app1" and "app2" together. VMs that are not listed keep their
+// relative order and are started after the last tier. Delays and actions of existing entries are preserved.
+func (vapp *VApp) SetStartupOrder(tiers ...[]string) (*types.StartupSection, error) {
+	startupSection, err := vapp.GetStartupSection()
+	if err != nil {
+		return nil, err
+	}
+
+	err = applyStartupOrder(startupSection, vapp.vmNames(), tiers)
+	if err != nil {
+		return nil, fmt.Errorf("error setting startup order of vApp '%s': %s", vapp.VApp.Name, err)
+	}
+
+	return vapp.UpdateStartupSection(startupSection)
+}
+
+// SetVmStartupSettings sets the startup settings of a single VM of the vApp by name. The ID of the given item is
+// ignored and replaced by vmName.
+func (vapp *VApp) SetVmStartupSettings(vmName string, settings types.StartupSectionItem) (*types.StartupSection, error) {
+	if !contains(vmName, vapp.vmNames()) {
+		return nil, fmt.Errorf("VM '%s' not found in vApp '%s': %s", vmName, vapp.VApp.Name, ErrorEntityNotFound)
+	}
+	startupSection, err := vapp.GetStartupSection()
+	if err != nil {
+		return nil, err
+	}
+
+	settings.ID = vmName
+	item := startupSection.GetItemByVmName(vmName)
+	if item == nil {
+		startupSection.Item = append(startupSection.Item, &settings)
+	} else {
+		*item = settings
+	}
+
+	return vapp.UpdateStartupSection(startupSection)
+}
+
+// vmNames returns the names of the VMs in the vApp, as known by the last refresh
+func (vapp *VApp) vmNames() []string {
+	var names []string
+	if vapp.VApp.Children == nil {
+		return names
+	}
+	for _, vm := range vapp.VApp.Children.VM {
+		names = append(names, vm.Name)
+	}
+	return names
+}
+
+// applyStartupOrder changes the order of startup section items following the given tiers. Items are added with default
+// settings for listed VMs that have no entry in the section.
+func applyStartupOrder(startupSection *types.StartupSection, vmNames []string, tiers [][]string) error {
+	tierByVm := make(map[string]int)
+	for tier, names := range tiers {
+		if len(names) == 0 {
+			return fmt.Errorf("startup tier %d is empty", tier)
+		}
+		for _, name := range names {
+			if _, found := tierByVm[name]; found {
+				return fmt.Errorf("VM '%s' is listed more than once", name)
+			}
+			if !contains(name, vmNames) {
+				return fmt.Errorf("VM '%s' is not part of the vApp", name)
+			}
+			tierByVm[name] = tier
+		}
+	}
+
+	for _, names := range tiers {
+		for _, name := range names {
+			if startupSection.GetItemByVmName(name) == nil {
+				startupSection.Item = append(startupSection.Item, &types.StartupSectionItem{
+					ID:          name,
+					StartAction: types.StartupActionPowerOn,
+					StopAction:  types.StopActionPowerOff,
+				})
+			}
+		}
+	}
+
+	// VMs that are not listed are placed after the last tier, keeping their relative order
+	var unlisted []*types.StartupSectionItem
+	for _, item := range startupSection.Item {
+		if tier, found := tierByVm[item.ID]; found {
+			item.Order = tier
+		} else {
+			unlisted = append(unlisted, item)
+		}
+	}
+	sort.SliceStable(unlisted, func(i, j int) bool {
+		return unlisted[i].Order < unlisted[j].Order
+	})
+	nextOrder, lastOrder := len(tiers), 0
+	for index, item := range unlisted {
+		originalOrder := item.Order
+		if index > 0 && item.Order != lastOrder {
+			nextOrder++
+		}
+		lastOrder = originalOrder
+		item.Order = nextOrder
+	}
+
+	sort.SliceStable(startupSection.Item, func(i, j int) bool {
+		return startupSection.Item[i].Order < startupSection.Item[j].Order
+	})
+	return nil
+}
@@ -0,0 +1,93 @@
+//go:build unit || ALL
+
+/*
+ * Copyright 2025 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+package govcd
+
+import (
+	"encoding/xml"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+const testStartupSectionXml = `<ovf:StartupSection xmlns:ovf="http://schemas.dmtf.org/ovf/envelope/1" xmlns:vcloud="http://www.vmware.com/vcloud/v1.5"
+    vcloud:type="application/vnd.vmware.vcloud.startupSection+xml" vcloud:href="https://vcd.example.com/api/vApp/vapp-1/startupSection/">
+  <ovf:Info>VApp startup section</ovf:Info>
+  <ovf:Item ovf:id="app" ovf:order="0" ovf:startAction="powerOn" ovf:startDelay="0" ovf:waitingForGuest="false" ovf:stopAction="powerOff" ovf:stopDelay="0"/>
+  <ovf:Item ovf:id="db" ovf:order="0" ovf:startAction="powerOn" ovf:startDelay="120" ovf:waitingForGuest="true" ovf:stopAction="guestShutdown" ovf:stopDelay="60"/>
+  <vcloud:Link rel="edit" href="https://vcd.example.com/api/vApp/vapp-1/startupSection/" type="application/vnd.vmware.vcloud.startupSection+xml"/>
+</ovf:StartupSection>`
+
+// Test_StartupSectionXml checks that the startup section is read and written with OVF attributes
+func Test_StartupSectionXml(t *testing.T) {
+	section := &types.StartupSection{}
+	err := xml.Unmarshal([]byte(testStartupSectionXml), section)
+	if err != nil {
+		t.Fatalf("error unmarshalling startup section: %s", err)
+	}
+	db := section.GetItemByVmName("db")
+	if section.HREF == "" || len(section.Item) != 2 || len(section.Link) != 1 || db == nil ||
+		!reflect.DeepEqual(*db, types.StartupSectionItem{ID: "db", StartAction: "powerOn", StartDelay: 120,
+			WaitingForGuest: true, StopAction: "guestShutdown", StopDelay: 60}) {
+		t.Fatalf("unexpected startup section: %+v", section)
+	}
+
+	output, err := xml.Marshal(section)
+	if err != nil {
+		t.Fatalf("error marshalling startup section: %s", err)
+	}
+	roundTrip := &types.StartupSection{}
+	err = xml.Unmarshal(output, roundTrip)
+	if err != nil || !reflect.DeepEqual(roundTrip.Item, section.Item) || !strings.Contains(string(output), `startDelay="120"`) {
+		t.Fatalf("unexpected marshalled startup section (error %v):\n%s", err, output)
+	}
+}
+
+// Test_applyStartupOrder checks VM ordering by tiers
+func Test_applyStartupOrder(t *testing.T) {
+	newSection := func() *types.StartupSection {
+		return &types.StartupSection{Item: []*types.StartupSectionItem{
+			{ID: "web", Order: 0, StartDelay: 10},
+			{ID: "app1", Order: 1},
+			{ID: "cache", Order: 5},
+			{ID: "batch", Order: 2},
+			{ID: "db", Order: 3, StartDelay: 120, WaitingForGuest: true},
+		}}
+	}
+	vmNames := []string{"web", "app1", "app2", "cache", "batch", "db"}
+
+	section := newSection()
+	err := applyStartupOrder(section, vmNames, [][]string{{"db"}, {"app1", "app2"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	orders := make(map[string]int)
+	var names []string
+	for _, item := range section.Item {
+		orders[item.ID] = item.Order
+		names = append(names, item.ID)
+	}
+	expected := map[string]int{"db": 0, "app1": 1, "app2": 1, "web": 2, "batch": 3, "cache": 4}
+	if !reflect.DeepEqual(orders, expected) || !reflect.DeepEqual(names, []string{"db", "app1", "app2", "web", "batch", "cache"}) {
+		t.Fatalf("unexpected orders %v (%v)", orders, names)
+	}
+	db, app2 := section.GetItemByVmName("db"), section.GetItemByVmName("app2")
+	if db.StartDelay != 120 || !db.WaitingForGuest || app2.StartAction != types.StartupActionPowerOn || app2.StopAction != types.StopActionPowerOff {
+		t.Fatalf("unexpected item settings: %+v %+v", db, app2)
+	}
+
+	for name, tiers := range map[string][][]string{
+		"unknown VM":   {{"db"}, {"missing"}},
+		"duplicate VM": {{"db"}, {"app1", "db"}},
+		"empty tier":   {{"db"}, {}},
+	} {
+		if applyStartupOrder(newSection(), vmNames, tiers) == nil {
+			t.Fatalf("expected error for %s", name)
+		}
+	}
+}
@@ -1921,6 +1921,22 @@ func (client *Client) GetVMByHref(vmHref string) (*VM, error) {
 	return vmFunctions.GetVMByHref(client, vmHref)
 }
 
+// GetVMById returns a VM by its ID (URN), such as the ones used in OpenAPI references. The VM can be in any vApp
+// or VDC visible to the user. It returns ErrorEntityNotFound when no VM has the given ID
+func (client *Client) GetVMById(id string) (*VM, error) {
+	vmRecords, err := QueryVmList(types.VmQueryFilterOnlyDeployed, client, map[string]string{"id": extractUuid(id)})
+	if err != nil {
+		return nil, err
+	}
+	if len(vmRecords) == 0 {
+		return nil, ErrorEntityNotFound
+	}
+	if len(vmRecords) > 1 {
+		return nil, fmt.Errorf("more than one VM found with ID %s", id)
+	}
+	return client.GetVMByHref(vmRecords[0].HREF)
+}
+
 // UpdateStorageProfile updates VM storage profile and returns refreshed VM or error.
 func (vm *VM) UpdateStorageProfile(storageProfileHref string) (*VM, error) {
 	task, err := vm.UpdateStorageProfileAsync(storageProfileHref)
@@ -1966,6 +1982,39 @@ func (vm *VM) UpdateStorageProfileAsync(storageProfileHref string) (Task, error)
 		})
 }
 
+// VmRelocateParams defines where VM.Relocate moves the VM files. Exactly one of the fields must be set.
+type VmRelocateParams struct {
+	// Datastore is a reference to the target datastore. Relocating to a datastore requires System administrator rights
+	Datastore *types.Reference
+	// StorageProfile is a reference to a storage profile of the VDC containing the VM
+	StorageProfile *types.Reference
+}
+
+// Relocate moves the VM files to another datastore or storage profile and returns the Task performing the move.
+// Storage profile relocation is the same as UpdateStorageProfileAsync.
+func (vm *VM) Relocate(params VmRelocateParams) (Task, error) {
+	if vm.VM.HREF == "" {
+		return Task{}, fmt.Errorf("cannot relocate VM, VM HREF is unset")
+	}
+
+	switch {
+	case params.Datastore != nil && params.StorageProfile != nil:
+		return Task{}, fmt.Errorf("cannot relocate VM '%s' to both a datastore and a storage profile", vm.VM.Name)
+	case params.Datastore != nil:
+		if params.Datastore.HREF == "" {
+			return Task{}, fmt.Errorf("cannot relocate VM, datastore HREF is unset")
+		}
+		return vm.client.ExecuteTaskRequest(vm.VM.HREF+"/action/relocate", http.MethodPost,
+			types.MimeRelocateVm, "error relocating VM: %s", &types.RelocateParams{
+				Xmlns:     types.XMLNamespaceVCloud,
+				Datastore: &types.Reference{HREF: params.Datastore.HREF},
+			})
+	case params.StorageProfile != nil:
+		return vm.UpdateStorageProfileAsync(params.StorageProfile.HREF)
+	}
+	return Task{}, fmt.Errorf("cannot relocate VM '%s': neither datastore nor storage profile was given", vm.VM.Name)
+}
+
 // UpdateBootOptions updates the Boot Options of a VM and returns the updated instance of the VM
 func (vm *VM) UpdateBootOptions(bootOptions *types.BootOptions) (*VM, error) {
 	task, err := vm.UpdateBootOptionsAsync(bootOptions)
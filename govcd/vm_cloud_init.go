@@ -0,0 +1,187 @@
+/*
+ * Copyright 2025 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+package govcd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/vmware/go-vcloud-director/v3/govcd/internal/iso9660"
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+	"github.com/vmware/go-vcloud-director/v3/util"
+)
+
+// cloudInitSeedVolumeLabel is the volume label that cloud-init looks for to find a NoCloud data source
+const cloudInitSeedVolumeLabel = "cidata"
+
+// cloudInitSeedUploadPieceSize is the piece size used when uploading seed images. Seeds are small, so a single
+// piece is usually enough
+const cloudInitSeedUploadPieceSize = 1024 * 1024
+
+// CloudInitSeed is a cloud-init NoCloud seed image uploaded to a catalog and inserted into a VM
+type CloudInitSeed struct {
+	Media *Media
+	vm    *VM
+}
+
+// BuildCloudInitSeedIso builds in memory an ISO image usable as cloud-init NoCloud data source. The image is labeled
+// "cidata" and contains the files "user-data", "meta-data" and, when networkConfig is not empty, "network-config".
+func BuildCloudInitSeedIso(userData, metaData, networkConfig string) ([]byte, error) {
+	files := []iso9660.File{
+		{Name: "user-data", Data: []byte(userData)},
+		{Name: "meta-data", Data: []byte(metaData)},
+	}
+	if networkConfig != "" {
+		files = append(files, iso9660.File{Name: "network-config", Data: []byte(networkConfig)})
+	}
+	return iso9660.Build(cloudInitSeedVolumeLabel, files, time.Now())
+}
+
+// AttachCloudInitSeed builds a cloud-init NoCloud seed image, uploads it as media to the given catalog and inserts it
+// into the VM. When metaData is empty, a minimal one is generated with the VM ID as instance ID and the VM name as
+// host name. The returned seed must be removed with CloudInitSeed.Cleanup or CloudInitSeed.CleanupAfterFirstBoot
+// once the VM has read it.
+func (vm *VM) AttachCloudInitSeed(catalog *Catalog, userData, metaData, networkConfig string) (*CloudInitSeed, error) {
+	if catalog == nil || catalog.Catalog == nil {
+		return nil, fmt.Errorf("a catalog is required to upload the cloud-init seed")
+	}
+	if vm.VM == nil || vm.VM.HREF == "" {
+		return nil, fmt.Errorf("cannot attach cloud-init seed: VM HREF is empty")
+	}
+	if metaData == "" {
+		metaData = fmt.Sprintf("instance-id: %s\nlocal-hostname: %s\n", extractUuid(vm.VM.ID), vm.VM.Name)
+	}
+
+	image, err := BuildCloudInitSeedIso(userData, metaData, networkConfig)
+	if err != nil {
+		return nil, fmt.Errorf("error building cloud-init seed image: %s", err)
+	}
+
+	file, err := os.CreateTemp("", "cloud-init-seed-*.iso")
+	if err != nil {
+		return nil, fmt.Errorf("error creating temporary file for cloud-init seed image: %s", err)
+	}
+	defer func() {
+		err := os.Remove(file.Name())
+		if err != nil {
+			util.Logger.Printf("[WARN] error removing temporary file %s: %s", file.Name(), err)
+		}
+	}()
+	_, err = file.Write(image)
+	if err != nil {
+		safeClose(file)
+		return nil, fmt.Errorf("error writing cloud-init seed image: %s", err)
+	}
+	safeClose(file)
+
+	mediaName := fmt.Sprintf("cloud-init-seed-%s-%d.iso", extractUuid(vm.VM.ID), time.Now().Unix())
+	uploadTask, err := catalog.UploadMediaImage(mediaName, fmt.Sprintf("cloud-init seed for VM %s", vm.VM.Name),
+		file.Name(), cloudInitSeedUploadPieceSize)
+	if err != nil {
+		return nil, fmt.Errorf("error uploading cloud-init seed image: %s", err)
+	}
+	err = uploadTask.WaitTaskCompletion()
+	if err != nil {
+		return nil, fmt.Errorf("error uploading cloud-init seed image: %s", err)
+	}
+
+	media, err := catalog.GetMediaByName(mediaName, true)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving uploaded cloud-init seed image: %s", err)
+	}
+	seed := &CloudInitSeed{Media: media, vm: vm}
+
+	task, err := vm.InsertMedia(&types.MediaInsertOrEjectParams{
+		Media: &types.Reference{
+			HREF: media.Media.HREF,
+			Name: media.Media.Name,
+			ID:   media.Media.ID,
+			Type: media.Media.Type,
+		},
+	})
+	if err == nil {
+		err = task.WaitTaskCompletion()
+	}
+	if err != nil {
+		deleteErr := seed.deleteMedia()
+		if deleteErr != nil {
+			util.Logger.Printf("[WARN] error removing cloud-init seed image '%s': %s", mediaName, deleteErr)
+		}
+		return nil, fmt.Errorf("error inserting cloud-init seed image into VM '%s': %s", vm.VM.Name, err)
+	}
+
+	return seed, nil
+}
+
+// Cleanup ejects the seed image from the VM and deletes it from the catalog. A pending question about ejecting
+// the media of a running VM is answered with "yes".
+func (seed *CloudInitSeed) Cleanup() error {
+	ejectTask, err := seed.vm.EjectMedia(&types.MediaInsertOrEjectParams{
+		Media: &types.Reference{HREF: seed.Media.Media.HREF},
+	})
+	if err != nil {
+		return fmt.Errorf("error ejecting cloud-init seed image: %s", err)
+	}
+	err = ejectTask.WaitTaskCompletion(true)
+	if err != nil {
+		return fmt.Errorf("error ejecting cloud-init seed image: %s", err)
+	}
+
+	return seed.deleteMedia()
+}
+
+// CleanupAfterFirstBoot waits until the VM is powered on and VMware Tools are running in the guest, which happens
+// after cloud-init has read its data source, and then calls Cleanup. Guests without VMware Tools never report
+// running tools, and the wait ends with an error after timeout.
+func (seed *CloudInitSeed) CleanupAfterFirstBoot(timeout time.Duration) error {
+	timeoutAfter := time.After(timeout)
+	tick := time.NewTicker(5 * time.Second)
+	defer tick.Stop()
+
+	for {
+		select {
+		case <-timeoutAfter:
+			return fmt.Errorf("timed out waiting for first boot of VM '%s' after %s", seed.vm.VM.Name, timeout)
+		case <-tick.C:
+			booted, err := seed.vm.isGuestBooted()
+			if err != nil {
+				return err
+			}
+			if booted {
+				return seed.Cleanup()
+			}
+		}
+	}
+}
+
+// isGuestBooted returns true when the VM is powered on and its VMware Tools are running
+func (vm *VM) isGuestBooted() (bool, error) {
+	status, err := vm.GetStatus()
+	if err != nil {
+		return false, fmt.Errorf("error retrieving status of VM '%s': %s", vm.VM.Name, err)
+	}
+	if status != "POWERED_ON" {
+		return false, nil
+	}
+
+	toolsStatus, err := vm.getVmToolsStatus()
+	if err != nil {
+		return false, err
+	}
+	return toolsStatus == "toolsOk" || toolsStatus == "toolsOld", nil
+}
+
+func (seed *CloudInitSeed) deleteMedia() error {
+	task, err := seed.Media.Delete()
+	if err != nil {
+		return fmt.Errorf("error deleting cloud-init seed image: %s", err)
+	}
+	err = task.WaitTaskCompletion()
+	if err != nil {
+		return fmt.Errorf("error deleting cloud-init seed image: %s", err)
+	}
+	return nil
+}
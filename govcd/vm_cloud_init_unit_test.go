@@ -0,0 +1,83 @@
+//go:build unit || ALL
+
+/*
+ * Copyright 2025 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+package govcd
+
+import (
+	"encoding/binary"
+	"reflect"
+	"testing"
+	"unicode/utf16"
+)
+
+// readJolietRoot returns the files of the root directory of a Joliet image, by name
+func readJolietRoot(t *testing.T, image []byte) map[string]string {
+	const sectorSize = 2048
+	supplementary := image[17*sectorSize : 18*sectorSize]
+	if supplementary[0] != 2 || string(supplementary[1:6]) != "CD001" || string(supplementary[88:91]) != "%/E" {
+		t.Fatalf("Joliet supplementary volume descriptor not found")
+	}
+
+	rootRecord := supplementary[156:]
+	extent := binary.LittleEndian.Uint32(rootRecord[2:])
+	length := binary.LittleEndian.Uint32(rootRecord[10:])
+	directory := image[int(extent)*sectorSize : int(extent)*sectorSize+int(length)]
+
+	files := make(map[string]string)
+	for position := 0; position < len(directory); {
+		recordLength := int(directory[position])
+		if recordLength == 0 {
+			position += sectorSize - position%sectorSize
+			continue
+		}
+		record := directory[position : position+recordLength]
+		position += recordLength
+
+		identifier := record[33 : 33+int(record[32])]
+		if len(identifier) == 1 {
+			continue
+		}
+		var units []uint16
+		for index := 0; index < len(identifier); index += 2 {
+			units = append(units, binary.BigEndian.Uint16(identifier[index:]))
+		}
+		fileExtent := binary.LittleEndian.Uint32(record[2:])
+		fileLength := binary.LittleEndian.Uint32(record[10:])
+		files[string(utf16.Decode(units))] = string(image[int(fileExtent)*sectorSize : int(fileExtent)*sectorSize+int(fileLength)])
+	}
+	return files
+}
+
+// Test_BuildCloudInitSeedIso checks the content of cloud-init NoCloud seed images
+func Test_BuildCloudInitSeedIso(t *testing.T) {
+	image, err := BuildCloudInitSeedIso("#cloud-config\nhostname: web\n", "instance-id: web-1\n", "version: 2\n")
+	if err != nil {
+		t.Fatalf("error building seed image: %s", err)
+	}
+	if !verifyHeader(image) {
+		t.Fatalf("seed image is not recognized as ISO")
+	}
+	if label := string(image[16*2048+40 : 16*2048+46]); label != "CIDATA" {
+		t.Fatalf("unexpected primary volume label '%s'", label)
+	}
+
+	expected := map[string]string{
+		"user-data;1":      "#cloud-config\nhostname: web\n",
+		"meta-data;1":      "instance-id: web-1\n",
+		"network-config;1": "version: 2\n",
+	}
+	if files := readJolietRoot(t, image); !reflect.DeepEqual(files, expected) {
+		t.Fatalf("unexpected files %v", files)
+	}
+
+	image, err = BuildCloudInitSeedIso("", "instance-id: web-1\n", "")
+	if err != nil {
+		t.Fatalf("error building seed image: %s", err)
+	}
+	if files := readJolietRoot(t, image); len(files) != 2 || files["user-data;1"] != "" {
+		t.Fatalf("unexpected files %v", files)
+	}
+}
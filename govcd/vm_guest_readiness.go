@@ -0,0 +1,324 @@
+/*
+ * Copyright 2025 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+package govcd
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+	"github.com/vmware/go-vcloud-director/v3/util"
+)
+
+// GuestReadinessOptions defines what WaitForGuestReadiness waits for. The zero value waits for guest customization,
+// VMware Tools and the IP addresses of DHCP NICs, polling every 5 seconds for up to 10 minutes.
+type GuestReadinessOptions struct {
+	// SkipGuestCustomization does not wait for guest customization to complete. Customization is never waited for
+	// when it is disabled in the VM
+	SkipGuestCustomization bool
+	// SkipVmTools does not wait for VMware Tools to run in the guest
+	SkipVmTools bool
+	// SkipDhcpIps does not wait for connected DHCP NICs to report an IP address. The addresses of POOL and MANUAL
+	// NICs are allocated by VCD before the VM is powered on, so they are never waited for: use Ports to check that
+	// the guest network is up
+	SkipDhcpIps bool
+	// Ports are TCP ports that must accept connections on the IP address of the primary NIC
+	Ports []int
+	// PollInterval is the time between checks. Defaults to 5 seconds
+	PollInterval time.Duration
+	// PortProbeTimeout is the timeout of each TCP connection attempt. Defaults to 3 seconds
+	PortProbeTimeout time.Duration
+	// Timeout is the maximum wait when the context has no deadline. Defaults to 10 minutes
+	Timeout time.Duration
+}
+
+// GuestReadinessReport is the readiness state of a VM
+type GuestReadinessReport struct {
+	VmName                   string
+	VmHref                   string
+	Status                   string // Power status, such as "POWERED_ON"
+	GuestCustomizationStatus string // Empty when guest customization is disabled or not checked
+	VmToolsStatus            string // Such as "toolsOk". Empty when not checked
+	Nics                     []GuestNicReadiness
+	Ports                    []GuestPortReadiness
+	Ready                    bool
+	Failed                   bool     // True when the VM cannot become ready, such as when guest customization failed
+	Pending                  []string // Reasons why the VM is not ready
+	Error                    string   // Error of the last check, which is retried at the next poll. Empty when the check succeeded
+}
+
+// GuestNicReadiness is the IP address state of a connected NIC
+type GuestNicReadiness struct {
+	NetworkConnectionIndex int
+	Network                string
+	MacAddress             string
+	IpAllocationMode       string
+	IpAddress              string
+	Primary                bool
+}
+
+// GuestPortReadiness is the result of a TCP port probe
+type GuestPortReadiness struct {
+	Address string
+	Port    int
+	Open    bool
+}
+
+// WaitForGuestReadiness waits until the guest of the VM is ready, as defined by options, or until ctx is done. It
+// returns the last readiness report, with an error when the VM is not ready. Errors retrieving the state of the VM
+// are recorded in the report and the check is retried until ctx is done.
+//
+// IP addresses are the ones reported by VCD in the network connection section: addresses reported by VMware Tools
+// for DHCP NICs, and allocated addresses for POOL and MANUAL NICs. Only DHCP NICs are waited for, as VCD does not
+// report whether the guest has configured allocated addresses. Use VM.WaitForDhcpIpByNicIndexes to find DHCP
+// addresses through NSX-V edge gateway leases for guests without VMware Tools.
+func (vm *VM) WaitForGuestReadiness(ctx context.Context, options GuestReadinessOptions) (*GuestReadinessReport, error) {
+	reports, err := waitForGuestReadiness(ctx, []*VM{vm}, options)
+	if len(reports) == 0 {
+		return nil, err
+	}
+	return reports[0], err
+}
+
+// WaitForGuestReadiness waits until the guests of all VMs in the vApp are ready, as defined by options, or until
+// ctx is done. It returns the last readiness report of each VM, with an error when some VM is not ready.
+// See VM.WaitForGuestReadiness for details.
+func (vapp *VApp) WaitForGuestReadiness(ctx context.Context, options GuestReadinessOptions) ([]*GuestReadinessReport, error) {
+	err := vapp.Refresh()
+	if err != nil {
+		return nil, fmt.Errorf("error refreshing vApp: %s", err)
+	}
+	if vapp.VApp.Children == nil || len(vapp.VApp.Children.VM) == 0 {
+		return nil, fmt.Errorf("vApp '%s' has no VMs", vapp.VApp.Name)
+	}
+
+	var vms []*VM
+	for _, child := range vapp.VApp.Children.VM {
+		vm := NewVM(vapp.client)
+		vm.VM = child
+		vms = append(vms, vm)
+	}
+	return waitForGuestReadiness(ctx, vms, options)
+}
+
+// waitForGuestReadiness checks the VMs that are not settled at every poll interval, until all of them are ready or
+// failed, or ctx is done. A check that fails is recorded in the report of the VM and retried at the next poll
+func waitForGuestReadiness(ctx context.Context, vms []*VM, options GuestReadinessOptions) ([]*GuestReadinessReport, error) {
+	if options.PollInterval <= 0 {
+		options.PollInterval = 5 * time.Second
+	}
+	if options.PortProbeTimeout <= 0 {
+		options.PortProbeTimeout = 3 * time.Second
+	}
+	if _, hasDeadline := ctx.Deadline(); !hasDeadline {
+		if options.Timeout <= 0 {
+			options.Timeout = 10 * time.Minute
+		}
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, options.Timeout)
+		defer cancel()
+	}
+
+	reports := make([]*GuestReadinessReport, len(vms))
+	tick := time.NewTicker(options.PollInterval)
+	defer tick.Stop()
+
+	for {
+		settled := true
+		for index, vm := range vms {
+			if reports[index] != nil && (reports[index].Ready || reports[index].Failed) {
+				continue
+			}
+			report, err := vm.checkGuestReadiness(ctx, options)
+			if err != nil {
+				util.Logger.Printf("[DEBUG] [Guest readiness] %s, retrying\n", err)
+				// Keep the last known state of the VM
+				report = &GuestReadinessReport{VmName: vm.VM.Name, VmHref: vm.VM.HREF}
+				if reports[index] != nil {
+					previous := *reports[index]
+					report = &previous
+				}
+				report.Error = err.Error()
+			}
+			reports[index] = report
+			settled = settled && (report.Ready || report.Failed)
+		}
+		if settled {
+			return reports, guestReadinessError(reports, nil)
+		}
+
+		select {
+		case <-ctx.Done():
+			return reports, guestReadinessError(reports, ctx.Err())
+		case <-tick.C:
+		}
+	}
+}
+
+// guestReadinessError returns an error describing the VMs that are not ready, or nil when all VMs are ready
+func guestReadinessError(reports []*GuestReadinessReport, cause error) error {
+	var notReady []string
+	for _, report := range reports {
+		if !report.Ready {
+			reasons := report.Pending
+			if report.Error != "" {
+				reasons = append([]string{report.Error}, reasons...)
+			}
+			notReady = append(notReady, fmt.Sprintf("'%s' (%s)", report.VmName, strings.Join(reasons, ", ")))
+		}
+	}
+	if len(notReady) == 0 {
+		return nil
+	}
+	if cause != nil {
+		return fmt.Errorf("VMs not ready: %s: %s", strings.Join(notReady, "; "), cause)
+	}
+	return fmt.Errorf("VMs not ready: %s", strings.Join(notReady, "; "))
+}
+
+// checkGuestReadiness retrieves the current state of the VM and evaluates its readiness
+func (vm *VM) checkGuestReadiness(ctx context.Context, options GuestReadinessOptions) (*GuestReadinessReport, error) {
+	previous := vm.VM
+	err := vm.Refresh()
+	if err != nil {
+		// Refresh clears the VM, which is needed to retry
+		vm.VM = previous
+		return nil, fmt.Errorf("error refreshing VM '%s': %s", vm.VM.Name, err)
+	}
+
+	powerStatus := types.VAppStatuses[vm.VM.Status]
+	customizationStatus := ""
+	toolsStatus := ""
+	if powerStatus == "POWERED_ON" {
+		section := vm.VM.GuestCustomizationSection
+		if !options.SkipGuestCustomization && section != nil && section.Enabled != nil && *section.Enabled {
+			customizationStatus, err = vm.GetGuestCustomizationStatus()
+			if err != nil {
+				return nil, fmt.Errorf("error retrieving guest customization status of VM '%s': %s", vm.VM.Name, err)
+			}
+		}
+		if !options.SkipVmTools {
+			toolsStatus, err = vm.getVmToolsStatus()
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	report := evaluateGuestReadiness(vm.VM, customizationStatus, toolsStatus, options)
+	if len(options.Ports) > 0 && len(report.Pending) == 0 {
+		primaryAddress := ""
+		for _, nic := range report.Nics {
+			if nic.Primary {
+				primaryAddress = nic.IpAddress
+			}
+		}
+		if primaryAddress == "" {
+			report.Pending = append(report.Pending, "primary NIC has no IP address to probe ports")
+		} else {
+			report.Ports = probeGuestPorts(ctx, primaryAddress, options.Ports, options.PortProbeTimeout)
+			for _, port := range report.Ports {
+				if !port.Open {
+					report.Pending = append(report.Pending, fmt.Sprintf("port %d is not open on %s", port.Port, port.Address))
+				}
+			}
+		}
+	}
+	report.Ready = len(report.Pending) == 0
+	util.Logger.Printf("[TRACE] [Guest readiness] VM '%s' ready: %t, pending: %v\n", vm.VM.Name, report.Ready, report.Pending)
+	return report, nil
+}
+
+// evaluateGuestReadiness builds the readiness report of a VM from its current state, without port probes
+func evaluateGuestReadiness(vm *types.Vm, customizationStatus, toolsStatus string, options GuestReadinessOptions) *GuestReadinessReport {
+	report := &GuestReadinessReport{
+		VmName:                   vm.Name,
+		VmHref:                   vm.HREF,
+		Status:                   types.VAppStatuses[vm.Status],
+		GuestCustomizationStatus: customizationStatus,
+		VmToolsStatus:            toolsStatus,
+	}
+	if report.Status != "POWERED_ON" {
+		report.Pending = append(report.Pending, fmt.Sprintf("VM is %s", report.Status))
+	}
+
+	section := vm.GuestCustomizationSection
+	if !options.SkipGuestCustomization && section != nil && section.Enabled != nil && *section.Enabled {
+		switch customizationStatus {
+		case types.GuestCustStatusComplete:
+		case types.GuestCustStatusFailed:
+			report.Failed = true
+			report.Pending = append(report.Pending, "guest customization failed")
+		default:
+			if report.Status == "POWERED_ON" {
+				report.Pending = append(report.Pending, fmt.Sprintf("guest customization status is %s", customizationStatus))
+			}
+		}
+	}
+
+	if !options.SkipVmTools && report.Status == "POWERED_ON" && toolsStatus != "toolsOk" && toolsStatus != "toolsOld" {
+		report.Pending = append(report.Pending, fmt.Sprintf("VMware Tools status is '%s'", toolsStatus))
+	}
+
+	if vm.NetworkConnectionSection != nil {
+		for _, connection := range vm.NetworkConnectionSection.NetworkConnection {
+			if !connection.IsConnected || connection.IPAddressAllocationMode == types.IPAllocationModeNone {
+				continue
+			}
+			nic := GuestNicReadiness{
+				NetworkConnectionIndex: connection.NetworkConnectionIndex,
+				Network:                connection.Network,
+				MacAddress:             connection.MACAddress,
+				IpAllocationMode:       connection.IPAddressAllocationMode,
+				IpAddress:              connection.IPAddress,
+				Primary:                connection.NetworkConnectionIndex == vm.NetworkConnectionSection.PrimaryNetworkConnectionIndex,
+			}
+			if !options.SkipDhcpIps && nic.IpAllocationMode == types.IPAllocationModeDHCP && nic.IpAddress == "" {
+				report.Pending = append(report.Pending, fmt.Sprintf("NIC %d has no IP address", nic.NetworkConnectionIndex))
+			}
+			report.Nics = append(report.Nics, nic)
+		}
+	}
+
+	report.Ready = len(report.Pending) == 0
+	return report
+}
+
+// probeGuestPorts tries a TCP connection to each port of the given address
+func probeGuestPorts(ctx context.Context, address string, ports []int, timeout time.Duration) []GuestPortReadiness {
+	dialer := net.Dialer{Timeout: timeout}
+	var results []GuestPortReadiness
+	for _, port := range ports {
+		result := GuestPortReadiness{Address: address, Port: port}
+		connection, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(address, strconv.Itoa(port)))
+		if err == nil {
+			result.Open = true
+			err = connection.Close()
+			if err != nil {
+				util.Logger.Printf("[WARN] error closing probe connection to %s:%d: %s", address, port, err)
+			}
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// getVmToolsStatus returns the VMware Tools status of the VM, such as "toolsOk" or "toolsNotRunning"
+func (vm *VM) getVmToolsStatus() (string, error) {
+	vmRecords, err := QueryVmList(types.VmQueryFilterOnlyDeployed, vm.client, map[string]string{"name": vm.VM.Name})
+	if err != nil {
+		return "", fmt.Errorf("error retrieving VMware Tools status of VM '%s': %s", vm.VM.Name, err)
+	}
+	for _, vmRecord := range vmRecords {
+		if vmRecord.HREF == vm.VM.HREF {
+			return vmRecord.VmToolsStatus, nil
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,118 @@
+//go:build unit || ALL
+
+/*
+ * Copyright 2025 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+package govcd
+
+import (
+	"context"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// Test_evaluateGuestReadiness checks the pending conditions of a VM
+func Test_evaluateGuestReadiness(t *testing.T) {
+	vm := &types.Vm{
+		Name:                      "web01",
+		Status:                    4,
+		GuestCustomizationSection: &types.GuestCustomizationSection{Enabled: addrOf(true)},
+		NetworkConnectionSection: &types.NetworkConnectionSection{
+			PrimaryNetworkConnectionIndex: 1,
+			NetworkConnection: []*types.NetworkConnection{
+				{Network: "web", NetworkConnectionIndex: 0, IsConnected: true, IPAddressAllocationMode: types.IPAllocationModeDHCP},
+				{Network: "app", NetworkConnectionIndex: 1, IsConnected: true, IPAddressAllocationMode: types.IPAllocationModePool, IPAddress: "10.0.0.5"},
+				{Network: "none", NetworkConnectionIndex: 2, IPAddressAllocationMode: types.IPAllocationModeNone},
+				// Only DHCP NICs are waited for
+				{Network: "db", NetworkConnectionIndex: 3, IsConnected: true, IPAddressAllocationMode: types.IPAllocationModeManual},
+			},
+		},
+	}
+
+	report := evaluateGuestReadiness(vm, types.GuestCustStatusPostPending, "toolsNotRunning", GuestReadinessOptions{})
+	expected := []string{
+		"guest customization status is POST_GC_PENDING",
+		"VMware Tools status is 'toolsNotRunning'",
+		"NIC 0 has no IP address",
+	}
+	if report.Ready || !reflect.DeepEqual(report.Pending, expected) {
+		t.Fatalf("unexpected pending conditions %v", report.Pending)
+	}
+	if len(report.Nics) != 3 || !report.Nics[1].Primary || report.Nics[1].IpAddress != "10.0.0.5" {
+		t.Fatalf("unexpected NICs %+v", report.Nics)
+	}
+
+	report = evaluateGuestReadiness(vm, types.GuestCustStatusComplete, "toolsOk", GuestReadinessOptions{SkipDhcpIps: true})
+	if !report.Ready {
+		t.Fatalf("expected VM to be ready, pending: %v", report.Pending)
+	}
+
+	report = evaluateGuestReadiness(vm, types.GuestCustStatusFailed, "toolsOk", GuestReadinessOptions{SkipDhcpIps: true})
+	if report.Ready || !report.Failed {
+		t.Fatalf("expected failed customization to be reported")
+	}
+
+	vm.Status = 8
+	vm.GuestCustomizationSection.Enabled = addrOf(false)
+	report = evaluateGuestReadiness(vm, "", "", GuestReadinessOptions{SkipDhcpIps: true})
+	if !reflect.DeepEqual(report.Pending, []string{"VM is POWERED_OFF"}) {
+		t.Fatalf("unexpected pending conditions %v", report.Pending)
+	}
+}
+
+// Test_probeGuestPorts checks TCP port probes against a local listener
+func Test_probeGuestPorts(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("cannot listen on a local port: %s", err)
+	}
+	openPort := listener.Addr().(*net.TCPAddr).Port
+
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("cannot listen on a local port: %s", err)
+	}
+	closedPort := closed.Addr().(*net.TCPAddr).Port
+	err = closed.Close()
+	if err != nil {
+		t.Fatalf("error closing listener: %s", err)
+	}
+	defer func() {
+		_ = listener.Close()
+	}()
+
+	results := probeGuestPorts(context.Background(), "127.0.0.1", []int{openPort, closedPort}, time.Second)
+	expected := []GuestPortReadiness{
+		{Address: "127.0.0.1", Port: openPort, Open: true},
+		{Address: "127.0.0.1", Port: closedPort, Open: false},
+	}
+	if !reflect.DeepEqual(results, expected) {
+		t.Fatalf("unexpected probe results %+v", results)
+	}
+}
+
+// Test_waitForGuestReadinessRetries checks that errors retrieving a VM are recorded and retried until the deadline
+func Test_waitForGuestReadinessRetries(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	vm := NewVM(&Client{})
+	vm.VM = &types.Vm{Name: "web01"}
+
+	start := time.Now()
+	reports, err := waitForGuestReadiness(ctx, []*VM{vm}, GuestReadinessOptions{PollInterval: 10 * time.Millisecond})
+	if err == nil || !strings.Contains(err.Error(), context.DeadlineExceeded.Error()) {
+		t.Fatalf("expected wait to end at the deadline, got: %v", err)
+	}
+	if time.Since(start) < 50*time.Millisecond {
+		t.Fatalf("wait ended before the deadline")
+	}
+	if len(reports) != 1 || reports[0].VmName != "web01" || !strings.Contains(reports[0].Error, "Object is empty") {
+		t.Fatalf("unexpected reports %+v", reports)
+	}
+}
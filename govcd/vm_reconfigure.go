@@ -0,0 +1,405 @@
+/*
+ * Copyright 2025 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+package govcd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// Modes of a VM hardware change, as computed by VM.PlanReconfigure
+const (
+	// VmChangeOnline is a change that can be applied without any power operation, either because the VM
+	// supports hot add for it or because the VM is already powered off
+	VmChangeOnline = "online"
+	// VmChangePowerCycle is a change that requires the VM to be powered off while it is applied
+	VmChangePowerCycle = "powerCycle"
+	// VmChangeForbidden is a change that is rejected by the VM sizing policy or by the hardware version
+	VmChangeForbidden = "forbidden"
+)
+
+// VmReconfiguration is the desired hardware configuration of a VM.
+// Nil fields are left unchanged.
+type VmReconfiguration struct {
+	NumCpus        *int
+	CoresPerSocket *int
+	MemoryMb       *int64
+	CpuHotAdd      *bool
+	MemoryHotAdd   *bool
+
+	// AllowPowerCycle allows Reconfigure to shut down a powered on VM when some changes cannot be applied
+	// online, and to power it on again afterwards. When false, such changes make Reconfigure fail
+	AllowPowerCycle bool
+	// ForcePowerOff powers off the VM instead of shutting down the guest OS during a power cycle.
+	// Guest shutdown requires VMware Tools to be running in the VM
+	ForcePowerOff bool
+}
+
+// VmHardwareChange describes a single setting that differs between the VM and the desired configuration
+type VmHardwareChange struct {
+	Setting string // One of NumCpus, CoresPerSocket, MemoryMb, CpuHotAdd, MemoryHotAdd
+	From    string
+	To      string
+	Mode    string // One of VmChangeOnline, VmChangePowerCycle, VmChangeForbidden
+	Reason  string // Why the change needs a power cycle or is forbidden
+}
+
+// VmReconfigurePlan is the list of changes needed to reach a desired VM hardware configuration
+type VmReconfigurePlan struct {
+	VmName    string
+	PoweredOn bool
+	Changes   []VmHardwareChange
+	// PowerCycled is set by Reconfigure when the VM was powered off to apply the changes
+	PowerCycled bool
+
+	// values to apply, only set for the settings that change
+	numCpus        *int
+	coresPerSocket *int
+	memoryMb       *int64
+	cpuHotAdd      *bool
+	memoryHotAdd   *bool
+}
+
+// Online returns the changes that can be applied without powering off the VM
+func (plan *VmReconfigurePlan) Online() []VmHardwareChange {
+	return plan.changesWithMode(VmChangeOnline)
+}
+
+// RequiringPowerCycle returns the changes that can only be applied while the VM is powered off
+func (plan *VmReconfigurePlan) RequiringPowerCycle() []VmHardwareChange {
+	return plan.changesWithMode(VmChangePowerCycle)
+}
+
+// Forbidden returns the changes that cannot be applied to the VM
+func (plan *VmReconfigurePlan) Forbidden() []VmHardwareChange {
+	return plan.changesWithMode(VmChangeForbidden)
+}
+
+func (plan *VmReconfigurePlan) changesWithMode(mode string) []VmHardwareChange {
+	var changes []VmHardwareChange
+	for _, change := range plan.Changes {
+		if change.Mode == mode {
+			changes = append(changes, change)
+		}
+	}
+	return changes
+}
+
+// PlanReconfigure computes which changes are needed to reach the desired hardware configuration, and
+// whether each of them can be applied online, needs a power cycle or is forbidden by the VM sizing
+// policy or hardware version. Nothing is changed in the VM.
+func (vm *VM) PlanReconfigure(desired VmReconfiguration) (*VmReconfigurePlan, error) {
+	err := vm.Refresh()
+	if err != nil {
+		return nil, fmt.Errorf("error refreshing VM: %s", err)
+	}
+	if vm.VM.VmSpecSection == nil {
+		return nil, fmt.Errorf("VM '%s' has no VM spec section", vm.VM.Name)
+	}
+
+	var hardwareVersion *types.VirtualHardwareVersion
+	if vm.VM.VmSpecSection.HardwareVersion != nil && vm.VM.VmSpecSection.HardwareVersion.Value != "" {
+		vdc, err := vm.GetParentVdc()
+		if err != nil {
+			return nil, fmt.Errorf("error retrieving parent VDC of VM '%s': %s", vm.VM.Name, err)
+		}
+		hardwareVersion, err = vdc.GetHardwareVersion(vm.VM.VmSpecSection.HardwareVersion.Value)
+		if err != nil {
+			return nil, fmt.Errorf("error retrieving hardware version %s: %s", vm.VM.VmSpecSection.HardwareVersion.Value, err)
+		}
+	}
+
+	var sizingPolicy *types.VdcComputePolicyV2
+	if vm.VM.ComputePolicy != nil && vm.VM.ComputePolicy.VmSizingPolicy != nil && vm.VM.ComputePolicy.VmSizingPolicy.ID != "" {
+		policy, err := getVdcComputePolicyV2ById(vm.client, vm.VM.ComputePolicy.VmSizingPolicy.ID)
+		if err != nil {
+			return nil, fmt.Errorf("error retrieving sizing policy of VM '%s': %s", vm.VM.Name, err)
+		}
+		sizingPolicy = policy.VdcComputePolicyV2
+	}
+
+	return planVmReconfigure(vm.VM, desired, hardwareVersion, sizingPolicy), nil
+}
+
+// Reconfigure changes the CPU, memory and hot add settings of the VM to the desired configuration,
+// using the smallest set of operations. Changes that cannot be applied online are only performed when
+// desired.AllowPowerCycle is set: the VM is then shut down (or powered off with desired.ForcePowerOff),
+// reconfigured and powered on again.
+// The plan is returned also on error, to show which changes prevented the reconfiguration.
+func (vm *VM) Reconfigure(desired VmReconfiguration) (*VmReconfigurePlan, error) {
+	plan, err := vm.PlanReconfigure(desired)
+	if err != nil {
+		return nil, err
+	}
+	if len(plan.Changes) == 0 {
+		return plan, nil
+	}
+	if forbidden := plan.Forbidden(); len(forbidden) > 0 {
+		return plan, fmt.Errorf("VM '%s' cannot be reconfigured: %s", vm.VM.Name, vmHardwareChangeReasons(forbidden))
+	}
+
+	powerCycle := plan.PoweredOn && len(plan.RequiringPowerCycle()) > 0
+	if powerCycle {
+		if !desired.AllowPowerCycle {
+			return plan, fmt.Errorf("VM '%s' must be powered off to apply the changes: %s", vm.VM.Name,
+				vmHardwareChangeReasons(plan.RequiringPowerCycle()))
+		}
+		err = vm.stopForReconfigure(desired.ForcePowerOff)
+		if err != nil {
+			return plan, err
+		}
+		plan.PowerCycled = true
+	}
+
+	err = vm.applyReconfigurePlan(plan)
+	if powerCycle {
+		// The VM is powered on again even when the changes fail, to leave it in the state it was found
+		task, powerOnErr := vm.PowerOn()
+		if powerOnErr == nil {
+			powerOnErr = task.WaitTaskCompletion()
+		}
+		if powerOnErr != nil {
+			if err != nil {
+				return plan, fmt.Errorf("%s (additionally, error powering on VM '%s': %s)", err, vm.VM.Name, powerOnErr)
+			}
+			return plan, fmt.Errorf("error powering on VM '%s' after reconfiguration: %s", vm.VM.Name, powerOnErr)
+		}
+	}
+	if err != nil {
+		return plan, err
+	}
+
+	err = vm.Refresh()
+	if err != nil {
+		return plan, fmt.Errorf("error refreshing VM: %s", err)
+	}
+	return plan, nil
+}
+
+// stopForReconfigure shuts down the guest OS, or powers off the VM when forced, and waits for it
+func (vm *VM) stopForReconfigure(forcePowerOff bool) error {
+	var task Task
+	var err error
+	if forcePowerOff {
+		task, err = vm.Undeploy()
+	} else {
+		task, err = vm.Shutdown()
+	}
+	if err == nil {
+		err = task.WaitTaskCompletion()
+	}
+	if err != nil {
+		return fmt.Errorf("error powering off VM '%s' for reconfiguration: %s", vm.VM.Name, err)
+	}
+	err = vm.Refresh()
+	if err != nil {
+		return fmt.Errorf("error refreshing VM: %s", err)
+	}
+	return nil
+}
+
+// applyReconfigurePlan sends the hot add flags and the VM spec section only when they change
+func (vm *VM) applyReconfigurePlan(plan *VmReconfigurePlan) error {
+	if plan.cpuHotAdd != nil || plan.memoryHotAdd != nil {
+		cpuHotAdd, memoryHotAdd := false, false
+		if vm.VM.VMCapabilities != nil {
+			cpuHotAdd, memoryHotAdd = vm.VM.VMCapabilities.CPUHotAddEnabled, vm.VM.VMCapabilities.MemoryHotAddEnabled
+		}
+		if plan.cpuHotAdd != nil {
+			cpuHotAdd = *plan.cpuHotAdd
+		}
+		if plan.memoryHotAdd != nil {
+			memoryHotAdd = *plan.memoryHotAdd
+		}
+		_, err := vm.UpdateVmCpuAndMemoryHotAdd(cpuHotAdd, memoryHotAdd)
+		if err != nil {
+			return fmt.Errorf("error changing hot add settings of VM '%s': %s", vm.VM.Name, err)
+		}
+	}
+
+	if plan.numCpus == nil && plan.coresPerSocket == nil && plan.memoryMb == nil {
+		return nil
+	}
+	vmSpecSection := *vm.VM.VmSpecSection
+	// update treats same values as changes and fails, with no values provided - no changes are made for that section
+	vmSpecSection.DiskSection = nil
+	if plan.numCpus != nil {
+		vmSpecSection.NumCpus = plan.numCpus
+	}
+	if plan.coresPerSocket != nil {
+		vmSpecSection.NumCoresPerSocket = plan.coresPerSocket
+	}
+	if plan.memoryMb != nil {
+		memory := types.MemoryResourceMb{}
+		if vmSpecSection.MemoryResourceMb != nil {
+			memory = *vmSpecSection.MemoryResourceMb
+		}
+		memory.Configured = *plan.memoryMb
+		vmSpecSection.MemoryResourceMb = &memory
+	}
+	_, err := vm.UpdateVmSpecSection(&vmSpecSection, vm.VM.Description)
+	if err != nil {
+		return fmt.Errorf("error changing CPU and memory of VM '%s': %s", vm.VM.Name, err)
+	}
+	return nil
+}
+
+// planVmReconfigure compares the VM with the desired configuration and classifies each change.
+// A powered on VM accepts online only CPU and memory increases with the matching hot add flag enabled,
+// a hardware version that supports hot add and, for CPUs, unchanged cores per socket.
+func planVmReconfigure(vm *types.Vm, desired VmReconfiguration, hardwareVersion *types.VirtualHardwareVersion,
+	sizingPolicy *types.VdcComputePolicyV2) *VmReconfigurePlan {
+	plan := &VmReconfigurePlan{
+		VmName:    vm.Name,
+		PoweredOn: types.VAppStatuses[vm.Status] == "POWERED_ON",
+	}
+
+	numCpus, coresPerSocket, memoryMb := 0, 0, int64(0)
+	if vm.VmSpecSection != nil {
+		if vm.VmSpecSection.NumCpus != nil {
+			numCpus = *vm.VmSpecSection.NumCpus
+		}
+		if vm.VmSpecSection.NumCoresPerSocket != nil {
+			coresPerSocket = *vm.VmSpecSection.NumCoresPerSocket
+		}
+		if vm.VmSpecSection.MemoryResourceMb != nil {
+			memoryMb = vm.VmSpecSection.MemoryResourceMb.Configured
+		}
+	}
+	cpuHotAdd, memoryHotAdd := false, false
+	if vm.VMCapabilities != nil {
+		cpuHotAdd, memoryHotAdd = vm.VMCapabilities.CPUHotAddEnabled, vm.VMCapabilities.MemoryHotAddEnabled
+	}
+	hotAddSupported := hardwareVersion == nil || hardwareVersion.SupportsHotAdd == nil || *hardwareVersion.SupportsHotAdd
+
+	if desired.NumCpus != nil && *desired.NumCpus != numCpus {
+		plan.numCpus = desired.NumCpus
+	}
+	if desired.CoresPerSocket != nil && *desired.CoresPerSocket != coresPerSocket {
+		plan.coresPerSocket = desired.CoresPerSocket
+	}
+	if desired.MemoryMb != nil && *desired.MemoryMb != memoryMb {
+		plan.memoryMb = desired.MemoryMb
+	}
+	if desired.CpuHotAdd != nil && *desired.CpuHotAdd != cpuHotAdd {
+		plan.cpuHotAdd = desired.CpuHotAdd
+	}
+	if desired.MemoryHotAdd != nil && *desired.MemoryHotAdd != memoryHotAdd {
+		plan.memoryHotAdd = desired.MemoryHotAdd
+	}
+
+	newCpus, newCores := numCpus, coresPerSocket
+	if plan.numCpus != nil {
+		newCpus = *plan.numCpus
+	}
+	if plan.coresPerSocket != nil {
+		newCores = *plan.coresPerSocket
+	}
+
+	// addChange classifies a change, checking first the reasons that forbid it, then the ones that need a power cycle
+	addChange := func(setting string, from, to any, forbidden []string, powerCycle []string) {
+		change := VmHardwareChange{Setting: setting, From: fmt.Sprint(from), To: fmt.Sprint(to), Mode: VmChangeOnline}
+		switch {
+		case len(forbidden) > 0:
+			change.Mode, change.Reason = VmChangeForbidden, strings.Join(forbidden, ", ")
+		case plan.PoweredOn && len(powerCycle) > 0:
+			change.Mode, change.Reason = VmChangePowerCycle, strings.Join(powerCycle, ", ")
+		}
+		plan.Changes = append(plan.Changes, change)
+	}
+
+	if plan.numCpus != nil {
+		var forbidden, powerCycle []string
+		switch {
+		case newCpus < 1:
+			forbidden = append(forbidden, "CPU count must be at least 1")
+		case newCores > 0 && newCpus%newCores != 0:
+			forbidden = append(forbidden, fmt.Sprintf("CPU count %d is not a multiple of cores per socket %d", newCpus, newCores))
+		}
+		if sizingPolicy != nil && sizingPolicy.CPUCount != nil && newCpus != *sizingPolicy.CPUCount {
+			forbidden = append(forbidden, fmt.Sprintf("sizing policy '%s' sets %d CPUs", sizingPolicy.Name, *sizingPolicy.CPUCount))
+		}
+		if hardwareVersion != nil && hardwareVersion.MaxCPUs > 0 && newCpus > hardwareVersion.MaxCPUs {
+			forbidden = append(forbidden, fmt.Sprintf("hardware version %s supports at most %d CPUs", hardwareVersion.Name, hardwareVersion.MaxCPUs))
+		}
+		switch {
+		case newCpus < numCpus:
+			powerCycle = append(powerCycle, "CPU count can only be increased online")
+		case !hotAddSupported:
+			powerCycle = append(powerCycle, "hardware version does not support hot add")
+		case !cpuHotAdd:
+			powerCycle = append(powerCycle, "CPU hot add is disabled")
+		case plan.coresPerSocket != nil:
+			powerCycle = append(powerCycle, "cores per socket change")
+		}
+		addChange("NumCpus", numCpus, newCpus, forbidden, powerCycle)
+	}
+
+	if plan.coresPerSocket != nil {
+		var forbidden []string
+		switch {
+		case newCores < 1:
+			forbidden = append(forbidden, "cores per socket must be at least 1")
+		case plan.numCpus == nil && newCpus%newCores != 0:
+			forbidden = append(forbidden, fmt.Sprintf("CPU count %d is not a multiple of cores per socket %d", newCpus, newCores))
+		}
+		if sizingPolicy != nil && sizingPolicy.CoresPerSocket != nil && newCores != *sizingPolicy.CoresPerSocket {
+			forbidden = append(forbidden, fmt.Sprintf("sizing policy '%s' sets %d cores per socket", sizingPolicy.Name, *sizingPolicy.CoresPerSocket))
+		}
+		if hardwareVersion != nil && hardwareVersion.MaxCoresPerSocket > 0 && newCores > hardwareVersion.MaxCoresPerSocket {
+			forbidden = append(forbidden, fmt.Sprintf("hardware version %s supports at most %d cores per socket", hardwareVersion.Name, hardwareVersion.MaxCoresPerSocket))
+		}
+		addChange("CoresPerSocket", coresPerSocket, newCores, forbidden, []string{"cores per socket can only be changed while the VM is powered off"})
+	}
+
+	if plan.memoryMb != nil {
+		newMemory := *plan.memoryMb
+		var forbidden, powerCycle []string
+		if newMemory < 1 {
+			forbidden = append(forbidden, "memory size must be at least 1 MB")
+		}
+		if sizingPolicy != nil && sizingPolicy.Memory != nil && newMemory != int64(*sizingPolicy.Memory) {
+			forbidden = append(forbidden, fmt.Sprintf("sizing policy '%s' sets %d MB of memory", sizingPolicy.Name, *sizingPolicy.Memory))
+		}
+		if hardwareVersion != nil && hardwareVersion.MaxMemorySizeMb > 0 && newMemory > int64(hardwareVersion.MaxMemorySizeMb) {
+			forbidden = append(forbidden, fmt.Sprintf("hardware version %s supports at most %d MB of memory", hardwareVersion.Name, hardwareVersion.MaxMemorySizeMb))
+		}
+		switch {
+		case newMemory < memoryMb:
+			powerCycle = append(powerCycle, "memory size can only be increased online")
+		case !hotAddSupported:
+			powerCycle = append(powerCycle, "hardware version does not support hot add")
+		case !memoryHotAdd:
+			powerCycle = append(powerCycle, "memory hot add is disabled")
+		}
+		addChange("MemoryMb", memoryMb, newMemory, forbidden, powerCycle)
+	}
+
+	if plan.cpuHotAdd != nil || plan.memoryHotAdd != nil {
+		var forbidden []string
+		if !hotAddSupported {
+			forbidden = append(forbidden, fmt.Sprintf("hardware version %s does not support hot add", hardwareVersion.Name))
+		}
+		if plan.cpuHotAdd != nil {
+			addChange("CpuHotAdd", cpuHotAdd, *plan.cpuHotAdd, forbidden, []string{"hot add settings can only be changed while the VM is powered off"})
+		}
+		if plan.memoryHotAdd != nil {
+			addChange("MemoryHotAdd", memoryHotAdd, *plan.memoryHotAdd, forbidden, []string{"hot add settings can only be changed while the VM is powered off"})
+		}
+	}
+
+	return plan
+}
+
+// vmHardwareChangeReasons lists changes with their reasons in a single string, for error messages
+func vmHardwareChangeReasons(changes []VmHardwareChange) string {
+	reasons := make([]string, len(changes))
+	for index, change := range changes {
+		reasons[index] = fmt.Sprintf("%s %s -> %s (%s)", change.Setting, change.From, change.To, change.Reason)
+	}
+	return strings.Join(reasons, "; ")
+}
@@ -0,0 +1,134 @@
+//go:build unit || ALL
+
+/*
+ * Copyright 2025 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+package govcd
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// Test_planVmReconfigure checks how changes are classified depending on power state, hot add flags,
+// sizing policy and hardware version
+func Test_planVmReconfigure(t *testing.T) {
+	hardwareVersion := &types.VirtualHardwareVersion{Name: "vmx-19", MaxCPUs: 16, MaxCoresPerSocket: 8, MaxMemorySizeMb: 65536,
+		SupportsHotAdd: addrOf(true)}
+	sizingPolicy := &types.VdcComputePolicyV2{VdcComputePolicy: types.VdcComputePolicy{Name: "small", Memory: addrOf(4096)}}
+
+	modes := func(plan *VmReconfigurePlan) map[string]string {
+		result := make(map[string]string)
+		for _, change := range plan.Changes {
+			result[change.Setting] = change.Mode
+		}
+		return result
+	}
+
+	tests := []struct {
+		name         string
+		status       int
+		cpuHotAdd    bool
+		memoryHotAdd bool
+		desired      VmReconfiguration
+		sizingPolicy *types.VdcComputePolicyV2
+		expected     map[string]string
+	}{
+		{
+			name:         "no changes",
+			status:       4,
+			cpuHotAdd:    true,
+			memoryHotAdd: true,
+			desired:      VmReconfiguration{NumCpus: addrOf(4), MemoryMb: addrOf(int64(4096)), CpuHotAdd: addrOf(true)},
+			expected:     map[string]string{},
+		},
+		{
+			name:         "hot add increases",
+			status:       4,
+			cpuHotAdd:    true,
+			memoryHotAdd: true,
+			desired:      VmReconfiguration{NumCpus: addrOf(8), MemoryMb: addrOf(int64(8192))},
+			expected:     map[string]string{"NumCpus": VmChangeOnline, "MemoryMb": VmChangeOnline},
+		},
+		{
+			name:         "hot add disabled",
+			status:       4,
+			cpuHotAdd:    false,
+			memoryHotAdd: true,
+			desired:      VmReconfiguration{NumCpus: addrOf(8), MemoryMb: addrOf(int64(2048))},
+			expected:     map[string]string{"NumCpus": VmChangePowerCycle, "MemoryMb": VmChangePowerCycle},
+		},
+		{
+			name:         "cores and flags on powered on VM",
+			status:       4,
+			cpuHotAdd:    true,
+			memoryHotAdd: true,
+			desired:      VmReconfiguration{NumCpus: addrOf(8), CoresPerSocket: addrOf(4), MemoryHotAdd: addrOf(false)},
+			expected:     map[string]string{"NumCpus": VmChangePowerCycle, "CoresPerSocket": VmChangePowerCycle, "MemoryHotAdd": VmChangePowerCycle},
+		},
+		{
+			name:         "powered off VM",
+			status:       8,
+			cpuHotAdd:    false,
+			memoryHotAdd: false,
+			desired:      VmReconfiguration{NumCpus: addrOf(2), CoresPerSocket: addrOf(1), MemoryMb: addrOf(int64(2048)), CpuHotAdd: addrOf(true)},
+			expected:     map[string]string{"NumCpus": VmChangeOnline, "CoresPerSocket": VmChangeOnline, "MemoryMb": VmChangeOnline, "CpuHotAdd": VmChangeOnline},
+		},
+		{
+			name:         "forbidden changes",
+			status:       4,
+			cpuHotAdd:    true,
+			memoryHotAdd: true,
+			desired:      VmReconfiguration{NumCpus: addrOf(32), CoresPerSocket: addrOf(3), MemoryMb: addrOf(int64(8192))},
+			sizingPolicy: sizingPolicy,
+			expected:     map[string]string{"NumCpus": VmChangeForbidden, "CoresPerSocket": VmChangePowerCycle, "MemoryMb": VmChangeForbidden},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			vm := &types.Vm{
+				Name:   "app01",
+				Status: test.status,
+				VmSpecSection: &types.VmSpecSection{
+					NumCpus:           addrOf(4),
+					NumCoresPerSocket: addrOf(2),
+					MemoryResourceMb:  &types.MemoryResourceMb{Configured: 4096},
+				},
+				VMCapabilities: &types.VmCapabilities{CPUHotAddEnabled: test.cpuHotAdd, MemoryHotAddEnabled: test.memoryHotAdd},
+			}
+			plan := planVmReconfigure(vm, test.desired, hardwareVersion, test.sizingPolicy)
+			if got := modes(plan); !reflect.DeepEqual(got, test.expected) {
+				t.Fatalf("expected changes %v, got %v", test.expected, got)
+			}
+		})
+	}
+
+	poweredOnVm := &types.Vm{
+		Name:   "app01",
+		Status: 4,
+		VmSpecSection: &types.VmSpecSection{
+			NumCpus:           addrOf(4),
+			NumCoresPerSocket: addrOf(2),
+			MemoryResourceMb:  &types.MemoryResourceMb{Configured: 4096},
+		},
+		VMCapabilities: &types.VmCapabilities{CPUHotAddEnabled: true, MemoryHotAddEnabled: true},
+	}
+	plan := planVmReconfigure(poweredOnVm, VmReconfiguration{NumCpus: addrOf(32), CoresPerSocket: addrOf(3)},
+		hardwareVersion, nil)
+	expectedReason := "CPU count 32 is not a multiple of cores per socket 3, hardware version vmx-19 supports at most 16 CPUs"
+	if forbidden := plan.Forbidden(); len(forbidden) != 1 || forbidden[0].Reason != expectedReason {
+		t.Fatalf("unexpected forbidden changes %+v", forbidden)
+	}
+
+	plan = planVmReconfigure(poweredOnVm, VmReconfiguration{MemoryMb: addrOf(int64(8192))},
+		&types.VirtualHardwareVersion{Name: "vmx-04", SupportsHotAdd: addrOf(false)}, nil)
+	if changes := plan.RequiringPowerCycle(); len(changes) != 1 || changes[0].Reason != "hardware version does not support hot add" {
+		t.Fatalf("unexpected power cycle changes %+v", changes)
+	}
+	if plan.memoryMb == nil || *plan.memoryMb != 8192 || plan.numCpus != nil {
+		t.Fatalf("only changed settings must be applied")
+	}
+}
@@ -0,0 +1,731 @@
+/*
+ * Copyright 2025 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+package govcd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// VmSpec is a fluent builder for the specification of a new VM. It is validated against the VDC where the VM is
+// created, and converted to the payloads of VApp.AddEmptyVm, Vdc.CreateStandaloneVm and
+// Vdc.CreateStandaloneVMFromTemplate:
+//
+//	spec := NewVmSpec().WithName("web01").WithOs("ubuntu64Guest").WithCpu(2, 1).WithMemory(2048).
+//		WithDisk(20480).WithNic("web-network", types.IPAllocationModePool)
+//	vm, err := vdc.CreateStandaloneVmFromSpec(spec)
+//
+// Settings that are not given are left to VCD, except the hardware version, which defaults to the highest one
+// supported by the VDC, and the CPU count, which defaults to 1. The OS type and the memory size are required, unless
+// the VM is created from a template or the memory size is set by a sizing policy.
+type VmSpec struct {
+	name               string
+	description        string
+	osType             string
+	firmware           string
+	hardwareVersion    string
+	numCpus            int
+	coresPerSocket     int
+	cpuSet             bool
+	memoryMb           int64
+	disks              []*types.DiskSettings
+	nics               []*types.NetworkConnection
+	storageProfile     string
+	sizingPolicy       string
+	placementPolicy    string
+	bootOptions        *types.BootOptions
+	bootMedia          *types.Reference
+	guestCustomization *types.GuestCustomizationSection
+	powerOn            bool
+}
+
+// VmSpecValidationError is returned when a VmSpec is not valid for the VDC where the VM would be created
+type VmSpecValidationError struct {
+	Problems []string
+}
+
+func (err *VmSpecValidationError) Error() string {
+	return fmt.Sprintf("invalid VM specification: %s", strings.Join(err.Problems, "; "))
+}
+
+// NewVmSpec returns an empty VM specification
+func NewVmSpec() *VmSpec {
+	return &VmSpec{numCpus: 1, coresPerSocket: 1}
+}
+
+// WithName sets the name of the VM
+func (spec *VmSpec) WithName(name string) *VmSpec {
+	spec.name = name
+	return spec
+}
+
+// WithDescription sets the description of the VM
+func (spec *VmSpec) WithDescription(description string) *VmSpec {
+	spec.description = description
+	return spec
+}
+
+// WithOs sets the guest OS type, using its internal name, such as "ubuntu64Guest" or "windows2019srv_64Guest"
+func (spec *VmSpec) WithOs(osType string) *VmSpec {
+	spec.osType = osType
+	return spec
+}
+
+// WithFirmware sets the firmware of the VM: "bios" or "efi"
+func (spec *VmSpec) WithFirmware(firmware string) *VmSpec {
+	spec.firmware = firmware
+	return spec
+}
+
+// WithHardwareVersion sets the virtual hardware version, such as "vmx-19"
+func (spec *VmSpec) WithHardwareVersion(hardwareVersion string) *VmSpec {
+	spec.hardwareVersion = hardwareVersion
+	return spec
+}
+
+// WithCpu sets the number of virtual CPUs and how many cores each socket has
+func (spec *VmSpec) WithCpu(numCpus, coresPerSocket int) *VmSpec {
+	spec.numCpus = numCpus
+	spec.coresPerSocket = coresPerSocket
+	spec.cpuSet = true
+	return spec
+}
+
+// WithMemory sets the memory size in MB
+func (spec *VmSpec) WithMemory(sizeMb int64) *VmSpec {
+	spec.memoryMb = sizeMb
+	return spec
+}
+
+// WithDisk adds a thin provisioned disk of the given size on the default disk controller of the guest OS. Bus and
+// unit numbers are assigned in order.
+func (spec *VmSpec) WithDisk(sizeMb int64) *VmSpec {
+	spec.disks = append(spec.disks, &types.DiskSettings{SizeMb: sizeMb, ThinProvisioned: addrOf(true), UnitNumber: -1, BusNumber: -1})
+	return spec
+}
+
+// WithDiskSettings adds a disk with the given settings. AdapterType is a disk controller ID, such as "lsilogicsas",
+// or its legacy ID, such as "4". When AdapterType is empty, the default disk controller of the guest OS is used and
+// bus and unit numbers are assigned in order. A storage profile can be given by name only.
+func (spec *VmSpec) WithDiskSettings(disk types.DiskSettings) *VmSpec {
+	if disk.AdapterType == "" {
+		disk.BusNumber, disk.UnitNumber = -1, -1
+	}
+	spec.disks = append(spec.disks, &disk)
+	return spec
+}
+
+// WithNic adds a connected NIC attached to the given network, with the given IP allocation mode (one of
+// types.IPAllocationModePool, types.IPAllocationModeDHCP or types.IPAllocationModeNone). The first NIC is the primary
+// one.
+func (spec *VmSpec) WithNic(networkName, ipAllocationMode string) *VmSpec {
+	spec.nics = append(spec.nics, &types.NetworkConnection{
+		Network:                 networkName,
+		IsConnected:             networkName != types.NoneNetwork,
+		IPAddressAllocationMode: ipAllocationMode,
+	})
+	return spec
+}
+
+// WithNicSettings adds a NIC with the given settings. Its index is set by its position
+func (spec *VmSpec) WithNicSettings(nic types.NetworkConnection) *VmSpec {
+	spec.nics = append(spec.nics, &nic)
+	return spec
+}
+
+// WithStorageProfile sets the storage profile of the VM by name
+func (spec *VmSpec) WithStorageProfile(name string) *VmSpec {
+	spec.storageProfile = name
+	return spec
+}
+
+// WithSizingPolicy sets the VM sizing policy by name or ID. The policy must be assigned to the VDC
+func (spec *VmSpec) WithSizingPolicy(nameOrId string) *VmSpec {
+	spec.sizingPolicy = nameOrId
+	return spec
+}
+
+// WithPlacementPolicy sets the VM placement policy by name or ID. The policy must be assigned to the VDC
+func (spec *VmSpec) WithPlacementPolicy(nameOrId string) *VmSpec {
+	spec.placementPolicy = nameOrId
+	return spec
+}
+
+// WithBootOptions sets the boot options of the VM
+func (spec *VmSpec) WithBootOptions(bootOptions types.BootOptions) *VmSpec {
+	spec.bootOptions = &bootOptions
+	return spec
+}
+
+// WithBootMedia sets the media inserted in the VM at creation, usually an installation ISO
+func (spec *VmSpec) WithBootMedia(media *types.Reference) *VmSpec {
+	spec.bootMedia = media
+	return spec
+}
+
+// WithGuestCustomization sets the guest customization settings of the VM
+func (spec *VmSpec) WithGuestCustomization(section types.GuestCustomizationSection) *VmSpec {
+	spec.guestCustomization = &section
+	return spec
+}
+
+// WithPowerOn sets whether the VM is powered on after creation
+func (spec *VmSpec) WithPowerOn(powerOn bool) *VmSpec {
+	spec.powerOn = powerOn
+	return spec
+}
+
+// vmSpecComputePolicy is a compute policy assigned to the VDC, with its reference usable in payloads
+type vmSpecComputePolicy struct {
+	reference *types.Reference
+	policy    *types.VdcComputePolicyV2
+}
+
+// vmSpecTarget holds what the VDC offers to a new VM
+type vmSpecTarget struct {
+	hardwareVersion *types.VirtualHardwareVersion
+	// os is the guest OS of the specification in hardwareVersion. It is nil when the hardware version does not
+	// support it
+	os              *types.OperatingSystemInfoType
+	storageProfiles []*types.Reference
+	computePolicies []vmSpecComputePolicy
+	networks        []string
+	fromTemplate    bool
+}
+
+// vmSpecSettings is the result of a VmSpec validated against a vmSpecTarget
+type vmSpecSettings struct {
+	vmSpecSection  *types.VmSpecSection
+	storageProfile *types.Reference
+	computePolicy  *types.ComputePolicy
+	networks       *types.NetworkConnectionSection
+}
+
+// EmptyVmParams validates the specification against the VDC of the vApp and the vApp networks, and returns the
+// payload for VApp.AddEmptyVm
+func (spec *VmSpec) EmptyVmParams(vapp *VApp) (*types.RecomposeVAppParamsForEmptyVm, error) {
+	vdc, err := vapp.GetParentVDC()
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving VDC of vApp '%s': %s", vapp.VApp.Name, err)
+	}
+	networkConfig, err := vapp.GetNetworkConfig()
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving vApp network configuration: %s", err)
+	}
+	var networks []string
+	for _, network := range networkConfig.NetworkConfig {
+		networks = append(networks, network.NetworkName)
+	}
+
+	settings, err := spec.resolve(&vdc, networks, false)
+	if err != nil {
+		return nil, err
+	}
+
+	params := &types.RecomposeVAppParamsForEmptyVm{
+		XmlnsVcloud: types.XMLNamespaceVCloud,
+		XmlnsOvf:    types.XMLNamespaceOVF,
+		PowerOn:     spec.powerOn,
+		CreateItem: &types.CreateItem{
+			Name:                      spec.name,
+			Description:               spec.description,
+			GuestCustomizationSection: spec.guestCustomization,
+			NetworkConnectionSection:  settings.networks,
+			VmSpecSection:             settings.vmSpecSection,
+			StorageProfile:            settings.storageProfile,
+			ComputePolicy:             settings.computePolicy,
+			BootOptions:               spec.bootOptions,
+		},
+		AllEULAsAccepted: true,
+	}
+	if spec.bootMedia != nil {
+		params.CreateItem.BootImage = &types.Media{HREF: spec.bootMedia.HREF, ID: spec.bootMedia.ID, Name: spec.bootMedia.Name}
+	}
+	return params, nil
+}
+
+// CreateVmParams validates the specification against the VDC and its networks, and returns the payload for
+// Vdc.CreateStandaloneVm
+func (spec *VmSpec) CreateVmParams(vdc *Vdc) (*types.CreateVmParams, error) {
+	settings, err := spec.resolve(vdc, vdcNetworkNames(vdc), false)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.CreateVmParams{
+		Xmlns:       types.XMLNamespaceVCloud,
+		Name:        spec.name,
+		PowerOn:     spec.powerOn,
+		Description: spec.description,
+		CreateVm: &types.Vm{
+			Name:                      spec.name,
+			Description:               spec.description,
+			VmSpecSection:             settings.vmSpecSection,
+			NetworkConnectionSection:  settings.networks,
+			GuestCustomizationSection: spec.guestCustomization,
+			StorageProfile:            settings.storageProfile,
+			ComputePolicy:             settings.computePolicy,
+			BootOptions:               spec.bootOptions,
+		},
+		Media: spec.bootMedia,
+	}, nil
+}
+
+// TemplateVmParams validates the specification against the VDC and its networks, and returns the payload for
+// Vdc.CreateStandaloneVMFromTemplate. vmTemplate is the VM of a vApp template. Hardware settings (OS, firmware,
+// hardware version, CPU, memory and disks) come from the template and cannot be set in the specification.
+func (spec *VmSpec) TemplateVmParams(vdc *Vdc, vmTemplate *VAppTemplate) (*types.InstantiateVmTemplateParams, error) {
+	if vmTemplate == nil || vmTemplate.VAppTemplate == nil || vmTemplate.VAppTemplate.HREF == "" {
+		return nil, fmt.Errorf("a VM template with HREF is required")
+	}
+	settings, err := spec.resolve(vdc, vdcNetworkNames(vdc), true)
+	if err != nil {
+		return nil, err
+	}
+
+	instantiationParams := &types.InstantiationParams{
+		NetworkConnectionSection:  settings.networks,
+		GuestCustomizationSection: spec.guestCustomization,
+	}
+	return &types.InstantiateVmTemplateParams{
+		Xmlns:       types.XMLNamespaceVCloud,
+		Name:        spec.name,
+		PowerOn:     spec.powerOn,
+		Description: spec.description,
+		SourcedVmTemplateItem: &types.SourcedVmTemplateParams{
+			Source:                        &types.Reference{HREF: vmTemplate.VAppTemplate.HREF},
+			VmGeneralParams:               &types.VMGeneralParams{Name: spec.name, Description: spec.description},
+			VmTemplateInstantiationParams: instantiationParams,
+			StorageProfile:                settings.storageProfile,
+		},
+		AllEULAsAccepted: true,
+		ComputePolicy:    settings.computePolicy,
+	}, nil
+}
+
+// AddEmptyVmFromSpec validates the specification and adds the new VM to the vApp
+func (vapp *VApp) AddEmptyVmFromSpec(spec *VmSpec) (*VM, error) {
+	params, err := spec.EmptyVmParams(vapp)
+	if err != nil {
+		return nil, err
+	}
+	return vapp.AddEmptyVm(params)
+}
+
+// CreateStandaloneVmFromSpec validates the specification and creates a standalone VM in the VDC
+func (vdc *Vdc) CreateStandaloneVmFromSpec(spec *VmSpec) (*VM, error) {
+	params, err := spec.CreateVmParams(vdc)
+	if err != nil {
+		return nil, err
+	}
+	return vdc.CreateStandaloneVm(params)
+}
+
+// resolve retrieves what the VDC offers to the new VM and validates the specification against it
+func (spec *VmSpec) resolve(vdc *Vdc, networks []string, fromTemplate bool) (*vmSpecSettings, error) {
+	target := vmSpecTarget{networks: networks, fromTemplate: fromTemplate}
+	if vdc.Vdc.VdcStorageProfiles != nil {
+		target.storageProfiles = vdc.Vdc.VdcStorageProfiles.VdcStorageProfile
+	}
+
+	var err error
+	if !fromTemplate {
+		if spec.hardwareVersion == "" {
+			target.hardwareVersion, err = vdc.GetHighestHardwareVersion()
+		} else {
+			target.hardwareVersion, err = vdc.GetHardwareVersion(spec.hardwareVersion)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error retrieving hardware version of VDC '%s': %s", vdc.Vdc.Name, err)
+		}
+		if spec.osType != "" && target.hardwareVersion.SupportedOperatingSystems != nil {
+			// An OS that is not found is reported by the validation
+			target.os, _ = vdc.FindOsFromId(target.hardwareVersion, spec.osType)
+		}
+	}
+
+	if spec.sizingPolicy != "" || spec.placementPolicy != "" {
+		policies, err := getAllAssignedVdcComputePoliciesV2(vdc.client, vdc.Vdc.ID, nil)
+		if err != nil {
+			return nil, fmt.Errorf("error retrieving compute policies of VDC '%s': %s", vdc.Vdc.Name, err)
+		}
+		for _, policy := range policies {
+			policyHref, err := vdc.client.OpenApiBuildEndpoint(types.OpenApiPathVersion1_0_0, types.OpenApiEndpointVdcComputePolicies, policy.VdcComputePolicyV2.ID)
+			if err != nil {
+				return nil, fmt.Errorf("error constructing HREF for compute policy: %s", err)
+			}
+			target.computePolicies = append(target.computePolicies, vmSpecComputePolicy{
+				reference: &types.Reference{HREF: policyHref.String(), ID: policy.VdcComputePolicyV2.ID, Name: policy.VdcComputePolicyV2.Name},
+				policy:    policy.VdcComputePolicyV2,
+			})
+		}
+	}
+
+	return validateVmSpec(spec, target)
+}
+
+// vdcNetworkNames returns the names of the networks available in the VDC
+func vdcNetworkNames(vdc *Vdc) []string {
+	var networks []string
+	for _, availableNetworks := range vdc.Vdc.AvailableNetworks {
+		for _, network := range availableNetworks.Network {
+			networks = append(networks, network.Name)
+		}
+	}
+	return networks
+}
+
+// validateVmSpec checks the specification against the target and computes the VM settings
+func validateVmSpec(spec *VmSpec, target vmSpecTarget) (*vmSpecSettings, error) {
+	var problems []string
+	addProblem := func(format string, args ...any) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+	settings := &vmSpecSettings{}
+
+	if spec.name == "" {
+		addProblem("VM name is required")
+	}
+
+	var sizingPolicy *types.VdcComputePolicyV2
+	if spec.sizingPolicy != "" || spec.placementPolicy != "" {
+		settings.computePolicy = &types.ComputePolicy{}
+	}
+	if spec.sizingPolicy != "" {
+		policy := findVmSpecComputePolicy(target.computePolicies, spec.sizingPolicy)
+		if policy == nil {
+			addProblem("sizing policy '%s' is not assigned to the VDC", spec.sizingPolicy)
+		} else {
+			sizingPolicy = policy.policy
+			settings.computePolicy.VmSizingPolicy = policy.reference
+		}
+	}
+	if spec.placementPolicy != "" {
+		policy := findVmSpecComputePolicy(target.computePolicies, spec.placementPolicy)
+		switch {
+		case policy == nil:
+			addProblem("placement policy '%s' is not assigned to the VDC", spec.placementPolicy)
+		case policy.policy.IsSizingOnly:
+			addProblem("compute policy '%s' is a sizing policy and cannot be used for placement", spec.placementPolicy)
+		default:
+			settings.computePolicy.VmPlacementPolicy = policy.reference
+		}
+	}
+
+	if spec.storageProfile != "" {
+		settings.storageProfile = findReferenceByName(target.storageProfiles, spec.storageProfile)
+		if settings.storageProfile == nil {
+			addProblem("storage profile '%s' is not available in the VDC", spec.storageProfile)
+		}
+	}
+
+	settings.networks, problems = validateVmSpecNics(spec, target, problems)
+
+	if target.fromTemplate {
+		if spec.osType != "" || spec.firmware != "" || spec.hardwareVersion != "" || spec.cpuSet || spec.memoryMb != 0 || len(spec.disks) > 0 {
+			addProblem("OS, firmware, hardware version, CPU, memory and disks cannot be set when creating a VM from a template")
+		}
+	} else {
+		settings.vmSpecSection, problems = validateVmSpecHardware(spec, target.hardwareVersion, target.os, sizingPolicy, target.storageProfiles, problems)
+	}
+
+	if len(problems) > 0 {
+		return nil, &VmSpecValidationError{Problems: problems}
+	}
+	return settings, nil
+}
+
+// validateVmSpecNics checks the NICs of the specification and builds their network connection section
+func validateVmSpecNics(spec *VmSpec, target vmSpecTarget, problems []string) (*types.NetworkConnectionSection, []string) {
+	if len(spec.nics) == 0 {
+		return nil, problems
+	}
+	section := &types.NetworkConnectionSection{PrimaryNetworkConnectionIndex: 0}
+	for index, nic := range spec.nics {
+		connection := *nic
+		connection.NetworkConnectionIndex = index
+		if connection.IPAddressAllocationMode == "" {
+			connection.IPAddressAllocationMode = types.IPAllocationModeNone
+		}
+		switch connection.IPAddressAllocationMode {
+		case types.IPAllocationModePool, types.IPAllocationModeDHCP, types.IPAllocationModeNone:
+		case types.IPAllocationModeManual:
+			if connection.IPAddress == "" {
+				problems = append(problems, fmt.Sprintf("NIC %d: an IP address is required with MANUAL allocation", index))
+			}
+		default:
+			problems = append(problems, fmt.Sprintf("NIC %d: invalid IP allocation mode '%s'", index, connection.IPAddressAllocationMode))
+		}
+		if connection.Network != types.NoneNetwork && !contains(connection.Network, target.networks) {
+			problems = append(problems, fmt.Sprintf("NIC %d: network '%s' is not available", index, connection.Network))
+		}
+		section.NetworkConnection = append(section.NetworkConnection, &connection)
+	}
+	return section, problems
+}
+
+// validateVmSpecHardware checks the hardware settings of the specification against the hardware version and the
+// guest OS, and builds the VM specification section. os is the guest OS found in the hardware version, if any
+func validateVmSpecHardware(spec *VmSpec, hardwareVersion *types.VirtualHardwareVersion, os *types.OperatingSystemInfoType, sizingPolicy *types.VdcComputePolicyV2,
+	storageProfiles []*types.Reference, problems []string) (*types.VmSpecSection, []string) {
+	addProblem := func(format string, args ...any) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	numCpus, coresPerSocket, memoryMb := spec.numCpus, spec.coresPerSocket, spec.memoryMb
+	if sizingPolicy != nil {
+		if sizingPolicy.CPUCount != nil {
+			if spec.cpuSet && numCpus != *sizingPolicy.CPUCount {
+				addProblem("CPU count %d conflicts with sizing policy '%s', which sets %d", numCpus, sizingPolicy.Name, *sizingPolicy.CPUCount)
+			}
+			numCpus = *sizingPolicy.CPUCount
+		}
+		if sizingPolicy.CoresPerSocket != nil {
+			if spec.cpuSet && coresPerSocket != *sizingPolicy.CoresPerSocket {
+				addProblem("cores per socket %d conflict with sizing policy '%s', which sets %d", coresPerSocket, sizingPolicy.Name, *sizingPolicy.CoresPerSocket)
+			}
+			coresPerSocket = *sizingPolicy.CoresPerSocket
+		}
+		if sizingPolicy.Memory != nil {
+			if memoryMb != 0 && memoryMb != int64(*sizingPolicy.Memory) {
+				addProblem("memory size %d MB conflicts with sizing policy '%s', which sets %d MB", memoryMb, sizingPolicy.Name, *sizingPolicy.Memory)
+			}
+			memoryMb = int64(*sizingPolicy.Memory)
+		}
+	}
+
+	if numCpus < 1 || coresPerSocket < 1 {
+		addProblem("CPU count and cores per socket must be at least 1")
+	} else if numCpus%coresPerSocket != 0 {
+		addProblem("CPU count %d is not a multiple of cores per socket %d", numCpus, coresPerSocket)
+	}
+	if memoryMb <= 0 {
+		addProblem("memory size is required when it is not set by a sizing policy")
+	}
+	if numCpus > hardwareVersion.MaxCPUs {
+		addProblem("hardware version %s supports at most %d CPUs", hardwareVersion.Name, hardwareVersion.MaxCPUs)
+	}
+	if coresPerSocket > hardwareVersion.MaxCoresPerSocket {
+		addProblem("hardware version %s supports at most %d cores per socket", hardwareVersion.Name, hardwareVersion.MaxCoresPerSocket)
+	}
+	if memoryMb > int64(hardwareVersion.MaxMemorySizeMb) {
+		addProblem("hardware version %s supports at most %d MB of memory", hardwareVersion.Name, hardwareVersion.MaxMemorySizeMb)
+	}
+	if len(spec.nics) > hardwareVersion.MaxNICs {
+		addProblem("hardware version %s supports at most %d NICs", hardwareVersion.Name, hardwareVersion.MaxNICs)
+	}
+
+	if spec.osType == "" {
+		addProblem("OS type is required")
+	} else if os == nil && hardwareVersion.SupportedOperatingSystems != nil {
+		addProblem("OS type '%s' is not supported by hardware version %s", spec.osType, hardwareVersion.Name)
+	}
+
+	if os != nil {
+		if os.SupportedForCreate != nil && !*os.SupportedForCreate {
+			addProblem("OS type '%s' cannot be used to create VMs", spec.osType)
+		}
+		if os.MinimumHardwareVersion != nil && hardwareVersionNumber(hardwareVersion.Name) < *os.MinimumHardwareVersion {
+			addProblem("OS type '%s' requires hardware version vmx-%d or later", spec.osType, *os.MinimumHardwareVersion)
+		}
+		if os.MaximumCpuCount != nil && numCpus > *os.MaximumCpuCount {
+			addProblem("OS type '%s' supports at most %d CPUs", spec.osType, *os.MaximumCpuCount)
+		}
+		if os.MaximumCoresPerSocket != nil && coresPerSocket > *os.MaximumCoresPerSocket {
+			addProblem("OS type '%s' supports at most %d cores per socket", spec.osType, *os.MaximumCoresPerSocket)
+		}
+		if os.MinimumMemoryMegabytes != nil && memoryMb > 0 && memoryMb < int64(*os.MinimumMemoryMegabytes) {
+			addProblem("OS type '%s' requires at least %d MB of memory", spec.osType, *os.MinimumMemoryMegabytes)
+		}
+		if spec.firmware != "" && len(os.SupportedFirmware) > 0 && !containsFold(spec.firmware, os.SupportedFirmware) {
+			addProblem("OS type '%s' does not support firmware '%s'", spec.osType, spec.firmware)
+		}
+		if spec.guestCustomization != nil && os.PersonalizationEnabled != nil && !*os.PersonalizationEnabled {
+			addProblem("OS type '%s' does not support guest customization", spec.osType)
+		}
+		for index, nic := range spec.nics {
+			if nic.NetworkAdapterType == "" || len(os.SupportedNICType) == 0 {
+				continue
+			}
+			supported := false
+			for _, nicType := range os.SupportedNICType {
+				supported = supported || strings.EqualFold(nicType.Name, nic.NetworkAdapterType)
+			}
+			if !supported {
+				addProblem("NIC %d: adapter type '%s' is not supported by OS type '%s'", index, nic.NetworkAdapterType, spec.osType)
+			}
+		}
+	}
+
+	if spec.bootOptions != nil && spec.bootOptions.EfiSecureBootEnabled != nil && *spec.bootOptions.EfiSecureBootEnabled &&
+		!strings.EqualFold(spec.firmware, "efi") {
+		addProblem("EFI secure boot requires 'efi' firmware")
+	}
+
+	disks, problems := validateVmSpecDisks(spec.disks, hardwareVersion, os, storageProfiles, problems)
+
+	section := &types.VmSpecSection{
+		Modified:          addrOf(true),
+		Info:              "Virtual Machine specification",
+		OsType:            spec.osType,
+		Firmware:          spec.firmware,
+		NumCpus:           addrOf(numCpus),
+		NumCoresPerSocket: addrOf(coresPerSocket),
+		MemoryResourceMb:  &types.MemoryResourceMb{Configured: memoryMb},
+		HardwareVersion:   &types.HardwareVersion{Value: hardwareVersion.Name},
+		VirtualCpuType:    "VM32",
+	}
+	if os != nil && os.X64 != nil && *os.X64 {
+		section.VirtualCpuType = "VM64"
+	}
+	if len(disks) > 0 {
+		section.DiskSection = &types.DiskSection{DiskSettings: disks}
+	}
+	return section, problems
+}
+
+// validateVmSpecDisks checks the disks against the disk controllers of the hardware version and the guest OS, and
+// assigns bus and unit numbers to the disks that don't have them
+func validateVmSpecDisks(specDisks []*types.DiskSettings, hardwareVersion *types.VirtualHardwareVersion, os *types.OperatingSystemInfoType,
+	storageProfiles []*types.Reference, problems []string) ([]*types.DiskSettings, []string) {
+	addProblem := func(format string, args ...any) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	used := make(map[string]bool)
+	for index, disk := range specDisks {
+		adapter := findHardDiskAdapter(hardwareVersion.HardDiskAdapter, disk.AdapterType)
+		if adapter == nil || disk.BusNumber < 0 {
+			continue
+		}
+		key := diskSlotKey(adapter, disk.BusNumber, disk.UnitNumber)
+		if used[key] {
+			addProblem("disk %d: bus %d and unit %d are already used on disk controller '%s'", index, disk.BusNumber, disk.UnitNumber, adapter.Name)
+		}
+		used[key] = true
+	}
+
+	var disks []*types.DiskSettings
+	for index, specDisk := range specDisks {
+		disk := *specDisk
+		if disk.AdapterType == "" && os != nil {
+			disk.AdapterType = os.DefaultHardDiskAdapterType
+		}
+		if disk.SizeMb <= 0 {
+			addProblem("disk %d: size must be greater than 0", index)
+		}
+
+		adapter := findHardDiskAdapter(hardwareVersion.HardDiskAdapter, disk.AdapterType)
+		if adapter == nil {
+			if disk.AdapterType != "" {
+				addProblem("disk %d: disk controller '%s' is not supported by hardware version %s", index, disk.AdapterType, hardwareVersion.Name)
+			}
+			disks = append(disks, &disk)
+			continue
+		}
+		disk.AdapterType = strconv.Itoa(adapter.LegacyId)
+		if os != nil && len(os.SupportedHardDiskAdapter) > 0 {
+			supported := false
+			for _, supportedAdapter := range os.SupportedHardDiskAdapter {
+				supported = supported || supportedAdapter.Ref == adapter.Id
+			}
+			if !supported {
+				addProblem("disk %d: disk controller '%s' is not supported by OS type '%s'", index, adapter.Name, os.InternalName)
+			}
+		}
+		if adapter.MaximumDiskSizeGb > 0 && disk.SizeMb > int64(adapter.MaximumDiskSizeGb)*1024 {
+			addProblem("disk %d: disk controller '%s' supports disks up to %d GB", index, adapter.Name, adapter.MaximumDiskSizeGb)
+		}
+
+		if disk.BusNumber < 0 {
+			if !assignDiskBusUnit(&disk, adapter, used) {
+				addProblem("disk %d: no free slot left on disk controller '%s'", index, adapter.Name)
+			}
+		} else if disk.BusNumber < adapter.BusNumberRanges.Begin || disk.BusNumber > adapter.BusNumberRanges.End ||
+			disk.UnitNumber < adapter.UnitNumberRanges.Begin || disk.UnitNumber > adapter.UnitNumberRanges.End ||
+			isReservedBusUnit(adapter, disk.BusNumber, disk.UnitNumber) {
+			addProblem("disk %d: bus %d and unit %d are not valid for disk controller '%s'", index, disk.BusNumber, disk.UnitNumber, adapter.Name)
+		}
+
+		if disk.StorageProfile != nil && disk.StorageProfile.HREF == "" {
+			storageProfile := findReferenceByName(storageProfiles, disk.StorageProfile.Name)
+			if storageProfile == nil {
+				addProblem("disk %d: storage profile '%s' is not available in the VDC", index, disk.StorageProfile.Name)
+			} else {
+				disk.StorageProfile = storageProfile
+				disk.OverrideVmDefault = true
+			}
+		}
+		disks = append(disks, &disk)
+	}
+	return disks, problems
+}
+
+// assignDiskBusUnit sets the first free bus and unit number of the adapter to the disk
+func assignDiskBusUnit(disk *types.DiskSettings, adapter *types.HardDiskAdapter, used map[string]bool) bool {
+	for bus := adapter.BusNumberRanges.Begin; bus <= adapter.BusNumberRanges.End; bus++ {
+		for unit := adapter.UnitNumberRanges.Begin; unit <= adapter.UnitNumberRanges.End; unit++ {
+			key := diskSlotKey(adapter, bus, unit)
+			if used[key] || isReservedBusUnit(adapter, bus, unit) {
+				continue
+			}
+			used[key] = true
+			disk.BusNumber, disk.UnitNumber = bus, unit
+			return true
+		}
+	}
+	return false
+}
+
+// diskSlotKey identifies a bus and unit number of a disk controller
+func diskSlotKey(adapter *types.HardDiskAdapter, bus, unit int) string {
+	return fmt.Sprintf("%s/%d/%d", adapter.Id, bus, unit)
+}
+
+// isReservedBusUnit returns true if the bus and unit numbers are reserved by the controller, such as unit 7 of SCSI
+// controllers
+func isReservedBusUnit(adapter *types.HardDiskAdapter, bus, unit int) bool {
+	reserved := adapter.ReservedBusUnitNumber
+	return (reserved.BusNumber != 0 || reserved.UnitNumber != 0) && reserved.BusNumber == bus && reserved.UnitNumber == unit
+}
+
+// findHardDiskAdapter finds a disk controller by ID or legacy ID
+func findHardDiskAdapter(adapters []*types.HardDiskAdapter, adapterType string) *types.HardDiskAdapter {
+	for _, adapter := range adapters {
+		if adapter.Id == adapterType || strconv.Itoa(adapter.LegacyId) == adapterType {
+			return adapter
+		}
+	}
+	return nil
+}
+
+// findVmSpecComputePolicy finds a compute policy by ID or name
+func findVmSpecComputePolicy(policies []vmSpecComputePolicy, nameOrId string) *vmSpecComputePolicy {
+	for index := range policies {
+		if policies[index].reference.ID == nameOrId || policies[index].reference.Name == nameOrId {
+			return &policies[index]
+		}
+	}
+	return nil
+}
+
+// hardwareVersionNumber returns the number of a hardware version name, such as 19 for "vmx-19"
+func hardwareVersionNumber(name string) int {
+	number, _ := strconv.Atoi(strings.TrimPrefix(name, "vmx-"))
+	return number
+}
+
+// containsFold returns true if the slice contains the value, ignoring case
+func containsFold(value string, slice []string) bool {
+	for _, item := range slice {
+		if strings.EqualFold(item, value) {
+			return true
+		}
+	}
+	return false
+}
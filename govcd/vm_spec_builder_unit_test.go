@@ -0,0 +1,151 @@
+//go:build unit || ALL
+
+/*
+ * Copyright 2025 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+package govcd
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// buildVmSpecTarget returns a VDC offering hardware version vmx-19 with Ubuntu, a storage profile, a sizing policy
+// and a network
+func buildVmSpecTarget() vmSpecTarget {
+	adapter := &types.HardDiskAdapter{Id: "lsilogicsas", LegacyId: 4, Name: "LSI Logic SAS", MaximumDiskSizeGb: 62 * 1024}
+	adapter.BusNumberRanges.End = 3
+	adapter.UnitNumberRanges.End = 15
+	adapter.ReservedBusUnitNumber.BusNumber = 0
+	adapter.ReservedBusUnitNumber.UnitNumber = 7
+
+	ide := &types.HardDiskAdapter{Id: "ide", LegacyId: 1, Name: "IDE", MaximumDiskSizeGb: 8 * 1024}
+	ide.BusNumberRanges.End = 1
+	ide.UnitNumberRanges.End = 1
+
+	ubuntu := &types.OperatingSystemInfoType{
+		InternalName:               "ubuntu64Guest",
+		DefaultHardDiskAdapterType: "lsilogicsas",
+		MinimumMemoryMegabytes:     addrOf(512),
+		MaximumCpuCount:            addrOf(64),
+		X64:                        addrOf(true),
+		SupportedFirmware:          []string{"bios", "efi"},
+		PersonalizationEnabled:     addrOf(true),
+		SupportedForCreate:         addrOf(true),
+	}
+	ubuntu.SupportedHardDiskAdapter = append(ubuntu.SupportedHardDiskAdapter, struct {
+		Ref string `xml:"ref,attr"`
+	}{Ref: "lsilogicsas"})
+
+	return vmSpecTarget{
+		hardwareVersion: &types.VirtualHardwareVersion{
+			Name:              "vmx-19",
+			MaxCPUs:           128,
+			MaxCoresPerSocket: 64,
+			MaxMemorySizeMb:   6 * 1024 * 1024,
+			MaxNICs:           10,
+			HardDiskAdapter:   []*types.HardDiskAdapter{adapter, ide},
+			SupportedOperatingSystems: &types.SupportedOperatingSystemsInfoType{
+				OperatingSystemFamilyInfo: []*types.OperatingSystemFamilyInfoType{
+					{Name: "Linux", OperatingSystems: []*types.OperatingSystemInfoType{ubuntu}},
+				},
+			},
+		},
+		os:              ubuntu,
+		storageProfiles: []*types.Reference{{HREF: "https://vcd/api/vdcStorageProfile/1", Name: "gold"}},
+		computePolicies: []vmSpecComputePolicy{
+			{
+				reference: &types.Reference{HREF: "https://vcd/policy/small", ID: "urn:vcloud:vdcComputePolicy:small", Name: "small"},
+				policy:    &types.VdcComputePolicyV2{VdcComputePolicy: types.VdcComputePolicy{Name: "small", CPUCount: addrOf(2), Memory: addrOf(2048), IsSizingOnly: true}},
+			},
+		},
+		networks: []string{"web"},
+	}
+}
+
+// Test_validateVmSpec checks the VM settings computed from a valid specification
+func Test_validateVmSpec(t *testing.T) {
+	spec := NewVmSpec().WithName("web01").WithOs("ubuntu64Guest").WithFirmware("efi").WithSizingPolicy("small").
+		WithDisk(20480).WithDisk(1024).WithDiskSettings(types.DiskSettings{SizeMb: 2048, AdapterType: "lsilogicsas", BusNumber: 0, UnitNumber: 0,
+		StorageProfile: &types.Reference{Name: "gold"}}).
+		WithNic("web", types.IPAllocationModePool).WithNic("none", "").WithStorageProfile("gold")
+
+	settings, err := validateVmSpec(spec, buildVmSpecTarget())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	section := settings.vmSpecSection
+	if *section.NumCpus != 2 || section.MemoryResourceMb.Configured != 2048 || section.VirtualCpuType != "VM64" ||
+		section.HardwareVersion.Value != "vmx-19" {
+		t.Fatalf("unexpected VM specification %+v", section)
+	}
+	var slots [][2]int
+	for _, disk := range section.DiskSection.DiskSettings {
+		if disk.AdapterType != "4" {
+			t.Fatalf("unexpected adapter type '%s'", disk.AdapterType)
+		}
+		slots = append(slots, [2]int{disk.BusNumber, disk.UnitNumber})
+	}
+	if !reflect.DeepEqual(slots, [][2]int{{0, 1}, {0, 2}, {0, 0}}) {
+		t.Fatalf("unexpected disk slots %v", slots)
+	}
+	if section.DiskSection.DiskSettings[2].StorageProfile.HREF != "https://vcd/api/vdcStorageProfile/1" {
+		t.Fatalf("disk storage profile not resolved")
+	}
+	if settings.storageProfile.Name != "gold" || settings.computePolicy.VmSizingPolicy.ID != "urn:vcloud:vdcComputePolicy:small" {
+		t.Fatalf("unexpected storage profile or compute policy")
+	}
+	nics := settings.networks.NetworkConnection
+	if len(nics) != 2 || nics[1].NetworkConnectionIndex != 1 || nics[1].IsConnected || nics[1].IPAddressAllocationMode != types.IPAllocationModeNone {
+		t.Fatalf("unexpected NICs %+v", nics)
+	}
+}
+
+// Test_validateVmSpecProblems checks that invalid specifications report all their problems
+func Test_validateVmSpecProblems(t *testing.T) {
+	spec := NewVmSpec().WithName("web01").WithOs("ubuntu64Guest").WithCpu(6, 4).WithMemory(256).WithSizingPolicy("small").
+		WithPlacementPolicy("small").WithDiskSettings(types.DiskSettings{SizeMb: 1024, AdapterType: "ide", BusNumber: 0, UnitNumber: 0}).
+		WithDiskSettings(types.DiskSettings{SizeMb: 1024, AdapterType: "4", BusNumber: 0, UnitNumber: 7}).
+		WithNic("db", types.IPAllocationModeManual).WithStorageProfile("silver").
+		WithBootOptions(types.BootOptions{EfiSecureBootEnabled: addrOf(true)})
+
+	_, err := validateVmSpec(spec, buildVmSpecTarget())
+	var validationError *VmSpecValidationError
+	if !errors.As(err, &validationError) {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+	expected := []string{
+		"compute policy 'small' is a sizing policy and cannot be used for placement",
+		"storage profile 'silver' is not available in the VDC",
+		"NIC 0: an IP address is required with MANUAL allocation",
+		"NIC 0: network 'db' is not available",
+		"CPU count 6 conflicts with sizing policy 'small', which sets 2",
+		"memory size 256 MB conflicts with sizing policy 'small', which sets 2048 MB",
+		"CPU count 2 is not a multiple of cores per socket 4",
+		"EFI secure boot requires 'efi' firmware",
+		"disk 0: disk controller 'IDE' is not supported by OS type 'ubuntu64Guest'",
+		"disk 1: bus 0 and unit 7 are not valid for disk controller 'LSI Logic SAS'",
+	}
+	if !reflect.DeepEqual(validationError.Problems, expected) {
+		t.Fatalf("unexpected problems:\n%v\nexpected:\n%v", validationError.Problems, expected)
+	}
+
+	target := buildVmSpecTarget()
+	target.os = nil
+	_, err = validateVmSpec(NewVmSpec().WithName("web01").WithOs("windows2019srv_64Guest"), target)
+	expected = []string{"memory size is required when it is not set by a sizing policy",
+		"OS type 'windows2019srv_64Guest' is not supported by hardware version vmx-19"}
+	if !errors.As(err, &validationError) || !reflect.DeepEqual(validationError.Problems, expected) {
+		t.Fatalf("expected missing memory and unsupported OS to be reported, got %v", err)
+	}
+
+	_, err = validateVmSpec(NewVmSpec().WithName("web01").WithMemory(1024), vmSpecTarget{fromTemplate: true})
+	if !errors.As(err, &validationError) || len(validationError.Problems) != 1 {
+		t.Fatalf("expected hardware settings to be rejected for templates, got %v", err)
+	}
+}
@@ -0,0 +1,278 @@
+/*
+ * Copyright 2025 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+package govcd
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// importableVmsPageSize is the page size used when listing the VMs of a vCenter that can be imported
+const importableVmsPageSize = 128
+
+// VmImportParams defines how a vCenter VM is imported into a VDC
+type VmImportParams struct {
+	// Name is the name of the new vApp for Vdc.ImportVmFromVCenter, or the name of the imported VM for
+	// Vdc.ImportVmIntoExistingVApp
+	Name        string
+	Description string
+	// VmMoRef is the managed object reference of the vCenter VM, as returned by VCenter.GetAllImportableVms
+	VmMoRef string
+	// Move removes the VM from its original vCenter location. When false, the VM is copied and the original
+	// one is left untouched
+	Move bool
+	// StorageProfileName is the VDC storage profile used by the imported VM. The VDC default one is used when empty
+	StorageProfileName string
+	// NicNetworks maps NIC indexes of the imported VM to Org VDC network names. The import API does not handle
+	// networks, so the mapping is applied by Vdc.ConnectImportedVmNics once the import task is complete
+	NicNetworks map[int]string
+}
+
+// GetAllImportableVms retrieves the VMs of the vCenter that are not managed by VCD and can be imported
+func (v *VCenter) GetAllImportableVms() ([]*types.VmObjectRef, error) {
+	vimServerUrl, err := v.GetVimServerUrl()
+	if err != nil {
+		return nil, fmt.Errorf("error building vCenter URL: %s", err)
+	}
+
+	var vms []*types.VmObjectRef
+	for page := 1; ; page++ {
+		queryParams := url.Values{}
+		queryParams.Set("page", strconv.Itoa(page))
+		queryParams.Set("pageSize", strconv.Itoa(importableVmsPageSize))
+
+		vmList := &types.VmObjectRefsList{}
+		_, err = v.client.Client.ExecuteRequest(vimServerUrl+"/vmsList?"+queryParams.Encode(), http.MethodGet,
+			types.MimeVmObjectRefsList, "error retrieving importable VMs: %s", nil, vmList)
+		if err != nil {
+			return nil, err
+		}
+		vms = append(vms, vmList.VmObjectRef...)
+
+		if len(vmList.VmObjectRef) == 0 || len(vms) >= vmList.Total {
+			break
+		}
+	}
+
+	return vms, nil
+}
+
+// GetImportableVmByName retrieves a VM of the vCenter that can be imported by its name
+func (v *VCenter) GetImportableVmByName(name string) (*types.VmObjectRef, error) {
+	if name == "" {
+		return nil, fmt.Errorf("empty importable VM name specified")
+	}
+	vms, err := v.GetAllImportableVms()
+	if err != nil {
+		return nil, err
+	}
+
+	var found []*types.VmObjectRef
+	for _, vm := range vms {
+		if vm.Name == name {
+			found = append(found, vm)
+		}
+	}
+	return oneOrError("name", name, found)
+}
+
+// ImportVmFromVCenter imports a VM from the given vCenter into the VDC as a new vApp named params.Name. The VM
+// keeps its vCenter name. Once the returned task is complete, call ConnectImportedVmNics to apply
+// params.NicNetworks.
+func (vdc *Vdc) ImportVmFromVCenter(vcenter *VCenter, params VmImportParams) (Task, error) {
+	storageProfile, err := vdc.validateVmImportParams(vcenter, params)
+	if err != nil {
+		return Task{}, err
+	}
+
+	importParams := &types.ImportVmAsVAppParams{
+		Xmlns:             types.XMLNamespaceExtension,
+		Name:              params.Name,
+		SourceMove:        params.Move,
+		Description:       params.Description,
+		VmMoRef:           params.VmMoRef,
+		Vdc:               &types.Reference{HREF: vdc.Vdc.HREF},
+		VdcStorageProfile: storageProfile,
+	}
+
+	vimServerUrl, err := vcenter.GetVimServerUrl()
+	if err != nil {
+		return Task{}, fmt.Errorf("error building vCenter URL: %s", err)
+	}
+	vapp := NewVApp(vdc.client)
+	_, err = vdc.client.ExecuteRequest(vimServerUrl+"/importVmAsVApp", http.MethodPost,
+		types.MimeImportVmAsVApp, "error importing VM as vApp: %s", importParams, vapp.VApp)
+	if err != nil {
+		return Task{}, err
+	}
+	if vapp.VApp.Tasks == nil || len(vapp.VApp.Tasks.Task) == 0 {
+		return Task{}, fmt.Errorf("no task found after importing VM '%s' as vApp '%s'", params.VmMoRef, params.Name)
+	}
+
+	task := NewTask(vdc.client)
+	task.Task = vapp.VApp.Tasks.Task[0]
+	return *task, nil
+}
+
+// ImportVmIntoExistingVApp imports a VM from the given vCenter into an existing vApp of the VDC. The imported VM is
+// named params.Name. Once the returned task is complete, call ConnectImportedVmNics to apply params.NicNetworks.
+func (vdc *Vdc) ImportVmIntoExistingVApp(vcenter *VCenter, vapp *VApp, params VmImportParams) (Task, error) {
+	if vapp == nil || vapp.VApp == nil || vapp.VApp.HREF == "" {
+		return Task{}, fmt.Errorf("a vApp with HREF is required to import a VM into it")
+	}
+	storageProfile, err := vdc.validateVmImportParams(vcenter, params)
+	if err != nil {
+		return Task{}, err
+	}
+
+	importParams := &types.ImportVmIntoExistingVAppParams{
+		Xmlns:             types.XMLNamespaceExtension,
+		Name:              params.Name,
+		SourceMove:        params.Move,
+		Description:       params.Description,
+		VmMoRef:           params.VmMoRef,
+		VApp:              &types.Reference{HREF: vapp.VApp.HREF},
+		VdcStorageProfile: storageProfile,
+	}
+
+	vimServerUrl, err := vcenter.GetVimServerUrl()
+	if err != nil {
+		return Task{}, fmt.Errorf("error building vCenter URL: %s", err)
+	}
+	return vdc.client.ExecuteTaskRequest(vimServerUrl+"/importVmIntoExistingVApp", http.MethodPost,
+		types.MimeImportVmIntoExistingVApp, "error importing VM into existing vApp: %s", importParams)
+}
+
+// ConnectImportedVmNics connects the NICs of a VM imported from vCenter to Org VDC networks, following the
+// nicNetworks map of NIC indexes to network names. Org VDC networks that are not yet part of the vApp are added to
+// it as bridged vApp networks. Returns the updated VM.
+func (vdc *Vdc) ConnectImportedVmNics(vapp *VApp, vmName string, nicNetworks map[int]string) (*VM, error) {
+	err := vapp.Refresh()
+	if err != nil {
+		return nil, fmt.Errorf("error refreshing vApp: %s", err)
+	}
+	vm, err := vapp.GetVMByName(vmName, false)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving imported VM '%s': %s", vmName, err)
+	}
+	if len(nicNetworks) == 0 {
+		return vm, nil
+	}
+
+	networkConfig, err := vapp.GetNetworkConfig()
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving vApp network configuration: %s", err)
+	}
+	var vappNetworks []string
+	for _, network := range networkConfig.NetworkConfig {
+		vappNetworks = append(vappNetworks, network.NetworkName)
+	}
+
+	for _, networkName := range sortedNicNetworks(nicNetworks) {
+		if contains(networkName, vappNetworks) {
+			continue
+		}
+		orgNetwork, err := vdc.GetOrgVdcNetworkByName(networkName, false)
+		if err != nil {
+			return nil, fmt.Errorf("error retrieving Org VDC network '%s': %s", networkName, err)
+		}
+		_, err = vapp.AddOrgNetwork(&VappNetworkSettings{}, orgNetwork.OrgVDCNetwork, false)
+		if err != nil {
+			return nil, fmt.Errorf("error adding Org VDC network '%s' to vApp '%s': %s", networkName, vapp.VApp.Name, err)
+		}
+		vappNetworks = append(vappNetworks, networkName)
+	}
+
+	networkConnectionSection, err := vm.GetNetworkConnectionSection()
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving network connections of VM '%s': %s", vmName, err)
+	}
+	err = applyNicNetworkMapping(networkConnectionSection, nicNetworks)
+	if err != nil {
+		return nil, fmt.Errorf("error mapping networks of VM '%s': %s", vmName, err)
+	}
+	err = vm.UpdateNetworkConnectionSection(networkConnectionSection)
+	if err != nil {
+		return nil, fmt.Errorf("error updating network connections of VM '%s': %s", vmName, err)
+	}
+
+	err = vm.Refresh()
+	if err != nil {
+		return nil, fmt.Errorf("error refreshing VM '%s': %s", vmName, err)
+	}
+	return vm, nil
+}
+
+// validateVmImportParams checks the import parameters against the VDC and returns the reference of the storage
+// profile to use, which is nil for the VDC default one
+func (vdc *Vdc) validateVmImportParams(vcenter *VCenter, params VmImportParams) (*types.Reference, error) {
+	if vcenter == nil || vcenter.VSphereVCenter == nil {
+		return nil, fmt.Errorf("a vCenter is required to import a VM")
+	}
+	if params.Name == "" {
+		return nil, fmt.Errorf("a name is required to import a VM")
+	}
+	if params.VmMoRef == "" {
+		return nil, fmt.Errorf("the managed object reference of the VM to import is required")
+	}
+	for index, networkName := range params.NicNetworks {
+		if index < 0 {
+			return nil, fmt.Errorf("invalid NIC index %d", index)
+		}
+		_, err := vdc.GetOrgVdcNetworkByName(networkName, false)
+		if err != nil {
+			return nil, fmt.Errorf("error retrieving Org VDC network '%s' for NIC %d: %s", networkName, index, err)
+		}
+	}
+
+	if params.StorageProfileName == "" {
+		return nil, nil
+	}
+	storageProfile, err := vdc.FindStorageProfileReference(params.StorageProfileName)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving storage profile '%s': %s", params.StorageProfileName, err)
+	}
+	return &storageProfile, nil
+}
+
+// applyNicNetworkMapping connects the NICs listed in nicNetworks to the given networks. NICs that have no IP
+// allocation mode set are switched to DHCP, as VCD rejects connected NICs without one.
+func applyNicNetworkMapping(section *types.NetworkConnectionSection, nicNetworks map[int]string) error {
+	for index, networkName := range nicNetworks {
+		var connection *types.NetworkConnection
+		for _, candidate := range section.NetworkConnection {
+			if candidate.NetworkConnectionIndex == index {
+				connection = candidate
+				break
+			}
+		}
+		if connection == nil {
+			return fmt.Errorf("NIC %d not found", index)
+		}
+		connection.Network = networkName
+		connection.IsConnected = true
+		if connection.IPAddressAllocationMode == "" || connection.IPAddressAllocationMode == types.IPAllocationModeNone {
+			connection.IPAddressAllocationMode = types.IPAllocationModeDHCP
+		}
+	}
+	return nil
+}
+
+// sortedNicNetworks returns the distinct network names of a NIC mapping, sorted
+func sortedNicNetworks(nicNetworks map[int]string) []string {
+	var names []string
+	for _, name := range nicNetworks {
+		if !contains(name, names) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
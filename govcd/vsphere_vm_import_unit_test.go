@@ -0,0 +1,95 @@
+//go:build unit || ALL
+
+/*
+ * Copyright 2025 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+package govcd
+
+import (
+	"encoding/xml"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/vmware/go-vcloud-director/v3/types/v56"
+)
+
+// Test_applyNicNetworkMapping checks that NICs are connected to the mapped networks
+func Test_applyNicNetworkMapping(t *testing.T) {
+	section := &types.NetworkConnectionSection{NetworkConnection: []*types.NetworkConnection{
+		{Network: types.NoneNetwork, NetworkConnectionIndex: 0, IPAddressAllocationMode: types.IPAllocationModeNone},
+		{Network: types.NoneNetwork, NetworkConnectionIndex: 1, IPAddressAllocationMode: types.IPAllocationModeManual, IPAddress: "10.0.0.5"},
+		{Network: types.NoneNetwork, NetworkConnectionIndex: 2},
+	}}
+
+	err := applyNicNetworkMapping(section, map[int]string{0: "backend", 1: "frontend"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	first, second, third := section.NetworkConnection[0], section.NetworkConnection[1], section.NetworkConnection[2]
+	if first.Network != "backend" || !first.IsConnected || first.IPAddressAllocationMode != types.IPAllocationModeDHCP {
+		t.Fatalf("unexpected NIC 0 %+v", first)
+	}
+	if second.Network != "frontend" || !second.IsConnected || second.IPAddressAllocationMode != types.IPAllocationModeManual {
+		t.Fatalf("unexpected NIC 1 %+v", second)
+	}
+	if third.Network != types.NoneNetwork || third.IsConnected {
+		t.Fatalf("unmapped NIC 2 was modified %+v", third)
+	}
+
+	err = applyNicNetworkMapping(section, map[int]string{3: "backend"})
+	if err == nil || err.Error() != "NIC 3 not found" {
+		t.Fatalf("expected error for missing NIC, got %v", err)
+	}
+
+	names := sortedNicNetworks(map[int]string{0: "b", 1: "a", 2: "b"})
+	if !reflect.DeepEqual(names, []string{"a", "b"}) {
+		t.Fatalf("unexpected network names %v", names)
+	}
+}
+
+// Test_VmImportXml checks marshalling of import parameters and unmarshalling of importable VM lists
+func Test_VmImportXml(t *testing.T) {
+	params := &types.ImportVmAsVAppParams{
+		Xmlns:             types.XMLNamespaceExtension,
+		Name:              "legacy-app",
+		SourceMove:        true,
+		VmMoRef:           "vm-42",
+		Vdc:               &types.Reference{HREF: "https://vcd.example.com/api/vdc/1"},
+		VdcStorageProfile: &types.Reference{HREF: "https://vcd.example.com/api/vdcStorageProfile/gold"},
+	}
+	output, err := xml.Marshal(params)
+	if err != nil {
+		t.Fatalf("error marshalling import parameters: %s", err)
+	}
+	for _, expected := range []string{`name="legacy-app" sourceMove="true"`, `<VmMoRef>vm-42</VmMoRef>`,
+		`<Vdc href="https://vcd.example.com/api/vdc/1"`, `<VdcStorageProfile href="https://vcd.example.com/api/vdcStorageProfile/gold"`} {
+		if !strings.Contains(string(output), expected) {
+			t.Fatalf("expected '%s' in:\n%s", expected, output)
+		}
+	}
+
+	existing, err := xml.Marshal(&types.ImportVmIntoExistingVAppParams{Name: "db", VmMoRef: "vm-43",
+		VApp: &types.Reference{HREF: "https://vcd.example.com/api/vApp/vapp-1"}})
+	if err != nil {
+		t.Fatalf("error marshalling import parameters: %s", err)
+	}
+	if !strings.Contains(string(existing), `sourceMove="false"`) || strings.Contains(string(existing), "VdcStorageProfile") {
+		t.Fatalf("unexpected import parameters:\n%s", existing)
+	}
+
+	input := `<VmObjectRefsList xmlns="http://www.vmware.com/vcloud/extension/v1.5" page="1" pageSize="128" total="2">
+  <VmObjectRef name="web01"><VimServerRef href="https://vcd.example.com/api/admin/extension/vimServer/1"/><MoRef>vm-42</MoRef><VimObjectType>VIRTUAL_MACHINE</VimObjectType></VmObjectRef>
+  <VmObjectRef name="db01"><MoRef>vm-43</MoRef><VimObjectType>VIRTUAL_MACHINE</VimObjectType></VmObjectRef>
+</VmObjectRefsList>`
+	list := &types.VmObjectRefsList{}
+	err = xml.Unmarshal([]byte(input), list)
+	if err != nil {
+		t.Fatalf("error unmarshalling VM list: %s", err)
+	}
+	if list.Total != 2 || len(list.VmObjectRef) != 2 || list.VmObjectRef[0].Name != "web01" || list.VmObjectRef[0].MoRef != "vm-42" ||
+		list.VmObjectRef[0].VimServerRef == nil || list.VmObjectRef[1].Name != "db01" {
+		t.Fatalf("unexpected VM list %+v", list)
+	}
+}
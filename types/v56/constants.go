@@ -106,6 +106,16 @@ const (
 	MimeCaptureVappTemplateParams = "application/vnd.vmware.vcloud.captureVAppParams+xml"
 	// Mime for clone vApp template params
 	MimeCloneVapp = "application/vnd.vmware.vcloud.cloneVAppParams+xml"
+	// Mime for moving a vApp to another VDC
+	MimeMoveVapp = "application/vnd.vmware.vcloud.MoveVAppParams+xml"
+	// Mime for relocating a VM to another datastore
+	MimeRelocateVm = "application/vnd.vmware.vcloud.relocateVmParams+xml"
+	// Mime for the list of vCenter VMs that can be imported
+	MimeVmObjectRefsList = "application/vnd.vmware.admin.vmObjectRefsList+xml"
+	// Mime for importing a vCenter VM as a new vApp
+	MimeImportVmAsVApp = "application/vnd.vmware.admin.importVmAsVAppParams+xml"
+	// Mime for importing a vCenter VM into an existing vApp
+	MimeImportVmIntoExistingVApp = "application/vnd.vmware.admin.importVmIntoExistingVAppParams+xml"
 	// Mime for product section
 	MimeProductSection = "application/vnd.vmware.vcloud.productSections+xml"
 	// Mime for metadata
@@ -140,6 +150,8 @@ const (
 	MimeUpdateVdcStorageProfiles = "application/vnd.vmware.admin.updateVdcStorageProfiles+xml"
 	// Mime to modify lease settings
 	MimeLeaseSettingSection = "application/vnd.vmware.vcloud.leaseSettingsSection+xml"
+	// Mime to modify vApp startup section
+	MimeStartupSection = "application/vnd.vmware.vcloud.startupSection+xml"
 	// Mime to publish external catalog
 	PublishExternalCatalog = "application/vnd.vmware.admin.publishExternalCatalogParams+xml"
 	// Mime to publish a catalog
@@ -196,6 +208,15 @@ const (
 	FenceModeNAT      = "natRouted"
 )
 
+// Values used in StartupSectionItem actions
+const (
+	StartupActionPowerOn    = "powerOn"
+	StartupActionNone       = "none"
+	StopActionPowerOff      = "powerOff"
+	StopActionGuestShutdown = "guestShutdown"
+	StopActionNone          = "none"
+)
+
 const (
 	IPAllocationModeDHCP   = "DHCP"
 	IPAllocationModeManual = "MANUAL"
@@ -303,6 +324,8 @@ const (
 	QtOrgAssociation            = "orgAssociation"
 	QtAdminOrgVdcTemplate       = "adminOrgVdcTemplate"
 	QtOrgVdcTemplate            = "orgVdcTemplate"
+	QtDisk                      = "disk"      // Independent disk
+	QtAdminDisk                 = "adminDisk" // Independent disk as admin
 )
 
 // AdminQueryTypes returns the corresponding "admin" query type for each regular type
@@ -317,6 +340,7 @@ var AdminQueryTypes = map[string]string{
 	QtVm:            QtAdminVm,
 	QtVapp:          QtAdminVapp,
 	QtOrgVdc:        QtAdminOrgVdc,
+	QtDisk:          QtAdminDisk,
 }
 
 const (
@@ -439,9 +463,10 @@ const (
 	OpenApiEndpointSecurityTags                       = "securityTags"
 	OpenApiEndpointNsxtRouteAdvertisement             = "edgeGateways/%s/routing/advertisement"
 	OpenApiEndpointTestConnection                     = "testConnection/"
-	OpenApiEndpointEdgeBgpNeighbor                    = "edgeGateways/%s/routing/bgp/neighbors/"   // '%s' is NSX-T Edge Gateway ID
-	OpenApiEndpointEdgeBgpConfigPrefixLists           = "edgeGateways/%s/routing/bgp/prefixLists/" // '%s' is NSX-T Edge Gateway ID
-	OpenApiEndpointEdgeBgpConfig                      = "edgeGateways/%s/routing/bgp"              // '%s' is NSX-T Edge Gateway ID
+	OpenApiEndpointEdgeBgpNeighbor                    = "edgeGateways/%s/routing/bgp/neighbors/"          // '%s' is NSX-T Edge Gateway ID
+	OpenApiEndpointEdgeBgpConfigPrefixLists           = "edgeGateways/%s/routing/bgp/prefixLists/"        // '%s' is NSX-T Edge Gateway ID
+	OpenApiEndpointEdgeBgpConfig                      = "edgeGateways/%s/routing/bgp"                     // '%s' is NSX-T Edge Gateway ID
+	OpenApiEndpointEdgeBgpNeighborStatus              = "edgeGateways/%s/routing/bgp/neighbors/%s/status" // '%s' is NSX-T Edge Gateway ID, '%s' is BGP Neighbor ID
 	OpenApiEndpointRdeInterfaces                      = "interfaces/"
 	OpenApiEndpointRdeInterfaceBehaviors              = "interfaces/%s/behaviors/"
 	OpenApiEndpointRdeEntityTypes                     = "entityTypes/"
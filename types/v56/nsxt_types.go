@@ -1661,6 +1661,14 @@ type EdgeBgpNeighbor struct {
 	Bfd *EdgeBgpNeighborBfd `json:"bfd,omitempty"`
 }
 
+// EdgeBgpNeighborStatus holds the connection state of a BGP neighbor as reported by NSX-T
+type EdgeBgpNeighborStatus struct {
+	// ConnectionState is the BGP session state.
+	//
+	// Possible values are: UNKNOWN, IDLE, CONNECT, ACTIVE, OPEN_SENT, OPEN_CONFIRM, ESTABLISHED
+	ConnectionState string `json:"connectionState"`
+}
+
 // EdgeBgpNeighborBfd describes BFD (Bidirectional Forwarding Detection) configuration for failure detection.
 type EdgeBgpNeighborBfd struct {
 	// A flag indicating whether BFD configuration is enabled or not.
@@ -1640,12 +1640,56 @@ type Property struct {
 	Value            *Value `xml:"http://schemas.dmtf.org/ovf/envelope/1 Value,omitempty"`
 	Type             string `xml:"http://schemas.dmtf.org/ovf/envelope/1 type,attr,omitempty"`
 	UserConfigurable bool   `xml:"http://schemas.dmtf.org/ovf/envelope/1 userConfigurable,attr"`
+	Qualifiers       string `xml:"http://schemas.dmtf.org/ovf/envelope/1 qualifiers,attr,omitempty"` // Constraints on the value, such as MinLen(1) or ValueMap{"a","b"}
+	Password         bool   `xml:"http://schemas.dmtf.org/ovf/envelope/1 password,attr,omitempty"`   // True if the value is a password and should be hidden
 }
 
 type Value struct {
 	Value string `xml:"http://schemas.dmtf.org/ovf/envelope/1 value,attr,omitempty"`
 }
 
+// StartupSection specifies the order in which VMs of a vApp are started and stopped, and the delays between them.
+// VMs with a lower Order are started first and stopped last. VMs sharing the same Order are started together.
+// Type: StartupSection_Type
+// Namespace: http://schemas.dmtf.org/ovf/envelope/1
+type StartupSection struct {
+	XMLName xml.Name              `xml:"http://schemas.dmtf.org/ovf/envelope/1 StartupSection"`
+	HREF    string                `xml:"http://www.vmware.com/vcloud/v1.5 href,attr,omitempty"`
+	Type    string                `xml:"http://www.vmware.com/vcloud/v1.5 type,attr,omitempty"`
+	Info    string                `xml:"http://schemas.dmtf.org/ovf/envelope/1 Info"`
+	Item    []*StartupSectionItem `xml:"http://schemas.dmtf.org/ovf/envelope/1 Item,omitempty"`
+	Link    LinkList              `xml:"http://www.vmware.com/vcloud/v1.5 Link,omitempty"`
+}
+
+// StartupSectionItem holds the startup and shutdown settings of a single VM in a vApp
+type StartupSectionItem struct {
+	// ID is the name of the VM
+	ID string `xml:"http://schemas.dmtf.org/ovf/envelope/1 id,attr"`
+	// Order is the startup order of the VM. VMs with the same order are started together
+	Order int `xml:"http://schemas.dmtf.org/ovf/envelope/1 order,attr"`
+	// StartAction is the action taken on vApp power on. Possible values are: powerOn, none
+	StartAction string `xml:"http://schemas.dmtf.org/ovf/envelope/1 startAction,attr,omitempty"`
+	// StartDelay is the time in seconds to wait after starting this VM before starting the next order
+	StartDelay int `xml:"http://schemas.dmtf.org/ovf/envelope/1 startDelay,attr"`
+	// WaitingForGuest when true, the next order is started once VMware Tools report the guest as ready, or
+	// when StartDelay expires, whichever comes first
+	WaitingForGuest bool `xml:"http://schemas.dmtf.org/ovf/envelope/1 waitingForGuest,attr"`
+	// StopAction is the action taken on vApp power off. Possible values are: powerOff, guestShutdown, none
+	StopAction string `xml:"http://schemas.dmtf.org/ovf/envelope/1 stopAction,attr,omitempty"`
+	// StopDelay is the time in seconds to wait after stopping this VM before stopping the previous order
+	StopDelay int `xml:"http://schemas.dmtf.org/ovf/envelope/1 stopDelay,attr"`
+}
+
+// GetItemByVmName returns the startup settings of the VM with the given name, or nil if it is not found
+func (section *StartupSection) GetItemByVmName(vmName string) *StartupSectionItem {
+	for _, item := range section.Item {
+		if item.ID == vmName {
+			return item
+		}
+	}
+	return nil
+}
+
 // MetadataValue is the type returned when querying a unique entry of metadata.
 // Type: MetadataValueType
 // Namespace: http://www.vmware.com/vcloud/v1.5
@@ -2130,6 +2174,37 @@ type CloneVAppParams struct {
 	SourcedItem         *SourcedCompositionItemParam `xml:"SourcedItem,omitempty"`         // Composition item. One of: vApp vAppTemplate VM.
 }
 
+// MoveVAppParams represents parameters for moving a vApp to another VDC of the same organization.
+// Type: MoveVAppParamsType
+// Namespace: http://www.vmware.com/vcloud/v1.5
+// Description: Parameters for a moveVApp request.
+// Since: 32.0
+type MoveVAppParams struct {
+	XMLName xml.Name `xml:"MoveVAppParams"`
+	Ovf     string   `xml:"xmlns:ovf,attr"`
+	Xmlns   string   `xml:"xmlns,attr"`
+	// Elements
+	Source      *Reference                     `xml:"Source"`                // A reference to the vApp to move.
+	Sections    *MoveVAppSections              `xml:"Sections,omitempty"`    // vApp sections to apply in the target VDC, such as vApp networks.
+	SourcedItem []*SourcedCompositionItemParam `xml:"SourcedItem,omitempty"` // Settings of each VM of the vApp in the target VDC: network connections, storage profile and compute policy.
+}
+
+// MoveVAppSections holds the vApp level sections of MoveVAppParams
+type MoveVAppSections struct {
+	NetworkConfigSection *NetworkConfigSection `xml:"NetworkConfigSection,omitempty"`
+}
+
+// RelocateParams represents parameters for relocating a VM to another datastore.
+// Type: RelocateParamsType
+// Namespace: http://www.vmware.com/vcloud/v1.5
+// Description: Parameters for a relocate request.
+// Since: 1.5
+type RelocateParams struct {
+	XMLName   xml.Name   `xml:"RelocateParams"`
+	Xmlns     string     `xml:"xmlns,attr"`
+	Datastore *Reference `xml:"Datastore"` // Reference to a destination datastore.
+}
+
 // EdgeGateway represents a gateway.
 // Element: EdgeGateway
 // Type: GatewayType
@@ -3115,6 +3190,61 @@ type VimObjectRefs struct {
 	VimObjectRef []*VimObjectRef `xml:"VimObjectRef" json:"vimObjectRef"`
 }
 
+// Type: VmObjectRefType
+// Namespace: http://www.vmware.com/vcloud/extension/v1.5
+// Description: Reference to a vSphere VM that is not managed by VCD and can be imported.
+// Since: 1.0
+type VmObjectRef struct {
+	Name          string     `xml:"name,attr,omitempty"` // Name of the VM in vCenter.
+	VimServerRef  *Reference `xml:"VimServerRef"`        // The vCenter server that hosts the VM.
+	MoRef         string     `xml:"MoRef"`               // Managed object reference of the VM.
+	VimObjectType string     `xml:"VimObjectType"`       // Always VIRTUAL_MACHINE.
+}
+
+// Type: VmObjectRefsListType
+// Namespace: http://www.vmware.com/vcloud/extension/v1.5
+// Description: A paged list of VMs that are not managed by VCD.
+// Since: 1.0
+type VmObjectRefsList struct {
+	XMLName     xml.Name       `xml:"VmObjectRefsList"`
+	Page        int            `xml:"page,attr,omitempty"`     // Page of the result set.
+	PageSize    int            `xml:"pageSize,attr,omitempty"` // Page size.
+	Total       int            `xml:"total,attr,omitempty"`    // Total number of VMs.
+	VmObjectRef []*VmObjectRef `xml:"VmObjectRef,omitempty"`   // VMs in this page.
+}
+
+// Type: ImportVmAsVAppParamsType
+// Namespace: http://www.vmware.com/vcloud/extension/v1.5
+// Description: Parameters for importing a vSphere VM into a VDC as a new vApp.
+// Since: 1.0
+type ImportVmAsVAppParams struct {
+	XMLName    xml.Name `xml:"ImportVmAsVAppParams"`
+	Xmlns      string   `xml:"xmlns,attr"`
+	Name       string   `xml:"name,attr"`       // Name of the new vApp.
+	SourceMove bool     `xml:"sourceMove,attr"` // True to move the VM, false to copy it.
+	// Elements
+	Description       string     `xml:"Description,omitempty"`       // Description of the new vApp.
+	VmMoRef           string     `xml:"VmMoRef"`                     // Managed object reference of the VM to import.
+	Vdc               *Reference `xml:"Vdc"`                         // The VDC into which the VM is imported.
+	VdcStorageProfile *Reference `xml:"VdcStorageProfile,omitempty"` // Storage profile for the imported VM. Defaults to the VDC default one.
+}
+
+// Type: ImportVmIntoExistingVAppParamsType
+// Namespace: http://www.vmware.com/vcloud/extension/v1.5
+// Description: Parameters for importing a vSphere VM into an existing vApp.
+// Since: 1.5
+type ImportVmIntoExistingVAppParams struct {
+	XMLName    xml.Name `xml:"ImportVmIntoExistingVAppParams"`
+	Xmlns      string   `xml:"xmlns,attr"`
+	Name       string   `xml:"name,attr"`       // Name of the imported VM.
+	SourceMove bool     `xml:"sourceMove,attr"` // True to move the VM, false to copy it.
+	// Elements
+	Description       string     `xml:"Description,omitempty"`       // Description of the imported VM.
+	VmMoRef           string     `xml:"VmMoRef"`                     // Managed object reference of the VM to import.
+	VApp              *Reference `xml:"VApp"`                        // The vApp into which the VM is imported.
+	VdcStorageProfile *Reference `xml:"VdcStorageProfile,omitempty"` // Storage profile for the imported VM. Defaults to the VDC default one.
+}
+
 // Type: VMWExternalNetworkType
 // Namespace: http://www.vmware.com/vcloud/extension/v1.5
 // https://vdc-repo.vmware.com/vmwb-repository/dcr-public/7a028e78-bd37-4a6a-8298-9c26c7eeb9aa/09142237-dd46-4dee-8326-e07212fb63a8/doc/doc/types/VMWExternalNetworkType.html
@@ -3259,6 +3389,14 @@ type DiskAttachOrDetachParams struct {
 	VCloudExtension *VCloudExtension `xml:"VCloudExtension,omitempty"`
 }
 
+// DiskMoveParams are the parameters for moving a detached independent disk to another VDC of the same organization
+type DiskMoveParams struct {
+	XMLName        xml.Name   `xml:"DiskMoveParams"`
+	Xmlns          string     `xml:"xmlns,attr,omitempty"`
+	Vdc            *Reference `xml:"Vdc"`                      // The VDC where the disk is moved
+	StorageProfile *Reference `xml:"StorageProfile,omitempty"` // A storage profile of the target VDC
+}
+
 // Represents a list of virtual machines
 // Reference: vCloud API 30.0 - VmsType
 // https://code.vmware.com/apis/287/vcloud?h=Director#/doc/doc/types/FilesListType.html